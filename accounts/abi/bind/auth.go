@@ -80,6 +80,18 @@ func NewKeyedTransactor(key *ecdsa.PrivateKey) *TransactOpts {
 	}
 }
 
+// NewKeyedPayer is a utility method to easily create a payer signer from a
+// single private key, it is set as the PayerSigner of the sponsored transactor.
+func NewKeyedPayer(key *ecdsa.PrivateKey) SignerFn {
+	keyAddr := crypto.PubkeyToAddress(key.PublicKey)
+	return func(signer types.Signer, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if address != keyAddr {
+			return nil, errors.New("not authorized to pay for this account")
+		}
+		return types.SignTx_Payment(tx, signer, key)
+	}
+}
+
 // NewClefTransactor is a utility method to easily create a transaction signer
 // with a clef backend.
 // func NewClefTransactor(clef *external.ExternalSigner, account accounts.Account) *TransactOpts {
@@ -24,9 +24,11 @@ import (
 	"github.com/iceming123/go-ice/core"
 	"github.com/iceming123/go-ice/core/bloombits"
 	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/snailchain"
 	"github.com/iceming123/go-ice/core/state"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
 	"github.com/iceming123/go-ice/event"
 	"github.com/iceming123/go-ice/ice/filters"
 	"github.com/iceming123/go-ice/icedb"
@@ -40,16 +42,23 @@ var (
 	errBlockNumberUnsupported  = errors.New("simulatedBackend cannot access blocks other than the latest block")
 	errBlockDoesNotExist       = errors.New("block does not exist in blockchain")
 	errTransactionDoesNotExist = errors.New("transaction does not exist")
+	errFruitsUnavailable       = errors.New("not enough fast blocks for the fruits of the snail block")
 )
 
+// committeeKey is the key of the genesis committee member, it is elected in the
+// first epoch so that the fruits of the first epoch can be rewarded.
+var committeeKey, _ = crypto.HexToECDSA("d5939c73167cd3a815530fd8b4b13f1f5492c1c75e4eafb5c07e8fb7f4b09c7c")
+
 // SimulatedBackend implements bind.ContractBackend, simulating a blockchain in
 // the background. Its main purpose is to allow easily testing contract bindings.
 // Simulated backend implements the following interfaces:
 // ChainReader, ChainStateReader, ContractBackend, ContractCaller, ContractFilterer, ContractTransactor,
 // DeployBackend, GasEstimator, GasPricer, LogFilterer, PendingContractCaller, TransactionReader, and TransactionSender
 type SimulatedBackend struct {
-	database   icedb.Database   // In memory database to store our testing data
-	blockchain *core.BlockChain // Ethereum blockchain to handle the consensus
+	database   icedb.Database              // In memory database to store our testing data
+	blockchain *core.BlockChain            // Ethereum blockchain to handle the consensus
+	snailchain *snailchain.SnailBlockChain // Snail blockchain to package the fast blocks as fruits
+	engine     *ethash.Minerva             // Fake consensus engine rewarding the staking accounts
 
 	mu           sync.Mutex
 	pendingBlock *types.Block   // Currently pending block that will be imported on request
@@ -63,19 +72,32 @@ type SimulatedBackend struct {
 // NewSimulatedBackendWithDatabase creates a new binding backend based on the given database
 // and uses a simulated blockchain for testing purposes.
 func NewSimulatedBackendWithDatabase(database icedb.Database, alloc types.GenesisAlloc, gasLimit uint64) *SimulatedBackend {
-	genesis := core.Genesis{Config: params.AllMinervaProtocolChanges, GasLimit: gasLimit, Alloc: alloc}
+	config := *params.AllMinervaProtocolChanges
+	config.Minerva = &params.MinervaConfig{MinimumDifficulty: params.MinimumDifficulty, MinimumFruitDifficulty: params.MinimumFruitDifficulty, DurationLimit: params.DurationLimit}
+	genesis := core.Genesis{Config: &config, GasLimit: gasLimit, Alloc: alloc, Committee: []*types.CommitteeMember{
+		{Coinbase: crypto.PubkeyToAddress(committeeKey.PublicKey), Publickey: crypto.FromECDSAPub(&committeeKey.PublicKey)},
+	}}
 	params.MinTimeGap = big.NewInt(0)
 	params.SnailRewardInterval = big.NewInt(3)
-	genesis.Config.TIP7 = &params.BlockConfig{FastNumber: big.NewInt(10000)}
-	genesis.Config.TIP8 = &params.BlockConfig{FastNumber: big.NewInt(1000), CID: big.NewInt(10)}
-	genesis.Config.TIP9 = &params.BlockConfig{FastNumber: big.NewInt(0), SnailNumber: big.NewInt(0)}
+	// the staking precompile works from the genesis and the staking accounts are
+	// elected at the epoch boundaries, the snail blocks are never disabled so
+	// the committees are rewarded by the fast blocks referencing them
+	genesis.Config.TIP7 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	genesis.Config.TIP8 = &params.BlockConfig{FastNumber: big.NewInt(0), CID: big.NewInt(-1)}
+	genesis.Config.TIP9 = &params.BlockConfig{FastNumber: math.MaxBig63, SnailNumber: math.MaxBig63}
 
 	genesis.MustFastCommit(database)
-	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, ethash.NewFaker(), vm.Config{})
+	genesis.MustSnailCommit(database)
+	engine := ethash.NewFaker()
+	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, engine, vm.Config{})
+	snailBlockchain, _ := snailchain.NewSnailBlockChain(database, genesis.Config, engine, blockchain)
+	engine.SetSnailChainReader(snailBlockchain)
 
 	backend := &SimulatedBackend{
 		database:   database,
 		blockchain: blockchain,
+		snailchain: snailBlockchain,
+		engine:     engine,
 		config:     genesis.Config,
 		events:     filters.NewEventSystem(new(event.TypeMux), &filterBackend{database, blockchain}, false),
 	}
@@ -91,6 +113,7 @@ func NewSimulatedBackend(alloc types.GenesisAlloc, gasLimit uint64) *SimulatedBa
 
 // Close terminates the underlying blockchain's update loop.
 func (b *SimulatedBackend) Close() error {
+	b.snailchain.Stop()
 	b.blockchain.Stop()
 	return nil
 }
@@ -116,7 +139,9 @@ func (b *SimulatedBackend) Rollback() {
 }
 
 func (b *SimulatedBackend) rollback() {
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(number int, block *core.BlockGen) {
+		b.rewardSnailBlock(block)
+	})
 	statedb, _ := b.blockchain.State()
 
 	b.pendingBlock = blocks[0]
@@ -596,7 +621,8 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 		panic(fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce))
 	}
 
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(number int, block *core.BlockGen) {
+		b.rewardSnailBlock(block)
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTxWithChain(b.blockchain, tx)
 		}
@@ -708,7 +734,8 @@ func (b *SimulatedBackend) SubscribeNewHead(ctx context.Context, ch chan<- *type
 func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), ethash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, 1, func(number int, block *core.BlockGen) {
+		b.rewardSnailBlock(block)
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTx(tx)
 		}
@@ -727,6 +754,117 @@ func (b *SimulatedBackend) Blockchain() *core.BlockChain {
 	return b.blockchain
 }
 
+// SnailBlockchain returns the underlying snail blockchain.
+func (b *SimulatedBackend) SnailBlockchain() *snailchain.SnailBlockChain {
+	return b.snailchain
+}
+
+// CommitSnail packages the next fast blocks which are not in the snail chain
+// as the fruits of a new snail block, and imports it.
+func (b *SimulatedBackend) CommitSnail() (*types.SnailBlock, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.snailchain.CurrentBlock()
+	next := current.Number().Uint64() + 1
+	if b.blockchain.CurrentBlock().NumberU64() < next*uint64(params.MinimumFruits) {
+		return nil, errFruitsUnavailable
+	}
+	parents := make([]*types.SnailBlock, 0, next)
+	for i := uint64(0); i < next; i++ {
+		parents = append(parents, b.snailchain.GetBlockByNumber(i))
+	}
+	blocks := snailchain.GenerateChain(b.config, b.blockchain, parents, 1, 7, nil)
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil, errFruitsUnavailable
+	}
+	if _, err := b.snailchain.InsertChain(blocks); err != nil {
+		return nil, err
+	}
+	return blocks[0], nil
+}
+
+// rewardSnailBlock references the next snail block to be rewarded in the block,
+// once the snail chain is SnailRewardInterval blocks ahead of it.
+func (b *SimulatedBackend) rewardSnailBlock(block *core.BlockGen) {
+	next := b.blockchain.NextSnailNumberReward()
+	if new(big.Int).Sub(b.snailchain.CurrentBlock().Number(), next).Cmp(params.SnailRewardInterval) < 0 {
+		return
+	}
+	if sb := b.snailchain.GetHeaderByNumber(next.Uint64()); sb != nil {
+		header := block.GetHeader()
+		header.SnailNumber = next
+		header.SnailHash = sb.Hash()
+	}
+}
+
+// CommitEpoch imports the pending block and empty blocks until the end of the
+// current epoch. The elections, the epoch rewards and the shift of the staking
+// accounts are done on the way, so the staking accounts of the next epoch take
+// effect from the next block.
+func (b *SimulatedBackend) CommitEpoch() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, err := b.blockchain.InsertChain([]*types.Block{b.pendingBlock}); err != nil {
+		return err
+	}
+	end := types.GetEpochFromHeight(b.blockchain.CurrentBlock().NumberU64()).EndHeight
+	for current := b.blockchain.CurrentBlock().NumberU64(); current < end; current = b.blockchain.CurrentBlock().NumberU64() {
+		n := end - current
+		if n > 1024 {
+			n = 1024
+		}
+		blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), b.engine, b.database, int(n), func(number int, block *core.BlockGen) {
+			if number == 0 {
+				b.rewardSnailBlock(block)
+			}
+		})
+		if _, err := b.blockchain.InsertChain(blocks); err != nil {
+			return err
+		}
+	}
+	b.rollback()
+	return nil
+}
+
+// CurrentEpoch returns the epoch of the pending block.
+func (b *SimulatedBackend) CurrentEpoch() *types.EpochIDInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return types.GetEpochFromHeight(b.pendingBlock.NumberU64())
+}
+
+// ImpawnAt returns the impawn state of the staking precompile in the blockchain.
+func (b *SimulatedBackend) ImpawnAt(ctx context.Context, blockNumber *big.Int) (*vm.ImpawnImpl, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statedb, err := b.stateByBlockNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	impawn := vm.NewImpawnImpl()
+	if err := impawn.Load(statedb, types.StakingAddress); err != nil {
+		return nil, err
+	}
+	return impawn, nil
+}
+
+// LockedBalanceAt returns the balance of a certain account locked by the
+// staking precompile in the blockchain.
+func (b *SimulatedBackend) LockedBalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statedb, err := b.stateByBlockNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return statedb.GetPOSLocked(account), nil
+}
+
 // callmsg implements core.Message to allow passing it as a transaction simulator.
 type callmsg struct {
 	icechain.CallMsg
@@ -742,6 +880,9 @@ func (m callmsg) Gas() uint64             { return m.CallMsg.Gas }
 func (m callmsg) Value() *big.Int         { return m.CallMsg.Value }
 func (m callmsg) Fee() *big.Int           { return m.CallMsg.Fee }
 func (m callmsg) Data() []byte            { return m.CallMsg.Data }
+func (m callmsg) AccessList() types.AccessList {
+	return m.CallMsg.AccessList
+}
 
 // filterBackend implements filters.Backend to support filtering for logs without
 // taking bloom-bits acceleration structures into account.
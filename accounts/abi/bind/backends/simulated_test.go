@@ -20,13 +20,16 @@ import (
 	"context"
 	"github.com/iceming123/go-ice/params"
 	"math/big"
+	"strings"
 	"testing"
 
 	ethereum "github.com/iceming123/go-ice"
+	"github.com/iceming123/go-ice/accounts/abi"
 	"github.com/iceming123/go-ice/accounts/abi/bind"
 	"github.com/iceming123/go-ice/accounts/abi/bind/backends"
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/crypto"
 )
 
@@ -81,3 +84,78 @@ func TestSimulatedBackend(t *testing.T) {
 	}
 
 }
+
+func TestSimulatedBackendStaking(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	balance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18))
+	sim := backends.NewSimulatedBackend(types.GenesisAlloc{from: {Balance: balance}}, 8000029)
+	defer sim.Close()
+
+	// deposit as a staking account with the staking precompile
+	abiStaking, _ := abi.JSON(strings.NewReader(vm.StakeABIJSON))
+	value := new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18))
+	input, err := abiStaking.Pack("deposit", crypto.FromECDSAPub(&key.PublicKey), big.NewInt(50), value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewTIP1Signer(params.AllMinervaProtocolChanges.ChainID)
+	tx, _ := types.SignTx(types.NewTransaction(0, types.StakingAddress, big.NewInt(0), 3000000, big.NewInt(1), input), signer, key)
+	if err := sim.SendTransaction(context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	if receipt, _ := sim.TransactionReceipt(context.Background(), tx.Hash()); receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deposit failed: %v", receipt)
+	}
+	locked, _ := sim.LockedBalanceAt(context.Background(), from, nil)
+	if locked.Cmp(value) != 0 {
+		t.Fatalf("locked balance mismatch: have %v, want %v", locked, value)
+	}
+
+	// the fruits need the fast blocks
+	if _, err := sim.CommitSnail(); err == nil {
+		t.Fatal("commit snail block without the fast blocks succeeded")
+	}
+	for i := 1; i <= 4; i++ {
+		for j := 0; j < params.MinimumFruits; j++ {
+			sim.Commit()
+		}
+		block, err := sim.CommitSnail()
+		if err != nil {
+			t.Fatalf("commit snail block failed: %v", err)
+		}
+		if len(block.Fruits()) != params.MinimumFruits || sim.SnailBlockchain().CurrentBlock().Hash() != block.Hash() {
+			t.Fatalf("snail block %d mismatch: fruits %d", i, len(block.Fruits()))
+		}
+	}
+	// the first snail block is rewarded once the snail chain is far enough ahead
+	sim.Commit()
+	sim.Commit()
+	if reward := sim.Blockchain().CurrentReward(); reward == nil || reward.SnailNumber.Uint64() != 1 {
+		t.Fatalf("snail block not rewarded: %v", reward)
+	}
+
+	// the staking account is elected in the next epoch
+	epoch := sim.CurrentEpoch()
+	if err := sim.CommitEpoch(); err != nil {
+		t.Fatalf("commit epoch failed: %v", err)
+	}
+	if next := sim.CurrentEpoch(); next.EpochID != epoch.EpochID+1 {
+		t.Fatalf("epoch mismatch: have %d, want %d", next.EpochID, epoch.EpochID+1)
+	}
+	impawn, err := sim.ImpawnAt(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sa, err := impawn.GetStakingAccount(epoch.EpochID, from)
+	if err != nil {
+		t.Fatalf("staking account not found: %v", err)
+	}
+	if !sa.Committee {
+		t.Errorf("staking account not elected")
+	}
+	if _, err := impawn.GetStakingAccount(epoch.EpochID+1, from); err != nil {
+		t.Errorf("staking account not shifted: %v", err)
+	}
+}
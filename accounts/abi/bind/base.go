@@ -55,6 +55,11 @@ type TransactOpts struct {
 	GasPrice *big.Int // Gas price to use for the transaction execution (nil = gas price oracle)
 	GasLimit uint64   // Gas limit to set for the transaction execution (0 = estimate)
 
+	AccessList types.AccessList // EIP-2930 access list to send a typed transaction with (nil = legacy transaction)
+
+	Payer       common.Address // Optional account paying the gas of the transaction (zero = the sender pays)
+	PayerSigner SignerFn       // Method to use for signing the transaction as its payer (mandatory with a payer)
+
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 }
 
@@ -224,7 +229,7 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	gasLimit := opts.GasLimit
 	if gasLimit == 0 {
 		// Gas estimation cannot succeed without code for method invocations
-		if contract != nil {
+		if contract != nil && !precompiled(c.address) {
 			if code, err := c.transactor.PendingCodeAt(ensureContext(opts.Context), c.address); err != nil {
 				return nil, err
 			} else if len(code) == 0 {
@@ -232,7 +237,7 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 			}
 		}
 		// If the contract surely has code (or code is not needed), estimate the transaction
-		msg := icechain.CallMsg{From: opts.From, To: contract, GasPrice: gasPrice, Value: value, Data: input}
+		msg := icechain.CallMsg{From: opts.From, To: contract, Payment: opts.Payer, GasPrice: gasPrice, Value: value, Data: input, AccessList: opts.AccessList}
 		gasLimit, err = c.transactor.EstimateGas(ensureContext(opts.Context), msg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to estimate gas needed: %v", err)
@@ -240,24 +245,46 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	}
 	// Create the transaction, sign it and schedule it for execution
 	var rawTx *types.Transaction
-	if contract == nil {
-		rawTx = types.NewContractCreation(nonce, value, gasLimit, gasPrice, input)
+	if opts.AccessList != nil {
+		var to *common.Address
+		if contract != nil {
+			to = &c.address
+		}
+		rawTx = types.NewAccessListTransaction_Payment(params.AllMinervaProtocolChanges.ChainID, nonce, to, value, nil, gasLimit, gasPrice, input, opts.AccessList, opts.Payer)
+	} else if contract == nil {
+		rawTx = types.NewContractCreation_Payment(nonce, value, nil, gasLimit, gasPrice, input, opts.Payer)
 	} else {
-		rawTx = types.NewTransaction(nonce, c.address, value, gasLimit, gasPrice, input)
+		rawTx = types.NewTransaction_Payment(nonce, c.address, value, nil, gasLimit, gasPrice, input, opts.Payer)
 	}
 	if opts.Signer == nil {
 		return nil, errors.New("no signer to authorize the transaction with")
 	}
-	signedTx, err := opts.Signer(types.NewTIP1Signer(params.AllMinervaProtocolChanges.ChainID), opts.From, rawTx)
+	signer := types.NewTIP1Signer(params.AllMinervaProtocolChanges.ChainID)
+	signedTx, err := opts.Signer(signer, opts.From, rawTx)
 	if err != nil {
 		return nil, err
 	}
+	// The payer signs over the signature of the sender
+	if opts.Payer != (common.Address{}) {
+		if opts.PayerSigner == nil {
+			return nil, errors.New("no signer to authorize the payment with")
+		}
+		if signedTx, err = opts.PayerSigner(signer, opts.Payer, signedTx); err != nil {
+			return nil, err
+		}
+	}
 	if err := c.transactor.SendTransaction(ensureContext(opts.Context), signedTx); err != nil {
 		return nil, err
 	}
 	return signedTx, nil
 }
 
+// precompiled reports whether the contract is the staking precompile, it has no
+// code in the state but its methods can be called.
+func precompiled(address common.Address) bool {
+	return address == types.StakingAddress
+}
+
 // FilterLogs filters contract logs for past blocks, returning the necessary
 // channels to construct a strongly typed bound iterator on top of them.
 func (c *BoundContract) FilterLogs(opts *FilterOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
@@ -0,0 +1,2988 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/iceming123/go-ice"
+	"github.com/iceming123/go-ice/accounts/abi"
+	"github.com/iceming123/go-ice/accounts/abi/bind"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// StakingABI is the input ABI used to generate the binding from.
+const StakingABI = "[{\"name\":\"Deposit\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"bytes\",\"name\":\"pubkey\",\"indexed\":false},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false},{\"type\":\"uint256\",\"name\":\"fee\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Delegate\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Undelegate\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"WithdrawDelegate\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Cancel\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Withdraw\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Append\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"SetFee\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"fee\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"SetPubkey\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"bytes\",\"name\":\"pubkey\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Slash\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"bytes32\",\"name\":\"evidence\",\"indexed\":false},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"AutoCompound\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"bool\",\"name\":\"auto\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"ReceiptTransfer\",\"inputs\":[{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"address\",\"name\":\"to\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"ReceiptApproval\",\"inputs\":[{\"type\":\"address\",\"name\":\"holder\",\"indexed\":true},{\"type\":\"address\",\"name\":\"owner\",\"indexed\":true},{\"type\":\"address\",\"name\":\"spender\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Propose\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"id\",\"indexed\":false},{\"type\":\"uint8\",\"name\":\"kind\",\"indexed\":false},{\"type\":\"uint256\",\"name\":\"value\",\"indexed\":false},{\"type\":\"address\",\"name\":\"account\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"Vote\",\"inputs\":[{\"type\":\"address\",\"name\":\"from\",\"indexed\":true},{\"type\":\"uint256\",\"name\":\"id\",\"indexed\":false},{\"type\":\"uint256\",\"name\":\"activation\",\"indexed\":false}],\"anonymous\":false,\"type\":\"event\"},{\"name\":\"deposit\",\"outputs\":[],\"inputs\":[{\"type\":\"bytes\",\"name\":\"pubkey\"},{\"type\":\"uint256\",\"name\":\"fee\"},{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"setFee\",\"outputs\":[],\"inputs\":[{\"type\":\"uint256\",\"name\":\"fee\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"setPubkey\",\"outputs\":[],\"inputs\":[{\"type\":\"bytes\",\"name\":\"pubkey\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"append\",\"outputs\":[],\"inputs\":[{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"delegate\",\"outputs\":[],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"undelegate\",\"outputs\":[],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"lockedBalance\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"out\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"owner\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"getDeposit\",\"outputs\":[{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"staked\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"locked\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"unlocked\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"owner\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"getDelegate\",\"outputs\":[{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"delegated\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"locked\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"unlocked\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"owner\"},{\"type\":\"address\",\"name\":\"holder\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"cancel\",\"outputs\":[],\"inputs\":[{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"withdraw\",\"outputs\":[],\"inputs\":[{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"withdrawDelegate\",\"outputs\":[],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"uint256\",\"unit\":\"wei\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"submitEvidence\",\"outputs\":[],\"inputs\":[{\"type\":\"bytes\",\"name\":\"evidence\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"setAutoCompound\",\"outputs\":[],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"bool\",\"name\":\"auto\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptBalanceOf\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"balance\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"address\",\"name\":\"owner\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptTotalSupply\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"supply\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptAllowance\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"remaining\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"address\",\"name\":\"owner\"},{\"type\":\"address\",\"name\":\"spender\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptTransfer\",\"outputs\":[{\"type\":\"bool\",\"name\":\"success\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"address\",\"name\":\"to\"},{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptApprove\",\"outputs\":[{\"type\":\"bool\",\"name\":\"success\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"address\",\"name\":\"spender\"},{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"receiptTransferFrom\",\"outputs\":[{\"type\":\"bool\",\"name\":\"success\"}],\"inputs\":[{\"type\":\"address\",\"name\":\"holder\"},{\"type\":\"address\",\"name\":\"from\"},{\"type\":\"address\",\"name\":\"to\"},{\"type\":\"uint256\",\"name\":\"value\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"propose\",\"outputs\":[{\"type\":\"uint256\",\"name\":\"id\"}],\"inputs\":[{\"type\":\"uint8\",\"name\":\"kind\"},{\"type\":\"uint256\",\"name\":\"value\"},{\"type\":\"address\",\"name\":\"account\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"vote\",\"outputs\":[],\"inputs\":[{\"type\":\"uint256\",\"name\":\"id\"}],\"constant\":false,\"payable\":false,\"type\":\"function\"},{\"name\":\"getProposal\",\"outputs\":[{\"type\":\"uint8\",\"name\":\"kind\"},{\"type\":\"uint256\",\"name\":\"value\"},{\"type\":\"address\",\"name\":\"account\"},{\"type\":\"uint256\",\"name\":\"votes\"},{\"type\":\"uint256\",\"name\":\"activation\"}],\"inputs\":[{\"type\":\"uint256\",\"name\":\"id\"}],\"constant\":true,\"payable\":false,\"type\":\"function\"}]"
+
+// Staking is an auto generated Go binding around an Ethereum contract.
+type Staking struct {
+	StakingCaller     // Read-only binding to the contract
+	StakingTransactor // Write-only binding to the contract
+	StakingFilterer   // Log filterer for contract events
+}
+
+// StakingCaller is an auto generated read-only Go binding around an Ethereum contract.
+type StakingCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// StakingTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type StakingTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// StakingFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type StakingFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// StakingSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type StakingSession struct {
+	Contract     *Staking          // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// StakingCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type StakingCallerSession struct {
+	Contract *StakingCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts  // Call options to use throughout this session
+}
+
+// StakingTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type StakingTransactorSession struct {
+	Contract     *StakingTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts  // Transaction auth options to use throughout this session
+}
+
+// StakingRaw is an auto generated low-level Go binding around an Ethereum contract.
+type StakingRaw struct {
+	Contract *Staking // Generic contract binding to access the raw methods on
+}
+
+// StakingCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type StakingCallerRaw struct {
+	Contract *StakingCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// StakingTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type StakingTransactorRaw struct {
+	Contract *StakingTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewStaking creates a new instance of Staking, bound to a specific deployed contract.
+func NewStaking(address common.Address, backend bind.ContractBackend) (*Staking, error) {
+	contract, err := bindStaking(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Staking{StakingCaller: StakingCaller{contract: contract}, StakingTransactor: StakingTransactor{contract: contract}, StakingFilterer: StakingFilterer{contract: contract}}, nil
+}
+
+// NewStakingCaller creates a new read-only instance of Staking, bound to a specific deployed contract.
+func NewStakingCaller(address common.Address, caller bind.ContractCaller) (*StakingCaller, error) {
+	contract, err := bindStaking(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingCaller{contract: contract}, nil
+}
+
+// NewStakingTransactor creates a new write-only instance of Staking, bound to a specific deployed contract.
+func NewStakingTransactor(address common.Address, transactor bind.ContractTransactor) (*StakingTransactor, error) {
+	contract, err := bindStaking(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingTransactor{contract: contract}, nil
+}
+
+// NewStakingFilterer creates a new log filterer instance of Staking, bound to a specific deployed contract.
+func NewStakingFilterer(address common.Address, filterer bind.ContractFilterer) (*StakingFilterer, error) {
+	contract, err := bindStaking(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingFilterer{contract: contract}, nil
+}
+
+// bindStaking binds a generic wrapper to an already deployed contract.
+func bindStaking(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(StakingABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Staking *StakingRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _Staking.Contract.StakingCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Staking *StakingRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Staking.Contract.StakingTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Staking *StakingRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Staking.Contract.StakingTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Staking *StakingCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _Staking.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Staking *StakingTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Staking.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Staking *StakingTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Staking.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetDelegate is a free data retrieval call binding the contract method 0x27bb01b1.
+//
+// Solidity: function getDelegate(address owner, address holder) returns(uint256 delegated, uint256 locked, uint256 unlocked)
+func (_Staking *StakingCaller) GetDelegate(opts *bind.CallOpts, owner common.Address, holder common.Address) (struct {
+	Delegated *big.Int
+	Locked    *big.Int
+	Unlocked  *big.Int
+}, error) {
+	ret := new(struct {
+		Delegated *big.Int
+		Locked    *big.Int
+		Unlocked  *big.Int
+	})
+	out := ret
+	err := _Staking.contract.Call(opts, out, "getDelegate", owner, holder)
+	return *ret, err
+}
+
+// GetDelegate is a free data retrieval call binding the contract method 0x27bb01b1.
+//
+// Solidity: function getDelegate(address owner, address holder) returns(uint256 delegated, uint256 locked, uint256 unlocked)
+func (_Staking *StakingSession) GetDelegate(owner common.Address, holder common.Address) (struct {
+	Delegated *big.Int
+	Locked    *big.Int
+	Unlocked  *big.Int
+}, error) {
+	return _Staking.Contract.GetDelegate(&_Staking.CallOpts, owner, holder)
+}
+
+// GetDelegate is a free data retrieval call binding the contract method 0x27bb01b1.
+//
+// Solidity: function getDelegate(address owner, address holder) returns(uint256 delegated, uint256 locked, uint256 unlocked)
+func (_Staking *StakingCallerSession) GetDelegate(owner common.Address, holder common.Address) (struct {
+	Delegated *big.Int
+	Locked    *big.Int
+	Unlocked  *big.Int
+}, error) {
+	return _Staking.Contract.GetDelegate(&_Staking.CallOpts, owner, holder)
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xe1254fba.
+//
+// Solidity: function getDeposit(address owner) returns(uint256 staked, uint256 locked, uint256 unlocked)
+func (_Staking *StakingCaller) GetDeposit(opts *bind.CallOpts, owner common.Address) (struct {
+	Staked   *big.Int
+	Locked   *big.Int
+	Unlocked *big.Int
+}, error) {
+	ret := new(struct {
+		Staked   *big.Int
+		Locked   *big.Int
+		Unlocked *big.Int
+	})
+	out := ret
+	err := _Staking.contract.Call(opts, out, "getDeposit", owner)
+	return *ret, err
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xe1254fba.
+//
+// Solidity: function getDeposit(address owner) returns(uint256 staked, uint256 locked, uint256 unlocked)
+func (_Staking *StakingSession) GetDeposit(owner common.Address) (struct {
+	Staked   *big.Int
+	Locked   *big.Int
+	Unlocked *big.Int
+}, error) {
+	return _Staking.Contract.GetDeposit(&_Staking.CallOpts, owner)
+}
+
+// GetDeposit is a free data retrieval call binding the contract method 0xe1254fba.
+//
+// Solidity: function getDeposit(address owner) returns(uint256 staked, uint256 locked, uint256 unlocked)
+func (_Staking *StakingCallerSession) GetDeposit(owner common.Address) (struct {
+	Staked   *big.Int
+	Locked   *big.Int
+	Unlocked *big.Int
+}, error) {
+	return _Staking.Contract.GetDeposit(&_Staking.CallOpts, owner)
+}
+
+// GetProposal is a free data retrieval call binding the contract method 0xc7f758a8.
+//
+// Solidity: function getProposal(uint256 id) returns(uint8 kind, uint256 value, address account, uint256 votes, uint256 activation)
+func (_Staking *StakingCaller) GetProposal(opts *bind.CallOpts, id *big.Int) (struct {
+	Kind       uint8
+	Value      *big.Int
+	Account    common.Address
+	Votes      *big.Int
+	Activation *big.Int
+}, error) {
+	ret := new(struct {
+		Kind       uint8
+		Value      *big.Int
+		Account    common.Address
+		Votes      *big.Int
+		Activation *big.Int
+	})
+	out := ret
+	err := _Staking.contract.Call(opts, out, "getProposal", id)
+	return *ret, err
+}
+
+// GetProposal is a free data retrieval call binding the contract method 0xc7f758a8.
+//
+// Solidity: function getProposal(uint256 id) returns(uint8 kind, uint256 value, address account, uint256 votes, uint256 activation)
+func (_Staking *StakingSession) GetProposal(id *big.Int) (struct {
+	Kind       uint8
+	Value      *big.Int
+	Account    common.Address
+	Votes      *big.Int
+	Activation *big.Int
+}, error) {
+	return _Staking.Contract.GetProposal(&_Staking.CallOpts, id)
+}
+
+// GetProposal is a free data retrieval call binding the contract method 0xc7f758a8.
+//
+// Solidity: function getProposal(uint256 id) returns(uint8 kind, uint256 value, address account, uint256 votes, uint256 activation)
+func (_Staking *StakingCallerSession) GetProposal(id *big.Int) (struct {
+	Kind       uint8
+	Value      *big.Int
+	Account    common.Address
+	Votes      *big.Int
+	Activation *big.Int
+}, error) {
+	return _Staking.Contract.GetProposal(&_Staking.CallOpts, id)
+}
+
+// LockedBalance is a free data retrieval call binding the contract method 0x9ae697bf.
+//
+// Solidity: function lockedBalance(address owner) returns(uint256 out)
+func (_Staking *StakingCaller) LockedBalance(opts *bind.CallOpts, owner common.Address) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Staking.contract.Call(opts, out, "lockedBalance", owner)
+	return *ret0, err
+}
+
+// LockedBalance is a free data retrieval call binding the contract method 0x9ae697bf.
+//
+// Solidity: function lockedBalance(address owner) returns(uint256 out)
+func (_Staking *StakingSession) LockedBalance(owner common.Address) (*big.Int, error) {
+	return _Staking.Contract.LockedBalance(&_Staking.CallOpts, owner)
+}
+
+// LockedBalance is a free data retrieval call binding the contract method 0x9ae697bf.
+//
+// Solidity: function lockedBalance(address owner) returns(uint256 out)
+func (_Staking *StakingCallerSession) LockedBalance(owner common.Address) (*big.Int, error) {
+	return _Staking.Contract.LockedBalance(&_Staking.CallOpts, owner)
+}
+
+// ReceiptAllowance is a free data retrieval call binding the contract method 0xaa9aae5e.
+//
+// Solidity: function receiptAllowance(address holder, address owner, address spender) returns(uint256 remaining)
+func (_Staking *StakingCaller) ReceiptAllowance(opts *bind.CallOpts, holder common.Address, owner common.Address, spender common.Address) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Staking.contract.Call(opts, out, "receiptAllowance", holder, owner, spender)
+	return *ret0, err
+}
+
+// ReceiptAllowance is a free data retrieval call binding the contract method 0xaa9aae5e.
+//
+// Solidity: function receiptAllowance(address holder, address owner, address spender) returns(uint256 remaining)
+func (_Staking *StakingSession) ReceiptAllowance(holder common.Address, owner common.Address, spender common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptAllowance(&_Staking.CallOpts, holder, owner, spender)
+}
+
+// ReceiptAllowance is a free data retrieval call binding the contract method 0xaa9aae5e.
+//
+// Solidity: function receiptAllowance(address holder, address owner, address spender) returns(uint256 remaining)
+func (_Staking *StakingCallerSession) ReceiptAllowance(holder common.Address, owner common.Address, spender common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptAllowance(&_Staking.CallOpts, holder, owner, spender)
+}
+
+// ReceiptBalanceOf is a free data retrieval call binding the contract method 0xc70892fa.
+//
+// Solidity: function receiptBalanceOf(address holder, address owner) returns(uint256 balance)
+func (_Staking *StakingCaller) ReceiptBalanceOf(opts *bind.CallOpts, holder common.Address, owner common.Address) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Staking.contract.Call(opts, out, "receiptBalanceOf", holder, owner)
+	return *ret0, err
+}
+
+// ReceiptBalanceOf is a free data retrieval call binding the contract method 0xc70892fa.
+//
+// Solidity: function receiptBalanceOf(address holder, address owner) returns(uint256 balance)
+func (_Staking *StakingSession) ReceiptBalanceOf(holder common.Address, owner common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptBalanceOf(&_Staking.CallOpts, holder, owner)
+}
+
+// ReceiptBalanceOf is a free data retrieval call binding the contract method 0xc70892fa.
+//
+// Solidity: function receiptBalanceOf(address holder, address owner) returns(uint256 balance)
+func (_Staking *StakingCallerSession) ReceiptBalanceOf(holder common.Address, owner common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptBalanceOf(&_Staking.CallOpts, holder, owner)
+}
+
+// ReceiptTotalSupply is a free data retrieval call binding the contract method 0x3374cf47.
+//
+// Solidity: function receiptTotalSupply(address holder) returns(uint256 supply)
+func (_Staking *StakingCaller) ReceiptTotalSupply(opts *bind.CallOpts, holder common.Address) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _Staking.contract.Call(opts, out, "receiptTotalSupply", holder)
+	return *ret0, err
+}
+
+// ReceiptTotalSupply is a free data retrieval call binding the contract method 0x3374cf47.
+//
+// Solidity: function receiptTotalSupply(address holder) returns(uint256 supply)
+func (_Staking *StakingSession) ReceiptTotalSupply(holder common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptTotalSupply(&_Staking.CallOpts, holder)
+}
+
+// ReceiptTotalSupply is a free data retrieval call binding the contract method 0x3374cf47.
+//
+// Solidity: function receiptTotalSupply(address holder) returns(uint256 supply)
+func (_Staking *StakingCallerSession) ReceiptTotalSupply(holder common.Address) (*big.Int, error) {
+	return _Staking.Contract.ReceiptTotalSupply(&_Staking.CallOpts, holder)
+}
+
+// Append is a paid mutator transaction binding the contract method 0xe33b8707.
+//
+// Solidity: function append(uint256 value) returns()
+func (_Staking *StakingTransactor) Append(opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "append", value)
+}
+
+// Append is a paid mutator transaction binding the contract method 0xe33b8707.
+//
+// Solidity: function append(uint256 value) returns()
+func (_Staking *StakingSession) Append(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Append(&_Staking.TransactOpts, value)
+}
+
+// Append is a paid mutator transaction binding the contract method 0xe33b8707.
+//
+// Solidity: function append(uint256 value) returns()
+func (_Staking *StakingTransactorSession) Append(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Append(&_Staking.TransactOpts, value)
+}
+
+// Cancel is a paid mutator transaction binding the contract method 0x40e58ee5.
+//
+// Solidity: function cancel(uint256 value) returns()
+func (_Staking *StakingTransactor) Cancel(opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "cancel", value)
+}
+
+// Cancel is a paid mutator transaction binding the contract method 0x40e58ee5.
+//
+// Solidity: function cancel(uint256 value) returns()
+func (_Staking *StakingSession) Cancel(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Cancel(&_Staking.TransactOpts, value)
+}
+
+// Cancel is a paid mutator transaction binding the contract method 0x40e58ee5.
+//
+// Solidity: function cancel(uint256 value) returns()
+func (_Staking *StakingTransactorSession) Cancel(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Cancel(&_Staking.TransactOpts, value)
+}
+
+// Delegate is a paid mutator transaction binding the contract method 0x026e402b.
+//
+// Solidity: function delegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactor) Delegate(opts *bind.TransactOpts, holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "delegate", holder, value)
+}
+
+// Delegate is a paid mutator transaction binding the contract method 0x026e402b.
+//
+// Solidity: function delegate(address holder, uint256 value) returns()
+func (_Staking *StakingSession) Delegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Delegate(&_Staking.TransactOpts, holder, value)
+}
+
+// Delegate is a paid mutator transaction binding the contract method 0x026e402b.
+//
+// Solidity: function delegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactorSession) Delegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Delegate(&_Staking.TransactOpts, holder, value)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0x5d322ae8.
+//
+// Solidity: function deposit(bytes pubkey, uint256 fee, uint256 value) returns()
+func (_Staking *StakingTransactor) Deposit(opts *bind.TransactOpts, pubkey []byte, fee *big.Int, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "deposit", pubkey, fee, value)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0x5d322ae8.
+//
+// Solidity: function deposit(bytes pubkey, uint256 fee, uint256 value) returns()
+func (_Staking *StakingSession) Deposit(pubkey []byte, fee *big.Int, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Deposit(&_Staking.TransactOpts, pubkey, fee, value)
+}
+
+// Deposit is a paid mutator transaction binding the contract method 0x5d322ae8.
+//
+// Solidity: function deposit(bytes pubkey, uint256 fee, uint256 value) returns()
+func (_Staking *StakingTransactorSession) Deposit(pubkey []byte, fee *big.Int, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Deposit(&_Staking.TransactOpts, pubkey, fee, value)
+}
+
+// Propose is a paid mutator transaction binding the contract method 0x80260fa7.
+//
+// Solidity: function propose(uint8 kind, uint256 value, address account) returns(uint256 id)
+func (_Staking *StakingTransactor) Propose(opts *bind.TransactOpts, kind uint8, value *big.Int, account common.Address) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "propose", kind, value, account)
+}
+
+// Propose is a paid mutator transaction binding the contract method 0x80260fa7.
+//
+// Solidity: function propose(uint8 kind, uint256 value, address account) returns(uint256 id)
+func (_Staking *StakingSession) Propose(kind uint8, value *big.Int, account common.Address) (*types.Transaction, error) {
+	return _Staking.Contract.Propose(&_Staking.TransactOpts, kind, value, account)
+}
+
+// Propose is a paid mutator transaction binding the contract method 0x80260fa7.
+//
+// Solidity: function propose(uint8 kind, uint256 value, address account) returns(uint256 id)
+func (_Staking *StakingTransactorSession) Propose(kind uint8, value *big.Int, account common.Address) (*types.Transaction, error) {
+	return _Staking.Contract.Propose(&_Staking.TransactOpts, kind, value, account)
+}
+
+// ReceiptApprove is a paid mutator transaction binding the contract method 0x3b64eecb.
+//
+// Solidity: function receiptApprove(address holder, address spender, uint256 value) returns(bool success)
+func (_Staking *StakingTransactor) ReceiptApprove(opts *bind.TransactOpts, holder common.Address, spender common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "receiptApprove", holder, spender, value)
+}
+
+// ReceiptApprove is a paid mutator transaction binding the contract method 0x3b64eecb.
+//
+// Solidity: function receiptApprove(address holder, address spender, uint256 value) returns(bool success)
+func (_Staking *StakingSession) ReceiptApprove(holder common.Address, spender common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptApprove(&_Staking.TransactOpts, holder, spender, value)
+}
+
+// ReceiptApprove is a paid mutator transaction binding the contract method 0x3b64eecb.
+//
+// Solidity: function receiptApprove(address holder, address spender, uint256 value) returns(bool success)
+func (_Staking *StakingTransactorSession) ReceiptApprove(holder common.Address, spender common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptApprove(&_Staking.TransactOpts, holder, spender, value)
+}
+
+// ReceiptTransfer is a paid mutator transaction binding the contract method 0x3d14e7ae.
+//
+// Solidity: function receiptTransfer(address holder, address to, uint256 value) returns(bool success)
+func (_Staking *StakingTransactor) ReceiptTransfer(opts *bind.TransactOpts, holder common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "receiptTransfer", holder, to, value)
+}
+
+// ReceiptTransfer is a paid mutator transaction binding the contract method 0x3d14e7ae.
+//
+// Solidity: function receiptTransfer(address holder, address to, uint256 value) returns(bool success)
+func (_Staking *StakingSession) ReceiptTransfer(holder common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptTransfer(&_Staking.TransactOpts, holder, to, value)
+}
+
+// ReceiptTransfer is a paid mutator transaction binding the contract method 0x3d14e7ae.
+//
+// Solidity: function receiptTransfer(address holder, address to, uint256 value) returns(bool success)
+func (_Staking *StakingTransactorSession) ReceiptTransfer(holder common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptTransfer(&_Staking.TransactOpts, holder, to, value)
+}
+
+// ReceiptTransferFrom is a paid mutator transaction binding the contract method 0x735fd88a.
+//
+// Solidity: function receiptTransferFrom(address holder, address from, address to, uint256 value) returns(bool success)
+func (_Staking *StakingTransactor) ReceiptTransferFrom(opts *bind.TransactOpts, holder common.Address, from common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "receiptTransferFrom", holder, from, to, value)
+}
+
+// ReceiptTransferFrom is a paid mutator transaction binding the contract method 0x735fd88a.
+//
+// Solidity: function receiptTransferFrom(address holder, address from, address to, uint256 value) returns(bool success)
+func (_Staking *StakingSession) ReceiptTransferFrom(holder common.Address, from common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptTransferFrom(&_Staking.TransactOpts, holder, from, to, value)
+}
+
+// ReceiptTransferFrom is a paid mutator transaction binding the contract method 0x735fd88a.
+//
+// Solidity: function receiptTransferFrom(address holder, address from, address to, uint256 value) returns(bool success)
+func (_Staking *StakingTransactorSession) ReceiptTransferFrom(holder common.Address, from common.Address, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.ReceiptTransferFrom(&_Staking.TransactOpts, holder, from, to, value)
+}
+
+// SetAutoCompound is a paid mutator transaction binding the contract method 0x601c2669.
+//
+// Solidity: function setAutoCompound(address holder, bool auto) returns()
+func (_Staking *StakingTransactor) SetAutoCompound(opts *bind.TransactOpts, holder common.Address, auto bool) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "setAutoCompound", holder, auto)
+}
+
+// SetAutoCompound is a paid mutator transaction binding the contract method 0x601c2669.
+//
+// Solidity: function setAutoCompound(address holder, bool auto) returns()
+func (_Staking *StakingSession) SetAutoCompound(holder common.Address, auto bool) (*types.Transaction, error) {
+	return _Staking.Contract.SetAutoCompound(&_Staking.TransactOpts, holder, auto)
+}
+
+// SetAutoCompound is a paid mutator transaction binding the contract method 0x601c2669.
+//
+// Solidity: function setAutoCompound(address holder, bool auto) returns()
+func (_Staking *StakingTransactorSession) SetAutoCompound(holder common.Address, auto bool) (*types.Transaction, error) {
+	return _Staking.Contract.SetAutoCompound(&_Staking.TransactOpts, holder, auto)
+}
+
+// SetFee is a paid mutator transaction binding the contract method 0x69fe0e2d.
+//
+// Solidity: function setFee(uint256 fee) returns()
+func (_Staking *StakingTransactor) SetFee(opts *bind.TransactOpts, fee *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "setFee", fee)
+}
+
+// SetFee is a paid mutator transaction binding the contract method 0x69fe0e2d.
+//
+// Solidity: function setFee(uint256 fee) returns()
+func (_Staking *StakingSession) SetFee(fee *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.SetFee(&_Staking.TransactOpts, fee)
+}
+
+// SetFee is a paid mutator transaction binding the contract method 0x69fe0e2d.
+//
+// Solidity: function setFee(uint256 fee) returns()
+func (_Staking *StakingTransactorSession) SetFee(fee *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.SetFee(&_Staking.TransactOpts, fee)
+}
+
+// SetPubkey is a paid mutator transaction binding the contract method 0x1c26a54b.
+//
+// Solidity: function setPubkey(bytes pubkey) returns()
+func (_Staking *StakingTransactor) SetPubkey(opts *bind.TransactOpts, pubkey []byte) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "setPubkey", pubkey)
+}
+
+// SetPubkey is a paid mutator transaction binding the contract method 0x1c26a54b.
+//
+// Solidity: function setPubkey(bytes pubkey) returns()
+func (_Staking *StakingSession) SetPubkey(pubkey []byte) (*types.Transaction, error) {
+	return _Staking.Contract.SetPubkey(&_Staking.TransactOpts, pubkey)
+}
+
+// SetPubkey is a paid mutator transaction binding the contract method 0x1c26a54b.
+//
+// Solidity: function setPubkey(bytes pubkey) returns()
+func (_Staking *StakingTransactorSession) SetPubkey(pubkey []byte) (*types.Transaction, error) {
+	return _Staking.Contract.SetPubkey(&_Staking.TransactOpts, pubkey)
+}
+
+// SubmitEvidence is a paid mutator transaction binding the contract method 0x9f7dcaec.
+//
+// Solidity: function submitEvidence(bytes evidence) returns()
+func (_Staking *StakingTransactor) SubmitEvidence(opts *bind.TransactOpts, evidence []byte) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "submitEvidence", evidence)
+}
+
+// SubmitEvidence is a paid mutator transaction binding the contract method 0x9f7dcaec.
+//
+// Solidity: function submitEvidence(bytes evidence) returns()
+func (_Staking *StakingSession) SubmitEvidence(evidence []byte) (*types.Transaction, error) {
+	return _Staking.Contract.SubmitEvidence(&_Staking.TransactOpts, evidence)
+}
+
+// SubmitEvidence is a paid mutator transaction binding the contract method 0x9f7dcaec.
+//
+// Solidity: function submitEvidence(bytes evidence) returns()
+func (_Staking *StakingTransactorSession) SubmitEvidence(evidence []byte) (*types.Transaction, error) {
+	return _Staking.Contract.SubmitEvidence(&_Staking.TransactOpts, evidence)
+}
+
+// Undelegate is a paid mutator transaction binding the contract method 0x4d99dd16.
+//
+// Solidity: function undelegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactor) Undelegate(opts *bind.TransactOpts, holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "undelegate", holder, value)
+}
+
+// Undelegate is a paid mutator transaction binding the contract method 0x4d99dd16.
+//
+// Solidity: function undelegate(address holder, uint256 value) returns()
+func (_Staking *StakingSession) Undelegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Undelegate(&_Staking.TransactOpts, holder, value)
+}
+
+// Undelegate is a paid mutator transaction binding the contract method 0x4d99dd16.
+//
+// Solidity: function undelegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactorSession) Undelegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Undelegate(&_Staking.TransactOpts, holder, value)
+}
+
+// Vote is a paid mutator transaction binding the contract method 0x0121b93f.
+//
+// Solidity: function vote(uint256 id) returns()
+func (_Staking *StakingTransactor) Vote(opts *bind.TransactOpts, id *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "vote", id)
+}
+
+// Vote is a paid mutator transaction binding the contract method 0x0121b93f.
+//
+// Solidity: function vote(uint256 id) returns()
+func (_Staking *StakingSession) Vote(id *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Vote(&_Staking.TransactOpts, id)
+}
+
+// Vote is a paid mutator transaction binding the contract method 0x0121b93f.
+//
+// Solidity: function vote(uint256 id) returns()
+func (_Staking *StakingTransactorSession) Vote(id *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Vote(&_Staking.TransactOpts, id)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0x2e1a7d4d.
+//
+// Solidity: function withdraw(uint256 value) returns()
+func (_Staking *StakingTransactor) Withdraw(opts *bind.TransactOpts, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "withdraw", value)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0x2e1a7d4d.
+//
+// Solidity: function withdraw(uint256 value) returns()
+func (_Staking *StakingSession) Withdraw(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Withdraw(&_Staking.TransactOpts, value)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0x2e1a7d4d.
+//
+// Solidity: function withdraw(uint256 value) returns()
+func (_Staking *StakingTransactorSession) Withdraw(value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.Withdraw(&_Staking.TransactOpts, value)
+}
+
+// WithdrawDelegate is a paid mutator transaction binding the contract method 0x7d6633d0.
+//
+// Solidity: function withdrawDelegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactor) WithdrawDelegate(opts *bind.TransactOpts, holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.contract.Transact(opts, "withdrawDelegate", holder, value)
+}
+
+// WithdrawDelegate is a paid mutator transaction binding the contract method 0x7d6633d0.
+//
+// Solidity: function withdrawDelegate(address holder, uint256 value) returns()
+func (_Staking *StakingSession) WithdrawDelegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.WithdrawDelegate(&_Staking.TransactOpts, holder, value)
+}
+
+// WithdrawDelegate is a paid mutator transaction binding the contract method 0x7d6633d0.
+//
+// Solidity: function withdrawDelegate(address holder, uint256 value) returns()
+func (_Staking *StakingTransactorSession) WithdrawDelegate(holder common.Address, value *big.Int) (*types.Transaction, error) {
+	return _Staking.Contract.WithdrawDelegate(&_Staking.TransactOpts, holder, value)
+}
+
+// StakingAppendIterator is returned from FilterAppend and is used to iterate over the raw logs and unpacked data for Append events raised by the Staking contract.
+type StakingAppendIterator struct {
+	Event *StakingAppend // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingAppendIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingAppend)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingAppend)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingAppendIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingAppendIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingAppend represents a Append event raised by the Staking contract.
+type StakingAppend struct {
+	From  common.Address
+	Value *big.Int
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterAppend is a free log retrieval operation binding the contract event 0xf95b08176211c6a5d6b7bdf8a69536a9126b7fe20d3a8fc6cbfcc547c5b29f29.
+//
+// Solidity: event Append(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) FilterAppend(opts *bind.FilterOpts, from []common.Address) (*StakingAppendIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Append", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingAppendIterator{contract: _Staking.contract, event: "Append", logs: logs, sub: sub}, nil
+}
+
+// WatchAppend is a free log subscription operation binding the contract event 0xf95b08176211c6a5d6b7bdf8a69536a9126b7fe20d3a8fc6cbfcc547c5b29f29.
+//
+// Solidity: event Append(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) WatchAppend(opts *bind.WatchOpts, sink chan<- *StakingAppend, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Append", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingAppend)
+				if err := _Staking.contract.UnpackLog(event, "Append", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAppend is a log parse operation binding the contract event 0xf95b08176211c6a5d6b7bdf8a69536a9126b7fe20d3a8fc6cbfcc547c5b29f29.
+//
+// Solidity: event Append(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) ParseAppend(log types.Log) (*StakingAppend, error) {
+	event := new(StakingAppend)
+	if err := _Staking.contract.UnpackLog(event, "Append", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingAutoCompoundIterator is returned from FilterAutoCompound and is used to iterate over the raw logs and unpacked data for AutoCompound events raised by the Staking contract.
+type StakingAutoCompoundIterator struct {
+	Event *StakingAutoCompound // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingAutoCompoundIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingAutoCompound)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingAutoCompound)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingAutoCompoundIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingAutoCompoundIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingAutoCompound represents a AutoCompound event raised by the Staking contract.
+type StakingAutoCompound struct {
+	From   common.Address
+	Holder common.Address
+	Auto   bool
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterAutoCompound is a free log retrieval operation binding the contract event 0x71fc65d292170486f767e835760342f7cf706e8f89af31767da6ad41882de357.
+//
+// Solidity: event AutoCompound(address indexed from, address indexed holder, bool auto)
+func (_Staking *StakingFilterer) FilterAutoCompound(opts *bind.FilterOpts, from []common.Address, holder []common.Address) (*StakingAutoCompoundIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "AutoCompound", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingAutoCompoundIterator{contract: _Staking.contract, event: "AutoCompound", logs: logs, sub: sub}, nil
+}
+
+// WatchAutoCompound is a free log subscription operation binding the contract event 0x71fc65d292170486f767e835760342f7cf706e8f89af31767da6ad41882de357.
+//
+// Solidity: event AutoCompound(address indexed from, address indexed holder, bool auto)
+func (_Staking *StakingFilterer) WatchAutoCompound(opts *bind.WatchOpts, sink chan<- *StakingAutoCompound, from []common.Address, holder []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "AutoCompound", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingAutoCompound)
+				if err := _Staking.contract.UnpackLog(event, "AutoCompound", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseAutoCompound is a log parse operation binding the contract event 0x71fc65d292170486f767e835760342f7cf706e8f89af31767da6ad41882de357.
+//
+// Solidity: event AutoCompound(address indexed from, address indexed holder, bool auto)
+func (_Staking *StakingFilterer) ParseAutoCompound(log types.Log) (*StakingAutoCompound, error) {
+	event := new(StakingAutoCompound)
+	if err := _Staking.contract.UnpackLog(event, "AutoCompound", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingCancelIterator is returned from FilterCancel and is used to iterate over the raw logs and unpacked data for Cancel events raised by the Staking contract.
+type StakingCancelIterator struct {
+	Event *StakingCancel // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingCancelIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingCancel)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingCancel)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingCancelIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingCancelIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingCancel represents a Cancel event raised by the Staking contract.
+type StakingCancel struct {
+	From  common.Address
+	Value *big.Int
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterCancel is a free log retrieval operation binding the contract event 0x27f83af92b39768b17fe0c8d6922452702717efb8626d97e7a754e0b27d4f6d2.
+//
+// Solidity: event Cancel(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) FilterCancel(opts *bind.FilterOpts, from []common.Address) (*StakingCancelIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Cancel", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingCancelIterator{contract: _Staking.contract, event: "Cancel", logs: logs, sub: sub}, nil
+}
+
+// WatchCancel is a free log subscription operation binding the contract event 0x27f83af92b39768b17fe0c8d6922452702717efb8626d97e7a754e0b27d4f6d2.
+//
+// Solidity: event Cancel(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) WatchCancel(opts *bind.WatchOpts, sink chan<- *StakingCancel, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Cancel", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingCancel)
+				if err := _Staking.contract.UnpackLog(event, "Cancel", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseCancel is a log parse operation binding the contract event 0x27f83af92b39768b17fe0c8d6922452702717efb8626d97e7a754e0b27d4f6d2.
+//
+// Solidity: event Cancel(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) ParseCancel(log types.Log) (*StakingCancel, error) {
+	event := new(StakingCancel)
+	if err := _Staking.contract.UnpackLog(event, "Cancel", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingDelegateIterator is returned from FilterDelegate and is used to iterate over the raw logs and unpacked data for Delegate events raised by the Staking contract.
+type StakingDelegateIterator struct {
+	Event *StakingDelegate // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingDelegateIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingDelegate)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingDelegate)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingDelegateIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingDelegateIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingDelegate represents a Delegate event raised by the Staking contract.
+type StakingDelegate struct {
+	From   common.Address
+	Holder common.Address
+	Value  *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterDelegate is a free log retrieval operation binding the contract event 0x510b11bb3f3c799b11307c01ab7db0d335683ef5b2da98f7697de744f465eacc.
+//
+// Solidity: event Delegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) FilterDelegate(opts *bind.FilterOpts, from []common.Address, holder []common.Address) (*StakingDelegateIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Delegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingDelegateIterator{contract: _Staking.contract, event: "Delegate", logs: logs, sub: sub}, nil
+}
+
+// WatchDelegate is a free log subscription operation binding the contract event 0x510b11bb3f3c799b11307c01ab7db0d335683ef5b2da98f7697de744f465eacc.
+//
+// Solidity: event Delegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) WatchDelegate(opts *bind.WatchOpts, sink chan<- *StakingDelegate, from []common.Address, holder []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Delegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingDelegate)
+				if err := _Staking.contract.UnpackLog(event, "Delegate", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDelegate is a log parse operation binding the contract event 0x510b11bb3f3c799b11307c01ab7db0d335683ef5b2da98f7697de744f465eacc.
+//
+// Solidity: event Delegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) ParseDelegate(log types.Log) (*StakingDelegate, error) {
+	event := new(StakingDelegate)
+	if err := _Staking.contract.UnpackLog(event, "Delegate", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingDepositIterator is returned from FilterDeposit and is used to iterate over the raw logs and unpacked data for Deposit events raised by the Staking contract.
+type StakingDepositIterator struct {
+	Event *StakingDeposit // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingDepositIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingDeposit)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingDeposit)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingDepositIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingDepositIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingDeposit represents a Deposit event raised by the Staking contract.
+type StakingDeposit struct {
+	From   common.Address
+	Pubkey []byte
+	Value  *big.Int
+	Fee    *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeposit is a free log retrieval operation binding the contract event 0xc6b1f1535b3bb3bdffa2f97a671ab7bd6f2512deec58103fa47eb40ed9527427.
+//
+// Solidity: event Deposit(address indexed from, bytes pubkey, uint256 value, uint256 fee)
+func (_Staking *StakingFilterer) FilterDeposit(opts *bind.FilterOpts, from []common.Address) (*StakingDepositIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingDepositIterator{contract: _Staking.contract, event: "Deposit", logs: logs, sub: sub}, nil
+}
+
+// WatchDeposit is a free log subscription operation binding the contract event 0xc6b1f1535b3bb3bdffa2f97a671ab7bd6f2512deec58103fa47eb40ed9527427.
+//
+// Solidity: event Deposit(address indexed from, bytes pubkey, uint256 value, uint256 fee)
+func (_Staking *StakingFilterer) WatchDeposit(opts *bind.WatchOpts, sink chan<- *StakingDeposit, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingDeposit)
+				if err := _Staking.contract.UnpackLog(event, "Deposit", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeposit is a log parse operation binding the contract event 0xc6b1f1535b3bb3bdffa2f97a671ab7bd6f2512deec58103fa47eb40ed9527427.
+//
+// Solidity: event Deposit(address indexed from, bytes pubkey, uint256 value, uint256 fee)
+func (_Staking *StakingFilterer) ParseDeposit(log types.Log) (*StakingDeposit, error) {
+	event := new(StakingDeposit)
+	if err := _Staking.contract.UnpackLog(event, "Deposit", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingProposeIterator is returned from FilterPropose and is used to iterate over the raw logs and unpacked data for Propose events raised by the Staking contract.
+type StakingProposeIterator struct {
+	Event *StakingPropose // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingProposeIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingPropose)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingPropose)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingProposeIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingProposeIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingPropose represents a Propose event raised by the Staking contract.
+type StakingPropose struct {
+	From    common.Address
+	Id      *big.Int
+	Kind    uint8
+	Value   *big.Int
+	Account common.Address
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterPropose is a free log retrieval operation binding the contract event 0xd27a79f1526be39c88bcbe1cc8eafa54157d83f4b2f0b0b12e6de6d38fd84dd0.
+//
+// Solidity: event Propose(address indexed from, uint256 id, uint8 kind, uint256 value, address account)
+func (_Staking *StakingFilterer) FilterPropose(opts *bind.FilterOpts, from []common.Address) (*StakingProposeIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Propose", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingProposeIterator{contract: _Staking.contract, event: "Propose", logs: logs, sub: sub}, nil
+}
+
+// WatchPropose is a free log subscription operation binding the contract event 0xd27a79f1526be39c88bcbe1cc8eafa54157d83f4b2f0b0b12e6de6d38fd84dd0.
+//
+// Solidity: event Propose(address indexed from, uint256 id, uint8 kind, uint256 value, address account)
+func (_Staking *StakingFilterer) WatchPropose(opts *bind.WatchOpts, sink chan<- *StakingPropose, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Propose", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingPropose)
+				if err := _Staking.contract.UnpackLog(event, "Propose", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParsePropose is a log parse operation binding the contract event 0xd27a79f1526be39c88bcbe1cc8eafa54157d83f4b2f0b0b12e6de6d38fd84dd0.
+//
+// Solidity: event Propose(address indexed from, uint256 id, uint8 kind, uint256 value, address account)
+func (_Staking *StakingFilterer) ParsePropose(log types.Log) (*StakingPropose, error) {
+	event := new(StakingPropose)
+	if err := _Staking.contract.UnpackLog(event, "Propose", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingReceiptApprovalIterator is returned from FilterReceiptApproval and is used to iterate over the raw logs and unpacked data for ReceiptApproval events raised by the Staking contract.
+type StakingReceiptApprovalIterator struct {
+	Event *StakingReceiptApproval // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingReceiptApprovalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingReceiptApproval)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingReceiptApproval)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingReceiptApprovalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingReceiptApprovalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingReceiptApproval represents a ReceiptApproval event raised by the Staking contract.
+type StakingReceiptApproval struct {
+	Holder  common.Address
+	Owner   common.Address
+	Spender common.Address
+	Value   *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterReceiptApproval is a free log retrieval operation binding the contract event 0x9a72f1e6b1d5040a3d27ab298194ff0670f27047d9cf2194fef4ef2ac35efa7e.
+//
+// Solidity: event ReceiptApproval(address indexed holder, address indexed owner, address indexed spender, uint256 value)
+func (_Staking *StakingFilterer) FilterReceiptApproval(opts *bind.FilterOpts, holder []common.Address, owner []common.Address, spender []common.Address) (*StakingReceiptApprovalIterator, error) {
+
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+	var spenderRule []interface{}
+	for _, spenderItem := range spender {
+		spenderRule = append(spenderRule, spenderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "ReceiptApproval", holderRule, ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingReceiptApprovalIterator{contract: _Staking.contract, event: "ReceiptApproval", logs: logs, sub: sub}, nil
+}
+
+// WatchReceiptApproval is a free log subscription operation binding the contract event 0x9a72f1e6b1d5040a3d27ab298194ff0670f27047d9cf2194fef4ef2ac35efa7e.
+//
+// Solidity: event ReceiptApproval(address indexed holder, address indexed owner, address indexed spender, uint256 value)
+func (_Staking *StakingFilterer) WatchReceiptApproval(opts *bind.WatchOpts, sink chan<- *StakingReceiptApproval, holder []common.Address, owner []common.Address, spender []common.Address) (event.Subscription, error) {
+
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+	var ownerRule []interface{}
+	for _, ownerItem := range owner {
+		ownerRule = append(ownerRule, ownerItem)
+	}
+	var spenderRule []interface{}
+	for _, spenderItem := range spender {
+		spenderRule = append(spenderRule, spenderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "ReceiptApproval", holderRule, ownerRule, spenderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingReceiptApproval)
+				if err := _Staking.contract.UnpackLog(event, "ReceiptApproval", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseReceiptApproval is a log parse operation binding the contract event 0x9a72f1e6b1d5040a3d27ab298194ff0670f27047d9cf2194fef4ef2ac35efa7e.
+//
+// Solidity: event ReceiptApproval(address indexed holder, address indexed owner, address indexed spender, uint256 value)
+func (_Staking *StakingFilterer) ParseReceiptApproval(log types.Log) (*StakingReceiptApproval, error) {
+	event := new(StakingReceiptApproval)
+	if err := _Staking.contract.UnpackLog(event, "ReceiptApproval", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingReceiptTransferIterator is returned from FilterReceiptTransfer and is used to iterate over the raw logs and unpacked data for ReceiptTransfer events raised by the Staking contract.
+type StakingReceiptTransferIterator struct {
+	Event *StakingReceiptTransfer // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingReceiptTransferIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingReceiptTransfer)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingReceiptTransfer)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingReceiptTransferIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingReceiptTransferIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingReceiptTransfer represents a ReceiptTransfer event raised by the Staking contract.
+type StakingReceiptTransfer struct {
+	Holder common.Address
+	From   common.Address
+	To     common.Address
+	Value  *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterReceiptTransfer is a free log retrieval operation binding the contract event 0xf8fc6f19df1c33fb9b28c1fb71049d8f87855b9513c88ec87f7c4a601a9a64bd.
+//
+// Solidity: event ReceiptTransfer(address indexed holder, address indexed from, address indexed to, uint256 value)
+func (_Staking *StakingFilterer) FilterReceiptTransfer(opts *bind.FilterOpts, holder []common.Address, from []common.Address, to []common.Address) (*StakingReceiptTransferIterator, error) {
+
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "ReceiptTransfer", holderRule, fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingReceiptTransferIterator{contract: _Staking.contract, event: "ReceiptTransfer", logs: logs, sub: sub}, nil
+}
+
+// WatchReceiptTransfer is a free log subscription operation binding the contract event 0xf8fc6f19df1c33fb9b28c1fb71049d8f87855b9513c88ec87f7c4a601a9a64bd.
+//
+// Solidity: event ReceiptTransfer(address indexed holder, address indexed from, address indexed to, uint256 value)
+func (_Staking *StakingFilterer) WatchReceiptTransfer(opts *bind.WatchOpts, sink chan<- *StakingReceiptTransfer, holder []common.Address, from []common.Address, to []common.Address) (event.Subscription, error) {
+
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "ReceiptTransfer", holderRule, fromRule, toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingReceiptTransfer)
+				if err := _Staking.contract.UnpackLog(event, "ReceiptTransfer", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseReceiptTransfer is a log parse operation binding the contract event 0xf8fc6f19df1c33fb9b28c1fb71049d8f87855b9513c88ec87f7c4a601a9a64bd.
+//
+// Solidity: event ReceiptTransfer(address indexed holder, address indexed from, address indexed to, uint256 value)
+func (_Staking *StakingFilterer) ParseReceiptTransfer(log types.Log) (*StakingReceiptTransfer, error) {
+	event := new(StakingReceiptTransfer)
+	if err := _Staking.contract.UnpackLog(event, "ReceiptTransfer", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingSetFeeIterator is returned from FilterSetFee and is used to iterate over the raw logs and unpacked data for SetFee events raised by the Staking contract.
+type StakingSetFeeIterator struct {
+	Event *StakingSetFee // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingSetFeeIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingSetFee)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingSetFee)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingSetFeeIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingSetFeeIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingSetFee represents a SetFee event raised by the Staking contract.
+type StakingSetFee struct {
+	From common.Address
+	Fee  *big.Int
+	Raw  types.Log // Blockchain specific contextual infos
+}
+
+// FilterSetFee is a free log retrieval operation binding the contract event 0x01fe2943baee27f47add82886c2200f910c749c461c9b63c5fe83901a53bdb49.
+//
+// Solidity: event SetFee(address indexed from, uint256 fee)
+func (_Staking *StakingFilterer) FilterSetFee(opts *bind.FilterOpts, from []common.Address) (*StakingSetFeeIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "SetFee", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingSetFeeIterator{contract: _Staking.contract, event: "SetFee", logs: logs, sub: sub}, nil
+}
+
+// WatchSetFee is a free log subscription operation binding the contract event 0x01fe2943baee27f47add82886c2200f910c749c461c9b63c5fe83901a53bdb49.
+//
+// Solidity: event SetFee(address indexed from, uint256 fee)
+func (_Staking *StakingFilterer) WatchSetFee(opts *bind.WatchOpts, sink chan<- *StakingSetFee, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "SetFee", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingSetFee)
+				if err := _Staking.contract.UnpackLog(event, "SetFee", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSetFee is a log parse operation binding the contract event 0x01fe2943baee27f47add82886c2200f910c749c461c9b63c5fe83901a53bdb49.
+//
+// Solidity: event SetFee(address indexed from, uint256 fee)
+func (_Staking *StakingFilterer) ParseSetFee(log types.Log) (*StakingSetFee, error) {
+	event := new(StakingSetFee)
+	if err := _Staking.contract.UnpackLog(event, "SetFee", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingSetPubkeyIterator is returned from FilterSetPubkey and is used to iterate over the raw logs and unpacked data for SetPubkey events raised by the Staking contract.
+type StakingSetPubkeyIterator struct {
+	Event *StakingSetPubkey // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingSetPubkeyIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingSetPubkey)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingSetPubkey)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingSetPubkeyIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingSetPubkeyIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingSetPubkey represents a SetPubkey event raised by the Staking contract.
+type StakingSetPubkey struct {
+	From   common.Address
+	Pubkey []byte
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterSetPubkey is a free log retrieval operation binding the contract event 0xfbccee79fecaa7e28cf41dd589caed20e78f7a4093343263ff2844426a4456da.
+//
+// Solidity: event SetPubkey(address indexed from, bytes pubkey)
+func (_Staking *StakingFilterer) FilterSetPubkey(opts *bind.FilterOpts, from []common.Address) (*StakingSetPubkeyIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "SetPubkey", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingSetPubkeyIterator{contract: _Staking.contract, event: "SetPubkey", logs: logs, sub: sub}, nil
+}
+
+// WatchSetPubkey is a free log subscription operation binding the contract event 0xfbccee79fecaa7e28cf41dd589caed20e78f7a4093343263ff2844426a4456da.
+//
+// Solidity: event SetPubkey(address indexed from, bytes pubkey)
+func (_Staking *StakingFilterer) WatchSetPubkey(opts *bind.WatchOpts, sink chan<- *StakingSetPubkey, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "SetPubkey", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingSetPubkey)
+				if err := _Staking.contract.UnpackLog(event, "SetPubkey", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSetPubkey is a log parse operation binding the contract event 0xfbccee79fecaa7e28cf41dd589caed20e78f7a4093343263ff2844426a4456da.
+//
+// Solidity: event SetPubkey(address indexed from, bytes pubkey)
+func (_Staking *StakingFilterer) ParseSetPubkey(log types.Log) (*StakingSetPubkey, error) {
+	event := new(StakingSetPubkey)
+	if err := _Staking.contract.UnpackLog(event, "SetPubkey", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingSlashIterator is returned from FilterSlash and is used to iterate over the raw logs and unpacked data for Slash events raised by the Staking contract.
+type StakingSlashIterator struct {
+	Event *StakingSlash // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingSlashIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingSlash)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingSlash)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingSlashIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingSlashIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingSlash represents a Slash event raised by the Staking contract.
+type StakingSlash struct {
+	From     common.Address
+	Holder   common.Address
+	Evidence [32]byte
+	Value    *big.Int
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterSlash is a free log retrieval operation binding the contract event 0x2e52541d1e8cb934a6d00a4c3116edef7ec82a406e313e5087c9977d159a5ac9.
+//
+// Solidity: event Slash(address indexed from, address indexed holder, bytes32 evidence, uint256 value)
+func (_Staking *StakingFilterer) FilterSlash(opts *bind.FilterOpts, from []common.Address, holder []common.Address) (*StakingSlashIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Slash", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingSlashIterator{contract: _Staking.contract, event: "Slash", logs: logs, sub: sub}, nil
+}
+
+// WatchSlash is a free log subscription operation binding the contract event 0x2e52541d1e8cb934a6d00a4c3116edef7ec82a406e313e5087c9977d159a5ac9.
+//
+// Solidity: event Slash(address indexed from, address indexed holder, bytes32 evidence, uint256 value)
+func (_Staking *StakingFilterer) WatchSlash(opts *bind.WatchOpts, sink chan<- *StakingSlash, from []common.Address, holder []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Slash", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingSlash)
+				if err := _Staking.contract.UnpackLog(event, "Slash", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSlash is a log parse operation binding the contract event 0x2e52541d1e8cb934a6d00a4c3116edef7ec82a406e313e5087c9977d159a5ac9.
+//
+// Solidity: event Slash(address indexed from, address indexed holder, bytes32 evidence, uint256 value)
+func (_Staking *StakingFilterer) ParseSlash(log types.Log) (*StakingSlash, error) {
+	event := new(StakingSlash)
+	if err := _Staking.contract.UnpackLog(event, "Slash", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingUndelegateIterator is returned from FilterUndelegate and is used to iterate over the raw logs and unpacked data for Undelegate events raised by the Staking contract.
+type StakingUndelegateIterator struct {
+	Event *StakingUndelegate // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingUndelegateIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingUndelegate)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingUndelegate)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingUndelegateIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingUndelegateIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingUndelegate represents a Undelegate event raised by the Staking contract.
+type StakingUndelegate struct {
+	From   common.Address
+	Holder common.Address
+	Value  *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterUndelegate is a free log retrieval operation binding the contract event 0xbda8c0e95802a0e6788c3e9027292382d5a41b86556015f846b03a9874b2b827.
+//
+// Solidity: event Undelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) FilterUndelegate(opts *bind.FilterOpts, from []common.Address, holder []common.Address) (*StakingUndelegateIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Undelegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingUndelegateIterator{contract: _Staking.contract, event: "Undelegate", logs: logs, sub: sub}, nil
+}
+
+// WatchUndelegate is a free log subscription operation binding the contract event 0xbda8c0e95802a0e6788c3e9027292382d5a41b86556015f846b03a9874b2b827.
+//
+// Solidity: event Undelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) WatchUndelegate(opts *bind.WatchOpts, sink chan<- *StakingUndelegate, from []common.Address, holder []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Undelegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingUndelegate)
+				if err := _Staking.contract.UnpackLog(event, "Undelegate", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseUndelegate is a log parse operation binding the contract event 0xbda8c0e95802a0e6788c3e9027292382d5a41b86556015f846b03a9874b2b827.
+//
+// Solidity: event Undelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) ParseUndelegate(log types.Log) (*StakingUndelegate, error) {
+	event := new(StakingUndelegate)
+	if err := _Staking.contract.UnpackLog(event, "Undelegate", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingVoteIterator is returned from FilterVote and is used to iterate over the raw logs and unpacked data for Vote events raised by the Staking contract.
+type StakingVoteIterator struct {
+	Event *StakingVote // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingVoteIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingVote)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingVote)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingVoteIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingVoteIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingVote represents a Vote event raised by the Staking contract.
+type StakingVote struct {
+	From       common.Address
+	Id         *big.Int
+	Activation *big.Int
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterVote is a free log retrieval operation binding the contract event 0xafd3f234c1f8e944129b26b206d98e5752ad3336a4059938b4a3e990e9588530.
+//
+// Solidity: event Vote(address indexed from, uint256 id, uint256 activation)
+func (_Staking *StakingFilterer) FilterVote(opts *bind.FilterOpts, from []common.Address) (*StakingVoteIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Vote", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingVoteIterator{contract: _Staking.contract, event: "Vote", logs: logs, sub: sub}, nil
+}
+
+// WatchVote is a free log subscription operation binding the contract event 0xafd3f234c1f8e944129b26b206d98e5752ad3336a4059938b4a3e990e9588530.
+//
+// Solidity: event Vote(address indexed from, uint256 id, uint256 activation)
+func (_Staking *StakingFilterer) WatchVote(opts *bind.WatchOpts, sink chan<- *StakingVote, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Vote", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingVote)
+				if err := _Staking.contract.UnpackLog(event, "Vote", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseVote is a log parse operation binding the contract event 0xafd3f234c1f8e944129b26b206d98e5752ad3336a4059938b4a3e990e9588530.
+//
+// Solidity: event Vote(address indexed from, uint256 id, uint256 activation)
+func (_Staking *StakingFilterer) ParseVote(log types.Log) (*StakingVote, error) {
+	event := new(StakingVote)
+	if err := _Staking.contract.UnpackLog(event, "Vote", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingWithdrawIterator is returned from FilterWithdraw and is used to iterate over the raw logs and unpacked data for Withdraw events raised by the Staking contract.
+type StakingWithdrawIterator struct {
+	Event *StakingWithdraw // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingWithdrawIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingWithdraw)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingWithdraw)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingWithdrawIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingWithdrawIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingWithdraw represents a Withdraw event raised by the Staking contract.
+type StakingWithdraw struct {
+	From  common.Address
+	Value *big.Int
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdraw is a free log retrieval operation binding the contract event 0x884edad9ce6fa2440d8a54cc123490eb96d2768479d49ff9c7366125a9424364.
+//
+// Solidity: event Withdraw(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) FilterWithdraw(opts *bind.FilterOpts, from []common.Address) (*StakingWithdrawIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "Withdraw", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingWithdrawIterator{contract: _Staking.contract, event: "Withdraw", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdraw is a free log subscription operation binding the contract event 0x884edad9ce6fa2440d8a54cc123490eb96d2768479d49ff9c7366125a9424364.
+//
+// Solidity: event Withdraw(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) WatchWithdraw(opts *bind.WatchOpts, sink chan<- *StakingWithdraw, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "Withdraw", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingWithdraw)
+				if err := _Staking.contract.UnpackLog(event, "Withdraw", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWithdraw is a log parse operation binding the contract event 0x884edad9ce6fa2440d8a54cc123490eb96d2768479d49ff9c7366125a9424364.
+//
+// Solidity: event Withdraw(address indexed from, uint256 value)
+func (_Staking *StakingFilterer) ParseWithdraw(log types.Log) (*StakingWithdraw, error) {
+	event := new(StakingWithdraw)
+	if err := _Staking.contract.UnpackLog(event, "Withdraw", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// StakingWithdrawDelegateIterator is returned from FilterWithdrawDelegate and is used to iterate over the raw logs and unpacked data for WithdrawDelegate events raised by the Staking contract.
+type StakingWithdrawDelegateIterator struct {
+	Event *StakingWithdrawDelegate // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StakingWithdrawDelegateIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StakingWithdrawDelegate)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StakingWithdrawDelegate)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StakingWithdrawDelegateIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StakingWithdrawDelegateIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StakingWithdrawDelegate represents a WithdrawDelegate event raised by the Staking contract.
+type StakingWithdrawDelegate struct {
+	From   common.Address
+	Holder common.Address
+	Value  *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdrawDelegate is a free log retrieval operation binding the contract event 0xcbf5097d7ce966a22dae2c2ba95893b6450f70b4e7eb3b1c42fabc2b64df3dbb.
+//
+// Solidity: event WithdrawDelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) FilterWithdrawDelegate(opts *bind.FilterOpts, from []common.Address, holder []common.Address) (*StakingWithdrawDelegateIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.FilterLogs(opts, "WithdrawDelegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return &StakingWithdrawDelegateIterator{contract: _Staking.contract, event: "WithdrawDelegate", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdrawDelegate is a free log subscription operation binding the contract event 0xcbf5097d7ce966a22dae2c2ba95893b6450f70b4e7eb3b1c42fabc2b64df3dbb.
+//
+// Solidity: event WithdrawDelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) WatchWithdrawDelegate(opts *bind.WatchOpts, sink chan<- *StakingWithdrawDelegate, from []common.Address, holder []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+	var holderRule []interface{}
+	for _, holderItem := range holder {
+		holderRule = append(holderRule, holderItem)
+	}
+
+	logs, sub, err := _Staking.contract.WatchLogs(opts, "WithdrawDelegate", fromRule, holderRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(StakingWithdrawDelegate)
+				if err := _Staking.contract.UnpackLog(event, "WithdrawDelegate", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWithdrawDelegate is a log parse operation binding the contract event 0xcbf5097d7ce966a22dae2c2ba95893b6450f70b4e7eb3b1c42fabc2b64df3dbb.
+//
+// Solidity: event WithdrawDelegate(address indexed from, address indexed holder, uint256 value)
+func (_Staking *StakingFilterer) ParseWithdrawDelegate(log types.Log) (*StakingWithdrawDelegate, error) {
+	event := new(StakingWithdrawDelegate)
+	if err := _Staking.contract.UnpackLog(event, "WithdrawDelegate", log); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
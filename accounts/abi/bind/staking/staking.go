@@ -0,0 +1,39 @@
+// Package staking provides the Go binding of the staking precompile at
+// types.StakingAddress.
+package staking
+
+//go:generate abigen --abi contract/staking.abi --pkg contract --type Staking --out contract/staking.go
+
+import (
+	"github.com/iceming123/go-ice/accounts/abi/bind"
+	"github.com/iceming123/go-ice/accounts/abi/bind/staking/contract"
+	"github.com/iceming123/go-ice/core/types"
+)
+
+// Version is the version of the staking ABI in core/vm the binding is generated
+// from, it is bumped with every change of the ABI.
+const Version = "1.1.0"
+
+// Staking is the binding of the staking precompile, the transactions are sent
+// with the transact options of the session, set their Payer and PayerSigner to
+// have the gas paid by a sponsor.
+type Staking struct {
+	*contract.StakingSession
+	contractBackend bind.ContractBackend
+}
+
+// NewStaking creates a struct exposing the methods, the getters and the event
+// filterers of the staking precompile.
+func NewStaking(transactOpts *bind.TransactOpts, contractBackend bind.ContractBackend) (*Staking, error) {
+	staking, err := contract.NewStaking(types.StakingAddress, contractBackend)
+	if err != nil {
+		return nil, err
+	}
+	return &Staking{
+		&contract.StakingSession{
+			Contract:     staking,
+			TransactOpts: *transactOpts,
+		},
+		contractBackend,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package staking
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/iceming123/go-ice/accounts/abi"
+	"github.com/iceming123/go-ice/accounts/abi/bind"
+	"github.com/iceming123/go-ice/accounts/abi/bind/backends"
+	"github.com/iceming123/go-ice/accounts/abi/bind/staking/contract"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
+)
+
+var (
+	key, _      = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	addr        = crypto.PubkeyToAddress(key.PublicKey)
+	payerKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	payer       = crypto.PubkeyToAddress(payerKey.PublicKey)
+)
+
+func TestStaking(t *testing.T) {
+	balance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18))
+	sim := backends.NewSimulatedBackend(types.GenesisAlloc{addr: {Balance: balance}, payer: {Balance: balance}}, 10000000)
+	defer sim.Close()
+
+	staking, err := NewStaking(bind.NewKeyedTransactor(key), sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18))
+	if _, err := staking.Deposit(crypto.FromECDSAPub(&key.PublicKey), big.NewInt(50), value); err != nil {
+		t.Fatalf("deposit failed: %v", err)
+	}
+	sim.Commit()
+
+	locked, err := staking.LockedBalance(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked.Cmp(value) != 0 {
+		t.Fatalf("locked balance mismatch: have %v, want %v", locked, value)
+	}
+	deposit, err := staking.GetDeposit(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deposit.Staked.Cmp(value) != 0 {
+		t.Fatalf("staked mismatch: have %v, want %v", deposit.Staked, value)
+	}
+	it, err := staking.Contract.FilterDeposit(nil, []common.Address{addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !it.Next() {
+		t.Fatalf("deposit event not found: %v", it.Error())
+	}
+	if it.Event.From != addr || it.Event.Value.Cmp(value) != 0 || it.Event.Fee.Cmp(big.NewInt(50)) != 0 {
+		t.Fatalf("deposit event mismatch: %+v", it.Event)
+	}
+	it.Close()
+
+	// the gas of the sponsored transactions is paid by the payer
+	staking.TransactOpts.Payer = payer
+	staking.TransactOpts.PayerSigner = bind.NewKeyedPayer(payerKey)
+	before, _ := sim.BalanceAt(nil, addr, nil)
+	tx, err := staking.SetFee(big.NewInt(100))
+	if err != nil {
+		t.Fatalf("sponsored set fee failed: %v", err)
+	}
+	sim.Commit()
+	receipt, _ := sim.TransactionReceipt(nil, tx.Hash())
+	if receipt == nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("sponsored set fee reverted: %v", receipt)
+	}
+	if after, _ := sim.BalanceAt(nil, addr, nil); after.Cmp(before) != 0 {
+		t.Errorf("sender balance changed: have %v, want %v", after, before)
+	}
+	if after, _ := sim.BalanceAt(nil, payer, nil); after.Cmp(balance) >= 0 {
+		t.Errorf("payer balance not charged: %v", after)
+	}
+	staking.TransactOpts.PayerSigner = nil
+	if _, err := staking.SetFee(big.NewInt(200)); err == nil {
+		t.Errorf("sponsored transaction without the payer signer succeeded")
+	}
+}
+
+// Tests that the binding is regenerated with the ABI of the staking precompile.
+func TestStakingABI(t *testing.T) {
+	want, err := abi.JSON(strings.NewReader(vm.StakeABIJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	have, err := abi.JSON(strings.NewReader(contract.StakingABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("binding of version %s is out of date with the staking ABI", Version)
+	}
+}
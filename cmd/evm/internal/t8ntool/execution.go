@@ -0,0 +1,234 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of go-ice.
+//
+// go-ice is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ice is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ice. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/common/math"
+	"github.com/iceming123/go-ice/consensus"
+	"github.com/iceming123/go-ice/core"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// Prestate is the state and block environment the transactions are applied on.
+type Prestate struct {
+	Env stEnv `json:"env"`
+	Pre Alloc `json:"pre"`
+}
+
+// ExecutionResult contains the roots, receipts and rejected transactions of
+// the block built by a state transition.
+type ExecutionResult struct {
+	StateRoot   common.Hash    `json:"stateRoot"`
+	TxRoot      common.Hash    `json:"txRoot"`
+	ReceiptRoot common.Hash    `json:"receiptRoot"`
+	LogsHash    common.Hash    `json:"logsHash"`
+	Bloom       types.Bloom    `json:"logsBloom"`
+	Receipts    types.Receipts `json:"receipts"`
+	Rejected    []*rejectedTx  `json:"rejected,omitempty"`
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	Fees        *hexutil.Big   `json:"fees"`
+}
+
+// rejectedTx is a transaction which could not be applied on the prestate.
+type rejectedTx struct {
+	Index int    `json:"index"`
+	Err   string `json:"error"`
+}
+
+type stEnv struct {
+	Coinbase    common.Address                      `json:"currentCoinbase"`
+	GasLimit    math.HexOrDecimal64                 `json:"currentGasLimit"`
+	Number      math.HexOrDecimal64                 `json:"currentNumber"`
+	Timestamp   math.HexOrDecimal64                 `json:"currentTimestamp"`
+	BlockHashes map[math.HexOrDecimal64]common.Hash `json:"blockHashes,omitempty"`
+}
+
+// chainContext serves the headers of the block hashes given in the env to
+// the BLOCKHASH opcode.
+type chainContext struct {
+	hashes map[uint64]common.Hash
+}
+
+func (c *chainContext) Engine() consensus.Engine {
+	return nil
+}
+
+func (c *chainContext) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if c.hashes[number] != hash || number == 0 {
+		return nil
+	}
+	return &types.Header{Number: new(big.Int).SetUint64(number), ParentHash: c.hashes[number-1]}
+}
+
+// Apply applies the given transactions on the prestate, returning the post
+// state and the execution result. Transactions failing consensus checks are
+// reported as rejected instead of aborting the transition.
+//
+// Only the transactions themselves are executed, the engine finalisation
+// (fee distribution to the committee, elections) is not, so the collected
+// fees are reported in the result instead.
+func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig *params.ChainConfig,
+	txs types.Transactions, getTracerFn func(txIndex int, txHash common.Hash) (tracer vm.Tracer, err error)) (*state.StateDB, *ExecutionResult, error) {
+
+	hashes := make(map[uint64]common.Hash, len(pre.Env.BlockHashes))
+	for num, hash := range pre.Env.BlockHashes {
+		hashes[uint64(num)] = hash
+	}
+	number := uint64(pre.Env.Number)
+	var parentHash common.Hash
+	if number > 0 {
+		parentHash = hashes[number-1]
+	}
+	statedb, err := MakePreState(icedb.NewMemDatabase(), chainConfig, pre.Pre, number)
+	if err != nil {
+		return nil, nil, NewError(ErrorEVM, err)
+	}
+	var (
+		chain       = &chainContext{hashes: hashes}
+		gaspool     = new(core.GasPool)
+		usedGas     = new(uint64)
+		feeAmount   = new(big.Int)
+		receipts    = make(types.Receipts, 0)
+		rejectedTxs []*rejectedTx
+		includedTxs types.Transactions
+	)
+	header := &types.Header{
+		ParentHash: parentHash,
+		Proposer:   pre.Env.Coinbase,
+		Number:     new(big.Int).SetUint64(number),
+		GasLimit:   uint64(pre.Env.GasLimit),
+		Time:       new(big.Int).SetUint64(uint64(pre.Env.Timestamp)),
+	}
+	gaspool.AddGas(header.GasLimit)
+
+	for i, tx := range txs {
+		tracer, err := getTracerFn(len(includedTxs), tx.Hash())
+		if err != nil {
+			return nil, nil, err
+		}
+		vmConfig.Tracer = tracer
+		vmConfig.Debug = (tracer != nil)
+		statedb.Prepare(tx.Hash(), common.Hash{}, len(includedTxs))
+
+		snapshot := statedb.Snapshot()
+		receipt, err := applyTransaction(chainConfig, chain, gaspool, statedb, header, tx, usedGas, feeAmount, vmConfig)
+		if err != nil {
+			statedb.RevertToSnapshot(snapshot)
+			log.Info("rejected tx", "index", i, "hash", tx.Hash(), "error", err)
+			rejectedTxs = append(rejectedTxs, &rejectedTx{i, err.Error()})
+			continue
+		}
+		includedTxs = append(includedTxs, tx)
+		receipts = append(receipts, receipt)
+	}
+	root, err := statedb.Commit(true)
+	if err != nil {
+		return nil, nil, NewError(ErrorEVM, fmt.Errorf("could not commit state: %v", err))
+	}
+	execRs := &ExecutionResult{
+		StateRoot:   root,
+		TxRoot:      types.DeriveSha(includedTxs),
+		ReceiptRoot: types.DeriveSha(receipts),
+		Bloom:       types.CreateBloom(receipts),
+		LogsHash:    rlpHash(statedb.Logs()),
+		Receipts:    receipts,
+		Rejected:    rejectedTxs,
+		GasUsed:     hexutil.Uint64(*usedGas),
+		Fees:        (*hexutil.Big)(feeAmount),
+	}
+	return statedb, execRs, nil
+}
+
+// applyTransaction runs a transaction through core.ApplyTransaction, making
+// sure a panic of the EVM is reported as a rejection instead of killing the
+// tool.
+func applyTransaction(config *params.ChainConfig, chain *chainContext, gp *core.GasPool, statedb *state.StateDB,
+	header *types.Header, tx *types.Transaction, usedGas *uint64, feeAmount *big.Int, cfg vm.Config) (receipt *types.Receipt, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			receipt, err = nil, fmt.Errorf("evm panic: %v", r)
+		}
+	}()
+	return core.ApplyTransaction(config, chain, gp, statedb, header, tx, usedGas, feeAmount, cfg)
+}
+
+// MakePreState creates a state containing the given allocation. If the staking
+// fork is active and the allocation does not contain the staking state, an
+// empty one is created the same way the genesis initialises it.
+func MakePreState(db icedb.Database, config *params.ChainConfig, accounts Alloc, number uint64) (*state.StateDB, error) {
+	sdb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, sdb)
+	for addr, a := range accounts {
+		statedb.SetCode(addr, a.Code)
+		statedb.SetNonce(addr, uint64(a.Nonce))
+		if a.Balance != nil {
+			statedb.SetBalance(addr, (*big.Int)(a.Balance))
+		}
+		for k, v := range a.Storage {
+			statedb.SetState(addr, k, v)
+		}
+		for k, v := range a.POSStorage {
+			statedb.SetPOSState(addr, k, v)
+		}
+	}
+	if config.IsTIP7(new(big.Int).SetUint64(number)) {
+		key := common.BytesToHash(types.StakingAddress[:])
+		if len(statedb.GetPOSState(types.StakingAddress, key)) == 0 {
+			impl := vm.NewImpawnImpl()
+			if consensus.IsTIP8(new(big.Int).SetUint64(number), config, nil) {
+				// Open the first epoch like the genesis does, deposits are
+				// refused before that.
+				if _, err := impl.DoElections(1, 0); err != nil {
+					log.Warn("Staking elections failed", "err", err)
+				}
+				if err := impl.Shift(1, 0); err != nil {
+					log.Warn("Staking epoch shift failed", "err", err)
+				}
+			}
+			if err := impl.Save(statedb, types.StakingAddress); err != nil {
+				return nil, err
+			}
+			statedb.SetNonce(types.StakingAddress, 1)
+			statedb.SetCode(types.StakingAddress, types.StakingAddress[:])
+		}
+	}
+	// Commit and re-open to start with a clean state.
+	root, err := statedb.Commit(false)
+	if err != nil {
+		return nil, err
+	}
+	return state.New(root, sdb)
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
@@ -0,0 +1,331 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of go-ice.
+//
+// go-ice is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ice is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ice. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/common/math"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	ErrorEVM      = 2
+	ErrorVMConfig = 3
+
+	ErrorJson = 10
+	ErrorIO   = 11
+
+	stdinSelector = "stdin"
+)
+
+// NumberedError is an error carrying the exit code of the tool.
+type NumberedError struct {
+	errorCode int
+	err       error
+}
+
+func NewError(errorCode int, err error) *NumberedError {
+	return &NumberedError{errorCode, err}
+}
+
+func (n *NumberedError) Error() string {
+	return fmt.Sprintf("ERROR(%d): %v", n.errorCode, n.err.Error())
+}
+
+func (n *NumberedError) Code() int {
+	return n.errorCode
+}
+
+// Account is an account of the prestate and poststate allocations. Besides
+// the regular storage, the staking account carries the raw values of the
+// staking state in posStorage.
+type Account struct {
+	Code       hexutil.Bytes                 `json:"code,omitempty"`
+	Storage    map[common.Hash]common.Hash   `json:"storage,omitempty"`
+	POSStorage map[common.Hash]hexutil.Bytes `json:"posStorage,omitempty"`
+	Balance    *math.HexOrDecimal256         `json:"balance"`
+	Nonce      math.HexOrDecimal64           `json:"nonce,omitempty"`
+}
+
+// Alloc is the set of accounts of a state.
+type Alloc map[common.Address]Account
+
+type input struct {
+	Alloc Alloc              `json:"alloc,omitempty"`
+	Env   *stEnv             `json:"env,omitempty"`
+	Txs   types.Transactions `json:"txs,omitempty"`
+}
+
+// Main is the entry point of the t8n command. It reads the prestate, the
+// environment and the signed transactions, applies them and writes the post
+// state allocation and the execution result.
+func Main(ctx *cli.Context) error {
+	// Configure the go-ice logger
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.Int(VerbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	var (
+		err     error
+		baseDir = ""
+	)
+	var getTracer func(txIndex int, txHash common.Hash) (vm.Tracer, error)
+
+	// If user specified a basedir, make sure it exists
+	if ctx.IsSet(OutputBasedir.Name) {
+		if base := ctx.String(OutputBasedir.Name); len(base) > 0 {
+			err := os.MkdirAll(base, 0755)
+			if err != nil {
+				return NewError(ErrorIO, fmt.Errorf("failed creating output basedir: %v", err))
+			}
+			baseDir = base
+		}
+	}
+	if ctx.Bool(TraceFlag.Name) {
+		// Configure the EVM logger
+		logConfig := &vm.LogConfig{
+			DisableStack:      ctx.Bool(TraceDisableStackFlag.Name),
+			DisableMemory:     ctx.Bool(TraceDisableMemoryFlag.Name),
+			DisableReturnData: ctx.Bool(TraceDisableReturnDataFlag.Name),
+			Debug:             true,
+		}
+		var prevFile *os.File
+		// This one closes the last file
+		defer func() {
+			if prevFile != nil {
+				prevFile.Close()
+			}
+		}()
+		getTracer = func(txIndex int, txHash common.Hash) (vm.Tracer, error) {
+			if prevFile != nil {
+				prevFile.Close()
+			}
+			traceFile, err := os.Create(filepath.Join(baseDir, fmt.Sprintf("trace-%d-%v.jsonl", txIndex, txHash.String())))
+			if err != nil {
+				return nil, NewError(ErrorIO, fmt.Errorf("failed creating trace-file: %v", err))
+			}
+			prevFile = traceFile
+			return vm.NewJSONLogger(logConfig, traceFile), nil
+		}
+	} else {
+		getTracer = func(txIndex int, txHash common.Hash) (tracer vm.Tracer, err error) {
+			return nil, nil
+		}
+	}
+	// We need to load three things: alloc, env and transactions. May be either in
+	// stdin input or in files.
+	// Check if anything needs to be read from stdin
+	var (
+		prestate Prestate
+		txs      types.Transactions // txs to apply
+		allocStr = ctx.String(InputAllocFlag.Name)
+
+		envStr    = ctx.String(InputEnvFlag.Name)
+		txStr     = ctx.String(InputTxsFlag.Name)
+		inputData = &input{}
+	)
+
+	if allocStr == stdinSelector || envStr == stdinSelector || txStr == stdinSelector {
+		decoder := json.NewDecoder(os.Stdin)
+		if err := decoder.Decode(inputData); err != nil {
+			return NewError(ErrorJson, fmt.Errorf("failed unmarshaling stdin: %v", err))
+		}
+	}
+	if allocStr != stdinSelector {
+		if err := readJSONFile(allocStr, &inputData.Alloc); err != nil {
+			return err
+		}
+	}
+	prestate.Pre = inputData.Alloc
+
+	// Set the block environment
+	if envStr != stdinSelector {
+		var env stEnv
+		if err := readJSONFile(envStr, &env); err != nil {
+			return err
+		}
+		inputData.Env = &env
+	}
+	if inputData.Env == nil {
+		return NewError(ErrorJson, fmt.Errorf("missing env"))
+	}
+	prestate.Env = *inputData.Env
+
+	// Construct the chainconfig
+	chainConfig, err := networkConfig(ctx.String(NetworkFlag.Name))
+	if err != nil {
+		return NewError(ErrorVMConfig, err)
+	}
+	if chainID := ctx.Int64(ChainIDFlag.Name); chainID != 0 {
+		chainConfig.ChainID = big.NewInt(chainID)
+	}
+	if txStr != stdinSelector {
+		if err := readJSONFile(txStr, &txs); err != nil {
+			return err
+		}
+	} else {
+		txs = inputData.Txs
+	}
+	// Run the test and aggregate the result
+	state, result, err := prestate.Apply(vm.Config{}, chainConfig, txs, getTracer)
+	if err != nil {
+		return err
+	}
+	// Dump the execution result
+	collector := make(Alloc)
+	collectAlloc(state, collector)
+	return dispatchOutput(ctx, baseDir, result, collector)
+}
+
+// networkConfig returns a copy of the chain rules of the given network.
+func networkConfig(network string) (*params.ChainConfig, error) {
+	var config params.ChainConfig
+	switch network {
+	case "mainnet":
+		config = *params.MainnetChainConfig
+	case "testnet":
+		config = *params.TestnetChainConfig
+	case "devnet":
+		config = *params.DevnetChainConfig
+	case "singlenode":
+		config = *params.SingleNodeChainConfig
+	case "developer":
+		config = *params.DeveloperChainConfig
+	default:
+		return nil, fmt.Errorf("unknown network %q", network)
+	}
+	return &config, nil
+}
+
+// readJSONFile decodes the given JSON file into val.
+func readJSONFile(fname string, val interface{}) error {
+	inFile, err := os.Open(fname)
+	if err != nil {
+		return NewError(ErrorIO, fmt.Errorf("failed reading %s file: %v", fname, err))
+	}
+	defer inFile.Close()
+	decoder := json.NewDecoder(inFile)
+	if err := decoder.Decode(val); err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed unmarshaling %s file: %v", fname, err))
+	}
+	return nil
+}
+
+// collectAlloc gathers the accounts of the post state. The storage values of
+// the staking account are kept raw as they are not RLP encoded words.
+func collectAlloc(statedb *state.StateDB, alloc Alloc) {
+	for addrHex, dumpAccount := range statedb.RawDump().Accounts {
+		addr := common.HexToAddress(addrHex)
+		balance, _ := new(big.Int).SetString(dumpAccount.Balance, 10)
+		account := Account{
+			Code:    common.FromHex(dumpAccount.Code),
+			Balance: (*math.HexOrDecimal256)(balance),
+			Nonce:   math.HexOrDecimal64(dumpAccount.Nonce),
+		}
+		if addr == types.StakingAddress {
+			account.POSStorage = make(map[common.Hash]hexutil.Bytes)
+			statedb.ForEachPOSStorage(addr, func(key common.Hash, value []byte) bool {
+				account.POSStorage[key] = common.CopyBytes(value)
+				return true
+			})
+		} else if len(dumpAccount.Storage) > 0 {
+			account.Storage = make(map[common.Hash]common.Hash)
+			for k, v := range dumpAccount.Storage {
+				_, content, _, err := rlp.Split(common.FromHex(v))
+				if err != nil {
+					log.Error("Invalid storage value", "address", addr, "key", k, "err", err)
+					continue
+				}
+				account.Storage[common.HexToHash(k)] = common.BytesToHash(content)
+			}
+		}
+		alloc[addr] = account
+	}
+}
+
+// saveFile marshalls the object to the given file
+func saveFile(baseDir, filename string, data interface{}) error {
+	b, err := json.MarshalIndent(data, "", " ")
+	if err != nil {
+		return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+	}
+	location := filepath.Join(baseDir, filename)
+	if err = ioutil.WriteFile(location, b, 0644); err != nil {
+		return NewError(ErrorIO, fmt.Errorf("failed writing output: %v", err))
+	}
+	log.Info("Wrote file", "file", location)
+	return nil
+}
+
+// dispatchOutput writes the output data to either stderr or stdout, or to the specified
+// files
+func dispatchOutput(ctx *cli.Context, baseDir string, result *ExecutionResult, alloc Alloc) error {
+	stdOutObject := make(map[string]interface{})
+	stdErrObject := make(map[string]interface{})
+	dispatch := func(baseDir, fName, name string, obj interface{}) error {
+		switch fName {
+		case "stdout":
+			stdOutObject[name] = obj
+		case "stderr":
+			stdErrObject[name] = obj
+		case "":
+			// don't save
+		default: // save to file
+			if err := saveFile(baseDir, fName, obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := dispatch(baseDir, ctx.String(OutputAllocFlag.Name), "alloc", alloc); err != nil {
+		return err
+	}
+	if err := dispatch(baseDir, ctx.String(OutputResultFlag.Name), "result", result); err != nil {
+		return err
+	}
+	if len(stdOutObject) > 0 {
+		b, err := json.MarshalIndent(stdOutObject, "", " ")
+		if err != nil {
+			return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+		}
+		os.Stdout.Write(b)
+		os.Stdout.Write([]byte("\n"))
+	}
+	if len(stdErrObject) > 0 {
+		b, err := json.MarshalIndent(stdErrObject, "", " ")
+		if err != nil {
+			return NewError(ErrorJson, fmt.Errorf("failed marshalling output: %v", err))
+		}
+		os.Stderr.Write(b)
+		os.Stderr.Write([]byte("\n"))
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of go-ice.
+//
+// go-ice is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ice is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ice. If not, see <http://www.gnu.org/licenses/>.
+
+package t8ntool
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/iceming123/go-ice/accounts/abi"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/math"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/params"
+)
+
+var (
+	senderKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	payerKey, _  = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	sender       = crypto.PubkeyToAddress(senderKey.PublicKey)
+	payer        = crypto.PubkeyToAddress(payerKey.PublicKey)
+)
+
+func testPrestate(number uint64) *Prestate {
+	return &Prestate{
+		Env: stEnv{
+			GasLimit: math.HexOrDecimal64(params.GenesisGasLimit),
+			Number:   math.HexOrDecimal64(number),
+		},
+		Pre: Alloc{
+			sender: Account{Balance: (*math.HexOrDecimal256)(new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether)))},
+			payer:  Account{Balance: (*math.HexOrDecimal256)(big.NewInt(params.Ether))},
+		},
+	}
+}
+
+func noTracer(int, common.Hash) (vm.Tracer, error) { return nil, nil }
+
+// Tests that transactions sponsored by a payer charge the gas to the payer
+// and that invalid transactions are rejected without aborting the transition.
+func TestTransitionPayer(t *testing.T) {
+	config, _ := networkConfig("developer")
+	signer := types.MakeSigner(config, big.NewInt(1))
+	recipient := common.Address{0x01}
+
+	tx := types.NewTransaction_Payment(0, recipient, big.NewInt(1000), nil, params.TxGas, big.NewInt(1), nil, payer)
+	tx, _ = types.SignTx(tx, signer, senderKey)
+	tx, _ = types.SignTx_Payment(tx, signer, payerKey)
+
+	bad, _ := types.SignTx(types.NewTransaction(5, recipient, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, senderKey)
+
+	statedb, result, err := testPrestate(1).Apply(vm.Config{}, config, types.Transactions{tx, bad}, noTracer)
+	if err != nil {
+		t.Fatalf("failed to apply transactions: %v", err)
+	}
+	if len(result.Receipts) != 1 || len(result.Rejected) != 1 || result.Rejected[0].Index != 1 {
+		t.Fatalf("inclusion mismatch: have %d receipts, %v rejected", len(result.Receipts), result.Rejected)
+	}
+	if uint64(result.GasUsed) != params.TxGas {
+		t.Errorf("gas used mismatch: have %d, want %d", result.GasUsed, params.TxGas)
+	}
+	if have, want := statedb.GetBalance(payer), new(big.Int).Sub(big.NewInt(params.Ether), new(big.Int).SetUint64(params.TxGas)); have.Cmp(want) != 0 {
+		t.Errorf("payer balance mismatch: have %v, want %v", have, want)
+	}
+	if have := statedb.GetBalance(recipient); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("recipient balance mismatch: have %v, want 1000", have)
+	}
+	if statedb.IntermediateRoot(true) != result.StateRoot {
+		t.Errorf("state root mismatch")
+	}
+}
+
+// Tests that deposits to the staking precompile are executed and that the
+// staking state survives a round trip through the allocation output.
+func TestTransitionStaking(t *testing.T) {
+	config, _ := networkConfig("developer")
+	signer := types.MakeSigner(config, big.NewInt(1))
+
+	stakingABI, err := abi.JSON(strings.NewReader(vm.StakeABIJSON))
+	if err != nil {
+		t.Fatalf("failed to parse staking abi: %v", err)
+	}
+	value := new(big.Int).Mul(big.NewInt(20000), big.NewInt(params.Ether))
+	input, err := stakingABI.Pack("deposit", crypto.FromECDSAPub(&senderKey.PublicKey), big.NewInt(100), value)
+	if err != nil {
+		t.Fatalf("failed to pack deposit: %v", err)
+	}
+	tx, _ := types.SignTx(types.NewTransaction(0, types.StakingAddress, big.NewInt(0), 3000000, big.NewInt(1), input), signer, senderKey)
+
+	statedb, result, err := testPrestate(1).Apply(vm.Config{}, config, types.Transactions{tx}, noTracer)
+	if err != nil {
+		t.Fatalf("failed to apply transactions: %v", err)
+	}
+	if len(result.Rejected) != 0 || result.Receipts[0].Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("deposit failed: rejected %v", result.Rejected)
+	}
+	if locked := statedb.GetPOSLocked(sender); locked.Cmp(value) != 0 {
+		t.Errorf("locked balance mismatch: have %v, want %v", locked, value)
+	}
+	// Feed the post state back in and check that nothing changes
+	alloc := make(Alloc)
+	collectAlloc(statedb, alloc)
+
+	if len(alloc[types.StakingAddress].POSStorage) == 0 {
+		t.Fatalf("staking state missing from the allocation")
+	}
+	pre := testPrestate(2)
+	pre.Pre = alloc
+	_, post, err := pre.Apply(vm.Config{}, config, nil, noTracer)
+	if err != nil {
+		t.Fatalf("failed to apply empty transition: %v", err)
+	}
+	if post.StateRoot != result.StateRoot {
+		t.Errorf("state root mismatch after round trip: have %x, want %x", post.StateRoot, result.StateRoot)
+	}
+}
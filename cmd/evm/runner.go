@@ -0,0 +1,281 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of go-ice.
+//
+// go-ice is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ice is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ice. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	goruntime "runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/iceming123/go-ice/cmd/utils"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core"
+	"github.com/iceming123/go-ice/core/asm"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/core/vm/runtime"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var runCommand = cli.Command{
+	Action:      runCmd,
+	Name:        "run",
+	Usage:       "run arbitrary evm binary",
+	ArgsUsage:   "<code>",
+	Description: `The run command runs arbitrary EVM code.`,
+}
+
+// readGenesis will read the given JSON format genesis file and return
+// the initialized Genesis structure
+func readGenesis(genesisPath string) *core.Genesis {
+	// Make sure we have a valid genesis JSON
+	if len(genesisPath) == 0 {
+		utils.Fatalf("Must supply path to genesis JSON file")
+	}
+	file, err := os.Open(genesisPath)
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	return genesis
+}
+
+// compileEASM assembles the given EVM assembly file into hex encoded bytecode.
+func compileEASM(fn string, debug bool) string {
+	src, err := ioutil.ReadFile(fn)
+	if err != nil {
+		utils.Fatalf("Could not load code from file: %v", err)
+	}
+	compiler := asm.NewCompiler(debug)
+	compiler.Feed(asm.Lex(src, debug))
+
+	bin, errs := compiler.Compile()
+	if len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		utils.Fatalf("Failed to compile %s", fn)
+	}
+	return bin
+}
+
+func runCmd(ctx *cli.Context) error {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.GlobalInt(VerbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+	logconfig := &vm.LogConfig{
+		DisableMemory:     ctx.GlobalBool(DisableMemoryFlag.Name),
+		DisableStack:      ctx.GlobalBool(DisableStackFlag.Name),
+		DisableStorage:    ctx.GlobalBool(DisableStorageFlag.Name),
+		DisableReturnData: ctx.GlobalBool(DisableReturnDataFlag.Name),
+		Debug:             ctx.GlobalBool(DebugFlag.Name),
+	}
+
+	var (
+		tracer      vm.Tracer
+		debugLogger *vm.StructLogger
+		statedb     *state.StateDB
+		sender      = common.BytesToAddress([]byte("sender"))
+		receiver    = common.BytesToAddress([]byte("receiver"))
+		genesis     = new(core.Genesis)
+	)
+	if ctx.GlobalBool(MachineFlag.Name) {
+		tracer = vm.NewJSONLogger(logconfig, os.Stdout)
+	} else if ctx.GlobalBool(DebugFlag.Name) {
+		debugLogger = vm.NewStructLogger(logconfig)
+		tracer = debugLogger
+	} else {
+		debugLogger = vm.NewStructLogger(logconfig)
+	}
+	if ctx.GlobalString(GenesisFlag.Name) != "" {
+		genesis = readGenesis(ctx.GlobalString(GenesisFlag.Name))
+	}
+	// Default to a chain config with every fork active, the staking state is
+	// initialised by the genesis in that case.
+	if genesis.Config == nil {
+		genesis.Config = params.DeveloperChainConfig
+	}
+	db := icedb.NewMemDatabase()
+	statedb, _ = state.New(genesis.ToFastBlock(db).Root(), state.NewDatabase(db))
+
+	if ctx.GlobalString(SenderFlag.Name) != "" {
+		sender = common.HexToAddress(ctx.GlobalString(SenderFlag.Name))
+	}
+	statedb.CreateAccount(sender)
+
+	if ctx.GlobalString(ReceiverFlag.Name) != "" {
+		receiver = common.HexToAddress(ctx.GlobalString(ReceiverFlag.Name))
+	}
+
+	var code []byte
+	codeFileFlag := ctx.GlobalString(CodeFileFlag.Name)
+	codeFlag := ctx.GlobalString(CodeFlag.Name)
+
+	// The '--code' or '--codefile' flag overrides code in state
+	if codeFileFlag != "" || codeFlag != "" {
+		var hexcode []byte
+		if codeFileFlag != "" {
+			var err error
+			// If - is specified, it means that code comes from stdin
+			if codeFileFlag == "-" {
+				//Try reading from stdin
+				if hexcode, err = ioutil.ReadAll(os.Stdin); err != nil {
+					fmt.Printf("Could not load code from stdin: %v\n", err)
+					os.Exit(1)
+				}
+			} else {
+				// Codefile with hex assembly
+				if hexcode, err = ioutil.ReadFile(codeFileFlag); err != nil {
+					fmt.Printf("Could not load code from file: %v\n", err)
+					os.Exit(1)
+				}
+			}
+		} else {
+			hexcode = []byte(codeFlag)
+		}
+		hexcode = bytes.TrimSpace(hexcode)
+		if len(hexcode)%2 != 0 {
+			fmt.Printf("Invalid input length for hex data (%d)\n", len(hexcode))
+			os.Exit(1)
+		}
+		code = common.FromHex(string(hexcode))
+	} else if fn := ctx.Args().First(); len(fn) > 0 {
+		// EASM-file to compile
+		code = common.Hex2Bytes(compileEASM(fn, ctx.GlobalBool(DebugFlag.Name)))
+	}
+	initialGas := ctx.GlobalUint64(GasFlag.Name)
+	if genesis.GasLimit != 0 {
+		initialGas = genesis.GasLimit
+	}
+	runtimeConfig := runtime.Config{
+		Origin:      sender,
+		State:       statedb,
+		GasLimit:    initialGas,
+		GasPrice:    utils.GlobalBig(ctx, PriceFlag.Name),
+		Value:       utils.GlobalBig(ctx, ValueFlag.Name),
+		Difficulty:  genesis.Difficulty,
+		Time:        new(big.Int).SetUint64(genesis.Timestamp),
+		Coinbase:    genesis.Coinbase,
+		BlockNumber: new(big.Int).SetUint64(genesis.Number),
+		ChainConfig: genesis.Config,
+		EVMConfig: vm.Config{
+			Tracer: tracer,
+			Debug:  ctx.GlobalBool(DebugFlag.Name) || ctx.GlobalBool(MachineFlag.Name),
+		},
+	}
+
+	if cpuProfilePath := ctx.GlobalString(CPUProfileFlag.Name); cpuProfilePath != "" {
+		f, err := os.Create(cpuProfilePath)
+		if err != nil {
+			fmt.Println("could not create CPU profile: ", err)
+			os.Exit(1)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fmt.Println("could not start CPU profile: ", err)
+			os.Exit(1)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	var hexInput []byte
+	if inputFileFlag := ctx.GlobalString(InputFlag.Name); inputFileFlag != "" {
+		hexInput = []byte(inputFileFlag)
+	}
+	input := common.FromHex(string(bytes.TrimSpace(hexInput)))
+
+	var (
+		ret     []byte
+		leftGas uint64
+		err     error
+	)
+	var mstats goruntime.MemStats
+	goruntime.ReadMemStats(&mstats)
+	allocs, bytesAllocated := mstats.Mallocs, mstats.TotalAlloc
+
+	tstart := time.Now()
+	if ctx.GlobalBool(CreateFlag.Name) {
+		input = append(code, input...)
+		ret, _, leftGas, err = runtime.Create(input, &runtimeConfig)
+	} else {
+		if len(code) > 0 {
+			statedb.SetCode(receiver, code)
+		}
+		ret, leftGas, err = runtime.Call(receiver, input, &runtimeConfig)
+	}
+	execTime := time.Since(tstart)
+
+	if ctx.GlobalBool(DumpFlag.Name) {
+		statedb.Commit(true)
+		fmt.Println(string(statedb.Dump()))
+	}
+
+	if memProfilePath := ctx.GlobalString(MemProfileFlag.Name); memProfilePath != "" {
+		f, err := os.Create(memProfilePath)
+		if err != nil {
+			fmt.Println("could not create memory profile: ", err)
+			os.Exit(1)
+		}
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			fmt.Println("could not write memory profile: ", err)
+			os.Exit(1)
+		}
+		f.Close()
+	}
+
+	if ctx.GlobalBool(DebugFlag.Name) {
+		if debugLogger != nil {
+			fmt.Fprintln(os.Stderr, "#### TRACE ####")
+			vm.WriteTrace(os.Stderr, debugLogger.StructLogs())
+		}
+		fmt.Fprintln(os.Stderr, "#### LOGS ####")
+		vm.WriteLogs(os.Stderr, statedb.Logs())
+	}
+
+	if ctx.GlobalBool(StatDumpFlag.Name) {
+		goruntime.ReadMemStats(&mstats)
+		fmt.Fprintf(os.Stderr, `evm execution time: %v
+heap objects:       %d
+allocations:        %d
+total allocations:  %d
+GC calls:           %d
+Gas used:           %d
+
+`, execTime, mstats.HeapObjects, mstats.Mallocs-allocs, mstats.TotalAlloc-bytesAllocated, mstats.NumGC, initialGas-leftGas)
+	}
+	if tracer == nil {
+		fmt.Printf("0x%x\n", ret)
+		if err != nil {
+			fmt.Printf(" error: %v\n", err)
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync/atomic"
@@ -13,13 +14,16 @@ import (
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/console"
 	"github.com/iceming123/go-ice/core"
+	"github.com/iceming123/go-ice/core/rawdb"
 	"github.com/iceming123/go-ice/core/snailchain"
+	"github.com/iceming123/go-ice/core/state/pruner"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/event"
 	"github.com/iceming123/go-ice/ice/downloader"
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/log"
 	"github.com/iceming123/go-ice/trie"
+	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 
@@ -56,6 +60,8 @@ It expects the genesis file as argument.`,
 			utils.GCModeFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
+			utils.CacheSnapshotFlag,
+			utils.SnapshotFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -114,9 +120,10 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		Action:    utils.MigrateFlags(copyDb),
 		Name:      "copydb",
 		Usage:     "Create a local chain from a target chaindata folder",
-		ArgsUsage: "<sourceChaindataDir>",
+		ArgsUsage: "<sourceChaindataDir> [<sourceAncientDir>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
@@ -126,7 +133,9 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
-The first argument must be the directory containing the blockchain to download from`,
+The first argument must be the directory containing the blockchain to download from,
+the optional second one the directory of its ancient chain segments (default =
+inside the source chaindata)`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -135,11 +144,13 @@ The first argument must be the directory containing the blockchain to download f
 		ArgsUsage: " ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			//utils.LightModeFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
-Remove blockchain and state databases`,
+Remove blockchain and state databases, the ancient chain segments are confirmed
+separately so they can be kept for a resync`,
 	}
 	dumpCommand = cli.Command{
 		Action:    utils.MigrateFlags(dump),
@@ -156,6 +167,29 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "icechain dump 0" to dump the genesis block.`,
 	}
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Prune the stale state data of a full node",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.PruneRetainFlag,
+			utils.BloomFilterSizeFlag,
+			utils.TestnetFlag,
+			utils.DevnetFlag,
+			utils.SingleNodeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command deletes the trie nodes and contract codes that aren't
+referenced by the state of the last --prune.retain fast blocks, the genesis or
+the persisted snapshot. The impawn state is part of the state and is kept with
+it. The node must be stopped while pruning, and the head state is verified to
+be complete afterwards.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -242,15 +276,15 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*icedb.LDBDatabase)
+	db := levelDB(chainDb)
 
-	stats, err := db.LDB().GetProperty("leveldb.stats")
+	stats, err := db.GetProperty("leveldb.stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
+	ioStats, err := db.GetProperty("leveldb.iostats")
 	if err != nil {
 		utils.Fatalf("Failed to read database iostats: %v", err)
 	}
@@ -275,18 +309,18 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err = db.CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
+	stats, err = db.GetProperty("leveldb.stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
+	ioStats, err = db.GetProperty("leveldb.iostats")
 	if err != nil {
 		utils.Fatalf("Failed to read database iostats: %v", err)
 	}
@@ -357,7 +391,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*icedb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -373,7 +407,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*icedb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack).(icedb.Iteratee)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -385,9 +419,12 @@ func exportPreimages(ctx *cli.Context) error {
 
 func copyDb(ctx *cli.Context) error {
 	// Ensure we have a source chain directory to copy
-	if len(ctx.Args()) != 1 {
+	if len(ctx.Args()) < 1 {
 		utils.Fatalf("Source chaindata directory path argument missing")
 	}
+	if len(ctx.Args()) > 2 {
+		utils.Fatalf("Too many arguments given")
+	}
 	// Initialize a new chain for the running node to sync into
 	stack := makeFullNode(ctx)
 	fchain, schain, chainDb := utils.MakeChain(ctx, stack)
@@ -401,7 +438,15 @@ func copyDb(ctx *cli.Context) error {
 	sdl := downloader.New(syncmode, 0, chainDb, new(event.TypeMux), schain, nil, nil, fdl)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := icedb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	ldb, err := icedb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	if err != nil {
+		return err
+	}
+	ancient := filepath.Join(ctx.Args().First(), "ancient")
+	if len(ctx.Args()) == 2 {
+		ancient = ctx.Args().Get(1)
+	}
+	db, err := rawdb.NewDatabaseWithFreezer(ldb, ancient, "")
 	if err != nil {
 		return err
 	}
@@ -442,7 +487,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*icedb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = levelDB(chainDb).CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -451,34 +496,68 @@ func copyDb(ctx *cli.Context) error {
 }
 
 func removeDB(ctx *cli.Context) error {
-	stack, _ := makeConfigNode(ctx)
-
-	for _, name := range []string{"chaindata", "lightchaindata"} {
-		// Ensure the database exists in the first place
-		logger := log.New("database", name)
+	stack, config := makeConfigNode(ctx)
 
-		dbdir := stack.ResolvePath(name)
-		if !common.FileExist(dbdir) {
-			logger.Info("Database doesn't exist, skipping", "path", dbdir)
-			continue
-		}
-		// Confirm removal and execute
-		fmt.Println(dbdir)
-		confirm, err := console.Stdin.PromptConfirm("Remove this database?")
-		switch {
-		case err != nil:
-			utils.Fatalf("%v", err)
-		case !confirm:
-			logger.Warn("Database deletion aborted")
-		default:
-			start := time.Now()
-			os.RemoveAll(dbdir)
-			logger.Info("Database successfully deleted", "elapsed", common.PrettyDuration(time.Since(start)))
-		}
+	// The ancient chain segments live inside the chaindata unless moved away
+	chaindata := stack.ResolvePath("chaindata")
+	ancient := filepath.Join(chaindata, "ancient")
+	if config.Ice.DatabaseFreezer != "" {
+		ancient = stack.ResolvePath(config.Ice.DatabaseFreezer)
 	}
+	confirmAndRemoveDB("chaindata", chaindata, ancient)
+	confirmAndRemoveDB("ancient", ancient, "")
+	confirmAndRemoveDB("lightchaindata", stack.ResolvePath("lightchaindata"), "")
 	return nil
 }
 
+// confirmAndRemoveDB prompts the user for a last confirmation and removes the
+// folder if accepted, the keep folder nested in it is left untouched.
+func confirmAndRemoveDB(name string, dbdir string, keep string) {
+	// Ensure the database exists in the first place
+	logger := log.New("database", name)
+
+	if !common.FileExist(dbdir) {
+		logger.Info("Database doesn't exist, skipping", "path", dbdir)
+		return
+	}
+	// Confirm removal and execute
+	fmt.Println(dbdir)
+	confirm, err := console.Stdin.PromptConfirm("Remove this database?")
+	switch {
+	case err != nil:
+		utils.Fatalf("%v", err)
+	case !confirm:
+		logger.Warn("Database deletion aborted")
+	default:
+		start := time.Now()
+		filepath.Walk(dbdir, func(path string, info os.FileInfo, err error) error {
+			// If we're at the top level folder, recurse into
+			if path == dbdir {
+				return nil
+			}
+			// Skip the nested ancient folder, it's confirmed on its own
+			if path == keep {
+				return filepath.SkipDir
+			}
+			if info != nil && info.IsDir() {
+				os.RemoveAll(path)
+				return filepath.SkipDir
+			}
+			os.Remove(path)
+			return nil
+		})
+		os.Remove(dbdir) // Fails on purpose if the kept folder is nested
+		logger.Info("Database successfully deleted", "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// levelDB returns the LevelDB instance backing the chain database.
+func levelDB(db icedb.Database) *leveldb.DB {
+	return db.(interface {
+		LDB() *leveldb.DB
+	}).LDB()
+}
+
 func dump(ctx *cli.Context) error {
 	stack := makeFullNode(ctx)
 	_, schain, chainDb := utils.MakeChain(ctx, stack)
@@ -505,6 +584,26 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+// pruneState deletes the stale state data of the chain database, keeping the
+// state of the recent fast blocks only.
+func pruneState(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	defer chaindb.Close()
+
+	p, err := pruner.NewPruner(chaindb, ctx.GlobalUint64(utils.PruneRetainFlag.Name), ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to create state pruner: %v", err)
+	}
+	if err := p.Prune(); err != nil {
+		log.Error("Failed to prune state", "err", err)
+		return err
+	}
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
@@ -137,6 +137,14 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 
 	utils.RegisterIceService(stack, &cfg.Ice)
 
+	// Add the GraphQL endpoint if requested, it is served by the HTTP-RPC server.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		if !ctx.GlobalBool(utils.RPCEnabledFlag.Name) {
+			utils.Fatalf("GraphQL requires the HTTP-RPC server to be enabled (--%s)", utils.RPCEnabledFlag.Name)
+		}
+		utils.RegisterGraphQLService(stack)
+	}
+
 	// Add the Icechain Stats daemon if requested.
 	if cfg.Icestats.URL != "" {
 		utils.RegisterIceStatsService(stack, cfg.Icestats.URL)
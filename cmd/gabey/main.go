@@ -44,6 +44,7 @@ var (
 		utils.BootnodesFlag,
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
+		utils.AncientFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
 
@@ -56,6 +57,7 @@ var (
 		utils.TxPoolGlobalSlotsFlag,
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
+		utils.TxPoolPayerSlotsFlag,
 		utils.TxPoolLifetimeFlag,
 
 		utils.SnailPoolJournalFlag,
@@ -64,6 +66,8 @@ var (
 		utils.SyncModeFlag,
 
 		utils.SingleNodeFlag,
+		utils.DeveloperFlag,
+		utils.DeveloperPeriodFlag,
 
 		utils.EnableElectionFlag,
 
@@ -72,6 +76,7 @@ var (
 		utils.BFTIPFlag,
 		utils.BftKeyFileFlag,
 		utils.BftKeyHexFlag,
+		utils.TbftHealthFlag,
 
 		utils.GCModeFlag,
 		utils.LightServFlag,
@@ -80,6 +85,8 @@ var (
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
 		utils.CacheGCFlag,
+		utils.CacheSnapshotFlag,
+		utils.SnapshotFlag,
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
@@ -92,6 +99,8 @@ var (
 		utils.MineFruitFlag,
 		utils.MiningEnabledFlag,
 		utils.MiningRemoteEnableFlag,
+		utils.StratumAddrFlag,
+		utils.StratumDifficultyFlag,
 		utils.GasTargetFlag,
 		utils.GasLimitFlag,
 
@@ -122,6 +131,7 @@ var (
 		utils.RPCListenAddrFlag,
 		utils.RPCPortFlag,
 		utils.RPCApiFlag,
+		utils.GraphQLEnabledFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
@@ -156,6 +166,9 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		pruneStateCommand,
+		// See snapshotcmd.go:
+		snapshotCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -297,7 +310,7 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 		}
 	}()
 	// Start auxiliary services if enabled
-	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.MineFruitFlag.Name) {
+	if ctx.GlobalBool(utils.MiningEnabledFlag.Name) || ctx.GlobalBool(utils.MineFruitFlag.Name) || ctx.GlobalBool(utils.DeveloperFlag.Name) {
 		// Mining only makes sense if a full Icechain node is running
 		if ctx.GlobalString(utils.SyncModeFlag.Name) == "light" {
 			utils.Fatalf("Light clients do not support mining")
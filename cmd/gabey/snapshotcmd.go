@@ -0,0 +1,66 @@
+package main
+
+import (
+	"github.com/iceming123/go-ice/cmd/utils"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state/snapshot"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/trie"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	snapshotCommand = cli.Command{
+		Name:        "snapshot",
+		Usage:       "A set of commands based on the state snapshot",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "verify",
+				Usage:     "Recalculate the state snapshot against the state trie",
+				ArgsUsage: "<root>",
+				Action:    utils.MigrateFlags(verifySnapshot),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.TestnetFlag,
+					utils.DevnetFlag,
+					utils.SingleNodeFlag,
+				},
+				Description: `
+gabey snapshot verify <state-root>
+will traverse the whole account and storage tries of the state root along with
+the persisted state snapshot, and compare every leaf with the snapshot entry.
+If no root is given, the root of the persisted snapshot is used.`,
+			},
+		},
+	}
+)
+
+// verifySnapshot checks the persisted snapshot against the state trie.
+func verifySnapshot(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		utils.Fatalf("Too many arguments given")
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	defer chaindb.Close()
+
+	root := rawdb.ReadSnapshotRoot(chaindb)
+	if ctx.NArg() == 1 {
+		root = common.HexToHash(ctx.Args()[0])
+	}
+	if root == (common.Hash{}) {
+		utils.Fatalf("No state snapshot found")
+	}
+	if err := snapshot.VerifyState(chaindb, trie.NewDatabase(chaindb), root); err != nil {
+		log.Error("Failed to verify state snapshot", "root", root, "err", err)
+		return err
+	}
+	log.Info("Verified the state snapshot", "root", root)
+	return nil
+}
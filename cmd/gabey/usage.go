@@ -53,6 +53,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			configFileFlag,
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.NetworkIdFlag,
@@ -67,12 +68,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.LightKDFFlag,
 		},
 	},
-	//{Name: "DEVELOPER CHAIN",
-	//	Flags: []cli.Flag{
-	//		utils.DeveloperFlag,
-	//		utils.DeveloperPeriodFlag,
-	//	},
-	//},
+	{Name: "DEVELOPER CHAIN",
+		Flags: []cli.Flag{
+			utils.DeveloperFlag,
+			utils.DeveloperPeriodFlag,
+		},
+	},
 	{Name: "SINGLE NODE MODEL START",
 		Flags: []cli.Flag{
 			utils.SingleNodeFlag,
@@ -90,6 +91,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.BFTStandbyPortFlag,
 			utils.BftKeyFileFlag,
 			utils.BftKeyHexFlag,
+			utils.TbftHealthFlag,
 		},
 	},
 
@@ -105,6 +107,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolGlobalSlotsFlag,
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
+			utils.TxPoolPayerSlotsFlag,
 			utils.TxPoolLifetimeFlag,
 		},
 	},
@@ -114,6 +117,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.CacheFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
+			utils.CacheSnapshotFlag,
+			utils.SnapshotFlag,
 			utils.TrieCacheGenFlag,
 		},
 	},
@@ -131,6 +136,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCListenAddrFlag,
 			utils.RPCPortFlag,
 			utils.RPCApiFlag,
+			utils.GraphQLEnabledFlag,
 			utils.WSEnabledFlag,
 			utils.WSListenAddrFlag,
 			utils.WSPortFlag,
@@ -166,6 +172,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MiningEnabledFlag,
 			utils.MineFruitFlag,
 			utils.MiningRemoteEnableFlag,
+			utils.StratumAddrFlag,
+			utils.StratumDifficultyFlag,
 			utils.MinerThreadsFlag,
 			utils.CoinbaseFlag,
 			utils.GasTargetFlag,
@@ -61,6 +61,10 @@ var (
 		Name:  "snailnumber",
 		Usage: "Query reward use snail number,please current snail number -14",
 	}
+	AutoCompoundFlag = cli.BoolFlag{
+		Name:  "auto",
+		Usage: "Stake the delegation rewards in the next epoch",
+	}
 	ImpawnFlags = []cli.Flag{
 		KeyFlag,
 		KeyStoreFlag,
@@ -91,6 +95,7 @@ func init() {
 		PubKeyKeyFlag,
 		SnailNumberFlag,
 		BFTKeyKeyFlag,
+		AutoCompoundFlag,
 	}
 	app.Action = utils.MigrateFlags(impawn)
 	app.CommandNotFound = func(ctx *cli.Context, cmd string) {
@@ -211,6 +211,13 @@ var withdrawDCommand = cli.Command{
 	Flags:  append(ImpawnFlags, AddressFlag),
 }
 
+var compoundDCommand = cli.Command{
+	Name:   "compound",
+	Usage:  "Set the delegation rewards staked automatically in the next epoch",
+	Action: utils.MigrateFlags(compoundDImpawn),
+	Flags:  append(ImpawnFlags, AddressFlag, AutoCompoundFlag),
+}
+
 var delegateCommand = cli.Command{
 	Name:  "delegate",
 	Usage: "Delegate staking on a validator address",
@@ -218,6 +225,7 @@ var delegateCommand = cli.Command{
 		depositDCommand,
 		cancelDCommand,
 		withdrawDCommand,
+		compoundDCommand,
 	},
 }
 
@@ -285,6 +293,24 @@ func withdrawDImpawn(ctx *cli.Context) error {
 	return nil
 }
 
+func compoundDImpawn(ctx *cli.Context) error {
+	loadPrivate(ctx)
+	conn, url := dialConn(ctx)
+	printBaseInfo(conn, url)
+
+	address := ctx.GlobalString(AddressFlag.Name)
+	if !common.IsHexAddress(address) {
+		printError("Must input correct address")
+	}
+	holder = common.HexToAddress(address)
+
+	input := packInput("setAutoCompound", holder, ctx.GlobalBool(AutoCompoundFlag.Name))
+	txHash := sendContractTransaction(conn, from, types.StakingAddress, new(big.Int).SetInt64(0), priKey, input)
+
+	getResult(conn, txHash, true, true)
+	return nil
+}
+
 var queryTxCommand = cli.Command{
 	Name:   "querytx",
 	Usage:  "Query tx hash, get transaction result",
@@ -42,10 +42,13 @@ import (
 
 	"github.com/iceming123/go-ice/consensus/minerva"
 	"github.com/iceming123/go-ice/core"
+	"github.com/iceming123/go-ice/core/rawdb"
 	"github.com/iceming123/go-ice/core/snailchain"
 	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/state/pruner"
 	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/graphql"
 	"github.com/iceming123/go-ice/ice"
 	"github.com/iceming123/go-ice/ice/downloader"
 	"github.com/iceming123/go-ice/ice/gasprice"
@@ -130,6 +133,10 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -173,6 +180,14 @@ var (
 		Name:  "singlenode",
 		Usage: "sing node model start",
 	}
+	DeveloperFlag = cli.BoolFlag{
+		Name:  "dev",
+		Usage: "Ephemeral single node chain with a pre-funded developer account, mining enabled",
+	}
+	DeveloperPeriodFlag = cli.IntFlag{
+		Name:  "dev.period",
+		Usage: "Fast block period to use in developer mode (0 = seal only if transactions are pending)",
+	}
 
 	//election setting
 	EnableElectionFlag = cli.BoolFlag{
@@ -203,11 +218,15 @@ var (
 		Name:  "bftkeyhex",
 		Usage: "committee generate bft_privatekey as hex (for testing)",
 	}
+	TbftHealthFlag = cli.BoolFlag{
+		Name:  "tbft.health",
+		Usage: "Enables the health manager to switch the unhealthy committee members",
+	}
 
 	defaultSyncMode = ice.DefaultConfig.SyncMode
 	SyncModeFlag    = TextMarshalerFlag{
 		Name:  "syncmode",
-		Usage: `Blockchain sync mode ("full", or "snapshot")`,
+		Usage: `Blockchain sync mode ("full", "snap", or "snapshot")`,
 		Value: &defaultSyncMode,
 	}
 	GCModeFlag = cli.StringFlag{
@@ -278,6 +297,11 @@ var (
 		Usage: "Maximum number of non-executable transaction slots for all accounts",
 		Value: ice.DefaultConfig.TxPool.GlobalQueue,
 	}
+	TxPoolPayerSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.payerslots",
+		Usage: "Maximum number of sponsored transaction slots permitted per payer",
+		Value: ice.DefaultConfig.TxPool.PayerSlots,
+	}
 	TxPoolLifetimeFlag = cli.DurationFlag{
 		Name:  "txpool.lifetime",
 		Usage: "Maximum amount of time non-executable transaction are queued",
@@ -315,6 +339,25 @@ var (
 		Usage: "Percentage of cache memory allowance to use for trie pruning",
 		Value: 25,
 	}
+	CacheSnapshotFlag = cli.IntFlag{
+		Name:  "cache.snapshot",
+		Usage: "Percentage of cache memory allowance to use for snapshot caching",
+		Value: 10,
+	}
+	SnapshotFlag = cli.BoolFlag{
+		Name:  "snapshot",
+		Usage: "Enables the flat state snapshot to accelerate the state access",
+	}
+	PruneRetainFlag = cli.Uint64Flag{
+		Name:  "prune.retain",
+		Usage: "Number of recent fast blocks whose state is kept by the state pruning",
+		Value: pruner.DefaultRetainBlocks,
+	}
+	BloomFilterSizeFlag = cli.Uint64Flag{
+		Name:  "bloomfilter.size",
+		Usage: "Megabytes of memory allocated to the bloom filter of the state pruning",
+		Value: pruner.DefaultBloomSize,
+	}
 	TrieCacheGenFlag = cli.IntFlag{
 		Name:  "trie-cache-gens",
 		Usage: "Number of trie node generations to keep in memory",
@@ -340,6 +383,15 @@ var (
 		Usage: "Number of CPU threads to use for mining",
 		Value: runtime.NumCPU() - 1,
 	}
+	StratumAddrFlag = cli.StringFlag{
+		Name:  "stratum.addr",
+		Usage: "Stratum server listening address for remote miners (implies --remote)",
+	}
+	StratumDifficultyFlag = cli.Uint64Flag{
+		Name:  "stratum.difficulty",
+		Usage: "Share difficulty handed to the stratum miners",
+		Value: ice.DefaultConfig.StratumDifficulty,
+	}
 
 	GasTargetFlag = cli.Uint64Flag{
 		Name:  "gastarget",
@@ -431,6 +483,10 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable GraphQL on the HTTP-RPC server. Note that GraphQL can only be started if an HTTP server is started as well.",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -944,6 +1000,14 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.DiscoveryV5 = true
 	}
 
+	if ctx.GlobalBool(DeveloperFlag.Name) {
+		// --dev mode can't use p2p networking.
+		cfg.MaxPeers = 0
+		cfg.ListenAddr = ":0"
+		cfg.NoDiscovery = true
+		cfg.DiscoveryV5 = false
+	}
+
 	if netrestrict := ctx.GlobalString(NetrestrictFlag.Name); netrestrict != "" {
 		list, err := netutil.ParseNetlist(netrestrict)
 		if err != nil {
@@ -974,6 +1038,8 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "devnet")
 	case ctx.GlobalBool(SingleNodeFlag.Name):
 		cfg.DataDir = ctx.GlobalString(DataDirFlag.Name)
+	case ctx.GlobalBool(DeveloperFlag.Name):
+		cfg.DataDir = "" // unless explicitly requested, use memory databases
 	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
@@ -1023,6 +1089,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolGlobalQueueFlag.Name) {
 		cfg.GlobalQueue = ctx.GlobalUint64(TxPoolGlobalQueueFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPayerSlotsFlag.Name) {
+		cfg.PayerSlots = ctx.GlobalUint64(TxPoolPayerSlotsFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
@@ -1089,7 +1158,7 @@ func CheckExclusive(ctx *cli.Context, args ...interface{}) {
 // SetIcechainConfig applies ice-related command line flags to the config.
 func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 	// Avoid conflicting network flags
-	CheckExclusive(ctx, TestnetFlag, DevnetFlag, SingleNodeFlag)
+	CheckExclusive(ctx, TestnetFlag, DevnetFlag, SingleNodeFlag, DeveloperFlag)
 	//CheckExclusive(ctx, LightServFlag, LightModeFlag)
 	CheckExclusive(ctx, LightServFlag, SyncModeFlag, "light")
 
@@ -1122,7 +1191,14 @@ func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 	if ctx.GlobalBool(MiningRemoteEnableFlag.Name) {
 		cfg.RemoteMine = true
 	}
-	if ctx.GlobalBool(SingleNodeFlag.Name) {
+	if ctx.GlobalIsSet(StratumAddrFlag.Name) {
+		cfg.StratumAddr = ctx.GlobalString(StratumAddrFlag.Name)
+		cfg.RemoteMine = true
+	}
+	if ctx.GlobalIsSet(StratumDifficultyFlag.Name) {
+		cfg.StratumDifficulty = ctx.GlobalUint64(StratumDifficultyFlag.Name)
+	}
+	if ctx.GlobalBool(SingleNodeFlag.Name) || ctx.GlobalBool(DeveloperFlag.Name) {
 		cfg.NodeType = true
 	}
 	if ctx.GlobalIsSet(BFTIPFlag.Name) {
@@ -1148,6 +1224,9 @@ func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 	if ctx.GlobalBool(EnableElectionFlag.Name) {
 		cfg.EnableElection = true
 	}
+	if ctx.GlobalBool(TbftHealthFlag.Name) {
+		cfg.EnableHealthMgr = true
+	}
 	if cfg.EnableElection && !cfg.NodeType {
 		if cfg.Host == "" {
 			Fatalf("election set true,Option %q  must be exist.", BFTIPFlag.Name)
@@ -1169,6 +1248,9 @@ func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1185,6 +1267,11 @@ func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
+	if ctx.GlobalBool(SnapshotFlag.Name) {
+		cfg.SnapshotCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheSnapshotFlag.Name) / 100
+	} else {
+		cfg.SnapshotCache = 0
+	}
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
 	}
@@ -1226,6 +1313,34 @@ func SetIcechainConfig(ctx *cli.Context, stack *node.Node, cfg *ice.Config) {
 			cfg.NetworkId = 176
 		}
 		cfg.Genesis = core.DefaultSingleNodeGenesisBlock()
+	case ctx.GlobalBool(DeveloperFlag.Name):
+		if !ctx.GlobalIsSet(NetworkIdFlag.Name) {
+			cfg.NetworkId = 1337
+		}
+		// Create new developer account or reuse existing one
+		var (
+			developer accounts.Account
+			err       error
+		)
+		if accs := ks.Accounts(); len(accs) > 0 {
+			developer = ks.Accounts()[0]
+		} else {
+			developer, err = ks.NewAccount("")
+			if err != nil {
+				Fatalf("Failed to create developer account: %v", err)
+			}
+		}
+		if err := ks.Unlock(developer, ""); err != nil {
+			Fatalf("Failed to unlock developer account: %v", err)
+		}
+		log.Info("Using developer account", "address", developer.Address)
+
+		cfg.Developer = true
+		cfg.DeveloperPeriod = uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name))
+		cfg.Etherbase = developer.Address
+		cfg.Mine = true
+		cfg.MinervaHash.PowMode = minerva.ModeFake
+		cfg.Genesis = core.DeveloperGenesisBlock(developer.Address, crypto.FromECDSAPub(&cfg.PrivateKey.PublicKey))
 	}
 	// TODO(fjl): move trie cache generations into config
 	if gen := ctx.GlobalInt(TrieCacheGenFlag.Name); gen > 0 {
@@ -1272,6 +1387,20 @@ func RegisterIceStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds the GraphQL endpoint to the HTTP-RPC server of
+// the given node.
+func RegisterGraphQLService(stack *node.Node) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		var fullNode *ice.Icechain
+		if err := ctx.Service(&fullNode); err != nil {
+			return nil, fmt.Errorf("GraphQL requires a full node: %v", err)
+		}
+		return graphql.New(fullNode.APIBackend)
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1307,6 +1436,15 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) icedb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	if ldb, ok := chainDb.(*icedb.LDBDatabase); ok {
+		ancient := filepath.Join(stack.ResolvePath(name), "ancient")
+		if ctx.GlobalIsSet(AncientFlag.Name) {
+			ancient = stack.ResolvePath(ctx.GlobalString(AncientFlag.Name))
+		}
+		if chainDb, err = rawdb.NewDatabaseWithFreezer(ldb, ancient, ""); err != nil {
+			Fatalf("Could not open ancient database: %v", err)
+		}
+	}
 	return chainDb
 }
 
@@ -1355,11 +1493,17 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (fchain *core.BlockChain, sch
 		Disabled:      ctx.GlobalString(GCModeFlag.Name) == "archive",
 		TrieNodeLimit: ice.DefaultConfig.TrieCache,
 		TrieTimeLimit: ice.DefaultConfig.TrieTimeout,
+		SnapshotLimit: ice.DefaultConfig.SnapshotCache,
 	}
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieNodeLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
+	if ctx.GlobalBool(SnapshotFlag.Name) {
+		cache.SnapshotLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheSnapshotFlag.Name) / 100
+	} else {
+		cache.SnapshotLimit = 0
+	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
 
 	fchain, err = core.NewBlockChain(chainDb, cache, config, engine, vmcfg)
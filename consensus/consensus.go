@@ -191,6 +191,9 @@ type PoW interface {
 }
 
 func IsTIP8(fastHeadNumber *big.Int, config *params.ChainConfig, reader SnailChainReader) bool {
+	if config.TIP8 == nil {
+		return false
+	}
 	if config.TIP8.CID.Sign() < 0 {
 		return true
 	}
@@ -274,7 +277,7 @@ func InitTIP8(config *params.ChainConfig, reader SnailChainReader) {
 	}
 }
 func makeImpawInitState(config *params.ChainConfig, state *state.StateDB, fastNumber *big.Int) bool {
-	if config.TIP7.FastNumber.Cmp(fastNumber) == 0 {
+	if config.TIP7 != nil && config.TIP7.FastNumber.Cmp(fastNumber) == 0 {
 		stateAddress := types.StakingAddress
 		key := common.BytesToHash(stateAddress[:])
 		obj := state.GetPOSState(stateAddress, key)
@@ -906,6 +906,9 @@ func (m *Minerva) Finalize(chain consensus.ChainReader, header *types.Header, st
 	if err := m.finalizeFastGas(state, header.Number, header.Hash(), feeAmount); err != nil {
 		return nil, nil, err
 	}
+	if err := m.finalizeUptime(chain, state, header); err != nil {
+		return nil, nil, err
+	}
 	if err := m.finalizeValidators(chain, state, header.Number); err != nil {
 		return nil, nil, err
 	}
@@ -973,12 +976,20 @@ func (m *Minerva) finalizeValidators(chain consensus.ChainReader, state *state.S
 		epoch := types.GetEpochFromHeight(fastNumber.Uint64())
 
 		if fastNumber.Uint64() == epoch.EndHeight-params.ElectionPoint {
+			var offline []common.Address
+			if chain.Config().IsTIP10(fastNumber) {
+				uptime := vm.NewValidatorUptime()
+				if err := uptime.Load(state, types.StakingAddress); err != nil {
+					return err
+				}
+				offline = uptime.OfflineMembers(epoch.EpochID, chain.Config().MinUptimeForElection())
+			}
 			i := vm.NewImpawnImpl()
 			error := i.Load(state, types.StakingAddress)
-			if es, err := i.DoElections(epoch.EpochID+1, fastNumber.Uint64()); err != nil {
+			if es, err := i.DoElections2(epoch.EpochID+1, fastNumber.Uint64(), offline); err != nil {
 				return err
 			} else {
-				log.Info("Do validators election", "height", fastNumber, "epoch:", epoch.EpochID+1, "len:", len(es), "err", error)
+				log.Info("Do validators election", "height", fastNumber, "epoch:", epoch.EpochID+1, "len:", len(es), "offline", len(offline), "err", error)
 			}
 			i.Save(state, types.StakingAddress)
 		}
@@ -996,6 +1007,37 @@ func (m *Minerva) finalizeValidators(chain consensus.ChainReader, state *state.S
 	return nil
 }
 
+// emptyCommitteeHash is the committee hash of a block without switch infos
+var emptyCommitteeHash = types.RlpHash([]*types.CommitteeMember{})
+
+// finalizeUptime records the signs of the committee members for the grandparent
+// block, which are carried by the parent block and committed by its snail hash.
+func (m *Minerva) finalizeUptime(chain consensus.ChainReader, state *state.StateDB, header *types.Header) error {
+	if !chain.Config().IsTIP10(header.Number) || header.Number.Uint64() < 2 {
+		return nil
+	}
+	// the block with switch infos keeps the parent state
+	if header.CommitteeHash != (common.Hash{}) && header.CommitteeHash != emptyCommitteeHash {
+		return nil
+	}
+	signed := new(big.Int).Sub(header.Number, big2)
+	if !chain.Config().IsTIP9(signed) || !consensus.IsTIP8(signed, chain.Config(), m.sbc) {
+		return nil
+	}
+	parent := chain.GetBlock(header.ParentHash, header.Number.Uint64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	signs, _ := parent.GetLocalSigns()
+
+	uptime := vm.NewValidatorUptime()
+	if err := uptime.Load(state, types.StakingAddress); err != nil {
+		return err
+	}
+	uptime.Record(state, signed.Uint64(), signs)
+	return uptime.Save(state, types.StakingAddress)
+}
+
 //LogPrint log debug
 func LogPrint(info string, addr common.Address, amount *big.Int) {
 	log.Debug("[Consensus AddBalance]", "info", info, "CoinBase:", addr.String(), "amount", amount)
@@ -1052,6 +1094,8 @@ func accumulateRewardsFast2(stateDB *state.StateDB, sBlock *types.SnailBlock, fa
 			LogPrint("committee:", vv.Address, vv.Amount)
 		}
 	}
+	// the rewards of the auto compound delegations are staked in the next epoch
+	impawn.CompoundRewards(stateDB, infos)
 	rewardsInfos := types.NewChainReward(sBlock.NumberU64(), sBlock.Time().Uint64(), coinbase, types.ToRewardInfos1(fruitMap), infos)
 	// log.Debug("[****accumulateRewardsFast2]", "Height", rewardsInfos.Height,
 	// "committeeCoin",committeeCoin.String(),"minerCoin",minerCoin.String(),
@@ -1080,6 +1124,8 @@ func accumulateRewardsFast3(stateDB *state.StateDB, fast, startRewardPos uint64)
 			LogPrint("committee:", vv.Address, vv.Amount)
 		}
 	}
+	// the rewards of the auto compound delegations are staked in the next epoch
+	impawn.CompoundRewards(stateDB, infos)
 	rewardsInfos := &types.ChainReward{
 		CommitteeBase: infos,
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/iceming123/go-ice/crypto"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -102,13 +103,14 @@ func GetCommittee(cnt int) []map[string]interface{} {
 func TestOutGenesisJson(t *testing.T) {
 
 	b, _ := ioutil.ReadFile("./test/genesis.json")
+	dir := t.TempDir()
 	out := make(map[string]interface{})
 	json.Unmarshal(b, &out)
 	for i := 1; i <= 52; i++ {
 		out["committee"] = GetCommittee(i)
 		bytes, _ := json.Marshal(out)
 		bytes = []byte(strings.Replace(string(bytes), "9e+22", "90000000000000000000000", -1))
-		e := ioutil.WriteFile("./test/"+fmt.Sprintf("genesis_%s.json", strconv.Itoa(i)), bytes, os.ModePerm)
+		e := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("genesis_%s.json", strconv.Itoa(i))), bytes, os.ModePerm)
 		if e != nil {
 			fmt.Println(string(bytes))
 		}
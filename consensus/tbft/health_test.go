@@ -12,11 +12,13 @@ import (
 	"time"
 
 	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
 	"github.com/iceming123/go-ice/consensus/tbft/help"
 	"github.com/iceming123/go-ice/consensus/tbft/tp2p"
 	ttypes "github.com/iceming123/go-ice/consensus/tbft/types"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/metrics"
 	config "github.com/iceming123/go-ice/params"
 	"github.com/iceming123/go-ice/rlp"
 	//"github.com/golang/mock/gomock"
@@ -115,6 +117,86 @@ func TestSwitchItemInCommittee(t *testing.T) {
 
 	mgr.Stop()
 }
+func TestProposeSwitch(t *testing.T) {
+	defer func(enable bool) { ttypes.EnableHealthMgr = enable }(ttypes.EnableHealthMgr)
+
+	mgr, addrs := ttypes.NewHealthMgr(1), make([][]byte, 0)
+	for _, val := range makeValidatorSet(makeCommitteeInfo(4, 1)).Validators {
+		id := tp2p.ID(hex.EncodeToString(val.Address))
+		mgr.PutWorkHealth(ttypes.NewHealth(id, types.TypeWorked, types.StateUsedFlag, val, false))
+		addrs = append(addrs, val.Address)
+	}
+	// the manager is disabled unless the consensus config enables it
+	ttypes.EnableHealthMgr = false
+	if _, err := mgr.ProposeSwitch(addrs[0], nil, ""); err != ttypes.ErrHealthMgrDisabled {
+		t.Fatalf("disabled switch mismatch: have %v, want %v", err, ttypes.ErrHealthMgrDisabled)
+	}
+	ttypes.EnableHealthMgr = true
+	mgr.Start()
+	defer mgr.Stop()
+	if !ttypes.EnableHealthMgr {
+		t.Fatal("the started manager is disabled")
+	}
+	if _, err := mgr.ProposeSwitch(common.Address{1}.Bytes(), nil, ""); err == nil {
+		t.Fatal("switched a member not in the committee")
+	}
+	// the healthy member can't be switched
+	if _, err := mgr.ProposeSwitch(addrs[0], nil, ""); err == nil {
+		t.Fatal("switched a healthy member")
+	}
+	remove := mgr.GetHealth(addrs[0])
+	atomic.StoreInt32(&remove.Tick, ttypes.HealthOut)
+	sv, err := mgr.ProposeSwitch(addrs[0], nil, "")
+	if err != nil {
+		t.Fatalf("propose switch failed: %v", err)
+	}
+	if have := <-mgr.ChanTo(); !have.Equal(sv) {
+		t.Fatalf("switch mismatch: have %v, want %v", have, sv)
+	}
+	if state := atomic.LoadUint32(&remove.State); state != types.StateSwitchingFlag {
+		t.Errorf("state mismatch: have %x, want %x", state, types.StateSwitchingFlag)
+	}
+	if _, err := mgr.ProposeSwitch(addrs[1], nil, ""); err == nil {
+		t.Error("proposed a switch with another one pending")
+	}
+	pending, recent := mgr.Switches()
+	if len(pending) != 1 || !pending[0].Equal(sv) {
+		t.Errorf("pending switches mismatch: %v", pending)
+	}
+	if len(recent) != 1 || recent[0].Result != "pending" || recent[0].SV.Resion != "Manual" {
+		t.Errorf("recent switches mismatch: %v", recent)
+	}
+}
+
+func TestHealthMetrics(t *testing.T) {
+	mgr, addrs := ttypes.NewHealthMgr(1), make([][]byte, 0)
+	for _, val := range makeValidatorSet(makeCommitteeInfo(4, 1)).Validators {
+		id := tp2p.ID(hex.EncodeToString(val.Address))
+		mgr.PutWorkHealth(ttypes.NewHealth(id, types.TypeWorked, types.StateUsedFlag, val, false))
+		addrs = append(addrs, val.Address)
+	}
+	name := func(addr []byte) string { return "consensus/tbft/health/tick/" + hexutil.Encode(addr) }
+	atomic.StoreInt32(&mgr.GetHealth(addrs[0]).Tick, 7)
+	mgr.UpdateMetrics()
+	gauge, ok := metrics.DefaultRegistry.Get(name(addrs[0])).(metrics.Gauge)
+	if !ok || gauge.Value() != 7 {
+		t.Fatalf("tick gauge mismatch: %v", gauge)
+	}
+	// the gauges of the members which leave are unregistered
+	atomic.StoreUint32(&mgr.GetHealth(addrs[0]).State, types.StateRemovedFlag)
+	mgr.UpdateMetrics()
+	if metrics.DefaultRegistry.Get(name(addrs[0])) != nil {
+		t.Errorf("gauge of the removed member is registered")
+	}
+	mgr.Start()
+	mgr.Stop()
+	for _, addr := range addrs {
+		if metrics.DefaultRegistry.Get(name(addr)) != nil {
+			t.Errorf("gauge of %x is registered after stop", addr)
+		}
+	}
+}
+
 func healthUpdate(recv <-chan *hUpdateItem, end chan<- int) {
 	// once update
 	for {
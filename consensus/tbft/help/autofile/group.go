@@ -662,7 +662,6 @@ func (gr *GroupReader) openFile(index int) error {
 	defer gr.Group.mtx.Unlock()
 
 	if index > gr.Group.maxIndex {
-		fmt.Println("openFile:", index, gr.Group.maxIndex)
 		return io.EOF
 	}
 
@@ -25,6 +25,17 @@ var (
 
 	//FetchFastBlock rounds count statistics
 	TBftFetchFastBlockRoundTime = metrics.NewRegisteredTimer("consensus/tbft/count/FetchFastBlockRound", nil)
+
+	//Health statistics, the ticks of the members are in consensus/tbft/health/tick/<address>
+	TbftHealthUsedGauge      = metrics.NewRegisteredGauge("consensus/tbft/health/used", nil)
+	TbftHealthUnhealthyGauge = metrics.NewRegisteredGauge("consensus/tbft/health/unhealthy", nil)
+	TbftHealthMaxTickGauge   = metrics.NewRegisteredGauge("consensus/tbft/health/tick/max", nil)
+
+	//Switch validator statistics
+	TbftSwitchProposedMeter = metrics.NewRegisteredMeter("consensus/tbft/health/switch/proposed", nil)
+	TbftSwitchSuccessMeter  = metrics.NewRegisteredMeter("consensus/tbft/health/switch/success", nil)
+	TbftSwitchFailedMeter   = metrics.NewRegisteredMeter("consensus/tbft/health/switch/failed", nil)
+	TbftSwitchPendingGauge  = metrics.NewRegisteredGauge("consensus/tbft/health/switch/pending", nil)
 )
 
 type ConsensusTime int
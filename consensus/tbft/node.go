@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/consensus/tbft/testlog"
 
 	tcrypto "github.com/iceming123/go-ice/consensus/tbft/crypto"
@@ -66,7 +67,7 @@ func newNodeService(p2pcfg *cfg.P2PConfig, cscfg *cfg.ConsensusConfig, state *tt
 	store *ttypes.BlockStore, cid uint64) *service {
 	return &service{
 		sw:             tp2p.NewSwitch(p2pcfg, state),
-		consensusState: NewConsensusState(cscfg, state, store),
+		consensusState: NewConsensusState(cscfg, state, store, WALFile(cscfg.CommitteeWalFile(cid))),
 		// nodeTable:      make(map[p2p.ID]*nodeInfo),
 		lock:       new(sync.Mutex),
 		updateChan: make(chan bool, 2),
@@ -123,9 +124,8 @@ func (s *service) start(cid *big.Int, node *Node) error {
 		log.New("p2p", "self"))
 	s.sw.AddListener(l)
 
-	privValidator := ttypes.NewPrivValidator(*node.priv)
-	s.consensusState.SetPrivValidator(privValidator)
-	s.sa.SetPrivValidator(privValidator)
+	s.consensusState.SetPrivValidator(node.privValidator)
+	s.sa.SetPrivValidator(node.privValidator)
 	// Start the switch (the P2P server).
 	help.CheckAndPrintError(s.healthMgr.OnStart())
 	err := s.sw.Start()
@@ -322,6 +322,8 @@ type Node struct {
 	config *cfg.TbftConfig
 	Agent  types.PbftAgentProxy
 	priv   *ecdsa.PrivateKey // local node's validator key
+	// signer shared by all committees, guards against double signing
+	privValidator ttypes.PrivValidator
 
 	// services
 	services   map[uint64]*service
@@ -356,6 +358,16 @@ func NewNode(config *cfg.TbftConfig, chainID string, priv *ecdsa.PrivateKey,
 			PrivKey: tcrypto.PrivKeyTrue(*priv),
 		},
 	}
+	if file := config.Consensus.PrivValidatorStateFile(); file != "" {
+		pv, err := ttypes.LoadOrGenPrivValidator(*priv, file)
+		if err != nil {
+			return nil, err
+		}
+		node.privValidator = pv
+	} else {
+		node.privValidator = ttypes.NewPrivValidator(*priv)
+	}
+	ttypes.EnableHealthMgr = config.Consensus.EnableHealthMgr
 	node.BaseService = *help.NewBaseService("Node", node)
 	return node, nil
 }
@@ -724,6 +736,100 @@ func (n *Node) GetCommitteeStatus(committeeID *big.Int) map[string]interface{} {
 	return result
 }
 
+var healthStates = map[uint32]string{
+	types.StateUnusedFlag:    "unused",
+	types.StateUsedFlag:      "used",
+	types.StateSwitchingFlag: "switching",
+	types.StateRemovedFlag:   "removed",
+	types.StateAppendFlag:    "append",
+}
+
+func getHealthInfo(h *ttypes.Health, role string) map[string]interface{} {
+	result := make(map[string]interface{})
+	result["id"] = h.ID
+	result["ip"] = h.IP
+	result["port"] = h.Port
+	if h.Val != nil {
+		result["address"] = common.BytesToAddress(h.Val.Address)
+	}
+	result["tick"] = atomic.LoadInt32(&h.Tick)
+	result["state"] = healthStates[atomic.LoadUint32(&h.State)]
+	result["role"] = role
+	result["self"] = h.Self
+	return result
+}
+
+func getSwitchInfo(sv *ttypes.SwitchValidator) map[string]interface{} {
+	result := make(map[string]interface{})
+	result["id"] = sv.ID
+	result["reason"] = sv.Resion
+	result["restore"] = sv.From == 1
+	if sv.Remove != nil && sv.Remove.Val != nil {
+		result["remove"] = common.BytesToAddress(sv.Remove.Val.Address)
+	}
+	if sv.Add != nil && sv.Add.Val != nil {
+		result["add"] = common.BytesToAddress(sv.Add.Val.Address)
+	}
+	return result
+}
+
+//GetHealthStatus is show the health of the members and the switches of committee in api
+func (n *Node) GetHealthStatus(committeeID *big.Int) (map[string]interface{}, error) {
+	s := getCommittee(n, committeeID.Uint64())
+	if s == nil {
+		return nil, errors.New("wrong conmmitt ID:" + committeeID.String())
+	}
+	members := make([]map[string]interface{}, 0)
+	work, back, seed := s.healthMgr.Healths()
+	for _, v := range work {
+		members = append(members, getHealthInfo(v, "work"))
+	}
+	for _, v := range back {
+		members = append(members, getHealthInfo(v, "back"))
+	}
+	for _, v := range seed {
+		members = append(members, getHealthInfo(v, "seed"))
+	}
+	pending, recent := s.healthMgr.Switches()
+	pendingInfo := make([]map[string]interface{}, 0, len(pending))
+	for _, v := range pending {
+		pendingInfo = append(pendingInfo, getSwitchInfo(v))
+	}
+	recentInfo := make([]map[string]interface{}, 0, len(recent))
+	for _, v := range recent {
+		info := getSwitchInfo(v.SV)
+		info["result"] = strings.TrimSpace(v.Result)
+		info["time"] = v.Time.Unix()
+		recentInfo = append(recentInfo, info)
+	}
+	result := make(map[string]interface{})
+	result["id"] = committeeID.Uint64()
+	result["enable"] = ttypes.EnableHealthMgr
+	result["healthOut"] = ttypes.HealthOut
+	result["members"] = members
+	result["pending"] = pendingInfo
+	result["recent"] = recentInfo
+	return result, nil
+}
+
+//ProposeSwitch propose to switch the member remove of committee with add by hand,
+//add is picked from the back members if it's nil
+func (n *Node) ProposeSwitch(committeeID *big.Int, remove common.Address, add *common.Address, reason string) (map[string]interface{}, error) {
+	s := getCommittee(n, committeeID.Uint64())
+	if s == nil {
+		return nil, errors.New("wrong conmmitt ID:" + committeeID.String())
+	}
+	var addBytes []byte
+	if add != nil {
+		addBytes = add.Bytes()
+	}
+	sv, err := s.healthMgr.ProposeSwitch(remove.Bytes(), addBytes, reason)
+	if err != nil {
+		return nil, err
+	}
+	return getSwitchInfo(sv), nil
+}
+
 func (n *Node) IsLeader(committeeID *big.Int) bool {
 	s := getCommittee(n, committeeID.Uint64())
 	if s != nil && s.consensusState != nil {
@@ -0,0 +1,114 @@
+package tbft
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/iceming123/go-ice/log"
+)
+
+// Functionality to replay blocks and messages on recovery from a crash.
+// The WAL records every proposal, block part, vote and timeout the consensus
+// state processed together with an EndHeightMessage each time it moves to a
+// new height. On restart the messages after the EndHeightMessage of the last
+// committed height are fed back into the state machine, so the node resumes
+// the round it was in before it stopped instead of waiting for the next one.
+
+// readReplayMessage handles a single message read back from the WAL.
+// Unlike the receiveRoutine the message is not written to the WAL again.
+func (cs *ConsensusState) readReplayMessage(msg *TimedWALMessage) error {
+	// skip meta messages
+	if _, ok := msg.Msg.(EndHeightMessage); ok {
+		return nil
+	}
+
+	switch m := msg.Msg.(type) {
+	case msgInfo:
+		log.Trace("Replay: msgInfo", "height", cs.Height, "round", cs.Round, "peer", m.PeerID, "msg", m.Msg)
+		cs.handleMsg(m)
+	case timeoutInfo:
+		log.Trace("Replay: timeout", "height", m.Height, "round", m.Round, "step", m.Step, "dur", m.Duration)
+		cs.handleTimeout(m, cs.RoundState)
+	default:
+		return fmt.Errorf("replay: unknown TimedWALMessage type: %v", msg.Msg)
+	}
+	return nil
+}
+
+// catchupReplay replays the WAL messages of csHeight which is the height the
+// consensus state resumes at.
+func (cs *ConsensusState) catchupReplay(csHeight uint64) error {
+	// Ensure that #ENDHEIGHT for this height doesn't exist, otherwise the
+	// chain is behind what the consensus already committed.
+	rd, found, err := cs.wal.SearchForEndHeight(csHeight, &WALSearchOptions{IgnoreDataCorruptionErrors: true})
+	if err != nil {
+		return err
+	}
+	if rd != nil {
+		rd.Close()
+	}
+	if found {
+		return fmt.Errorf("WAL should not contain #ENDHEIGHT %d", csHeight)
+	}
+
+	// Search for last height marker.
+	// Ignore data corruption errors in previous heights because we only care about last height
+	if csHeight == 0 {
+		return nil
+	}
+	rd, found, err = cs.wal.SearchForEndHeight(csHeight-1, &WALSearchOptions{IgnoreDataCorruptionErrors: true})
+	if err == io.EOF {
+		log.Warn("Replay: wal.group.Search returned EOF", "#ENDHEIGHT", csHeight-1)
+	} else if err != nil {
+		return err
+	}
+	if !found {
+		// the committee starts at this height or the chain moved past the
+		// heights in the WAL, there is nothing to replay.
+		log.Info("Replay: no #ENDHEIGHT in WAL, starting a new height", "#ENDHEIGHT", csHeight-1)
+		cs.wal.WriteSync(EndHeightMessage{csHeight - 1})
+		return nil
+	}
+	defer rd.Close()
+
+	log.Info("Catchup by replaying consensus messages", "height", csHeight)
+
+	var msg *TimedWALMessage
+	dec := NewWALDecoder(rd)
+
+	for {
+		msg, err = dec.Decode()
+		if err == io.EOF {
+			break
+		} else if IsDataCorruptionError(err) {
+			// the tail of the WAL was not completely written before the node stopped
+			log.Warn("Replay: data corruption, stop replaying", "height", csHeight, "err", err)
+			break
+		} else if err != nil {
+			return err
+		}
+
+		// NOTE: since the priv key is set when the msgs are received
+		// it will attempt to eg double sign but we can just ignore it
+		// since the votes will be replayed and we'll get to the next step
+		if err := cs.readReplayMessage(msg); err != nil {
+			return err
+		}
+	}
+	log.Info("Replay: Done", "height", cs.Height, "round", cs.Round, "step", cs.Step)
+	return nil
+}
+
+// walEndHeight records that the consensus state left height, replay starts
+// after the last recorded height.
+func (cs *ConsensusState) walEndHeight(height uint64) {
+	cs.wal.WriteSync(EndHeightMessage{height})
+}
+
+// isReplayable reports whether the message can be written to the WAL.
+// Validator updates are triggered by the local election and re-delivered
+// on restart, their fields are not serializable.
+func isReplayable(mi msgInfo) bool {
+	_, ok := mi.Msg.(*ValidatorUpdateMessage)
+	return !ok
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/log"
 	cfg "github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rlp"
 )
 
 //-----------------------------------------------------------------------------
@@ -89,6 +90,11 @@ type ConsensusState struct {
 	// and to notify external subscribers, eg. through a websocket
 	eventBus *ttypes.EventBus
 
+	// a Write-Ahead Log ensures we can recover from any kind of crash
+	// and helps us avoid signing conflicting votes
+	wal     WAL
+	walFile string
+
 	// for tests where we want to limit the number of transitions the state makes
 	nSteps int
 
@@ -129,6 +135,7 @@ func NewConsensusState(
 		state:            state,
 		evsw:             ttypes.NewEventSwitch(),
 		svs:              make([]*ttypes.SwitchValidator, 0, 0),
+		wal:              nilWAL{},
 	}
 	// set function defaults (may be overwritten before calling Start)
 	cs.decideProposal = cs.defaultDecideProposal
@@ -148,6 +155,12 @@ func NewConsensusState(
 	return cs
 }
 
+// WALFile sets the write-ahead log file of the consensus state.
+// Without it the consensus state runs with a nil WAL.
+func WALFile(walFile string) CSOption {
+	return func(cs *ConsensusState) { cs.walFile = walFile }
+}
+
 //----------------------------------------
 // Public interface
 
@@ -225,6 +238,14 @@ func (cs *ConsensusState) OnStart() error {
 	if err := cs.evsw.Start(); err != nil {
 		return err
 	}
+	if cs.walFile != "" {
+		wal, err := cs.OpenWAL(cs.walFile)
+		if err != nil {
+			log.Error("Error loading ConsensusState wal", "err", err)
+			return err
+		}
+		cs.wal = wal
+	}
 	// we need the timeoutRoutine for replay so
 	// we don't block on the tick chan.
 	// NOTE: we will get a build up of garbage go routines
@@ -237,6 +258,13 @@ func (cs *ConsensusState) OnStart() error {
 		return err
 	}
 	cs.updateToState(cs.state)
+
+	// we may have lost some votes if the process crashed
+	// reload from consensus log to catchup
+	if err := cs.catchupReplay(cs.Height); err != nil {
+		log.Error("Error on catchup replay. Proceeding to start ConsensusState anyway", "err", err)
+	}
+
 	// now start the receiveRoutine
 	go cs.receiveRoutine(0)
 
@@ -262,12 +290,26 @@ func (cs *ConsensusState) startRoutines(maxSteps int) {
 	go cs.receiveRoutine(maxSteps)
 }
 
+// OpenWAL opens a file to log all consensus messages and timeouts for deterministic accountability
+func (cs *ConsensusState) OpenWAL(walFile string) (WAL, error) {
+	wal, err := NewWAL(walFile)
+	if err != nil {
+		log.Error("Failed to open WAL for consensus state", "wal", walFile, "err", err)
+		return nil, err
+	}
+	if err := wal.Start(); err != nil {
+		return nil, err
+	}
+	return wal, nil
+}
+
 // OnStop implements help.Service. It stops all routines and waits for the WAL to finish.
 func (cs *ConsensusState) OnStop() {
 	log.Info("Begin ConsensusState finish")
 	help.CheckAndPrintError(cs.evsw.Stop())
 	help.CheckAndPrintError(cs.timeoutTicker.Stop())
 	help.CheckAndPrintError(cs.timeoutTask.Stop())
+	// WAL is stopped in receiveRoutine.
 	log.Info("End ConsensusState finish")
 }
 
@@ -380,6 +422,7 @@ func (cs *ConsensusState) updateStateForSync() {
 	newH := cs.state.GetLastBlockHeight() + 1
 	if oldH != newH {
 		cs.updateToState(cs.state)
+		cs.walEndHeight(cs.Height - 1)
 		log.Debug("Reset privValidator", "height", cs.Height)
 		cs.state.PrivReset()
 		sleepDuration := time.Duration(1) * time.Millisecond
@@ -507,6 +550,9 @@ func (cs *ConsensusState) validatorUpdate(msg *ValidatorUpdateMessage) {
 	cs.state.SetEndHeight(msg.eHeight)
 	cs.state.SetBeginHeight(msg.uHeight)
 	newHeight := cs.Height
+	if newHeight != oldHeight {
+		cs.walEndHeight(newHeight - 1)
+	}
 
 	if newHeight == oldHeight && round > 0 {
 		log.Trace("ValidatorUpdate,has same height in current consensus", "oldHeight", oldHeight, "newHeight", newHeight)
@@ -533,6 +579,9 @@ func (cs *ConsensusState) receiveRoutine(maxSteps int) {
 		// NOTE: the internalMsgQueue may have signed messages from our
 		// priv_val that haven't hit the WAL, but its ok because
 		// priv_val tracks LastSig
+
+		// close wal now that we're done writing to it
+		help.CheckAndPrintError(cs.wal.Stop())
 		log.Debug("Exit receiveRoutine")
 		close(cs.done)
 	}
@@ -565,13 +614,18 @@ func (cs *ConsensusState) receiveRoutine(maxSteps int) {
 
 		select {
 		case mi = <-cs.peerMsgQueue:
+			cs.wal.Write(mi)
 			// handles proposals, block parts, votes
 			// may generate internal events (votes, complete proposals, 2/3 majorities)
 			cs.handleMsg(mi)
 		case mi = <-cs.internalMsgQueue:
+			if isReplayable(mi) {
+				cs.wal.WriteSync(mi) // NOTE: fsync
+			}
 			// handles proposals, block parts, votes
 			cs.handleMsg(mi)
 		case ti := <-cs.timeoutTicker.Chan(): // tockChan:
+			cs.wal.Write(ti)
 			// if the timeout is relevant to the rs
 			// go to the next step
 			cs.handleTimeout(ti, rs)
@@ -1310,6 +1364,9 @@ func (cs *ConsensusState) finalizeCommit(height uint64) {
 		log.Debug("Error on ApplyBlock. Did the application crash? Please restart gice", "err", err)
 		return
 	}
+	// Write EndHeightMessage{height} to record the commit, a restart replays
+	// the messages of the next height only.
+	cs.walEndHeight(height)
 	// Save to blockStore.
 	if cs.blockStore.MaxBlockHeight() < block.NumberU64() {
 		// NOTE: the seenCommit is local justification to commit this block,
@@ -1458,6 +1515,9 @@ func (cs *ConsensusState) tryAddVote(vote *ttypes.Vote, peerID string) error {
 				return err
 			}
 			log.Debug("Found conflicting vote.", "height", vote.Height, "round", vote.Round, "type", vote.Type)
+			if vote.Height == cs.Height {
+				cs.reportDoubleSign(vote)
+			}
 			return err
 		}
 		// Probably an invalid signature / Bad peer.
@@ -1470,6 +1530,28 @@ func (cs *ConsensusState) tryAddVote(vote *ttypes.Vote, peerID string) error {
 	return nil
 }
 
+// reportDoubleSign logs the double sign evidence of the conflicting vote,
+// it can be submitted to the staking contract by impawn_submitEvidence.
+func (cs *ConsensusState) reportDoubleSign(vote *ttypes.Vote) {
+	var voteSet *ttypes.VoteSet
+	if vote.Type == ttypes.VoteTypePrevote {
+		voteSet = cs.Votes.Prevotes(int(vote.Round))
+	} else {
+		voteSet = cs.Votes.Precommits(int(vote.Round))
+	}
+	evidence := voteSet.MakeDoubleSignEvidence(vote)
+	if evidence == nil {
+		return
+	}
+	data, err := rlp.EncodeToBytes(evidence)
+	if err != nil {
+		log.Error("Encode double sign evidence", "err", err)
+		return
+	}
+	log.Warn("Found double sign evidence", "height", vote.Height, "round", vote.Round, "address", vote.ValidatorAddress,
+		"hash", evidence.Hash(), "evidence", hexutil.Encode(data))
+}
+
 //-----------------------------------------------------------------------------
 
 func (cs *ConsensusState) addVote(vote *ttypes.Vote, peerID string) (added bool, err error) {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,9 +13,11 @@ import (
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/common/hexutil"
 	"github.com/iceming123/go-ice/consensus/tbft/help"
+	"github.com/iceming123/go-ice/consensus/tbft/metrics"
 	"github.com/iceming123/go-ice/consensus/tbft/tp2p"
 	ctypes "github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/log"
+	gometrics "github.com/iceming123/go-ice/metrics"
 	"github.com/iceming123/go-ice/params"
 )
 
@@ -26,9 +30,17 @@ const (
 	SwitchPartWork = 0
 	SwitchPartBack = 1
 	SwitchPartSeed = 2
+
+	// switchHistoryCount is the count of the recent switches kept for the api
+	switchHistoryCount = 32
 )
 
-var EnableHealthMgr = true
+// EnableHealthMgr enables the health managers to switch the unhealthy members,
+// it's set from the consensus config when the node is created.
+var EnableHealthMgr = false
+
+// ErrHealthMgrDisabled is returned by ProposeSwitch while EnableHealthMgr is false
+var ErrHealthMgrDisabled = errors.New("health manager disabled")
 
 //Health struct
 type Health struct {
@@ -170,6 +182,15 @@ type HealthMgr struct {
 	cid            uint64
 	uid            uint64
 	lock           *sync.Mutex
+	history        []*SwitchRecord
+	gauges         map[string]bool // the names of the tick gauges of the members
+}
+
+// SwitchRecord is a switch proposed or handled by the mgr, it's kept for the api
+type SwitchRecord struct {
+	SV     *SwitchValidator
+	Result string // pending,restore,success or failed
+	Time   time.Time
 }
 
 //NewHealthMgr func
@@ -256,7 +277,6 @@ func (h *HealthMgr) ChanTo() chan *SwitchValidator {
 
 //OnStart mgr start
 func (h *HealthMgr) OnStart() error {
-	EnableHealthMgr = false
 	if h.healthTick == nil && EnableHealthMgr {
 		h.healthTick = time.NewTicker(1 * time.Second)
 		go h.healthGoroutine()
@@ -267,10 +287,15 @@ func (h *HealthMgr) OnStart() error {
 //OnStop mgr stop
 func (h *HealthMgr) OnStop() {
 	log.Info("Begin HealthMgr finish")
-	EnableHealthMgr = false
 	if h.healthTick != nil {
 		h.healthTick.Stop()
 	}
+	h.lock.Lock()
+	for name := range h.gauges {
+		gometrics.DefaultRegistry.Unregister(name)
+	}
+	h.gauges = nil
+	h.lock.Unlock()
 	help.CheckAndPrintError(h.Stop())
 	log.Info("End HealthMgr finish")
 }
@@ -282,12 +307,17 @@ func (h *HealthMgr) getCurSV() *SwitchValidator {
 	}
 	return nil
 }
-func (h *HealthMgr) setCurSV(sv *SwitchValidator) {
+
+// setCurSV sets sv as the current switch, it returns false if another switch
+// is set already.
+func (h *HealthMgr) setCurSV(sv *SwitchValidator) bool {
 	h.lock.Lock()
 	defer h.lock.Unlock()
 	if len(h.curSwitch) == 0 && sv != nil {
 		h.curSwitch = append(h.curSwitch, sv)
+		return true
 	}
+	return false
 }
 func (h *HealthMgr) removeCurSV() {
 	h.lock.Lock()
@@ -334,6 +364,7 @@ func (h *HealthMgr) work(sshift bool) {
 	for _, v := range h.Back {
 		h.checkSwitchValidator(v, sshift)
 	}
+	h.UpdateMetrics()
 }
 
 func (h *HealthMgr) checkSwitchValidator(v *Health, sshift bool) {
@@ -346,10 +377,14 @@ func (h *HealthMgr) checkSwitchValidator(v *Health, sshift bool) {
 				log.Warn("Health", "id", v.ID, "val", val)
 				back := h.pickUnuseValidator()
 				cur := h.makeSwitchValidators(v, back, "Switch", 0)
-				atomic.StoreUint32(&v.State, ctypes.StateSwitchingFlag)
-				h.setCurSV(cur)
-				log.Warn("CheckSwitchValidator(remove,add)", "info:", cur, "cid", h.cid)
-				go h.Switch(cur)
+				if h.setCurSV(cur) {
+					atomic.StoreUint32(&v.State, ctypes.StateSwitchingFlag)
+					h.record(cur, "pending")
+					log.Warn("CheckSwitchValidator(remove,add)", "info:", cur, "cid", h.cid)
+					go h.Switch(cur)
+				} else if back != nil {
+					atomic.CompareAndSwapUint32(&back.State, ctypes.StateSwitchingFlag, ctypes.StateUnusedFlag)
+				}
 			}
 		}
 	}
@@ -457,6 +492,8 @@ func (h *HealthMgr) switchResult(res *SwitchValidator) {
 			}
 		}
 	}
+	h.record(res, ss)
+	h.UpdateMetrics()
 	log.Debug("switchResult", "result:", ss, "res", res, "cid", h.cid)
 }
 
@@ -611,6 +648,7 @@ func (h *HealthMgr) UpdateFromCommittee(member, backMember ctypes.CommitteeMembe
 	}
 
 	h.checkSaveSwitchValidator(append(member, backMember...))
+	h.UpdateMetrics()
 }
 
 func (h *HealthMgr) checkSaveSwitchValidator(members ctypes.CommitteeMembers) {
@@ -640,6 +678,127 @@ func (h *HealthMgr) checkSaveSwitchValidator(members ctypes.CommitteeMembers) {
 	}
 }
 
+//ProposeSwitch propose to replace the member remove with add by hand, add is
+//picked from the back members if it's nil, the switch is checked by VerifySwitch
+//like the ones made by the health ticks. ErrHealthMgrDisabled is returned unless
+//the health manager is enabled by the consensus config.
+func (h *HealthMgr) ProposeSwitch(remove, add []byte, resion string) (*SwitchValidator, error) {
+	if !EnableHealthMgr {
+		return nil, ErrHealthMgrDisabled
+	}
+	rEnter := h.GetHealth(remove)
+	if rEnter == nil {
+		return nil, errors.New("not found the remove:" + hexutil.Encode(remove))
+	}
+	if rEnter.Self {
+		return nil, errors.New("can't remove self:" + rEnter.String())
+	}
+	var aEnter *Health
+	picked := false
+	if add != nil {
+		if aEnter = h.GetHealth(add); aEnter == nil {
+			return nil, errors.New("not found the add:" + hexutil.Encode(add))
+		}
+	} else if aEnter = h.pickUnuseValidator(); aEnter != nil {
+		picked = true
+	}
+	if resion == "" {
+		resion = "Manual"
+	}
+	sv := h.makeSwitchValidators(rEnter, aEnter, resion, 0)
+	err := h.VerifySwitch(sv)
+	// the check of the pending switch and the set are done under the lock
+	if err == nil && !h.setCurSV(sv) {
+		err = errors.New("a switch is pending")
+	}
+	if err != nil {
+		if picked {
+			// give back the picked member as the switch is refused
+			atomic.CompareAndSwapUint32(&aEnter.State, ctypes.StateSwitchingFlag, ctypes.StateUnusedFlag)
+		}
+		return nil, err
+	}
+	atomic.StoreUint32(&rEnter.State, ctypes.StateSwitchingFlag)
+	h.record(sv, "pending")
+	h.UpdateMetrics()
+	log.Warn("ProposeSwitch(remove,add)", "info:", sv, "cid", h.cid)
+	go h.Switch(sv)
+	return sv, nil
+}
+
+func (h *HealthMgr) record(sv *SwitchValidator, result string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if result == "pending" {
+		metrics.TbftSwitchProposedMeter.Mark(1)
+	} else if strings.HasSuffix(result, "Success") {
+		metrics.TbftSwitchSuccessMeter.Mark(1)
+	} else if sv.From == 0 {
+		metrics.TbftSwitchFailedMeter.Mark(1)
+	}
+	h.history = append(h.history, &SwitchRecord{SV: sv, Result: result, Time: time.Now()})
+	if len(h.history) > switchHistoryCount {
+		h.history = append(h.history[:0], h.history[len(h.history)-switchHistoryCount:]...)
+	}
+}
+
+//Switches return the pending switches and the recent ones
+func (h *HealthMgr) Switches() (pending []*SwitchValidator, recent []*SwitchRecord) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	pending = append(pending, h.curSwitch...)
+	recent = append(recent, h.history...)
+	return pending, recent
+}
+
+//Healths return the members of the work, back and seed parts
+func (h *HealthMgr) Healths() (work, back, seed []*Health) {
+	for _, v := range h.Work {
+		work = append(work, v)
+	}
+	sort.Sort(HealthsByAddress(work))
+	return work, append(back, h.Back...), append(seed, h.seed...)
+}
+
+//UpdateMetrics publish the health of the members and the switches, the tick of
+//a member is in consensus/tbft/health/tick/<address> until it leaves
+func (h *HealthMgr) UpdateMetrics() {
+	used, unhealthy, maxTick := 0, 0, int32(0)
+	gauges := make(map[string]bool)
+	work, back, seed := h.Healths()
+	for _, v := range append(append(work, back...), seed...) {
+		tick := atomic.LoadInt32(&v.Tick)
+		if atomic.LoadUint32(&v.State) == ctypes.StateUsedFlag {
+			used++
+		}
+		if tick >= HealthOut {
+			unhealthy++
+		}
+		if tick > maxTick {
+			maxTick = tick
+		}
+		if v.Val != nil && atomic.LoadUint32(&v.State) != ctypes.StateRemovedFlag {
+			name := "consensus/tbft/health/tick/" + hexutil.Encode(v.Val.Address)
+			gometrics.GetOrRegisterGauge(name, nil).Update(int64(tick))
+			gauges[name] = true
+		}
+	}
+	// unregister the ticks of the members which leave
+	h.lock.Lock()
+	for name := range h.gauges {
+		if !gauges[name] {
+			gometrics.DefaultRegistry.Unregister(name)
+		}
+	}
+	h.gauges = gauges
+	h.lock.Unlock()
+	metrics.TbftHealthUsedGauge.Update(int64(used))
+	metrics.TbftHealthUnhealthyGauge.Update(int64(unhealthy))
+	metrics.TbftHealthMaxTickGauge.Update(int64(maxTick))
+	pending, _ := h.Switches()
+	metrics.TbftSwitchPendingGauge.Update(int64(len(pending)))
+}
+
 //-------------------------------------------------
 // Implements sort for sorting Healths by address.
 
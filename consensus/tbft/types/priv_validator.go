@@ -3,9 +3,13 @@ package types
 import (
 	"bytes"
 	"crypto/ecdsa"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -57,8 +61,21 @@ type privValidator struct {
 	LastStep      uint8         `json:"last_step"`
 	LastSignature []byte        `json:"last_signature,omitempty"` // so we dont lose signatures XXX Why would we lose signatures?
 	LastSignBytes help.HexBytes `json:"last_signbytes,omitempty"` // so we dont lose signatures XXX Why would we lose signatures?
+	LastTimestamp time.Time     `json:"last_timestamp"`           // the sign bytes are hashed, keep the timestamp to sign again
 
-	mtx sync.Mutex
+	filePath string // where the last signed state is persisted, empty for memory only
+	mtx      sync.Mutex
+}
+
+// privValidatorState is the last signed height/round/step of a validator as it
+// is written to disk, so a restarted node never signs a conflicting message.
+type privValidatorState struct {
+	LastHeight    uint64        `json:"last_height"`
+	LastRound     uint          `json:"last_round"`
+	LastStep      uint8         `json:"last_step"`
+	LastSignature []byte        `json:"last_signature,omitempty"`
+	LastSignBytes help.HexBytes `json:"last_signbytes,omitempty"`
+	LastTimestamp time.Time     `json:"last_timestamp"`
 }
 
 //KeepBlockSign is block's sign
@@ -68,7 +85,8 @@ type KeepBlockSign struct {
 	Hash   common.Hash
 }
 
-//NewPrivValidator return new private Validator
+//NewPrivValidator return new private Validator which only keeps the last
+//signed state in memory
 func NewPrivValidator(priv ecdsa.PrivateKey) PrivValidator {
 	return &privValidator{
 		PrivKey:  tcrypto.PrivKeyTrue(priv),
@@ -76,24 +94,111 @@ func NewPrivValidator(priv ecdsa.PrivateKey) PrivValidator {
 	}
 }
 
+//LoadOrGenPrivValidator return new private Validator which persists the last
+//signed state to filePath. If the file already exists the state is reloaded,
+//so the validator refuses to sign anything below what it signed before a restart.
+func LoadOrGenPrivValidator(priv ecdsa.PrivateKey, filePath string) (PrivValidator, error) {
+	pv := &privValidator{
+		PrivKey:  tcrypto.PrivKeyTrue(priv),
+		LastStep: stepNone,
+		filePath: filePath,
+	}
+	data, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			return nil, err
+		}
+		log.Info("Create privValidator state", "file", filePath)
+		return pv, pv.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state privValidatorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error reading privValidator state from %v: %v", filePath, err)
+	}
+	if state.LastSignBytes != nil && state.LastSignature == nil {
+		return nil, fmt.Errorf("privValidator state %v has signbytes but no signature", filePath)
+	}
+	pv.LastHeight = state.LastHeight
+	pv.LastRound = state.LastRound
+	pv.LastStep = state.LastStep
+	pv.LastSignature = state.LastSignature
+	pv.LastSignBytes = state.LastSignBytes
+	pv.LastTimestamp = state.LastTimestamp
+	log.Info("Load privValidator state", "file", filePath, "height", pv.LastHeight, "round", pv.LastRound, "step", pv.LastStep)
+	return pv, nil
+}
+
+//Reset clear the last signed state of a memory only validator. A persisted
+//state is the double sign guard across restarts and is never rolled back.
 func (Validator *privValidator) Reset() {
+	Validator.mtx.Lock()
+	defer Validator.mtx.Unlock()
+	if Validator.filePath != "" {
+		return
+	}
 	var sig []byte
 	Validator.LastHeight = 0
 	Validator.LastRound = 0
 	Validator.LastStep = 0
 	Validator.LastSignature = sig
 	Validator.LastSignBytes = nil
+	Validator.LastTimestamp = time.Time{}
 }
 
-// Persist height/round/step and signature
+// Persist height/round/step and signature. The signature must not be released
+// if the state could not be written.
 func (Validator *privValidator) saveSigned(height uint64, round int, step uint8,
-	signBytes []byte, sig []byte) {
+	signBytes []byte, sig []byte, timestamp time.Time) error {
 
+	old := privValidatorState{
+		LastHeight:    Validator.LastHeight,
+		LastRound:     Validator.LastRound,
+		LastStep:      Validator.LastStep,
+		LastSignature: Validator.LastSignature,
+		LastSignBytes: Validator.LastSignBytes,
+		LastTimestamp: Validator.LastTimestamp,
+	}
 	Validator.LastHeight = height
 	Validator.LastRound = uint(round)
 	Validator.LastStep = step
 	Validator.LastSignature = sig
 	Validator.LastSignBytes = signBytes
+	Validator.LastTimestamp = timestamp
+	if err := Validator.save(); err != nil {
+		Validator.LastHeight = old.LastHeight
+		Validator.LastRound = old.LastRound
+		Validator.LastStep = old.LastStep
+		Validator.LastSignature = old.LastSignature
+		Validator.LastSignBytes = old.LastSignBytes
+		Validator.LastTimestamp = old.LastTimestamp
+		return err
+	}
+	return nil
+}
+
+// save atomically writes the last signed state to disk
+func (Validator *privValidator) save() error {
+	if Validator.filePath == "" {
+		return nil
+	}
+	data, err := json.Marshal(&privValidatorState{
+		LastHeight:    Validator.LastHeight,
+		LastRound:     Validator.LastRound,
+		LastStep:      Validator.LastStep,
+		LastSignature: Validator.LastSignature,
+		LastSignBytes: Validator.LastSignBytes,
+		LastTimestamp: Validator.LastTimestamp,
+	})
+	if err != nil {
+		return err
+	}
+	if err := help.WriteFileAtomic(Validator.filePath, data, 0600); err != nil {
+		return fmt.Errorf("error saving privValidator state: %v", err)
+	}
+	return nil
 }
 
 func (Validator *privValidator) GetAddress() help.Address {
@@ -134,7 +239,7 @@ func (Validator *privValidator) signVote(chainID string, vote *Vote) error {
 	if sameHRS {
 		if bytes.Equal(signBytes, Validator.LastSignBytes) {
 			vote.Signature = Validator.LastSignature
-		} else if timestamp, ok := checkVotesOnlyDifferByTimestamp(Validator.LastSignBytes, signBytes); ok {
+		} else if timestamp, ok := checkVotesOnlyDifferByTimestamp(Validator.LastSignBytes, Validator.LastTimestamp, chainID, vote); ok {
 			vote.Timestamp = timestamp
 			vote.Signature = Validator.LastSignature
 		} else {
@@ -148,7 +253,9 @@ func (Validator *privValidator) signVote(chainID string, vote *Vote) error {
 	if err != nil {
 		return err
 	}
-	Validator.saveSigned(height, int(round), step, signBytes, sig)
+	if err := Validator.saveSigned(height, int(round), step, signBytes, sig, vote.Timestamp); err != nil {
+		return err
+	}
 	vote.Signature = sig
 	return nil
 }
@@ -184,7 +291,7 @@ func (Validator *privValidator) signProposal(chainID string, proposal *Proposal)
 	if sameHRS {
 		if bytes.Equal(signBytes, Validator.LastSignBytes) {
 			proposal.Signature = Validator.LastSignature
-		} else if timestamp, ok := checkProposalsOnlyDifferByTimestamp(Validator.LastSignBytes, signBytes); ok {
+		} else if timestamp, ok := checkProposalsOnlyDifferByTimestamp(Validator.LastSignBytes, Validator.LastTimestamp, chainID, proposal); ok {
 			proposal.Timestamp = timestamp
 			proposal.Signature = Validator.LastSignature
 		} else {
@@ -198,7 +305,9 @@ func (Validator *privValidator) signProposal(chainID string, proposal *Proposal)
 	if err != nil {
 		return err
 	}
-	Validator.saveSigned(height, round, step, signBytes, sig)
+	if err := Validator.saveSigned(height, round, step, signBytes, sig, proposal.Timestamp); err != nil {
+		return err
+	}
 	proposal.Signature = sig
 	return nil
 }
@@ -231,56 +340,29 @@ func (Validator *privValidator) checkHRS(height uint64, round int, step uint8) (
 	return false, nil
 }
 
-// returns the timestamp from the lastSignBytes.
-// returns true if the only difference in the votes is their timestamp.
-func checkVotesOnlyDifferByTimestamp(lastSignBytes, newSignBytes []byte) (time.Time, bool) {
-	var lastVote, newVote CanonicalJSONVote
-	if err := cdc.UnmarshalJSON(lastSignBytes, &lastVote); err != nil {
-		panic(fmt.Sprintf("LastSignBytes cannot be unmarshalled into vote: %v", err))
-	}
-	if err := cdc.UnmarshalJSON(newSignBytes, &newVote); err != nil {
-		panic(fmt.Sprintf("signBytes cannot be unmarshalled into vote: %v", err))
-	}
-
-	lastTime, err := time.Parse(TimeFormat, lastVote.Timestamp)
-	if err != nil {
-		panic(err)
+// returns the timestamp of the last signed vote.
+// returns true if the only difference in the votes is their timestamp. The sign
+// bytes are hashed and can't be compared field by field, so the vote is hashed
+// again with the last timestamp and compared to the last sign bytes.
+func checkVotesOnlyDifferByTimestamp(lastSignBytes []byte, lastTime time.Time, chainID string, vote *Vote) (time.Time, bool) {
+	if lastTime.IsZero() {
+		return time.Time{}, false
 	}
-
-	// set the times to the same value and check equality
-	now := CanonicalTime(time.Now())
-	lastVote.Timestamp = now
-	newVote.Timestamp = now
-	lastVoteBytes, _ := cdc.MarshalJSON(lastVote)
-	newVoteBytes, _ := cdc.MarshalJSON(newVote)
-
-	return lastTime, bytes.Equal(newVoteBytes, lastVoteBytes)
+	lastVote := vote.Copy()
+	lastVote.Timestamp = lastTime
+	return lastTime, bytes.Equal(lastVote.SignBytes(chainID), lastSignBytes)
 }
 
-// returns the timestamp from the lastSignBytes.
-// returns true if the only difference in the proposals is their timestamp
-func checkProposalsOnlyDifferByTimestamp(lastSignBytes, newSignBytes []byte) (time.Time, bool) {
-	var lastProposal, newProposal CanonicalJSONProposal
-	if err := cdc.UnmarshalJSON(lastSignBytes, &lastProposal); err != nil {
-		panic(fmt.Sprintf("LastSignBytes cannot be unmarshalled into proposal: %v", err))
-	}
-	if err := cdc.UnmarshalJSON(newSignBytes, &newProposal); err != nil {
-		panic(fmt.Sprintf("signBytes cannot be unmarshalled into proposal: %v", err))
+// returns the timestamp of the last signed proposal.
+// returns true if the only difference in the proposals is their timestamp, it's
+// checked the same way as the votes.
+func checkProposalsOnlyDifferByTimestamp(lastSignBytes []byte, lastTime time.Time, chainID string, proposal *Proposal) (time.Time, bool) {
+	if lastTime.IsZero() {
+		return time.Time{}, false
 	}
-
-	lastTime, err := time.Parse(TimeFormat, lastProposal.Timestamp)
-	if err != nil {
-		panic(err)
-	}
-
-	// set the times to the same value and check equality
-	now := CanonicalTime(time.Now())
-	lastProposal.Timestamp = now
-	newProposal.Timestamp = now
-	lastProposalBytes, _ := cdc.MarshalJSON(lastProposal)
-	newProposalBytes, _ := cdc.MarshalJSON(newProposal)
-
-	return lastTime, bytes.Equal(newProposalBytes, lastProposalBytes)
+	lastProposal := *proposal
+	lastProposal.Timestamp = lastTime
+	return lastTime, bytes.Equal(lastProposal.SignBytes(chainID), lastSignBytes)
 }
 
 //----------------------------------------
@@ -565,7 +647,7 @@ func (state *StateAgentImpl) SignVote(chainID string, vote *Vote) error {
 
 //SignProposal sign of proposal msg
 func (state *StateAgentImpl) SignProposal(chainID string, proposal *Proposal) error {
-	return state.Priv.signProposal(chainID, proposal)
+	return state.Priv.SignProposal(chainID, proposal)
 }
 
 //Broadcast is agent Broadcast block
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iceming123/go-ice/crypto"
+)
+
+func makeTestVote(height uint64, round uint, typeB byte, hash []byte) *Vote {
+	return &Vote{
+		Height:    height,
+		Round:     round,
+		Type:      typeB,
+		Timestamp: time.Now().UTC(),
+		BlockID:   BlockID{Hash: hash},
+	}
+}
+
+func TestPrivValidatorPersistHRS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tbft-privval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "data", "priv_validator_state.json")
+
+	priv, _ := crypto.GenerateKey()
+	pv, err := LoadOrGenPrivValidator(*priv, file)
+	if err != nil {
+		t.Fatalf("create privValidator failed: %v", err)
+	}
+	chainID := "1"
+	vote := makeTestVote(10, 1, VoteTypePrecommit, []byte("block-a"))
+	if err := pv.SignVote(chainID, vote); err != nil {
+		t.Fatalf("sign vote failed: %v", err)
+	}
+	// a reset must not roll back the persisted state
+	pv.(*privValidator).Reset()
+
+	// simulate a restart
+	pv2, err := LoadOrGenPrivValidator(*priv, file)
+	if err != nil {
+		t.Fatalf("reload privValidator failed: %v", err)
+	}
+	loaded := pv2.(*privValidator)
+	if loaded.LastHeight != 10 || loaded.LastRound != 1 || loaded.LastStep != stepPrecommit {
+		t.Fatalf("wrong reloaded HRS: %d/%d/%d", loaded.LastHeight, loaded.LastRound, loaded.LastStep)
+	}
+	// the same vote may be re-signed with the same signature
+	same := *vote
+	same.Signature = nil
+	if err := pv2.SignVote(chainID, &same); err != nil {
+		t.Fatalf("re-sign same vote failed: %v", err)
+	}
+	// conflicting vote for the same HRS
+	if err := pv2.SignVote(chainID, makeTestVote(10, 1, VoteTypePrecommit, []byte("block-b"))); err == nil {
+		t.Fatal("conflicting vote was signed")
+	}
+	// regressions
+	if err := pv2.SignVote(chainID, makeTestVote(10, 1, VoteTypePrevote, []byte("block-b"))); err == nil {
+		t.Fatal("step regression was signed")
+	}
+	if err := pv2.SignVote(chainID, makeTestVote(10, 0, VoteTypePrecommit, []byte("block-b"))); err == nil {
+		t.Fatal("round regression was signed")
+	}
+	if err := pv2.SignVote(chainID, makeTestVote(9, 3, VoteTypePrevote, []byte("block-b"))); err == nil {
+		t.Fatal("height regression was signed")
+	}
+	// moving forward is fine
+	if err := pv2.SignVote(chainID, makeTestVote(11, 0, VoteTypePrevote, []byte("block-c"))); err != nil {
+		t.Fatalf("sign next height failed: %v", err)
+	}
+}
+
+func TestPrivValidatorSignDifferentTimestamp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tbft-privval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "priv_validator_state.json")
+
+	priv, _ := crypto.GenerateKey()
+	pv, err := LoadOrGenPrivValidator(*priv, file)
+	if err != nil {
+		t.Fatalf("create privValidator failed: %v", err)
+	}
+	chainID := "1"
+	vote := makeTestVote(10, 1, VoteTypePrevote, []byte("block-a"))
+	if err := pv.SignVote(chainID, vote); err != nil {
+		t.Fatalf("sign vote failed: %v", err)
+	}
+	proposal := NewProposal(11, 0, PartSetHeader{Total: 1}, 0, BlockID{})
+	if err := pv.SignProposal(chainID, proposal); err != nil {
+		t.Fatalf("sign proposal failed: %v", err)
+	}
+
+	// simulate a restart, the re-created proposal only differs by its timestamp
+	pv2, err := LoadOrGenPrivValidator(*priv, file)
+	if err != nil {
+		t.Fatalf("reload privValidator failed: %v", err)
+	}
+	same := *proposal
+	same.Signature = nil
+	same.Timestamp = proposal.Timestamp.Add(time.Second)
+	if err := pv2.SignProposal(chainID, &same); err != nil {
+		t.Fatalf("re-sign proposal with another timestamp failed: %v", err)
+	}
+	if !same.Timestamp.Equal(proposal.Timestamp) || !bytes.Equal(same.Signature, proposal.Signature) {
+		t.Fatalf("re-signed proposal mismatch: have %v %x, want %v %x", same.Timestamp, same.Signature, proposal.Timestamp, proposal.Signature)
+	}
+
+	// the same vote differing by its timestamp gets the last timestamp and signature
+	vote = makeTestVote(12, 0, VoteTypePrevote, []byte("block-b"))
+	if err := pv2.SignVote(chainID, vote); err != nil {
+		t.Fatalf("sign vote failed: %v", err)
+	}
+	sameVote := vote.Copy()
+	sameVote.Signature = nil
+	sameVote.Timestamp = vote.Timestamp.Add(time.Second)
+	if err := pv2.SignVote(chainID, sameVote); err != nil {
+		t.Fatalf("re-sign vote with another timestamp failed: %v", err)
+	}
+	if !sameVote.Timestamp.Equal(vote.Timestamp) || !bytes.Equal(sameVote.Signature, vote.Signature) {
+		t.Fatalf("re-signed vote mismatch: have %v %x, want %v %x", sameVote.Timestamp, sameVote.Signature, vote.Timestamp, vote.Signature)
+	}
+	// a vote for another block is still refused
+	other := makeTestVote(12, 0, VoteTypePrevote, []byte("block-c"))
+	if err := pv2.SignVote(chainID, other); err == nil {
+		t.Fatal("conflicting vote was signed")
+	}
+}
@@ -472,6 +472,29 @@ func (voteSet *VoteSet) MakePbftSigns(thash []byte) ([]*ttypes.PbftSign, error)
 	return signs, nil
 }
 
+//MakeDoubleSignEvidence returns the evidence of the vote and the conflicting vote
+//of the same validator in the set, nil if they didn't agree on two different blocks
+func (voteSet *VoteSet) MakeDoubleSignEvidence(vote *Vote) *ttypes.DoubleSignEvidence {
+	existing := voteSet.GetByIndex(vote.ValidatorIndex)
+	if existing == nil || len(existing.ResultSign) == 0 || len(vote.ResultSign) == 0 {
+		return nil
+	}
+	if existing.Height != vote.Height || help.EqualHashes(existing.BlockID.Hash, vote.BlockID.Hash) {
+		return nil
+	}
+	makeSign := func(v *Vote) *ttypes.PbftSign {
+		var hash common.Hash
+		copy(hash[:], v.BlockID.Hash)
+		return &ttypes.PbftSign{
+			FastHash:   hash,
+			FastHeight: new(big.Int).SetUint64(v.Height),
+			Result:     uint32(v.Result),
+			Sign:       v.ResultSign,
+		}
+	}
+	return ttypes.NewDoubleSignEvidence(makeSign(existing), makeSign(vote))
+}
+
 //GetSignByAddress is address to KeepBlockSign
 func (voteSet *VoteSet) GetSignByAddress(addr help.Address) *KeepBlockSign {
 	vote := voteSet.GetByAddress(addr[:])
@@ -0,0 +1,128 @@
+package tbft
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	ttypes "github.com/iceming123/go-ice/consensus/tbft/types"
+)
+
+func TestWALEncoderDecoder(t *testing.T) {
+	now := time.Now()
+	msgs := []TimedWALMessage{
+		{Time: now, Msg: EndHeightMessage{0}},
+		{Time: now, Msg: timeoutInfo{Duration: time.Second, Height: 1, Round: 1, Step: ttypes.RoundStepPropose, Wait: 1}},
+		{Time: now, Msg: msgInfo{Msg: &VoteMessage{Vote: &ttypes.Vote{Height: 1, Round: 1, Type: ttypes.VoteTypePrevote,
+			Timestamp: now.UTC(), BlockID: ttypes.BlockID{Hash: []byte("block")}}}, PeerID: "peer"}},
+	}
+
+	b := new(bytes.Buffer)
+	for _, msg := range msgs {
+		b.Reset()
+
+		enc := NewWALEncoder(b)
+		if err := enc.Encode(&msg); err != nil {
+			t.Fatalf("encode failed: %v", err)
+		}
+
+		dec := NewWALDecoder(b)
+		decoded, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if !msg.Time.Equal(decoded.Time) {
+			t.Fatalf("time mismatch: want %v, got %v", msg.Time, decoded.Time)
+		}
+		want, _ := cdc.MarshalJSON(msg.Msg)
+		got, _ := cdc.MarshalJSON(decoded.Msg)
+		if !bytes.Equal(want, got) {
+			t.Fatalf("msg mismatch: want %s, got %s", want, got)
+		}
+	}
+}
+
+func TestWALDecoderCorruption(t *testing.T) {
+	b := new(bytes.Buffer)
+	if err := NewWALEncoder(b).Encode(&TimedWALMessage{time.Now(), EndHeightMessage{1}}); err != nil {
+		t.Fatal(err)
+	}
+	data := b.Bytes()
+
+	// crash in the middle of a write
+	if _, err := NewWALDecoder(bytes.NewReader(data[:len(data)-1])).Decode(); !IsDataCorruptionError(err) {
+		t.Fatalf("expected data corruption on truncated entry, got %v", err)
+	}
+	// flipped bit
+	data[len(data)-1] ^= 0xff
+	if _, err := NewWALDecoder(bytes.NewReader(data)).Decode(); !IsDataCorruptionError(err) {
+		t.Fatalf("expected data corruption on checksum mismatch, got %v", err)
+	}
+}
+
+func TestWALSearchForEndHeight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tbft-wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wal, err := NewWAL(filepath.Join(dir, "wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wal.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for h := uint64(1); h <= 3; h++ {
+		wal.Write(timeoutInfo{Duration: time.Second, Height: h, Step: ttypes.RoundStepNewHeight})
+		wal.WriteSync(EndHeightMessage{h})
+		// every height goes to a new file
+		wal.Group().RotateFile()
+	}
+	wal.Write(timeoutInfo{Duration: time.Second, Height: 4, Step: ttypes.RoundStepPropose})
+	wal.WriteSync(msgInfo{Msg: &VoteMessage{Vote: &ttypes.Vote{Height: 4, Type: ttypes.VoteTypePrevote}}})
+	if max := wal.Group().MaxIndex(); max != 3 {
+		t.Fatalf("expected 3 rotated files, got %d", max)
+	}
+	if err := wal.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	wal, err = NewWAL(filepath.Join(dir, "wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wal.Group().Close()
+	rd, found, err := wal.SearchForEndHeight(2, &WALSearchOptions{})
+	if err != nil || !found {
+		t.Fatalf("height 2 not found: %v", err)
+	}
+	defer rd.Close()
+
+	// the messages after the marker span the rotated files
+	dec := NewWALDecoder(rd)
+	var heights []uint64
+	for {
+		msg, err := dec.Decode()
+		if err != nil {
+			break
+		}
+		switch m := msg.Msg.(type) {
+		case timeoutInfo:
+			heights = append(heights, m.Height)
+		case msgInfo:
+			heights = append(heights, m.Msg.(*VoteMessage).Vote.Height)
+		}
+	}
+	if len(heights) != 3 || heights[0] != 3 || heights[1] != 4 || heights[2] != 4 {
+		t.Fatalf("wrong replayed messages: %v", heights)
+	}
+
+	if _, found, _ := wal.SearchForEndHeight(5, &WALSearchOptions{}); found {
+		t.Fatal("found a height which was never written")
+	}
+}
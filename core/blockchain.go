@@ -33,6 +33,7 @@ import (
 	"github.com/iceming123/go-ice/consensus"
 	"github.com/iceming123/go-ice/core/rawdb"
 	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/state/snapshot"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/crypto"
@@ -82,6 +83,7 @@ type CacheConfig struct {
 	TrieCleanLimit int           // Memory allowance (MB) to use for caching trie nodes in memory
 	TrieNodeLimit  int           // Memory limit (MB) at which to start flushing dirty trie nodes to disk
 	TrieTimeLimit  time.Duration // Time limit after which to flush the current in-memory trie to disk
+	SnapshotLimit  int           // Memory allowance (MB) to use for caching snapshot entries in memory, 0 disables the snapshot
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -126,6 +128,7 @@ type BlockChain struct {
 	currentReward    atomic.Value // Current head of the currentReward
 
 	stateCache       state.Database // State database to reuse between imports (contains state cache)
+	snaps            *snapshot.Tree // Snapshot tree for fast trie leaf access
 	bodyCache        *lru.Cache     // Cache for the most recent block bodies
 	signCache        *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache     *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
@@ -216,6 +219,12 @@ func NewBlockChain(db icedb.Database, cacheConfig *CacheConfig,
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.SnapshotLimit > 0 {
+		if bc.snaps, err = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.cacheConfig.SnapshotLimit, bc.CurrentBlock().Root(), true); err != nil {
+			log.Warn("State snapshot disabled", "err", err)
+		}
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -361,6 +370,9 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop the frozen blocks above the new head, they aren't canonical anymore
+	rawdb.TruncateAncients(bc.db, currentHeader.Number.Uint64()+1)
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -386,6 +398,12 @@ func (bc *BlockChain) SetHead(head uint64) error {
 
 	if currentBlock := bc.CurrentBlock(); currentBlock != nil {
 		bc.currentFastBlock.Store(currentBlock)
+
+		// The snapshot keeps the recent diff layers only, rebuild it if the
+		// chain was rewound below the disk layer.
+		if bc.snaps != nil && bc.snaps.Snapshot(currentBlock.Root()) == nil {
+			bc.snaps.Rebuild(currentBlock.Root())
+		}
 	}
 
 	// Rewind the fast block in a simpleton way to the target head
@@ -537,7 +555,12 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
+}
+
+// Snapshots returns the blockchain snapshot tree, nil if the snapshot is disabled.
+func (bc *BlockChain) Snapshots() *snapshot.Tree {
+	return bc.snaps
 }
 
 // StateCache returns the caching database underpinning the blockchain instance.
@@ -816,6 +839,16 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Flatten the snapshot diff layers into the disk layer of the head state
+	// so the snapshot can be loaded at the next startup.
+	if bc.snaps != nil {
+		if root := bc.CurrentBlock().Root(); bc.snaps.DiskRoot() != root {
+			if err := bc.snaps.Cap(root, 0); err != nil {
+				log.Error("Failed to persist state snapshot", "err", err)
+			}
+		}
+		bc.snaps.Stop()
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -1085,6 +1118,20 @@ func (bc *BlockChain) writeBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return NonStatTy, err
 	}
+	// Keep the snapshot tree following the head, the layers beyond the
+	// tries in memory are flattened into the disk layer. The state pushed
+	// its layer on top of the parent one on commit, the written block is
+	// the new head so the tree is rebuilt from it if its layer is missing.
+	if bc.snaps != nil {
+		if bc.snaps.Snapshot(root) == nil {
+			log.Warn("Snapshot layer missing, rebuilding", "number", block.Number(), "root", root)
+			bc.snaps.Rebuild(root)
+		} else if bc.snaps.DiskRoot() != root {
+			if err := bc.snaps.Cap(root, TriesInMemory-1); err != nil {
+				log.Warn("Failed to cap snapshot tree", "root", root, "err", err)
+			}
+		}
+	}
 	triedb := bc.stateCache.TrieDB()
 
 	balanceC := &types.BlockBalance{Balance: types.ToBalanceInfos(state.BalancesChange())}
@@ -1314,7 +1361,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals bool) (int, []
 		if parent == nil {
 			parent = bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.NewWithSnapshot(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return it.index, events, coalescedLogs, err
 		}
@@ -315,10 +315,11 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 	}
 
 	head := &types.Header{
-		ParentHash: parent.Hash(),
-		GasLimit:   FastCalcGasLimit(parent, parent.GasLimit(), parent.GasLimit()),
-		Number:     new(big.Int).Add(parent.Number(), common.Big1),
-		Time:       time,
+		ParentHash:  parent.Hash(),
+		GasLimit:    FastCalcGasLimit(parent, parent.GasLimit(), parent.GasLimit()),
+		Number:      new(big.Int).Add(parent.Number(), common.Big1),
+		Time:        time,
+		SnailNumber: new(big.Int),
 	}
 	if chain.Config().IsTIP9(head.Number) {
 		head.SnailHash = common.Hash{}
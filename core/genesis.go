@@ -631,6 +631,22 @@ func DefaultSingleNodeGenesisBlock() *Genesis {
 	}
 }
 
+// DeveloperGenesisBlock returns the 'gice --dev' genesis block, a single node
+// chain sealed by the given committee key with a pre-funded faucet account.
+func DeveloperGenesisBlock(faucet common.Address, committeeKey []byte) *Genesis {
+	return &Genesis{
+		Config:     params.DeveloperChainConfig,
+		GasLimit:   22020096,
+		Difficulty: big.NewInt(256),
+		Alloc: map[common.Address]types.GenesisAccount{
+			faucet: {Balance: new(big.Int).Mul(big.NewInt(1e9), big.NewInt(params.Ether))},
+		},
+		Committee: []*types.CommitteeMember{
+			{Coinbase: faucet, Publickey: committeeKey},
+		},
+	}
+}
+
 // DefaultTestnetGenesisBlock returns the Ropsten network genesis block.
 func DefaultTestnetGenesisBlock() *Genesis {
 	// priv1: 55dcdfd62f565a66e1886959e82a365e4987ed0b405adc43614a42c3481edd1a
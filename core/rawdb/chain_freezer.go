@@ -0,0 +1,192 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/params"
+)
+
+// ReadAncients returns the number of the frozen fast blocks, zero if the
+// database has no freezer.
+func ReadAncients(db DatabaseReader) uint64 {
+	if reader, ok := db.(icedb.AncientReader); ok {
+		frozen, _ := reader.Ancients(freezerChain)
+		return frozen
+	}
+	return 0
+}
+
+// TruncateAncients discards the frozen fast blocks from number n onwards, it
+// is used when the chain is rewound below the frozen segment.
+func TruncateAncients(db DatabaseDeleter, n uint64) {
+	if writer, ok := db.(icedb.AncientWriter); ok {
+		if err := writer.TruncateAncients(freezerChain, n); err != nil {
+			log.Crit("Failed to truncate fast ancients", "err", err)
+		}
+	}
+}
+
+// readAncient retrieves an item of the frozen fast chain, nil is returned if
+// the database has no freezer or the item isn't frozen yet.
+func readAncient(db DatabaseReader, kind string, number uint64) []byte {
+	if reader, ok := db.(icedb.AncientReader); ok {
+		data, _ := reader.Ancient(freezerChain, kind, number)
+		return data
+	}
+	return nil
+}
+
+// readAncientBlock retrieves an item of a frozen fast block, making sure the
+// block with the given number is the canonical one with the given hash.
+func readAncientBlock(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	data := readAncient(db, freezerHashTable, number)
+	if len(data) == 0 || common.BytesToHash(data) != hash {
+		return nil
+	}
+	return readAncient(db, kind, number)
+}
+
+// freezeChain moves the canonical fast blocks older than the immutability
+// threshold out of the key-value store into the freezer. It is meant to be
+// run by the freezer on the raw key-value store.
+func freezeChain(db icedb.Database, f *icedb.Freezer) (int, error) {
+	// Retrieve the freezing threshold
+	hash := ReadHeadBlockHash(db)
+	if hash == (common.Hash{}) {
+		return 0, nil // Database not initialized yet
+	}
+	number := ReadHeaderNumber(db, hash)
+	if number == nil {
+		return 0, errors.New("current fast block number missing")
+	}
+	frozen, _ := f.Ancients()
+	if *number < params.FastImmutabilityThreshold || *number-params.FastImmutabilityThreshold < frozen {
+		return 0, nil
+	}
+	limit := *number - params.FastImmutabilityThreshold
+	if limit-frozen >= icedb.FreezerBatchLimit {
+		limit = frozen + icedb.FreezerBatchLimit - 1
+	}
+	// Inject all the canonical blocks into the freezer, the already frozen ones
+	// are wiped even if a later block fails.
+	var (
+		first    = frozen
+		ancients []common.Hash
+		err      error
+	)
+	for ; frozen <= limit; frozen++ {
+		hash := ReadCanonicalHash(db, frozen)
+		if hash == (common.Hash{}) {
+			err = fmt.Errorf("canonical hash missing, can't freeze block %d", frozen)
+			break
+		}
+		header := ReadHeaderRLP(db, hash, frozen)
+		if len(header) == 0 {
+			err = fmt.Errorf("block header missing, can't freeze block %d", frozen)
+			break
+		}
+		// The bodies and receipts removed by the body and receipt garbage
+		// collection are frozen empty
+		items := map[string][]byte{
+			freezerHashTable:    hash.Bytes(),
+			freezerHeaderTable:  header,
+			freezerBodiesTable:  ReadBodyRLP(db, hash, frozen),
+			freezerReceiptTable: ReadReceiptsRLP(db, hash, frozen),
+		}
+		if err = f.AppendAncient(frozen, items); err != nil {
+			break
+		}
+		ancients = append(ancients, hash)
+	}
+	if len(ancients) == 0 {
+		return 0, err
+	}
+	// Batch of blocks have been frozen, flush them before wiping from leveldb
+	if err := f.Sync(); err != nil {
+		log.Crit("Failed to flush frozen tables", "err", err)
+	}
+	// Wipe out all data from the active database
+	batch := db.NewBatch()
+	for i, hash := range ancients {
+		number := first + uint64(i)
+
+		// Always keep the genesis block in active database
+		if number == 0 {
+			continue
+		}
+		deleteFrozenBlock(batch, hash, number)
+
+		// Wipe out side chains at the same height too
+		for _, side := range readAllHashes(db, number) {
+			if side != hash {
+				DeleteBlock(batch, side, number)
+			}
+		}
+		if batch.ValueSize() >= icedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to delete frozen blocks", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to delete frozen blocks", "err", err)
+	}
+	return len(ancients), err
+}
+
+// deleteFrozenBlock removes the canonical block data moved into the freezer,
+// the hash to number mapping is kept in the key-value store.
+func deleteFrozenBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
+	for _, key := range [][]byte{
+		headerKey(number, hash),
+		blockBodyKey(number, hash),
+		blockReceiptsKey(number, hash),
+		headerHashKey(number),
+	} {
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete frozen block", "number", number, "err", err)
+		}
+	}
+}
+
+// readAllHashes retrieves all the hashes assigned to blocks at a certain
+// height, both canonical and reorged forks included.
+func readAllHashes(db icedb.Database, number uint64) []common.Hash {
+	iteratee, ok := db.(icedb.Iteratee)
+	if !ok {
+		return nil
+	}
+	prefix := append(append([]byte{}, headerPrefix...), encodeBlockNumber(number)...)
+
+	it := iteratee.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.HashLength {
+			hashes = append(hashes, common.BytesToHash(key[len(prefix):]))
+		}
+	}
+	return hashes
+}
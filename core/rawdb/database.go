@@ -0,0 +1,149 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/iceming123/go-ice/common"
+	snaildb "github.com/iceming123/go-ice/core/snailchain/rawdb"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+)
+
+// errUnknownChain is returned if the ancient data of a chain without freezer
+// is requested.
+var errUnknownChain = errors.New("unknown ancient chain")
+
+// freezerdb is a database wrapper that moves the immutable segments of the
+// fast and the snail chain into their freezers and serves reads from them.
+type freezerdb struct {
+	*icedb.LDBDatabase
+	freezers map[string]*icedb.Freezer
+}
+
+// NewDatabaseWithFreezer creates a high level database on top of a given
+// LevelDB, the immutable chain segments are moved into the fast and snail
+// freezers below the ancient directory.
+func NewDatabaseWithFreezer(db *icedb.LDBDatabase, ancient string, namespace string) (icedb.Database, error) {
+	fast, err := icedb.NewFreezer(filepath.Join(ancient, freezerChain), namespace+freezerChain+"/", freezerNoSnappy)
+	if err != nil {
+		return nil, err
+	}
+	snail, err := snaildb.NewFreezer(filepath.Join(ancient, snaildb.FreezerChain), namespace+snaildb.FreezerChain+"/")
+	if err != nil {
+		fast.Close()
+		return nil, err
+	}
+	// Make sure the freezers belong to the key-value store and don't run ahead
+	// of the chain heads in it.
+	var head, snailHead *uint64
+	if hash := ReadHeadHeaderHash(db); hash != (common.Hash{}) {
+		head = ReadHeaderNumber(db, hash)
+	}
+	if hash := snaildb.ReadHeadHeaderHash(db); hash != (common.Hash{}) {
+		snailHead = snaildb.ReadHeaderNumber(db, hash)
+	}
+	if err = checkAncients(fast, ReadCanonicalHash(db, 0), head); err == nil {
+		err = checkAncients(snail, snaildb.ReadCanonicalHash(db, 0), snailHead)
+	}
+	if err != nil {
+		fast.Close()
+		snail.Close()
+		return nil, err
+	}
+	fast.Freeze(db, freezeChain)
+	snail.Freeze(db, snaildb.FreezeChain)
+
+	return &freezerdb{
+		LDBDatabase: db,
+		freezers: map[string]*icedb.Freezer{
+			freezerChain:         fast,
+			snaildb.FreezerChain: snail,
+		},
+	}, nil
+}
+
+// checkAncients validates the frozen chain segment against the genesis and
+// the head number of the chain in the key-value store.
+func checkAncients(f *icedb.Freezer, genesis common.Hash, head *uint64) error {
+	frozen, _ := f.Ancients()
+	if frozen == 0 {
+		return nil
+	}
+	if genesis == (common.Hash{}) {
+		return errors.New("ancient chain segments already extracted, but the key-value database is empty")
+	}
+	data, err := f.Ancient(freezerHashTable, 0)
+	if err != nil {
+		return err
+	}
+	if frozenGenesis := common.BytesToHash(data); frozenGenesis != genesis {
+		return fmt.Errorf("genesis mismatch: %#x (leveldb) != %#x (ancients)", genesis, frozenGenesis)
+	}
+	// A rewound chain might have crashed before truncating the freezer
+	if head != nil && *head+1 < frozen {
+		log.Warn("Truncating ancients above the chain head", "head", *head, "frozen", frozen)
+		return f.TruncateAncients(*head + 1)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified data exists in the
+// ancient store of the chain.
+func (frdb *freezerdb) HasAncient(chain, kind string, number uint64) (bool, error) {
+	if f := frdb.freezers[chain]; f != nil {
+		return f.HasAncient(kind, number)
+	}
+	return false, errUnknownChain
+}
+
+// Ancient retrieves an ancient binary blob from the chain freezer.
+func (frdb *freezerdb) Ancient(chain, kind string, number uint64) ([]byte, error) {
+	if f := frdb.freezers[chain]; f != nil {
+		return f.Ancient(kind, number)
+	}
+	return nil, errUnknownChain
+}
+
+// Ancients returns the number of the items frozen for the chain.
+func (frdb *freezerdb) Ancients(chain string) (uint64, error) {
+	if f := frdb.freezers[chain]; f != nil {
+		return f.Ancients()
+	}
+	return 0, errUnknownChain
+}
+
+// TruncateAncients discards all but the first n ancient items of the chain.
+func (frdb *freezerdb) TruncateAncients(chain string, n uint64) error {
+	if f := frdb.freezers[chain]; f != nil {
+		return f.TruncateAncients(n)
+	}
+	return errUnknownChain
+}
+
+// Close stops the freezers before closing the key-value store.
+func (frdb *freezerdb) Close() {
+	for chain, f := range frdb.freezers {
+		if err := f.Close(); err != nil {
+			log.Error("Failed to close chain freezer", "chain", chain, "err", err)
+		}
+	}
+	frdb.LDBDatabase.Close()
+}
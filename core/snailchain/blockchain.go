@@ -281,6 +281,9 @@ func (bc *SnailBlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop the frozen blocks above the new head, they aren't canonical anymore
+	rawdb.TruncateAncients(bc.db, currentHeader.Number.Uint64()+1)
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -30,6 +30,9 @@ import (
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
 	data, _ := db.Get(headerHashKey(number))
+	if len(data) == 0 {
+		data = readAncient(db, freezerHashTable, number)
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -129,13 +132,16 @@ func WriteLightCheckPoint(db DatabaseWriter, count uint64) {
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientBlock(db, freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
-		return false
+		return len(readAncientBlock(db, freezerHeaderTable, hash, number)) > 0
 	}
 	return true
 }
@@ -191,6 +197,9 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(blockBodyKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientBlock(db, freezerBodiesTable, hash, number)
+	}
 	return data
 }
 
@@ -204,7 +213,7 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
-		return false
+		return len(readAncientBlock(db, freezerBodiesTable, hash, number)) > 0
 	}
 	return true
 }
@@ -242,6 +251,9 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
 	data, _ := db.Get(headerTDKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientBlock(db, freezerDifficultyTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -364,6 +376,9 @@ func WriteCommitteeStates(db DatabaseWriter, committee uint64, changes []*big.In
 // ReadFHsRLP retrieves the fruits head in RLP encoding.
 func ReadFHsRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(fruitHeadsKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientBlock(db, freezerFruitsTable, hash, number)
+	}
 	return data
 }
 
@@ -377,7 +392,7 @@ func WriteFHsRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Raw
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasFruitsHead(db DatabaseReader, hash common.Hash, number uint64) bool {
 	if has, err := db.Has(fruitHeadsKey(number, hash)); !has || err != nil {
-		return false
+		return len(readAncientBlock(db, freezerFruitsTable, hash, number)) > 0
 	}
 	return true
 }
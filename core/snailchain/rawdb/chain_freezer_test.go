@@ -0,0 +1,120 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/params"
+)
+
+// ancientTestDatabase serves the snail ancients of a freezer on top of an in
+// memory key-value store.
+type ancientTestDatabase struct {
+	*icedb.MemDatabase
+	freezer *icedb.Freezer
+}
+
+func (db *ancientTestDatabase) HasAncient(chain, kind string, number uint64) (bool, error) {
+	return db.freezer.HasAncient(kind, number)
+}
+
+func (db *ancientTestDatabase) Ancient(chain, kind string, number uint64) ([]byte, error) {
+	return db.freezer.Ancient(kind, number)
+}
+
+func (db *ancientTestDatabase) Ancients(chain string) (uint64, error) {
+	return db.freezer.Ancients()
+}
+
+// Tests that the finalized snail blocks are moved into the freezer and are
+// still served by the accessors afterwards.
+func TestFreezeChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snail-freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	freezer, err := NewFreezer(dir, "")
+	if err != nil {
+		t.Fatalf("Failed to open freezer: %v", err)
+	}
+	defer freezer.Close()
+
+	db := &ancientTestDatabase{MemDatabase: icedb.NewMemDatabase(), freezer: freezer}
+
+	// Assemble a canonical chain just long enough to freeze a few blocks
+	var (
+		frozen = uint64(10)
+		blocks []*types.SnailBlock
+		parent common.Hash
+	)
+	for i := uint64(0); i < params.SnailImmutabilityThreshold+frozen; i++ {
+		header := &types.SnailHeader{Number: new(big.Int).SetUint64(i), ParentHash: parent, Extra: []byte("test block")}
+		block := types.NewSnailBlockWithHeader(header)
+
+		WriteBlock(db, block)
+		WriteTd(db, block.Hash(), i, new(big.Int).SetUint64(i+1))
+		WriteCanonicalHash(db, block.Hash(), i)
+
+		blocks, parent = append(blocks, block), block.Hash()
+	}
+	WriteHeadBlockHash(db, parent)
+
+	// Add a side chain block that should be dropped with the canonical one
+	side := types.NewSnailBlockWithHeader(&types.SnailHeader{Number: big.NewInt(5), Extra: []byte("side block")})
+	WriteBlock(db, side)
+
+	if n, err := FreezeChain(db.MemDatabase, freezer); err != nil {
+		t.Fatalf("Failed to freeze chain: %v", err)
+	} else if uint64(n) != frozen {
+		t.Fatalf("Frozen block count mismatch: have %d, want %d", n, frozen)
+	}
+	if items := ReadAncients(db); items != frozen {
+		t.Fatalf("Ancient count mismatch: have %d, want %d", items, frozen)
+	}
+	for i, block := range blocks {
+		number := uint64(i)
+		if hash := ReadCanonicalHash(db, number); hash != block.Hash() {
+			t.Fatalf("Block %d: canonical hash mismatch: have %x, want %x", number, hash, block.Hash())
+		}
+		if entry := ReadBlock(db, block.Hash(), number); entry == nil || entry.Hash() != block.Hash() {
+			t.Fatalf("Block %d: block mismatch: have %v, want %v", number, entry, block)
+		}
+		if td := ReadTd(db, block.Hash(), number); td == nil || td.Uint64() != number+1 {
+			t.Fatalf("Block %d: total difficulty mismatch: have %v, want %d", number, td, number+1)
+		}
+		// The frozen blocks must be gone from the key-value store, apart from the genesis
+		if inKV := HasHeader(db.MemDatabase, block.Hash(), number); inKV != (number == 0 || number >= frozen) {
+			t.Fatalf("Block %d: header presence in key-value store mismatch: have %v", number, inKV)
+		}
+	}
+	if HasHeader(db, side.Hash(), 5) {
+		t.Fatalf("Side chain block at frozen height not deleted")
+	}
+	// Make sure nothing is frozen until the head progresses
+	if n, err := FreezeChain(db.MemDatabase, freezer); n != 0 || err != nil {
+		t.Fatalf("Unexpected freeze: have %d blocks, err %v", n, err)
+	}
+}
@@ -63,6 +63,36 @@ var (
 	headHashEpochSuffix = []byte("she") // headHashPrefix + num (uint64 big endian) + headHashEpochSuffix -> headHashEpoch
 )
 
+const (
+	// FreezerChain is the name of the snail chain freezer in the ancient store.
+	FreezerChain = "snail"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+
+	// freezerFruitsTable indicates the name of the freezer fruit headers table.
+	freezerFruitsTable = "fruits"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient-tables.
+// Hashes and difficulties are incompressible, don't waste time on them.
+var freezerNoSnappy = map[string]bool{
+	freezerHashTable:       true,
+	freezerHeaderTable:     false,
+	freezerBodiesTable:     false,
+	freezerDifficultyTable: true,
+	freezerFruitsTable:     false,
+}
+
 // FtLookupEntry is a positional metadata to help looking up the data content of
 // a fruit.
 type FtLookupEntry struct {
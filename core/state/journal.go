@@ -90,7 +90,9 @@ type (
 		account *common.Address
 	}
 	resetObjectChange struct {
-		prev *stateObject
+		prev         *stateObject
+		prevdestruct bool                   // whether the account was destructed in the snapshot changes
+		prevstorage  map[common.Hash][]byte // the snapshot storage changes of the account
 	}
 	suicideChange struct {
 		account     *common.Address
@@ -136,6 +138,15 @@ type (
 		prev      bool
 		prevDirty bool
 	}
+
+	// Changes to the access list
+	accessListAddAccountChange struct {
+		address *common.Address
+	}
+	accessListAddSlotChange struct {
+		address *common.Address
+		slot    *common.Hash
+	}
 )
 
 func (ch createObjectChange) revert(s *StateDB) {
@@ -149,6 +160,14 @@ func (ch createObjectChange) dirtied() *common.Address {
 
 func (ch resetObjectChange) revert(s *StateDB) {
 	s.setStateObject(ch.prev)
+	if s.snap != nil {
+		if !ch.prevdestruct {
+			delete(s.snapDestructs, ch.prev.addrHash)
+		}
+		if ch.prevstorage != nil {
+			s.snapStorage[ch.prev.addrHash] = ch.prevstorage
+		}
+	}
 }
 
 func (ch resetObjectChange) dirtied() *common.Address {
@@ -245,3 +264,28 @@ func (ch addPreimageChange) revert(s *StateDB) {
 func (ch addPreimageChange) dirtied() *common.Address {
 	return nil
 }
+
+func (ch accessListAddAccountChange) revert(s *StateDB) {
+	/*
+		One important invariant here, is that whenever a (addr, slot) is added, if the
+		addr is not already present, the add causes two journal entries:
+		- one for the address,
+		- one for the (address,slot)
+		Therefore, when unrolling the change, we can always blindly delete the
+		(addr) at this point, since no storage adds can remain when come upon
+		a single (addr) change.
+	*/
+	s.accessList.DeleteAddress(*ch.address)
+}
+
+func (ch accessListAddAccountChange) dirtied() *common.Address {
+	return nil
+}
+
+func (ch accessListAddSlotChange) revert(s *StateDB) {
+	s.accessList.DeleteSlot(*ch.address, *ch.slot)
+}
+
+func (ch accessListAddSlotChange) dirtied() *common.Address {
+	return nil
+}
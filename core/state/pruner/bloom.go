@@ -0,0 +1,69 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"encoding/binary"
+
+	"github.com/iceming123/go-ice/common"
+)
+
+// stateBloomHashes is the number of bits set in the filter for each key.
+const stateBloomHashes = 4
+
+// stateBloom is a bloom filter of the live trie nodes and contract codes. The
+// keys are keccak hashes already, so the bit positions are taken from the key
+// bytes directly instead of hashing them again.
+//
+// A false positive only keeps a stale node alive, it never drops a live one.
+type stateBloom struct {
+	bits []byte
+	size uint64 // Number of bits in the filter
+}
+
+// newStateBloom creates a filter of the given size in megabytes.
+func newStateBloom(size uint64) *stateBloom {
+	if size == 0 {
+		size = 1
+	}
+	bits := make([]byte, size*1024*1024)
+	return &stateBloom{bits: bits, size: uint64(len(bits)) * 8}
+}
+
+// Put marks the hash as a live entry.
+func (bloom *stateBloom) Put(hash common.Hash) {
+	for i := 0; i < stateBloomHashes; i++ {
+		bit := bloom.position(hash, i)
+		bloom.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// Contain reports whether the hash might be a live entry.
+func (bloom *stateBloom) Contain(hash common.Hash) bool {
+	for i := 0; i < stateBloomHashes; i++ {
+		bit := bloom.position(hash, i)
+		if bloom.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// position returns the i-th bit position of the hash in the filter.
+func (bloom *stateBloom) position(hash common.Hash, i int) uint64 {
+	return binary.BigEndian.Uint64(hash[i*8:]) % bloom.size
+}
@@ -0,0 +1,241 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements the offline removal of the stale state tries of
+// a full node.
+package pruner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// DefaultRetainBlocks is the number of recent fast blocks whose state is
+	// kept by default, it matches the tries a running node holds in memory.
+	DefaultRetainBlocks = 128
+
+	// DefaultBloomSize is the default size of the live node filter in
+	// megabytes.
+	DefaultBloomSize = 2048
+)
+
+// Pruner is an offline tool to prune the stale state of a full node. Trie
+// nodes are written to the database by hash and shared between the states of
+// many blocks, so the nodes of the retained states are collected into a
+// bloom filter and every trie node or contract code missing from the filter
+// is deleted.
+//
+// The retained states are the ones of the last few fast blocks still on disk,
+// the genesis state and the state of the persisted snapshot. The impawn state
+// lives in the storage trie of types.StakingAddress and is kept along with the
+// state it belongs to.
+type Pruner struct {
+	db        icedb.Database
+	retain    uint64 // Number of recent fast blocks whose state is kept
+	bloomSize uint64 // Size of the live node filter in megabytes
+}
+
+// NewPruner creates a pruner keeping the state of the last retain fast blocks.
+func NewPruner(db icedb.Database, retain uint64, bloomSize uint64) (*Pruner, error) {
+	if retain == 0 {
+		return nil, errors.New("at least the head state must be retained")
+	}
+	if _, ok := db.(icedb.Iteratee); !ok {
+		return nil, errors.New("database doesn't support iteration")
+	}
+	return &Pruner{db: db, retain: retain, bloomSize: bloomSize}, nil
+}
+
+// Prune deletes all the trie nodes and contract codes not referenced by the
+// retained states, and makes sure the head state is still complete after.
+func (p *Pruner) Prune() error {
+	head := rawdb.ReadHeadBlockHash(p.db)
+	if head == (common.Hash{}) {
+		return errors.New("head block missing, database not initialized")
+	}
+	number := rawdb.ReadHeaderNumber(p.db, head)
+	if number == nil {
+		return fmt.Errorf("head block number missing: %x", head)
+	}
+	header := rawdb.ReadHeader(p.db, head, *number)
+	if header == nil {
+		return fmt.Errorf("head block header missing: #%d [%x]", *number, head)
+	}
+	if !p.hasState(header.Root) {
+		return fmt.Errorf("head state missing, can't prune: #%d [%x]", *number, header.Root)
+	}
+	// Remember whether the staking state has to be found after pruning
+	staking := loadImpawn(p.db, header.Root) == nil
+
+	// Collect the live nodes of all the retained states
+	start := time.Now()
+	bloom := newStateBloom(p.bloomSize)
+	for _, root := range p.retainedRoots(header) {
+		if err := commitState(p.db, bloom, root); err != nil {
+			return fmt.Errorf("failed to iterate state %x: %v", root, err)
+		}
+	}
+	log.Info("Collected the live state nodes", "elapsed", common.PrettyDuration(time.Since(start)))
+
+	if err := p.sweep(bloom); err != nil {
+		return err
+	}
+	// Make sure the pruning didn't touch the head state
+	if err := commitState(p.db, newStateBloom(1), header.Root); err != nil {
+		return fmt.Errorf("head state incomplete after pruning: %v", err)
+	}
+	if staking {
+		if err := loadImpawn(p.db, header.Root); err != nil {
+			return fmt.Errorf("impawn state incomplete after pruning: %v", err)
+		}
+	}
+	log.Info("Pruned the stale state", "number", *number, "root", header.Root, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// hasState reports whether the root node of the state is on disk. The nodes
+// are flushed bottom-up, so the whole state is available with its root.
+func (p *Pruner) hasState(root common.Hash) bool {
+	ok, _ := p.db.Has(root.Bytes())
+	return ok
+}
+
+// retainedRoots returns the state roots kept by the pruning, the ones missing
+// from the disk are skipped.
+func (p *Pruner) retainedRoots(head *types.Header) []common.Hash {
+	var (
+		roots []common.Hash
+		seen  = make(map[common.Hash]struct{})
+	)
+	retain := func(root common.Hash) {
+		if _, ok := seen[root]; ok || !p.hasState(root) {
+			return
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, root)
+	}
+	retain(head.Root)
+	for i := uint64(1); i < p.retain && i <= head.Number.Uint64(); i++ {
+		number := head.Number.Uint64() - i
+		if header := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, number), number); header != nil {
+			retain(header.Root)
+		}
+	}
+	if genesis := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, 0), 0); genesis != nil {
+		retain(genesis.Root)
+	}
+	if root := rawdb.ReadSnapshotRoot(p.db); root != (common.Hash{}) {
+		retain(root)
+	}
+	log.Info("Retaining the recent states", "head", head.Number, "states", len(roots))
+	return roots
+}
+
+// sweep deletes the trie nodes and contract codes missing from the bloom.
+func (p *Pruner) sweep(bloom *stateBloom) error {
+	var (
+		start   = time.Now()
+		logged  = time.Now()
+		batch   = p.db.NewBatch()
+		count   int
+		size    common.StorageSize
+		skipped int
+	)
+	it := p.db.(icedb.Iteratee).NewIteratorWithPrefix(nil)
+	defer it.Release()
+
+	for it.Next() {
+		// Trie nodes and contract codes are the only entries keyed by a bare hash
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if bloom.Contain(common.BytesToHash(key)) {
+			skipped++
+			continue
+		}
+		count++
+		size += common.StorageSize(len(key) + len(it.Value()))
+		if err := batch.Delete(common.CopyBytes(key)); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= icedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data", "nodes", count, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "nodes", count, "size", size, "kept", skipped, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Reclaim the disk space of the deleted entries
+	if ldb, ok := p.db.(interface{ LDB() *leveldb.DB }); ok {
+		cstart := time.Now()
+		log.Info("Compacting database", "note", "this may take a while")
+		if err := ldb.LDB().CompactRange(util.Range{}); err != nil {
+			return err
+		}
+		log.Info("Database compaction finished", "elapsed", common.PrettyDuration(time.Since(cstart)))
+	}
+	return nil
+}
+
+// commitState marks every trie node and contract code of the state as live,
+// failing if any of them is missing from the database.
+func commitState(db icedb.Database, bloom *stateBloom, root common.Hash) error {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+		if it.Hash != (common.Hash{}) {
+			bloom.Put(it.Hash)
+		}
+	}
+	return it.Error
+}
+
+// loadImpawn loads the impawn state stored under types.StakingAddress.
+func loadImpawn(db icedb.Database, root common.Hash) error {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	return vm.NewImpawnImpl().Load(statedb, types.StakingAddress)
+}
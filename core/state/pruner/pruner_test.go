@@ -0,0 +1,109 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/icedb"
+)
+
+// makeState commits a state with the given balances on top of parent and
+// writes the fast block of it as the canonical head.
+func makeState(t *testing.T, db icedb.Database, parent common.Hash, number int64, balances map[common.Address]int64) common.Hash {
+	sdb := state.NewDatabase(db)
+	statedb, err := state.New(parent, sdb)
+	if err != nil {
+		t.Fatalf("Failed to open state %x: %v", parent, err)
+	}
+	for addr, balance := range balances {
+		statedb.SetBalance(addr, big.NewInt(balance))
+	}
+	if err := vm.NewImpawnImpl().Save(statedb, types.StakingAddress); err != nil {
+		t.Fatalf("Failed to save impawn state: %v", err)
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("Failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("Failed to flush state: %v", err)
+	}
+	header := &types.Header{Number: big.NewInt(number), Root: root}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	rawdb.WriteHeadBlockHash(db, header.Hash())
+	return root
+}
+
+// Tests that the pruning drops the stale states and keeps the recent ones
+// along with the genesis.
+func TestPruneState(t *testing.T) {
+	var (
+		db    = icedb.NewMemDatabase()
+		addr1 = common.Address{1}
+		addr2 = common.Address{2}
+	)
+	genesis := makeState(t, db, common.Hash{}, 0, map[common.Address]int64{addr1: 1})
+	stale := makeState(t, db, genesis, 1, map[common.Address]int64{addr2: 2})
+	parent := makeState(t, db, stale, 2, map[common.Address]int64{addr2: 3})
+	head := makeState(t, db, parent, 3, map[common.Address]int64{addr1: 4})
+
+	pruner, err := NewPruner(db, 2, 1)
+	if err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if err := pruner.Prune(); err != nil {
+		t.Fatalf("Failed to prune state: %v", err)
+	}
+	for _, root := range []common.Hash{genesis, parent, head} {
+		if err := commitState(db, newStateBloom(1), root); err != nil {
+			t.Errorf("Retained state %x incomplete: %v", root, err)
+		}
+	}
+	if ok, _ := db.Has(stale.Bytes()); ok {
+		t.Errorf("Stale state %x not pruned", stale)
+	}
+	if err := loadImpawn(db, head); err != nil {
+		t.Errorf("Impawn state lost: %v", err)
+	}
+}
+
+// Tests that the pruning refuses to run without the head state.
+func TestPruneMissingHeadState(t *testing.T) {
+	db := icedb.NewMemDatabase()
+	makeState(t, db, common.Hash{}, 0, map[common.Address]int64{{1}: 1})
+
+	header := &types.Header{Number: big.NewInt(1), Root: common.Hash{0xff}}
+	rawdb.WriteHeader(db, header)
+	rawdb.WriteCanonicalHash(db, header.Hash(), 1)
+	rawdb.WriteHeadBlockHash(db, header.Hash())
+
+	pruner, err := NewPruner(db, DefaultRetainBlocks, 1)
+	if err != nil {
+		t.Fatalf("Failed to create pruner: %v", err)
+	}
+	if err := pruner.Prune(); err == nil {
+		t.Fatalf("Pruning succeeded without the head state")
+	}
+}
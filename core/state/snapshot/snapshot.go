@@ -0,0 +1,500 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package snapshot implements a journalled, dynamic state dump.
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
+	"github.com/iceming123/go-ice/trie"
+)
+
+// approxEntrySize is the estimated size of a cached snapshot entry, used to
+// convert the memory allowance into a number of cache items.
+const approxEntrySize = 128
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
+	// layer had been invalidated due to the chain progressing forward far enough
+	// to not maintain the layer's original state.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the underlying snapshot
+	// is being generated currently and the requested data item is not yet in the
+	// range of accounts covered.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	// errSnapshotCycle is returned if a snapshot is attempted to be inserted
+	// that forms a cycle in the snapshot tree.
+	errSnapshotCycle = errors.New("snapshot cycle")
+)
+
+// Snapshot represents the functionality supported by a snapshot storage layer.
+// The entries are the raw values of the account and storage trie leaves.
+type Snapshot interface {
+	// Root returns the root hash for which this snapshot was made.
+	Root() common.Hash
+
+	// Account directly retrieves the RLP encoded account associated with a
+	// particular hash in the snapshot slim data format.
+	Account(hash common.Hash) ([]byte, error)
+
+	// Storage directly retrieves the storage data associated with a particular
+	// hash, within a particular account.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+}
+
+// snapshot is the internal version of the snapshot data layer that supports some
+// additional methods compared to the public API.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the subsequent layer of a snapshot, or nil if the base was
+	// reached.
+	Parent() snapshot
+
+	// Update creates a new layer on top of the existing snapshot diff tree with
+	// the specified data items.
+	Update(blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer
+
+	// Stale return whether this layer has become stale (was flattened across) or
+	// if it's still live.
+	Stale() bool
+}
+
+// Tree is an Ethereum state snapshot tree. It consists of one persistent base
+// layer backed by a key-value store, on top of which arbitrarily many in-memory
+// diff layers are topped. The memory diffs can form a tree with branching, but
+// the disk layer is singleton and common to all. If a reorg goes deeper than the
+// disk layer, everything needs to be deleted.
+//
+// The goal of a state snapshot is twofold: to allow direct access to account and
+// storage data to avoid expensive multi-level trie lookups; and to allow sorted,
+// cheap iteration of the account/storage tries for sync aid.
+type Tree struct {
+	diskdb icedb.Database           // Persistent database to store the snapshot
+	triedb *trie.Database           // In-memory cache to access the trie through
+	cache  int                      // Megabytes permitted to use for read caches
+	layers map[common.Hash]snapshot // Collection of all known layers
+	lock   sync.RWMutex
+}
+
+// New attempts to load an already existing snapshot from a persistent key-value
+// store (with a number of memory layers from a journal), ensuring that the head
+// of the snapshot matches the expected one.
+//
+// If the snapshot is missing or inconsistent, the entirety is deleted and will
+// be reconstructed from scratch based on the tries in the key-value store, on a
+// background thread. If async is false, New waits for the generation.
+func New(diskdb icedb.Database, triedb *trie.Database, cache int, root common.Hash, async bool) (*Tree, error) {
+	if _, ok := diskdb.(icedb.Iteratee); !ok {
+		return nil, errors.New("snapshot database is not iterable")
+	}
+	snap := &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		cache:  cache,
+		layers: make(map[common.Hash]snapshot),
+	}
+	head, err := loadSnapshot(diskdb, triedb, cache, root)
+	if err != nil {
+		log.Warn("Failed to load snapshot, regenerating", "err", err)
+		snap.Rebuild(root)
+	} else {
+		for head != nil {
+			snap.layers[head.Root()] = head
+			head = head.Parent()
+		}
+	}
+	if !async {
+		snap.waitGeneration()
+	}
+	return snap, nil
+}
+
+// loadSnapshot loads the persisted disk layer and resumes its generation if it
+// was interrupted.
+func loadSnapshot(diskdb icedb.Database, triedb *trie.Database, cache int, root common.Hash) (snapshot, error) {
+	baseRoot := rawdb.ReadSnapshotRoot(diskdb)
+	if baseRoot == (common.Hash{}) {
+		return nil, errors.New("missing or corrupted snapshot")
+	}
+	if baseRoot != root {
+		return nil, fmt.Errorf("head doesn't match snapshot: have %#x, want %#x", baseRoot, root)
+	}
+	blob := rawdb.ReadSnapshotGenerator(diskdb)
+	if len(blob) == 0 {
+		return nil, errors.New("missing snapshot generator")
+	}
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(blob, &generator); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot generator: %v", err)
+	}
+	base := &diskLayer{
+		diskdb: diskdb,
+		triedb: triedb,
+		cache:  newCache(cache),
+		root:   baseRoot,
+	}
+	if !generator.Done {
+		base.genMarker = generator.Marker
+		if base.genMarker == nil {
+			base.genMarker = []byte{}
+		}
+		base.genPending = make(chan struct{})
+		base.genAbort = make(chan chan *generatorStats)
+
+		go base.generate(&generatorStats{
+			start:    time.Now(),
+			accounts: generator.Accounts,
+			slots:    generator.Slots,
+			storage:  common.StorageSize(generator.Storage),
+		})
+	}
+	return base, nil
+}
+
+// newCache creates the read cache of the disk layer.
+func newCache(cache int) *lru.Cache {
+	items := cache * 1024 * 1024 / approxEntrySize
+	if items <= 0 {
+		items = 1
+	}
+	c, _ := lru.New(items)
+	return c
+}
+
+// waitGeneration blocks until the disk layer is fully generated.
+func (t *Tree) waitGeneration() {
+	t.lock.RLock()
+	var pending chan struct{}
+	for _, layer := range t.layers {
+		if dl, ok := layer.(*diskLayer); ok {
+			pending = dl.genPending
+		}
+	}
+	t.lock.RUnlock()
+
+	if pending != nil {
+		<-pending
+	}
+}
+
+// Snapshot retrieves a snapshot belonging to the given block root, or nil if no
+// snapshot is maintained for that block.
+func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if snap, ok := t.layers[blockRoot]; ok {
+		return snap
+	}
+	return nil
+}
+
+// DiskRoot returns the root of the persisted disk layer.
+func (t *Tree) DiskRoot() common.Hash {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	for _, layer := range t.layers {
+		if dl, ok := layer.(*diskLayer); ok {
+			return dl.Root()
+		}
+	}
+	return common.Hash{}
+}
+
+// Update adds a new snapshot into the tree, if that can be linked to an existing
+// old parent. It is disallowed to insert a disk layer (the origin of all).
+func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) error {
+	// Reject noop updates to avoid self-loops in the snapshot tree. This is a
+	// special case that can only happen for blocks without state changes.
+	if blockRoot == parentRoot {
+		return errSnapshotCycle
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The same state may be committed again when a block is reprocessed
+	if _, ok := t.layers[blockRoot]; ok {
+		return nil
+	}
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	t.layers[blockRoot] = parent.Update(blockRoot, destructs, accounts, storage)
+	return nil
+}
+
+// Cap traverses downwards the snapshot tree from a head block hash until the
+// number of allowed layers are crossed. All layers beyond the permitted number
+// are flattened downwards.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	diff, ok := snap.(*diffLayer)
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] is disk layer", root)
+	}
+	// Flattening the entire stack into the disk layer is a special case
+	if layers == 0 {
+		base := diffToDisk(diff.flatten().(*diffLayer))
+		for _, layer := range t.layers {
+			if diff, ok := layer.(*diffLayer); ok {
+				atomic.StoreUint32(&diff.stale, 1)
+			}
+		}
+		t.layers = map[common.Hash]snapshot{base.root: base}
+		return nil
+	}
+	t.cap(diff, layers)
+
+	// Remove any layer that is stale or links into a stale layer
+	children := make(map[common.Hash][]common.Hash)
+	for root, snap := range t.layers {
+		if diff, ok := snap.(*diffLayer); ok {
+			parent := diff.Parent().Root()
+			children[parent] = append(children[parent], root)
+		}
+	}
+	var remove func(root common.Hash)
+	remove = func(root common.Hash) {
+		delete(t.layers, root)
+		for _, child := range children[root] {
+			remove(child)
+		}
+		delete(children, root)
+	}
+	for root, snap := range t.layers {
+		if snap.Stale() {
+			remove(root)
+		}
+	}
+	return nil
+}
+
+// cap traverses downwards the diff tree until the number of allowed layers are
+// crossed and persists the diff layer below it into the disk layer.
+//
+// Note, the caller must hold the write lock on the tree.
+func (t *Tree) cap(diff *diffLayer, layers int) {
+	// Dive until we run out of layers or reach the persistent database
+	for i := 0; i < layers-1; i++ {
+		parent, ok := diff.Parent().(*diffLayer)
+		if !ok {
+			return
+		}
+		diff = parent
+	}
+	bottom, ok := diff.Parent().(*diffLayer)
+	if !ok {
+		return
+	}
+	base := diffToDisk(bottom.flatten().(*diffLayer))
+
+	diff.lock.Lock()
+	diff.parent = base
+	diff.lock.Unlock()
+
+	t.layers[base.root] = base
+}
+
+// diffToDisk merges a bottom-most diff into the persistent disk layer underneath
+// it. The method will panic if called onto a non-bottom-most diff layer.
+func diffToDisk(bottom *diffLayer) *diskLayer {
+	var (
+		base  = bottom.Parent().(*diskLayer)
+		batch = base.diskdb.NewBatch()
+		stats *generatorStats
+	)
+	// If the disk layer is running a snapshot generator, abort it
+	if base.genAbort != nil {
+		abort := make(chan *generatorStats)
+		base.genAbort <- abort
+		stats = <-abort
+	}
+	// Start by temporarily deleting the current snapshot block marker. This
+	// ensures that in the case of a crash, the entire snapshot is invalidated.
+	rawdb.DeleteSnapshotRoot(batch)
+
+	// Mark the original base as stale as we're going to create a new wrapper
+	base.lock.Lock()
+	if base.stale {
+		panic("parent disk layer is stale") // we've committed into the same base from two children, boo
+	}
+	base.stale = true
+	marker := base.genMarker
+	base.lock.Unlock()
+
+	flush := func() {
+		if batch.ValueSize() > icedb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write snapshot", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	// Destroy all the destructed accounts from the database
+	for hash := range bottom.destructSet {
+		// Skip any account not covered yet by the snapshot
+		if marker != nil && bytes.Compare(hash[:], marker) > 0 {
+			continue
+		}
+		rawdb.DeleteAccountSnapshot(batch, hash)
+		base.cache.Remove(string(hash[:]))
+
+		it := rawdb.IterateStorageSnapshots(base.diskdb.(icedb.Iteratee), hash)
+		for it.Next() {
+			key := it.Key()
+			if len(key) != len(rawdb.SnapshotStoragePrefix)+2*common.HashLength {
+				continue
+			}
+			batch.Delete(common.CopyBytes(key))
+			base.cache.Remove(string(key[len(rawdb.SnapshotStoragePrefix):]))
+			flush()
+		}
+		it.Release()
+		flush()
+	}
+	// Push all updated accounts into the database
+	for hash, data := range bottom.accountData {
+		if marker != nil && bytes.Compare(hash[:], marker) > 0 {
+			continue
+		}
+		rawdb.WriteAccountSnapshot(batch, hash, data)
+		base.cache.Add(string(hash[:]), data)
+		flush()
+	}
+	// Push all the storage slots into the database
+	for accountHash, storage := range bottom.storageData {
+		if marker != nil && bytes.Compare(accountHash[:], marker) > 0 {
+			continue
+		}
+		for storageHash, data := range storage {
+			key := append(accountHash[:], storageHash[:]...)
+			if marker != nil && bytes.Compare(key, marker) > 0 {
+				continue
+			}
+			if len(data) > 0 {
+				rawdb.WriteStorageSnapshot(batch, accountHash, storageHash, data)
+				base.cache.Add(string(key), data)
+			} else {
+				rawdb.DeleteStorageSnapshot(batch, accountHash, storageHash)
+				base.cache.Add(string(key), nil)
+			}
+		}
+		flush()
+	}
+	// Update the snapshot block marker and write any remainder data
+	rawdb.WriteSnapshotRoot(batch, bottom.root)
+	journalProgress(batch, marker, stats)
+
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write leftover snapshot", "err", err)
+	}
+	log.Debug("Journalled disk layer", "root", bottom.root, "complete", marker == nil)
+
+	res := &diskLayer{
+		root:       bottom.root,
+		cache:      base.cache,
+		diskdb:     base.diskdb,
+		triedb:     base.triedb,
+		genMarker:  marker,
+		genPending: base.genPending,
+	}
+	// If snapshot generation hasn't finished yet, port over all the starts and
+	// continue where the previous round left off.
+	if marker != nil {
+		res.genAbort = make(chan chan *generatorStats)
+		go res.generate(stats)
+	}
+	return res
+}
+
+// Rebuild wipes all available snapshot data from the persistent database and
+// discard all caches and diff layers. Afterwards, it starts a new snapshot
+// generator with the given root hash.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Track whether there's a wipe currently running and keep it alive if so
+	for _, layer := range t.layers {
+		switch layer := layer.(type) {
+		case *diskLayer:
+			// If the base layer is generating, abort it and save
+			if layer.genAbort != nil {
+				abort := make(chan *generatorStats)
+				layer.genAbort <- abort
+				<-abort
+			}
+			layer.lock.Lock()
+			layer.stale = true
+			layer.lock.Unlock()
+
+		case *diffLayer:
+			atomic.StoreUint32(&layer.stale, 1)
+
+		default:
+			panic(fmt.Sprintf("unknown layer type: %T", layer))
+		}
+	}
+	log.Info("Rebuilding state snapshot", "root", root)
+	t.layers = map[common.Hash]snapshot{
+		root: generateSnapshot(t.diskdb, t.triedb, t.cache, root),
+	}
+}
+
+// Stop aborts the running snapshot generation, the progress is persisted to
+// be resumed at the next startup.
+func (t *Tree) Stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		dl, ok := layer.(*diskLayer)
+		if !ok || dl.genAbort == nil {
+			continue
+		}
+		abort := make(chan *generatorStats)
+		dl.genAbort <- abort
+		if stats := <-abort; stats != nil {
+			stats.Log("Paused state snapshot generation", dl.root, dl.genMarker)
+		}
+		dl.lock.Lock()
+		dl.genAbort = nil
+		dl.lock.Unlock()
+	}
+}
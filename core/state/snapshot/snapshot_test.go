@@ -0,0 +1,172 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/rlp"
+	"github.com/iceming123/go-ice/trie"
+)
+
+func hashKey(i byte) []byte {
+	return crypto.Keccak256([]byte{i})
+}
+
+// makeState commits a state with a few accounts, the last one has storage.
+func makeState(t *testing.T, triedb *trie.Database) (common.Hash, map[common.Hash][]byte) {
+	storeTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	for i := byte(1); i <= 3; i++ {
+		storeTrie.Update([]byte{i}, []byte{i, i})
+	}
+	storeRoot, err := storeTrie.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accTrie, _ := trie.NewSecure(common.Hash{}, triedb, 0)
+	accounts := make(map[common.Hash][]byte)
+	for i := byte(1); i <= 5; i++ {
+		acc := Account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: emptyRoot, CodeHash: crypto.Keccak256(nil)}
+		if i == 5 {
+			acc.Root = storeRoot
+		}
+		enc, _ := rlp.EncodeToBytes(acc)
+		accTrie.Update([]byte{i}, enc)
+		accounts[common.BytesToHash(hashKey(i))] = enc
+	}
+	root, err := accTrie.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	return root, accounts
+}
+
+func TestSnapshotGeneration(t *testing.T) {
+	var (
+		diskdb = icedb.NewMemDatabase()
+		triedb = trie.NewDatabase(diskdb)
+	)
+	root, accounts := makeState(t, triedb)
+
+	snaps, err := New(diskdb, triedb, 1, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := snaps.Snapshot(root)
+	if snap == nil {
+		t.Fatalf("snapshot of the root missing")
+	}
+	for hash, enc := range accounts {
+		if data, err := snap.Account(hash); err != nil || !bytes.Equal(data, enc) {
+			t.Fatalf("account %x mismatch: have %x, %v, want %x", hash, data, err, enc)
+		}
+	}
+	owner := common.BytesToHash(hashKey(5))
+	if data, err := snap.Storage(owner, common.BytesToHash(hashKey(2))); err != nil || !bytes.Equal(data, []byte{2, 2}) {
+		t.Fatalf("storage mismatch: have %x, %v", data, err)
+	}
+	if err := VerifyState(diskdb, triedb, root); err != nil {
+		t.Fatalf("failed to verify the generated snapshot: %v", err)
+	}
+	snaps.Stop()
+
+	// A snapshot reloaded at the same root doesn't regenerate anything
+	if snaps, err = New(diskdb, triedb, 1, root, false); err != nil {
+		t.Fatal(err)
+	}
+	if snaps.DiskRoot() != root {
+		t.Fatalf("disk root mismatch: have %x, want %x", snaps.DiskRoot(), root)
+	}
+}
+
+func TestSnapshotDiffLayers(t *testing.T) {
+	var (
+		diskdb = icedb.NewMemDatabase()
+		triedb = trie.NewDatabase(diskdb)
+	)
+	root, accounts := makeState(t, triedb)
+
+	snaps, err := New(diskdb, triedb, 1, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		changed = common.BytesToHash(hashKey(1))
+		removed = common.BytesToHash(hashKey(5))
+		slot    = common.BytesToHash(hashKey(2))
+		root1   = common.HexToHash("0x01")
+		root2   = common.HexToHash("0x02")
+	)
+	// The first layer changes an account, the second destructs the contract
+	if err := snaps.Update(root1, root, nil, map[common.Hash][]byte{changed: {0x01}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := snaps.Update(root2, root1, map[common.Hash]struct{}{removed: {}}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := snaps.Update(root2, root2, nil, nil, nil); err != errSnapshotCycle {
+		t.Fatalf("cycle not rejected: %v", err)
+	}
+	check := func(snap Snapshot) {
+		if data, _ := snap.Account(changed); !bytes.Equal(data, []byte{0x01}) {
+			t.Fatalf("changed account mismatch: %x", data)
+		}
+		if data, _ := snap.Account(removed); data != nil {
+			t.Fatalf("destructed account returned: %x", data)
+		}
+		if data, _ := snap.Storage(removed, slot); data != nil {
+			t.Fatalf("destructed storage returned: %x", data)
+		}
+	}
+	check(snaps.Snapshot(root2))
+
+	// The parent layer must still see the original data
+	if data, _ := snaps.Snapshot(root1).Account(removed); !bytes.Equal(data, accounts[removed]) {
+		t.Fatalf("parent account mismatch: %x", data)
+	}
+	// Persist the bottom layer only, the top stays in memory
+	if err := snaps.Cap(root2, 1); err != nil {
+		t.Fatal(err)
+	}
+	if snaps.DiskRoot() != root1 {
+		t.Fatalf("disk root mismatch: have %x, want %x", snaps.DiskRoot(), root1)
+	}
+	if snaps.Snapshot(root) != nil {
+		t.Fatalf("stale disk layer retained")
+	}
+	check(snaps.Snapshot(root2))
+
+	// Flatten everything into the disk
+	if err := snaps.Cap(root2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if snaps.DiskRoot() != root2 {
+		t.Fatalf("disk root mismatch: have %x, want %x", snaps.DiskRoot(), root2)
+	}
+	check(snaps.Snapshot(root2))
+	if _, err := snaps.Snapshot(root2).Account(changed); err != nil {
+		t.Fatal(err)
+	}
+}
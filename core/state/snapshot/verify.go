@@ -0,0 +1,181 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
+	"github.com/iceming123/go-ice/trie"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+// entryIterator walks the snapshot entries of a prefix, skipping the other
+// database items sharing the prefix byte (e.g. trie nodes).
+type entryIterator struct {
+	it     iterator.Iterator
+	prefix int
+	keylen int
+}
+
+func newEntryIterator(db icedb.Iteratee, prefix []byte, keylen int) *entryIterator {
+	return &entryIterator{
+		it:     db.NewIteratorWithPrefix(prefix),
+		prefix: len(prefix),
+		keylen: len(prefix) + keylen,
+	}
+}
+
+// next returns the key without the prefix and the value of the next entry.
+func (e *entryIterator) next() ([]byte, []byte, bool) {
+	for e.it.Next() {
+		if key := e.it.Key(); len(key) == e.keylen {
+			return common.CopyBytes(key[e.prefix:]), common.CopyBytes(e.it.Value()), true
+		}
+	}
+	return nil, nil, false
+}
+
+func (e *entryIterator) release() {
+	e.it.Release()
+}
+
+// VerifyState walks the account and storage tries of root along with the
+// persisted snapshot, and checks every trie leaf has the same snapshot entry
+// and there is no dangling entry in the snapshot.
+func VerifyState(diskdb icedb.Database, triedb *trie.Database, root common.Hash) error {
+	db, ok := diskdb.(icedb.Iteratee)
+	if !ok {
+		return errors.New("snapshot database is not iterable")
+	}
+	if have := rawdb.ReadSnapshotRoot(diskdb); have != root {
+		return fmt.Errorf("snapshot root mismatch: have %#x, want %#x", have, root)
+	}
+	var generator journalGenerator
+	if blob := rawdb.ReadSnapshotGenerator(diskdb); len(blob) > 0 {
+		if err := rlp.DecodeBytes(blob, &generator); err != nil {
+			return fmt.Errorf("failed to decode snapshot generator: %v", err)
+		}
+	}
+	if !generator.Done {
+		return errors.New("snapshot is not fully generated")
+	}
+	accTrie, err := trie.NewSecure(root, triedb, 0)
+	if err != nil {
+		return err
+	}
+	var (
+		start    = time.Now()
+		logged   = time.Now()
+		accounts uint64
+		slots    uint64
+		accIt    = trie.NewIterator(accTrie.NodeIterator(nil))
+		snapIt   = newEntryIterator(db, rawdb.SnapshotAccountPrefix, common.HashLength)
+	)
+	defer snapIt.release()
+
+	for accIt.Next() {
+		key, value, ok := snapIt.next()
+		if !ok || bytes.Compare(key, accIt.Key) > 0 {
+			return fmt.Errorf("account %x missing in snapshot", accIt.Key)
+		}
+		if !bytes.Equal(key, accIt.Key) {
+			return fmt.Errorf("dangling account %x in snapshot", key)
+		}
+		if !bytes.Equal(value, accIt.Value) {
+			return fmt.Errorf("account %x mismatch: have %x, want %x", key, value, accIt.Value)
+		}
+		accounts++
+
+		var acc Account
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			return fmt.Errorf("invalid account %x: %v", accIt.Key, err)
+		}
+		n, err := verifyStorage(db, triedb, common.BytesToHash(accIt.Key), acc.Root)
+		if err != nil {
+			return err
+		}
+		slots += n
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying state snapshot", "at", common.BytesToHash(accIt.Key), "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if accIt.Err != nil {
+		return accIt.Err
+	}
+	if key, _, ok := snapIt.next(); ok {
+		return fmt.Errorf("dangling account %x in snapshot", key)
+	}
+	// The storage of the accounts missing in the trie are not visited above,
+	// make sure the snapshot doesn't contain more slots than the tries.
+	var total uint64
+	storageIt := newEntryIterator(db, rawdb.SnapshotStoragePrefix, 2*common.HashLength)
+	defer storageIt.release()
+	for _, _, ok := storageIt.next(); ok; _, _, ok = storageIt.next() {
+		total++
+	}
+	if total != slots {
+		return fmt.Errorf("dangling storage in snapshot: have %d slots, want %d", total, slots)
+	}
+	log.Info("Verified state snapshot", "root", root, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// verifyStorage checks the storage snapshot of an account against its storage
+// trie and returns the number of the slots.
+func verifyStorage(db icedb.Iteratee, triedb *trie.Database, accountHash common.Hash, root common.Hash) (uint64, error) {
+	snapIt := newEntryIterator(db, append(rawdb.SnapshotStoragePrefix, accountHash[:]...), common.HashLength)
+	defer snapIt.release()
+
+	var slots uint64
+	if root != emptyRoot {
+		storeTrie, err := trie.NewSecure(root, triedb, 0)
+		if err != nil {
+			return 0, err
+		}
+		storeIt := trie.NewIterator(storeTrie.NodeIterator(nil))
+		for storeIt.Next() {
+			key, value, ok := snapIt.next()
+			if !ok || bytes.Compare(key, storeIt.Key) > 0 {
+				return 0, fmt.Errorf("slot %x of account %x missing in snapshot", storeIt.Key, accountHash)
+			}
+			if !bytes.Equal(key, storeIt.Key) {
+				return 0, fmt.Errorf("dangling slot %x of account %x in snapshot", key, accountHash)
+			}
+			if !bytes.Equal(value, storeIt.Value) {
+				return 0, fmt.Errorf("slot %x of account %x mismatch: have %x, want %x", key, accountHash, value, storeIt.Value)
+			}
+			slots++
+		}
+		if storeIt.Err != nil {
+			return 0, storeIt.Err
+		}
+	}
+	if key, _, ok := snapIt.next(); ok {
+		return 0, fmt.Errorf("dangling slot %x of account %x in snapshot", key, accountHash)
+	}
+	return slots, nil
+}
@@ -202,8 +202,8 @@ func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.
 	if cached {
 		return value
 	}
-	// Otherwise load the value from the database
-	enc, err := self.getTrie(db).TryGet(key[:])
+	// Otherwise load the value from the snapshot or the database
+	enc, err := self.getStorage(db, key)
 	if err != nil {
 		self.setError(err)
 		return common.Hash{}
@@ -225,13 +225,41 @@ func (self *stateObject) GetPOSState(db Database, key common.Hash) []byte {
 		return value
 	}
 	// Load from DB in case it is missing.
-	value, err := self.getTrie(db).TryGet(key[:])
+	value, err := self.getStorage(db, key)
 	if err == nil && len(value) != 0 {
 		self.originPOSStorage[key] = value
 	}
 	return value
 }
 
+// getStorage retrieves the raw storage trie value of the key, the snapshot is
+// consulted before the trie.
+func (self *stateObject) getStorage(db Database, key common.Hash) ([]byte, error) {
+	if snap := self.db.snap; snap != nil {
+		// The storage of a destructed account is gone, even if it's recreated
+		if _, destructed := self.db.snapDestructs[self.addrHash]; destructed {
+			return nil, nil
+		}
+		if enc, err := snap.Storage(self.addrHash, crypto.Keccak256Hash(key[:])); err == nil {
+			return enc, nil
+		}
+	}
+	return self.getTrie(db).TryGet(key[:])
+}
+
+// updateSnapStorage tracks the storage change in the snapshot changes of the block.
+func (self *stateObject) updateSnapStorage(key common.Hash, value []byte) {
+	if self.db.snap == nil {
+		return
+	}
+	storage := self.db.snapStorage[self.addrHash]
+	if storage == nil {
+		storage = make(map[common.Hash][]byte)
+		self.db.snapStorage[self.addrHash] = storage
+	}
+	storage[crypto.Keccak256Hash(key[:])] = value
+}
+
 // SetState updates a value in account storage.
 func (self *stateObject) SetState(db Database, key, value common.Hash) {
 	// If the new value is the same as old, don't set
@@ -282,19 +310,23 @@ func (self *stateObject) updateTrie(db Database) Trie {
 
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
+			self.updateSnapStorage(key, nil)
 			continue
 		}
 		// Encoding []byte cannot fail, ok to ignore the error.
 		v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
 		self.setError(tr.TryUpdate(key[:], v))
+		self.updateSnapStorage(key, v)
 	}
 	for key, value := range self.dirtyPOSStorage {
 		delete(self.dirtyPOSStorage, key)
 		if len(value) == 0 {
 			self.setError(tr.TryDelete(key[:]))
+			self.updateSnapStorage(key, nil)
 			continue
 		}
 		self.setError(tr.TryUpdate(key[:], value))
+		self.updateSnapStorage(key, value)
 	}
 	return tr
 }
@@ -31,6 +31,7 @@ import (
 	"gopkg.in/check.v1"
 
 	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/state/snapshot"
 	ethdb "github.com/iceming123/go-ice/icedb"
 )
 
@@ -119,6 +120,81 @@ func TestIntermediateLeaks(t *testing.T) {
 // TestCopy tests that copying a statedb object indeed makes the original and
 // the copy independent of each other. This test is a regression test against
 // https://github.com/iceming123/go-ice/pull/15549.
+// Tests that the state reads through the snapshot match the trie, and the
+// committed changes are pushed into the snapshot tree.
+func TestStateSnapshot(t *testing.T) {
+	var (
+		db     = ethdb.NewMemDatabase()
+		sdb    = NewDatabase(db)
+		addrs  = []common.Address{{0x01}, {0x02}, {0x03}}
+		key    = common.Hash{0x01}
+		posKey = common.Hash{0x02}
+		state  *StateDB
+		err    error
+		commit = func(s *StateDB) common.Hash {
+			root, err := s.Commit(true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sdb.TrieDB().Commit(root, false); err != nil {
+				t.Fatal(err)
+			}
+			return root
+		}
+	)
+	state, _ = New(common.Hash{}, sdb)
+	for i, addr := range addrs {
+		state.SetBalance(addr, big.NewInt(int64(i+1)))
+		state.SetState(addr, key, common.Hash{byte(i + 1)})
+	}
+	state.SetPOSState(addrs[0], posKey, []byte("pos"))
+	root := commit(state)
+
+	snaps, err := snapshot.New(db, sdb.TrieDB(), 1, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state, err = NewWithSnapshot(root, sdb, snaps); err != nil {
+		t.Fatal(err)
+	}
+	if state.snap == nil {
+		t.Fatalf("snapshot not attached")
+	}
+	if have := state.GetBalance(addrs[1]); have.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("balance mismatch: have %v, want 2", have)
+	}
+	if have := state.GetPOSState(addrs[0], posKey); string(have) != "pos" {
+		t.Fatalf("pos state mismatch: have %x", have)
+	}
+	state.SetBalance(addrs[0], big.NewInt(10))
+	state.SetState(addrs[1], key, common.Hash{})
+	state.Suicide(addrs[2])
+	root = commit(state)
+
+	if snaps.Snapshot(root) == nil {
+		t.Fatalf("snapshot of the committed state missing")
+	}
+	snapState, _ := NewWithSnapshot(root, sdb, snaps)
+	trieState, _ := New(root, sdb)
+	for _, addr := range addrs {
+		if snapState.Exist(addr) != trieState.Exist(addr) {
+			t.Fatalf("existence mismatch of %x", addr)
+		}
+		if have, want := snapState.GetBalance(addr), trieState.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Fatalf("balance mismatch of %x: have %v, want %v", addr, have, want)
+		}
+		if have, want := snapState.GetState(addr, key), trieState.GetState(addr, key); have != want {
+			t.Fatalf("storage mismatch of %x: have %x, want %x", addr, have, want)
+		}
+	}
+	if err := snaps.Cap(root, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := snapshot.VerifyState(db, sdb.TrieDB(), root); err != nil {
+		t.Fatalf("snapshot mismatch with the state: %v", err)
+	}
+}
+
 func TestCopy(t *testing.T) {
 	// Create a random state test to copy and modify "independently"
 	orig, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
@@ -443,3 +519,68 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that access list additions are journaled and undone by reverting to
+// a snapshot, and that copies carry an independent access list.
+func TestStateDBAccessList(t *testing.T) {
+	addr := common.HexToAddress
+	slot := common.HexToHash
+
+	state, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+
+	verifyAddrs := func(want ...string) {
+		t.Helper()
+		if have := len(state.accessList.addresses); have != len(want) {
+			t.Fatalf("address count mismatch: have %d, want %d", have, len(want))
+		}
+		for _, a := range want {
+			if !state.AddressInAccessList(addr(a)) {
+				t.Fatalf("address %s missing from access list", a)
+			}
+		}
+	}
+	verifySlots := func(a string, want ...string) {
+		t.Helper()
+		idx, ok := state.accessList.addresses[addr(a)]
+		if !ok {
+			t.Fatalf("address %s missing from access list", a)
+		}
+		var have int
+		if idx != -1 {
+			have = len(state.accessList.slots[idx])
+		}
+		if have != len(want) {
+			t.Fatalf("slot count mismatch for %s: have %d, want %d", a, have, len(want))
+		}
+		for _, s := range want {
+			if _, ok := state.SlotInAccessList(addr(a), slot(s)); !ok {
+				t.Fatalf("slot %s of %s missing from access list", s, a)
+			}
+		}
+	}
+	state.AddAddressToAccessList(addr("aa"))          // 1
+	state.AddSlotToAccessList(addr("bb"), slot("01")) // 2,3
+	state.AddSlotToAccessList(addr("bb"), slot("02")) // 4
+	verifyAddrs("aa", "bb")
+	verifySlots("bb", "01", "02")
+
+	snapshot := state.Snapshot()
+	state.AddAddressToAccessList(addr("cc"))          // 5
+	state.AddSlotToAccessList(addr("aa"), slot("01")) // 6
+	state.AddSlotToAccessList(addr("bb"), slot("01")) // no-op
+	verifyAddrs("aa", "bb", "cc")
+	verifySlots("aa", "01")
+
+	cpy := state.Copy()
+	state.RevertToSnapshot(snapshot)
+	verifyAddrs("aa", "bb")
+	verifySlots("aa")
+	verifySlots("bb", "01", "02")
+
+	if !cpy.AddressInAccessList(addr("cc")) {
+		t.Fatalf("copied access list lost address")
+	}
+	if _, ok := cpy.SlotInAccessList(addr("aa"), slot("01")); !ok {
+		t.Fatalf("copied access list lost slot")
+	}
+}
@@ -17,7 +17,9 @@
 package core
 
 import (
-	//"github.com/iceming123/go-ice/common"
+	"fmt"
+
+	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/crypto"
 	"github.com/iceming123/go-ice/metrics"
 	"math"
@@ -102,14 +104,15 @@ func (fp *StateProcessor) Process(block *types.Block, statedb *state.StateDB,
 // indicating the block was invalid.
 func ApplyTransaction(config *params.ChainConfig, bc ChainContext, gp *GasPool,
 	statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, feeAmount *big.Int, cfg vm.Config) (*types.Receipt, error) {
+	if tx.Type() != types.LegacyTxType && !config.IsTIP11(header.Number) {
+		return nil, types.ErrTxTypeNotSupported
+	}
 	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number))
 	if err != nil {
 		return nil, err
 	}
-	if header.Number.Cmp(big.NewInt(6638000)) > 0 {
-		if err := types.ForbidAddress(msg.From()); err != nil {
-			return nil, err
-		}
+	if err := forbidAddress(config, statedb, msg.From(), header.Number); err != nil {
+		return nil, err
 	}
 
 	// Create a new context to be used in the EVM environment
@@ -163,15 +166,13 @@ func ReadTransaction(config *params.ChainConfig, bc ChainContext,
 
 	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number))
 
-	msgCopy := types.NewMessage(msg.From(), msg.To(), msg.Payment(), 0, msg.Value(), msg.Fee(), msg.Gas(), msg.GasPrice(), msg.Data(), false)
+	msgCopy := types.NewMessage(msg.From(), msg.To(), msg.Payment(), 0, msg.Value(), msg.Fee(), msg.Gas(), msg.GasPrice(), msg.Data(), msg.AccessList(), false)
 
 	if err != nil {
 		return nil, 0, err
 	}
-	if header.Number.Cmp(big.NewInt(6638000)) > 0 {
-		if err := types.ForbidAddress(msgCopy.From()); err != nil {
-			return nil, 0, err
-		}
+	if err := forbidAddress(config, statedb, msgCopy.From(), header.Number); err != nil {
+		return nil, 0, err
 	}
 
 	// Create a new context to be used in the EVM environment
@@ -188,3 +189,20 @@ func ReadTransaction(config *params.ChainConfig, bc ChainContext,
 
 	return result.ReturnData, result.UsedGas, err
 }
+
+// forbidAddress returns an error if the account can't send transactions in the
+// block, the governance overrides the built-in whitelist since TIP14.
+func forbidAddress(config *params.ChainConfig, statedb vm.StateDB, addr common.Address, number *big.Int) error {
+	if config.IsTIP14(number) {
+		if forbidden, ok := vm.GovernedForbidden(statedb, addr, number.Uint64()); ok {
+			if forbidden {
+				return fmt.Errorf("addr error:%s %v", addr.String(), types.ErrForbidAddress)
+			}
+			return nil
+		}
+	}
+	if number.Cmp(big.NewInt(6638000)) > 0 {
+		return types.ForbidAddress(addr)
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/params"
 )
@@ -75,6 +76,7 @@ type Message interface {
 	Nonce() uint64
 	CheckNonce() bool
 	Data() []byte
+	AccessList() types.AccessList
 }
 
 // ExecutionResult includes all output after executing given evm
@@ -112,8 +114,9 @@ func (result *ExecutionResult) Revert() []byte {
 	return common.CopyBytes(result.ReturnData)
 }
 
-// IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error) {
+// IntrinsicGas computes the 'intrinsic gas' for a message with the given data
+// and access list.
+func IntrinsicGas(data []byte, accessList types.AccessList, contractCreation, homestead bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation && homestead {
@@ -142,6 +145,10 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error)
 		}
 		gas += z * params.TxDataZeroGas
 	}
+	if accessList != nil {
+		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
+		gas += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+	}
 	return gas, nil
 }
 
@@ -245,13 +252,18 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	contractCreation := msg.To() == nil
 
 	// Pay intrinsic gas
-	gas, err := IntrinsicGas(st.data, contractCreation, true)
+	gas, err := IntrinsicGas(st.data, msg.AccessList(), contractCreation, true)
 	if err != nil {
 		return nil, err
 	}
 	if err = st.useGas(gas); err != nil {
 		return nil, ErrIntrinsicGas
 	}
+	// Warm up the sender, the recipient, the precompiles and the optional
+	// access list of the message
+	if rules := st.evm.ChainConfig().Rules(st.evm.BlockNumber); rules.IsTIP11 {
+		st.state.PrepareAccessList(msg.From(), msg.To(), vm.ActivePrecompiles(rules), msg.AccessList())
+	}
 
 	var (
 		ret   []byte
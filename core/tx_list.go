@@ -533,3 +533,70 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 	}
 	return drop
 }
+
+// payerList is the set of pooled transactions sponsored by a single payer,
+// along with the cumulative gas cost the payer is liable for. The payer is
+// recovered once when a transaction is added and kept here.
+type payerList struct {
+	payer common.Address
+	txs   map[common.Hash]*types.Transaction
+	cost  *big.Int
+}
+
+// newPayerList creates a new, empty list of the transactions sponsored by payer.
+func newPayerList(payer common.Address) *payerList {
+	return &payerList{
+		payer: payer,
+		txs:   make(map[common.Hash]*types.Transaction),
+		cost:  new(big.Int),
+	}
+}
+
+// Add inserts a sponsored transaction and charges its gas cost to the payer.
+func (l *payerList) Add(tx *types.Transaction) {
+	hash := tx.Hash()
+	if _, ok := l.txs[hash]; ok {
+		return
+	}
+	l.txs[hash] = tx
+	l.cost.Add(l.cost, tx.GasCost())
+}
+
+// Remove deletes a sponsored transaction and refunds its gas cost, returning
+// whether the transaction was found.
+func (l *payerList) Remove(hash common.Hash) bool {
+	tx, ok := l.txs[hash]
+	if !ok {
+		return false
+	}
+	delete(l.txs, hash)
+	l.cost.Sub(l.cost, tx.GasCost())
+	return true
+}
+
+// Len returns the number of transactions sponsored by the payer.
+func (l *payerList) Len() int {
+	return len(l.txs)
+}
+
+// Cost returns the cumulative gas cost of the sponsored transactions.
+func (l *payerList) Cost() *big.Int {
+	return new(big.Int).Set(l.cost)
+}
+
+// Flatten returns the sponsored transactions in eviction order: highest nonce
+// first so that evicting them never opens a nonce gap for their senders, and
+// cheapest gas price first among equal nonces.
+func (l *payerList) Flatten() types.Transactions {
+	txs := make(types.Transactions, 0, len(l.txs))
+	for _, tx := range l.txs {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Nonce() != txs[j].Nonce() {
+			return txs[i].Nonce() > txs[j].Nonce()
+		}
+		return txs[i].GasPrice().Cmp(txs[j].GasPrice()) < 0
+	})
+	return txs
+}
@@ -73,6 +73,10 @@ var (
 	//is higher than the balance of the payer's account.
 	ErrInsufficientFundsForPayer = errors.New("insufficient funds for gas * price for payer")
 
+	// ErrPayerSlots is returned if the payer of a transaction already sponsors
+	// the maximum number of transactions permitted in the pool.
+	ErrPayerSlots = errors.New("payer sponsors too many transactions")
+
 	//ErrInsufficientFundsForSender is returned if the amount of executing a transaction
 	//is higher than the balance of the user's account.
 	ErrInsufficientFundsForSender = errors.New("insufficient funds for value for sender")
@@ -160,6 +164,7 @@ type TxPoolConfig struct {
 	GlobalSlots  uint64 // Maximum number of executable transaction slots for all accounts
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
+	PayerSlots   uint64 // Maximum number of sponsored transaction slots permitted per payer
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 }
@@ -177,6 +182,7 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalSlots:  4096 * 5,
 	AccountQueue: 64 * 5,
 	GlobalQueue:  1024 * 5,
+	PayerSlots:   64 * 5,
 
 	Lifetime: 3 * time.Hour,
 }
@@ -213,6 +219,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool global queue", "provided", conf.GlobalQueue, "updated", DefaultTxPoolConfig.GlobalQueue)
 		conf.GlobalQueue = DefaultTxPoolConfig.GlobalQueue
 	}
+	if conf.PayerSlots < 1 {
+		log.Warn("Sanitizing invalid txpool payer slots", "provided", conf.PayerSlots, "updated", DefaultTxPoolConfig.PayerSlots)
+		conf.PayerSlots = DefaultTxPoolConfig.PayerSlots
+	}
 	if conf.Lifetime < 1 {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
@@ -242,6 +252,7 @@ type TxPool struct {
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
+	tip11         bool                // Fork indicator whether typed access list transactions are accepted
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
@@ -273,11 +284,11 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		pending:     make(map[common.Address]*txList),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
-		all:         newTxLookup(),
 		chainHeadCh: make(chan types.FastChainHeadEvent, chainHeadChanSize),
 		newTxsCh:    make(chan []*types.Transaction, txChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
+	pool.all = newTxLookup(pool.signer)
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
@@ -474,6 +485,10 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	//pool.currentMaxGas = newHead.GasLimit
 	pool.currentMaxGas = pool.chain.CurrentBlock().Header().GasLimit
 
+	// Update the fork indicators for the next block
+	next := new(big.Int).Add(pool.chain.CurrentBlock().Number(), big.NewInt(1))
+	pool.tip11 = pool.chainconfig.IsTIP11(next)
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
@@ -484,6 +499,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// have been invalidated because of another transaction (e.g.
 	// higher gas price)
 	pool.demoteUnexecutables()
+	pool.demoteUnaffordable()
 
 	// Update all accounts to the latest known pending nonce
 	for addr, list := range pool.pending {
@@ -585,6 +601,15 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// Sponsored retrieves all the pooled transactions paid for by an account other
+// than their sender, grouped by payer and sorted in eviction order.
+func (pool *TxPool) Sponsored() map[common.Address]types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.all.Payers()
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -618,6 +643,10 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+	// Accept only legacy transactions until the access lists are enabled
+	if !pool.tip11 && tx.Type() != types.LegacyTxType {
+		return types.ErrTxTypeNotSupported
+	}
 	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
 	if tx.Size() > 32*1024 {
 		return ErrOversizedData
@@ -643,10 +672,8 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		//return fmt.Errorf("%v err is:%v", ErrInvalidSender, err)
 	}
 
-	if pool.chain.CurrentBlock().Number().Cmp(big.NewInt(6638000)) > 0 {
-		if err := types.ForbidAddress(from); err != nil {
-			return err
-		}
+	if err := forbidAddress(pool.chainconfig, pool.currentState, from, pool.chain.CurrentBlock().Number()); err != nil {
+		return err
 	}
 
 	// Make sure the transaction is psigned properly
@@ -670,10 +697,8 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	// Transactor should have enough funds to cover the costs
 	// cost == V + GP * GL
 	if payer != params.EmptyAddress && payer != from {
-		if pool.currentState.GetValidBalance(payer).Cmp(tx.GasCost()) < 0 {
-			log.Error("insufficientFundsForPayer", "balance", pool.currentState.GetValidBalance(payer), "gasCost", tx.GasCost())
-			return ErrInsufficientFundsForPayer
-			//return fmt.Errorf("%v payer balance:%d;tx.Cost():%d", ErrInsufficientFundsForPayer, pool.currentState.GetBalance(payer), tx.Cost())
+		if err := pool.validatePayer(from, payer, tx, local); err != nil {
+			return err
 		}
 		if pool.currentState.GetValidBalance(from).Cmp(tx.AmountCost()) < 0 {
 			return ErrInsufficientFundsForSender
@@ -686,7 +711,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 			//return fmt.Errorf("%v your balance:%d;tx.Cost():%d", ErrInsufficientFunds, pool.currentState.GetBalance(from), tx.Cost())
 		}
 	}
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, true)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true)
 	if err != nil {
 		return err
 	}
@@ -697,6 +722,34 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// validatePayer checks whether a sponsored transaction fits into the slots and
+// the balance of its payer on top of everything the payer already sponsors in
+// the pool. A transaction replacing one sponsored by the same payer frees up
+// the slot and the gas cost of the replaced one.
+func (pool *TxPool) validatePayer(from, payer common.Address, tx *types.Transaction, local bool) error {
+	count, cost := pool.all.Sponsored(payer)
+	for _, list := range []*txList{pool.pending[from], pool.queue[from]} {
+		if list == nil {
+			continue
+		}
+		if old := list.txs.Get(tx.Nonce()); old != nil {
+			if oldPayer, ok := pool.all.Payer(old.Hash()); !ok || oldPayer != payer {
+				continue
+			}
+			count--
+			cost.Sub(cost, old.GasCost())
+		}
+	}
+	if !local && uint64(count) >= pool.config.PayerSlots {
+		return ErrPayerSlots
+	}
+	if balance := pool.currentState.GetValidBalance(payer); balance.Cmp(cost.Add(cost, tx.GasCost())) < 0 {
+		log.Trace("Insufficient funds for payer", "payer", payer, "balance", balance, "cost", cost)
+		return ErrInsufficientFundsForPayer
+	}
+	return nil
+}
+
 // add validates a transaction and inserts it into the non-executable queue for
 // later pending promotion and execution. If the transaction is a replacement for
 // an already pending or queued one, it overwrites the previous and returns this
@@ -1257,6 +1310,25 @@ func (pool *TxPool) demoteUnexecutables() {
 	}
 }
 
+// demoteUnaffordable drops sponsored transactions from every payer whose balance
+// no longer covers the cumulative gas cost of all the transactions it sponsors,
+// latest nonces first, until the remaining ones are affordable again.
+func (pool *TxPool) demoteUnaffordable() {
+	for payer, txs := range pool.all.Payers() {
+		balance := pool.currentState.GetValidBalance(payer)
+		_, cost := pool.all.Sponsored(payer)
+		for _, tx := range txs {
+			if cost.Cmp(balance) <= 0 {
+				break
+			}
+			log.Trace("Removed unaffordable sponsored transaction", "hash", tx.Hash(), "payer", payer)
+			pool.removeTx(tx.Hash(), true)
+			cost.Sub(cost, tx.GasCost())
+			pendingNofundsCounter.Inc(1)
+		}
+	}
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
@@ -1315,14 +1387,20 @@ func (as *accountSet) add(addr common.Address) {
 // peeking into the pool in TxPool.Get without having to acquire the widely scoped
 // TxPool.mu mutex.
 type txLookup struct {
-	all  map[common.Hash]*types.Transaction
-	lock sync.RWMutex
+	all       map[common.Hash]*types.Transaction
+	payers    map[common.Address]*payerList // Sponsored transactions grouped by payer
+	sponsored map[common.Hash]*payerList    // Payer list of each sponsored transaction
+	signer    types.Signer
+	lock      sync.RWMutex
 }
 
 // newTxLookup returns a new txLookup structure.
-func newTxLookup() *txLookup {
+func newTxLookup(signer types.Signer) *txLookup {
 	return &txLookup{
-		all: make(map[common.Hash]*types.Transaction),
+		all:       make(map[common.Hash]*types.Transaction),
+		payers:    make(map[common.Address]*payerList),
+		sponsored: make(map[common.Hash]*payerList),
+		signer:    signer,
 	}
 }
 
@@ -1360,6 +1438,13 @@ func (t *txLookup) Add(tx *types.Transaction) {
 	defer t.lock.Unlock()
 
 	t.all[tx.Hash()] = tx
+	if payer, ok := t.sponsor(tx); ok {
+		if t.payers[payer] == nil {
+			t.payers[payer] = newPayerList(payer)
+		}
+		t.payers[payer].Add(tx)
+		t.sponsored[tx.Hash()] = t.payers[payer]
+	}
 }
 
 // Remove removes a transaction from the lookup.
@@ -1367,5 +1452,64 @@ func (t *txLookup) Remove(hash common.Hash) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	if list := t.sponsored[hash]; list != nil {
+		if list.Remove(hash) && list.Len() == 0 {
+			delete(t.payers, list.payer)
+		}
+		delete(t.sponsored, hash)
+	}
 	delete(t.all, hash)
 }
+
+// Sponsored returns the number of transactions sponsored by the given payer and
+// their cumulative gas cost.
+func (t *txLookup) Sponsored(payer common.Address) (int, *big.Int) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if list := t.payers[payer]; list != nil {
+		return list.Len(), list.Cost()
+	}
+	return 0, new(big.Int)
+}
+
+// Payer returns the payer of a sponsored transaction as it was recovered when
+// the transaction was added.
+func (t *txLookup) Payer(hash common.Hash) (common.Address, bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if list := t.sponsored[hash]; list != nil {
+		return list.payer, true
+	}
+	return common.Address{}, false
+}
+
+// Payers returns all the sponsored transactions grouped by payer and sorted in
+// eviction order.
+func (t *txLookup) Payers() map[common.Address]types.Transactions {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	payers := make(map[common.Address]types.Transactions, len(t.payers))
+	for payer, list := range t.payers {
+		payers[payer] = list.Flatten()
+	}
+	return payers
+}
+
+// sponsor returns the payer of a transaction if its gas is paid for by an
+// account other than its sender.
+func (t *txLookup) sponsor(tx *types.Transaction) (common.Address, bool) {
+	if tx.Payer() == nil {
+		return common.Address{}, false
+	}
+	payer, err := types.Payer(t.signer, tx)
+	if err != nil || payer == params.EmptyAddress {
+		return common.Address{}, false
+	}
+	if from, err := types.Sender(t.signer, tx); err != nil || from == payer {
+		return common.Address{}, false
+	}
+	return payer, true
+}
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -83,6 +84,27 @@ func pricedTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ec
 	return tx
 }
 
+func sponsoredTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ecdsa.PrivateKey, payer *ecdsa.PrivateKey) *types.Transaction {
+	rawTx := types.NewTransaction_Payment(nonce, common.Address{}, big.NewInt(100), big.NewInt(0), gaslimit, gasprice, nil, crypto.PubkeyToAddress(payer.PublicKey))
+	signer := types.NewTIP1Signer(params.TestChainConfig.ChainID)
+	tx, _ := types.SignTx(rawTx, signer, key)
+	tx, _ = types.SignTx_Payment(tx, signer, payer)
+	return tx
+}
+
+func accessListTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ecdsa.PrivateKey, payer *ecdsa.PrivateKey, accesses types.AccessList) *types.Transaction {
+	to := common.Address{}
+	signer := types.NewTIP1Signer(params.TestChainConfig.ChainID)
+	if payer == nil {
+		tx, _ := types.SignTx(types.NewAccessListTransaction(params.TestChainConfig.ChainID, nonce, &to, big.NewInt(100), gaslimit, gasprice, nil, accesses), signer, key)
+		return tx
+	}
+	rawTx := types.NewAccessListTransaction_Payment(params.TestChainConfig.ChainID, nonce, &to, big.NewInt(100), big.NewInt(0), gaslimit, gasprice, nil, accesses, crypto.PubkeyToAddress(payer.PublicKey))
+	tx, _ := types.SignTx(rawTx, signer, key)
+	tx, _ = types.SignTx_Payment(tx, signer, payer)
+	return tx
+}
+
 func setupTxPool() (*TxPool, *ecdsa.PrivateKey) {
 	statedb, _ := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
 	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
@@ -319,6 +341,173 @@ func TestTransactionQueue(t *testing.T) {
 	}
 }
 
+// Tests that the cumulative gas cost of the transactions sponsored by a payer
+// is checked against its balance, both on admission and after a reset.
+func TestTransactionPayerFunding(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	payerKey, _ := crypto.GenerateKey()
+	payer := crypto.PubkeyToAddress(payerKey.PublicKey)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	price := new(big.Int).SetUint64(defaultGasPrice)
+	cost := new(big.Int).Mul(big.NewInt(100000), price)
+	pool.currentState.AddBalance(from, big.NewInt(1000000))
+	pool.currentState.AddBalance(payer, new(big.Int).Div(new(big.Int).Mul(cost, big.NewInt(7)), big.NewInt(2)))
+
+	for i := uint64(0); i < 3; i++ {
+		if err := pool.AddRemote(sponsoredTransaction(i, 100000, price, key, payerKey)); err != nil {
+			t.Fatalf("tx %d: failed to add sponsored transaction: %v", i, err)
+		}
+	}
+	if err := pool.AddRemote(sponsoredTransaction(3, 100000, price, key, payerKey)); err != ErrInsufficientFundsForPayer {
+		t.Fatalf("overspending payer error mismatch: have %v, want %v", err, ErrInsufficientFundsForPayer)
+	}
+	if count, spent := pool.all.Sponsored(payer); count != 3 || spent.Cmp(new(big.Int).Mul(cost, big.NewInt(3))) != 0 {
+		t.Fatalf("payer accounting mismatch: have %d txs costing %v, want 3 costing %v", count, spent, new(big.Int).Mul(cost, big.NewInt(3)))
+	}
+	// Replacements only charge the difference to the payer
+	if err := pool.AddRemote(sponsoredTransaction(2, 100000, new(big.Int).Div(new(big.Int).Mul(price, big.NewInt(11)), big.NewInt(10)), key, payerKey)); err != nil {
+		t.Fatalf("failed to replace sponsored transaction: %v", err)
+	}
+	if count, _ := pool.all.Sponsored(payer); count != 3 {
+		t.Fatalf("sponsored transaction count mismatch after replacement: have %d, want 3", count)
+	}
+	// Drain the payer and check that the latest transactions are evicted
+	pool.currentState.SetBalance(payer, new(big.Int).Add(cost, big.NewInt(1)))
+	pool.lockedReset(nil, nil)
+
+	if count, spent := pool.all.Sponsored(payer); count != 1 || spent.Cmp(cost) != 0 {
+		t.Fatalf("payer accounting mismatch after eviction: have %d txs costing %v, want 1 costing %v", count, spent, cost)
+	}
+	if pending, queued := pool.Stats(); pending != 1 || queued != 0 {
+		t.Fatalf("pool stats mismatch after eviction: have %d pending %d queued, want 1 pending 0 queued", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions can only claim a limited number of slots of
+// a single payer, while local ones are exempt.
+func TestTransactionPayerSlots(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.PayerSlots = 2
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	price := new(big.Int).SetUint64(defaultGasPrice)
+	payerKey, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(payerKey.PublicKey), new(big.Int).SetUint64(params.Ether))
+
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	for i := 0; i < 2; i++ {
+		if err := pool.AddRemote(sponsoredTransaction(0, 100000, price, keys[i], payerKey)); err != nil {
+			t.Fatalf("tx %d: failed to add sponsored transaction: %v", i, err)
+		}
+	}
+	if err := pool.AddRemote(sponsoredTransaction(0, 100000, price, keys[2], payerKey)); err != ErrPayerSlots {
+		t.Fatalf("payer slot error mismatch: have %v, want %v", err, ErrPayerSlots)
+	}
+	if err := pool.AddLocal(sponsoredTransaction(0, 100000, price, keys[3], payerKey)); err != nil {
+		t.Fatalf("failed to add local sponsored transaction: %v", err)
+	}
+	if sponsored := pool.Sponsored()[crypto.PubkeyToAddress(payerKey.PublicKey)]; len(sponsored) != 3 {
+		t.Fatalf("sponsored transaction count mismatch: have %d, want 3", len(sponsored))
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that typed access list transactions are only accepted once TIP11 is
+// active, that their access list is charged as intrinsic gas and that they
+// survive the canonical encoding with both sender and payer signatures.
+func TestTransactionAccessList(t *testing.T) {
+	t.Parallel()
+
+	price := new(big.Int).SetUint64(defaultGasPrice)
+	key, _ := crypto.GenerateKey()
+	payerKey, _ := crypto.GenerateKey()
+	accesses := types.AccessList{{Address: common.HexToAddress("0xaa"), StorageKeys: []common.Hash{{0x01}}}}
+
+	// Typed transactions are rejected before the fork
+	pool, _ := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).SetUint64(params.Ether))
+	if err := pool.AddRemote(accessListTransaction(0, 100000, price, key, nil, accesses)); err != types.ErrTxTypeNotSupported {
+		t.Fatalf("pre-fork error mismatch: have %v, want %v", err, types.ErrTxTypeNotSupported)
+	}
+	// And accepted afterwards
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.TIP11 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	pool = NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).SetUint64(params.Ether))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(payerKey.PublicKey), new(big.Int).SetUint64(params.Ether))
+
+	intrinsic := params.TxGas + params.TxAccessListAddressGas + params.TxAccessListStorageKeyGas
+	if err := pool.AddRemote(accessListTransaction(0, intrinsic-1, price, key, nil, accesses)); err != ErrIntrinsicGas {
+		t.Fatalf("intrinsic gas error mismatch: have %v, want %v", err, ErrIntrinsicGas)
+	}
+	if err := pool.AddRemote(accessListTransaction(0, intrinsic, price, key, nil, accesses)); err != nil {
+		t.Fatalf("failed to add access list transaction: %v", err)
+	}
+	tx := accessListTransaction(1, intrinsic, price, key, payerKey, accesses)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add sponsored access list transaction: %v", err)
+	}
+	if sponsored := pool.Sponsored()[crypto.PubkeyToAddress(payerKey.PublicKey)]; len(sponsored) != 1 {
+		t.Fatalf("sponsored transaction count mismatch: have %d, want 1", len(sponsored))
+	}
+	// Round trip the sponsored transaction and recover both signers
+	blob, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	if blob[0] != types.AccessListTxType {
+		t.Fatalf("envelope type mismatch: have %d, want %d", blob[0], types.AccessListTxType)
+	}
+	dec := new(types.Transaction)
+	if err := dec.UnmarshalBinary(blob); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Fatalf("hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	signer := types.NewTIP1Signer(params.TestChainConfig.ChainID)
+	if from, err := types.Sender(signer, dec); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("sender mismatch: have %x (%v), want %x", from, err, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	if payer, err := types.Payer(signer, dec); err != nil || payer != crypto.PubkeyToAddress(payerKey.PublicKey) {
+		t.Fatalf("payer mismatch: have %x (%v), want %x", payer, err, crypto.PubkeyToAddress(payerKey.PublicKey))
+	}
+	if !reflect.DeepEqual(dec.AccessList(), accesses) {
+		t.Fatalf("access list mismatch: have %v, want %v", dec.AccessList(), accesses)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestTransactionNegativeValue(t *testing.T) {
 	t.Parallel()
 
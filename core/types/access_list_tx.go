@@ -0,0 +1,234 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/rlp"
+	"golang.org/x/crypto/sha3"
+)
+
+// Transaction types.
+const (
+	LegacyTxType     = 0x00 // Untyped transaction of the original RLP list layout
+	AccessListTxType = 0x01 // EIP-2930 transaction carrying an access list
+)
+
+var (
+	// ErrTxTypeNotSupported is returned if a transaction is not supported in
+	// the current network configuration.
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+
+	errEmptyTypedTx = errors.New("empty typed transaction bytes")
+)
+
+// AccessList is an EIP-2930 access list.
+type AccessList []AccessTuple
+
+// AccessTuple is the element type of an access list.
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// StorageKeys returns the total number of storage keys in the access list.
+func (al AccessList) StorageKeys() int {
+	sum := 0
+	for _, tuple := range al {
+		sum += len(tuple.StorageKeys)
+	}
+	return sum
+}
+
+// copy returns a deep copy of the access list.
+func (al AccessList) copy() AccessList {
+	if al == nil {
+		return nil
+	}
+	cpy := make(AccessList, len(al))
+	for i, tuple := range al {
+		cpy[i] = AccessTuple{Address: tuple.Address, StorageKeys: append([]common.Hash{}, tuple.StorageKeys...)}
+	}
+	return cpy
+}
+
+// accessListTxdata is the payload of an EIP-2930 transaction envelope. Unlike
+// legacy transactions the chain id is encoded explicitly and V only holds the
+// signature parity. The payer extension follows the access list.
+type accessListTxdata struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"` // nil means contract creation
+	Amount       *big.Int
+	Payload      []byte
+	AccessList   AccessList
+	Payer        *common.Address `rlp:"nil"`
+	Fee          *big.Int        `rlp:"nil"`
+
+	// Signature values
+	V, R, S *big.Int
+
+	// Payer signature values
+	PV *big.Int `rlp:"nil"`
+	PR *big.Int `rlp:"nil"`
+	PS *big.Int `rlp:"nil"`
+}
+
+// NewAccessListTransaction creates an unsigned EIP-2930 transaction. A nil
+// recipient creates a contract.
+func NewAccessListTransaction(chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList) *Transaction {
+	return NewAccessListTransaction_Payment(chainID, nonce, to, amount, nil, gasLimit, gasPrice, data, accessList, common.Address{})
+}
+
+// NewAccessListTransaction_Payment creates an unsigned EIP-2930 transaction
+// whose gas is paid for by payer.
+func NewAccessListTransaction_Payment(chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, fee *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList, payer common.Address) *Transaction {
+	var tx *Transaction
+	if payer == (common.Address{}) {
+		tx = newTransaction(nonce, to, nil, amount, fee, gasLimit, gasPrice, data)
+	} else {
+		tx = newTransaction(nonce, to, &payer, amount, fee, gasLimit, gasPrice, data)
+	}
+	tx.data.Type = AccessListTxType
+	tx.data.ChainID = new(big.Int)
+	if chainID != nil {
+		tx.data.ChainID.Set(chainID)
+	}
+	tx.data.AccessList = accessList.copy()
+	if tx.data.AccessList == nil {
+		tx.data.AccessList = AccessList{}
+	}
+	return tx
+}
+
+// Type returns the transaction type.
+func (tx *Transaction) Type() uint8 { return tx.data.Type }
+
+// AccessList returns the access list of the transaction, nil for legacy ones.
+func (tx *Transaction) AccessList() AccessList { return tx.data.AccessList.copy() }
+
+// MarshalBinary returns the canonical encoding of the transaction: the RLP list
+// for legacy transactions and the EIP-2718 envelope for typed ones.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	if tx.data.Type == LegacyTxType {
+		return rlp.EncodeToBytes(&tx.data)
+	}
+	var buf bytes.Buffer
+	err := tx.encodeTyped(&buf)
+	return buf.Bytes(), err
+}
+
+// UnmarshalBinary decodes the canonical encoding of a transaction, accepting
+// both legacy and typed transactions.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) > 0 && b[0] > 0x7f {
+		// It's a legacy transaction
+		var data txdata
+		if err := rlp.DecodeBytes(b, &data); err != nil {
+			return err
+		}
+		*tx = Transaction{data: data}
+		tx.size.Store(common.StorageSize(len(b)))
+		return nil
+	}
+	var data txdata
+	if err := decodeTyped(b, &data); err != nil {
+		return err
+	}
+	*tx = Transaction{data: data}
+	tx.size.Store(common.StorageSize(len(b)))
+	return nil
+}
+
+// encodeTyped writes the EIP-2718 envelope of a typed transaction.
+func (tx *Transaction) encodeTyped(w *bytes.Buffer) error {
+	if tx.data.Type != AccessListTxType {
+		return ErrTxTypeNotSupported
+	}
+	w.WriteByte(tx.data.Type)
+	return rlp.Encode(w, &accessListTxdata{
+		ChainID:      tx.data.ChainID,
+		AccountNonce: tx.data.AccountNonce,
+		Price:        tx.data.Price,
+		GasLimit:     tx.data.GasLimit,
+		Recipient:    tx.data.Recipient,
+		Amount:       tx.data.Amount,
+		Payload:      tx.data.Payload,
+		AccessList:   tx.data.AccessList,
+		Payer:        tx.data.Payer,
+		Fee:          tx.data.Fee,
+		V:            tx.data.V,
+		R:            tx.data.R,
+		S:            tx.data.S,
+		PV:           tx.data.PV,
+		PR:           tx.data.PR,
+		PS:           tx.data.PS,
+	})
+}
+
+// decodeTyped decodes the EIP-2718 envelope of a typed transaction.
+func decodeTyped(b []byte, data *txdata) error {
+	if len(b) <= 1 {
+		return errEmptyTypedTx
+	}
+	if b[0] != AccessListTxType {
+		return ErrTxTypeNotSupported
+	}
+	var inner accessListTxdata
+	if err := rlp.DecodeBytes(b[1:], &inner); err != nil {
+		return err
+	}
+	if inner.AccessList == nil {
+		inner.AccessList = AccessList{}
+	}
+	*data = txdata{
+		Type:         b[0],
+		ChainID:      inner.ChainID,
+		AccountNonce: inner.AccountNonce,
+		Price:        inner.Price,
+		GasLimit:     inner.GasLimit,
+		Recipient:    inner.Recipient,
+		Amount:       inner.Amount,
+		Payload:      inner.Payload,
+		AccessList:   inner.AccessList,
+		Payer:        inner.Payer,
+		Fee:          inner.Fee,
+		V:            inner.V,
+		R:            inner.R,
+		S:            inner.S,
+		PV:           inner.PV,
+		PR:           inner.PR,
+		PS:           inner.PS,
+	}
+	return nil
+}
+
+// prefixedRlpHash writes the prefix into the hasher before rlp-encoding x.
+// It's used for typed transactions.
+func prefixedRlpHash(prefix byte, x interface{}) (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	hw.Write([]byte{prefix})
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
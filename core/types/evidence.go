@@ -0,0 +1,79 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/crypto"
+)
+
+var (
+	ErrEvidenceNilSign      = errors.New("evidence has a nil sign")
+	ErrEvidenceHeight       = errors.New("evidence signs are not for the same height")
+	ErrEvidenceSameBlock    = errors.New("evidence signs are for the same block")
+	ErrEvidenceDisagree     = errors.New("evidence sign does not agree the block")
+	ErrEvidenceSigner       = errors.New("evidence signs are not from the same signer")
+	ErrEvidenceInvalidSign  = errors.New("evidence has an invalid signature")
+	ErrEvidenceNotCommittee = errors.New("evidence signer is not a committee member")
+)
+
+// DoubleSignEvidence proves that a committee member agreed on two different
+// fast blocks at the same height. The signs are the PbftSigns carried by
+// the tbft votes (Vote.ResultSign) or by the fast blocks.
+type DoubleSignEvidence struct {
+	SignA *PbftSign
+	SignB *PbftSign
+}
+
+// NewDoubleSignEvidence returns the evidence of the two conflicting signs,
+// the signs are ordered by hash so the evidence has a unique hash.
+func NewDoubleSignEvidence(a, b *PbftSign) *DoubleSignEvidence {
+	if a != nil && b != nil {
+		ha, hb := a.Hash(), b.Hash()
+		if bytes.Compare(ha[:], hb[:]) > 0 {
+			a, b = b, a
+		}
+	}
+	return &DoubleSignEvidence{SignA: a, SignB: b}
+}
+
+// Height returns the fast block height of the signs.
+func (e *DoubleSignEvidence) Height() uint64 {
+	return e.SignA.FastHeight.Uint64()
+}
+
+// Hash returns the keccak256 hash of the RLP encoding of the evidence.
+func (e *DoubleSignEvidence) Hash() common.Hash {
+	return rlpHash(e)
+}
+
+// Verify checks the two signs are conflicting signs of the same key and
+// returns the committee base address of the signer.
+func (e *DoubleSignEvidence) Verify() (common.Address, error) {
+	if e.SignA == nil || e.SignB == nil || e.SignA.FastHeight == nil || e.SignB.FastHeight == nil {
+		return common.Address{}, ErrEvidenceNilSign
+	}
+	if e.SignA.FastHeight.Cmp(e.SignB.FastHeight) != 0 {
+		return common.Address{}, ErrEvidenceHeight
+	}
+	if e.SignA.FastHash == e.SignB.FastHash {
+		return common.Address{}, ErrEvidenceSameBlock
+	}
+	if e.SignA.Result != VoteAgree || e.SignB.Result != VoteAgree {
+		return common.Address{}, ErrEvidenceDisagree
+	}
+	pubA, err := crypto.SigToPub(e.SignA.HashWithNoSign().Bytes(), e.SignA.Sign)
+	if err != nil {
+		return common.Address{}, ErrEvidenceInvalidSign
+	}
+	pubB, err := crypto.SigToPub(e.SignB.HashWithNoSign().Bytes(), e.SignB.Sign)
+	if err != nil {
+		return common.Address{}, ErrEvidenceInvalidSign
+	}
+	addrA, addrB := crypto.PubkeyToAddress(*pubA), crypto.PubkeyToAddress(*pubB)
+	if addrA != addrB {
+		return common.Address{}, ErrEvidenceSigner
+	}
+	return addrA, nil
+}
@@ -29,6 +29,9 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 		PV           *hexutil.Big    `json:"pv" rlp:"nil"` // nil means donnot have payment
 		PR           *hexutil.Big    `json:"pr" rlp:"nil"`
 		PS           *hexutil.Big    `json:"ps" rlp:"nil"`
+		Type         hexutil.Uint64  `json:"type"                 rlp:"-"`
+		ChainID      *hexutil.Big    `json:"chainId,omitempty"    rlp:"-"`
+		AccessList   AccessList      `json:"accessList,omitempty" rlp:"-"`
 		Hash         *common.Hash    `json:"hash" rlp:"-"`
 	}
 	var enc txdata
@@ -46,6 +49,9 @@ func (t txdata) MarshalJSON() ([]byte, error) {
 	enc.PV = (*hexutil.Big)(t.PV)
 	enc.PR = (*hexutil.Big)(t.PR)
 	enc.PS = (*hexutil.Big)(t.PS)
+	enc.Type = hexutil.Uint64(t.Type)
+	enc.ChainID = (*hexutil.Big)(t.ChainID)
+	enc.AccessList = t.AccessList
 	enc.Hash = t.Hash
 	return json.Marshal(&enc)
 }
@@ -66,6 +72,9 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 		PV           *hexutil.Big    `json:"pv"  rlp:"nil"`
 		PR           *hexutil.Big    `json:"pr"  rlp:"nil"`
 		PS           *hexutil.Big    `json:"ps"  rlp:"nil"`
+		Type         *hexutil.Uint64 `json:"type"                 rlp:"-"`
+		ChainID      *hexutil.Big    `json:"chainId,omitempty"    rlp:"-"`
+		AccessList   *AccessList     `json:"accessList,omitempty" rlp:"-"`
 		Hash         *common.Hash    `json:"hash" rlp:"-"`
 	}
 	var dec txdata
@@ -124,6 +133,15 @@ func (t *txdata) UnmarshalJSON(input []byte) error {
 	if dec.PS != nil {
 		t.PS = (*big.Int)(dec.PS)
 	}
+	if dec.Type != nil {
+		t.Type = uint8(*dec.Type)
+	}
+	if dec.ChainID != nil {
+		t.ChainID = (*big.Int)(dec.ChainID)
+	}
+	if dec.AccessList != nil {
+		t.AccessList = *dec.AccessList
+	}
 
 	if dec.Hash != nil {
 		t.Hash = dec.Hash
@@ -72,6 +72,14 @@ var (
 	ErrRedeemAmount      = errors.New("wrong redeem amount")
 	ErrForbidAddress     = errors.New("Forbidding Address")
 	ErrRepeatPk          = errors.New("repeat PK on staking tx")
+	ErrRepeatEvidence    = errors.New("the evidence was submitted")
+	ErrExpiredEvidence   = errors.New("the evidence is expired")
+	ErrReceiptAllowance  = errors.New("the amount more than receipt allowance")
+	ErrMinImpawn         = errors.New("the amount less than the minimum staking")
+	ErrInvalidProposal   = errors.New("invalid governance proposal")
+	ErrNotVoter          = errors.New("only the validators can propose and vote")
+	ErrProposalClosed    = errors.New("the proposal is approved or expired")
+	ErrProposalLimit     = errors.New("too many open proposals")
 )
 
 const (
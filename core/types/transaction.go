@@ -17,6 +17,7 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"io"
@@ -75,6 +76,12 @@ type txdata struct {
 	PR *big.Int `json:"pr" rlp:"nil"`
 	PS *big.Int `json:"ps" rlp:"nil"`
 
+	// Typed transaction values, encoded in the EIP-2718 envelope instead of
+	// the legacy RLP list.
+	Type       uint8      `json:"type"                 rlp:"-"`
+	ChainID    *big.Int   `json:"chainId,omitempty"    rlp:"-"`
+	AccessList AccessList `json:"accessList,omitempty" rlp:"-"`
+
 	// This is only used when marshaling to JSON.
 	Hash *common.Hash `json:"hash" rlp:"-"`
 }
@@ -130,6 +137,8 @@ func (tx *Transaction) ConvertRawTransaction() *RawTransaction {
 }
 
 type txdataMarshaling struct {
+	Type         hexutil.Uint64
+	ChainID      *hexutil.Big
 	AccountNonce hexutil.Uint64
 	Price        *hexutil.Big
 	GasLimit     hexutil.Uint64
@@ -229,11 +238,17 @@ func newRawTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLim
 
 // ChainId returns which chain id this transaction was signed for (if at all)
 func (tx *Transaction) ChainId() *big.Int {
+	if tx.data.Type != LegacyTxType {
+		return new(big.Int).Set(tx.data.ChainID)
+	}
 	return deriveChainId(tx.data.V)
 }
 
 // Protected returns whether the transaction is protected from replay protection.
 func (tx *Transaction) Protected() bool {
+	if tx.data.Type != LegacyTxType {
+		return true
+	}
 	return isProtectedV(tx.data.V)
 }
 
@@ -250,20 +265,42 @@ func isProtectedV(V *big.Int) bool {
 	return true
 }
 
-// EncodeRLP implements rlp.Encoder
+// EncodeRLP implements rlp.Encoder. Typed transactions are encoded as an RLP
+// string holding their EIP-2718 envelope.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &tx.data)
+	if tx.data.Type == LegacyTxType {
+		return rlp.Encode(w, &tx.data)
+	}
+	var buf bytes.Buffer
+	if err := tx.encodeTyped(&buf); err != nil {
+		return err
+	}
+	return rlp.Encode(w, buf.Bytes())
 }
 
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
-	if err == nil {
-		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+	kind, size, err := s.Kind()
+	if err != nil {
+		return err
 	}
-
-	return err
+	if kind == rlp.List {
+		err := s.Decode(&tx.data)
+		if err == nil {
+			tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+		}
+		return err
+	}
+	// It's an EIP-2718 typed transaction envelope
+	b, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := decodeTyped(b, &tx.data); err != nil {
+		return err
+	}
+	tx.size.Store(common.StorageSize(len(b)))
+	return nil
 }
 
 // MarshalJSON encodes the web3 RPC transaction format.
@@ -342,6 +379,22 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if err := dec.UnmarshalJSON(input); err != nil {
 		return err
 	}
+	if dec.Type != LegacyTxType {
+		if dec.Type != AccessListTxType {
+			return ErrTxTypeNotSupported
+		}
+		if dec.ChainID == nil {
+			return errors.New("missing required field 'chainId' in transaction")
+		}
+		if dec.AccessList == nil {
+			dec.AccessList = AccessList{}
+		}
+		if !dec.V.IsUint64() || dec.V.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(dec.V.Uint64()), dec.R, dec.S, false) {
+			return ErrInvalidSig
+		}
+		*tx = Transaction{data: dec}
+		return nil
+	}
 	var V byte
 	if isProtectedV(dec.V) {
 		chainID := deriveChainId(dec.V).Uint64()
@@ -393,7 +446,15 @@ func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
 		return hash.(common.Hash)
 	}
-	v := rlpHash(tx)
+	var v common.Hash
+	if tx.data.Type == LegacyTxType {
+		v = rlpHash(tx)
+	} else {
+		// Typed transactions are identified by the hash of their envelope
+		var buf bytes.Buffer
+		tx.encodeTyped(&buf)
+		v = crypto.Keccak256Hash(buf.Bytes())
+	}
 	tx.hash.Store(v)
 	return v
 }
@@ -404,6 +465,12 @@ func (tx *Transaction) Size() common.StorageSize {
 	if size := tx.size.Load(); size != nil {
 		return size.(common.StorageSize)
 	}
+	if tx.data.Type != LegacyTxType {
+		var buf bytes.Buffer
+		tx.encodeTyped(&buf)
+		tx.size.Store(common.StorageSize(buf.Len()))
+		return common.StorageSize(buf.Len())
+	}
 	c := writeCounter(0)
 	rlp.Encode(&c, &tx.data)
 	tx.size.Store(common.StorageSize(c))
@@ -424,6 +491,7 @@ func (tx *Transaction) AsMessage(s Signer) (Message, error) {
 		amount:     tx.data.Amount,
 		fee:        tx.data.Fee,
 		data:       tx.data.Payload,
+		accessList: tx.data.AccessList,
 		checkNonce: true,
 	}
 
@@ -629,10 +697,11 @@ type Message struct {
 	gasLimit   uint64
 	gasPrice   *big.Int
 	data       []byte
+	accessList AccessList
 	checkNonce bool
 }
 
-func NewMessage(from common.Address, to *common.Address, payment common.Address, nonce uint64, amount *big.Int, fee *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, checkNonce bool) Message {
+func NewMessage(from common.Address, to *common.Address, payment common.Address, nonce uint64, amount *big.Int, fee *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList, checkNonce bool) Message {
 	return Message{
 		from:       from,
 		to:         to,
@@ -641,6 +710,7 @@ func NewMessage(from common.Address, to *common.Address, payment common.Address,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
 		data:       data,
+		accessList: accessList,
 		checkNonce: checkNonce,
 		payment:    payment,
 		fee:        fee,
@@ -659,3 +729,5 @@ func (m Message) Gas() uint64      { return m.gasLimit }
 func (m Message) Nonce() uint64    { return m.nonce }
 func (m Message) Data() []byte     { return m.data }
 func (m Message) CheckNonce() bool { return m.checkNonce }
+
+func (m Message) AccessList() AccessList { return m.accessList }
@@ -157,12 +157,20 @@ func (s TIP1Signer) Equal(s2 Signer) bool {
 	return ok && tip1.chainId.Cmp(s.chainId) == 0
 }
 
-var big8 = big.NewInt(8)
+var (
+	big8  = big.NewInt(8)
+	big27 = big.NewInt(27)
+)
 
 func (s TIP1Signer) Sender(tx *Transaction) (common.Address, error) {
 	if tx.ChainId().Cmp(s.chainId) != 0 {
 		return common.Address{}, ErrInvalidChainId
 	}
+	if tx.data.Type != LegacyTxType {
+		// Typed transactions only carry the signature parity in V
+		V := new(big.Int).Add(tx.data.V, big27)
+		return recoverPlain(s.Hash(tx), tx.data.R, tx.data.S, V, true)
+	}
 	V := new(big.Int).Sub(tx.data.V, s.chainIdMul)
 	V.Sub(V, big8)
 	return recoverPlain(s.Hash(tx), tx.data.R, tx.data.S, V, true)
@@ -172,6 +180,10 @@ func (s TIP1Signer) Payer(tx *Transaction) (common.Address, error) {
 	if tx.ChainId().Cmp(s.chainId) != 0 {
 		return common.Address{}, ErrInvalidChainId
 	}
+	if tx.data.Type != LegacyTxType {
+		PV := new(big.Int).Add(tx.data.PV, big27)
+		return recoverPlain(s.Hash_Payment(tx), tx.data.PR, tx.data.PS, PV, true)
+	}
 	PV := new(big.Int).Sub(tx.data.PV, s.chainIdMul)
 	PV.Sub(PV, big8)
 	return recoverPlain(s.Hash_Payment(tx), tx.data.PR, tx.data.PS, PV, true)
@@ -184,6 +196,9 @@ func (s TIP1Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.I
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if tx.data.Type != LegacyTxType {
+		return R, S, big.NewInt(int64(sig[64])), nil
+	}
 	if s.chainId.Sign() != 0 {
 		V = big.NewInt(int64(sig[64] + 35))
 		V.Add(V, s.chainIdMul)
@@ -200,6 +215,9 @@ func (s TIP1Signer) Hash(tx *Transaction) common.Hash {
 	if tx.data.Fee != nil && tx.data.Fee.Uint64() == 0 {
 		tx.data.Fee = nil
 	}
+	if tx.data.Type != LegacyTxType {
+		return s.hashTyped(tx)
+	}
 	if (tx.data.Payer == nil || *tx.data.Payer == (common.Address{})) && tx.data.Fee == nil {
 		hash = rlpHash([]interface{}{
 			tx.data.AccountNonce,
@@ -226,7 +244,52 @@ func (s TIP1Signer) Hash(tx *Transaction) common.Hash {
 	return hash
 }
 
+// hashTyped returns the hash to be signed by the sender of a typed transaction.
+func (s TIP1Signer) hashTyped(tx *Transaction) common.Hash {
+	if (tx.data.Payer == nil || *tx.data.Payer == (common.Address{})) && tx.data.Fee == nil {
+		return prefixedRlpHash(tx.data.Type, []interface{}{
+			s.chainId,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+			tx.data.AccessList,
+		})
+	}
+	return prefixedRlpHash(tx.data.Type, []interface{}{
+		s.chainId,
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.data.AccessList,
+		tx.data.Payer,
+		tx.data.Fee,
+	})
+}
+
 func (s TIP1Signer) Hash_Payment(tx *Transaction) common.Hash {
+	if tx.data.Type != LegacyTxType {
+		return prefixedRlpHash(tx.data.Type, []interface{}{
+			s.chainId,
+			tx.data.AccountNonce,
+			tx.data.Price,
+			tx.data.GasLimit,
+			tx.data.Recipient,
+			tx.data.Amount,
+			tx.data.Payload,
+			tx.data.AccessList,
+			tx.data.Payer,
+			tx.data.Fee,
+			tx.data.V,
+			tx.data.R,
+			tx.data.S,
+		})
+	}
 	return rlpHash([]interface{}{
 		tx.data.AccountNonce,
 		tx.data.Price,
@@ -102,6 +102,55 @@ var PrecompiledContractsYoloPos = map[common.Address]PrecompiledContract{
 	types.StakingAddress:              &staking{},
 }
 
+var (
+	// PrecompiledAddressesByzantium holds the addresses of PrecompiledContractsByzantium
+	// in a fixed order.
+	PrecompiledAddressesByzantium = []common.Address{
+		common.BytesToAddress([]byte{1}),
+		common.BytesToAddress([]byte{2}),
+		common.BytesToAddress([]byte{3}),
+		common.BytesToAddress([]byte{4}),
+		common.BytesToAddress([]byte{5}),
+		common.BytesToAddress([]byte{6}),
+		common.BytesToAddress([]byte{7}),
+		common.BytesToAddress([]byte{8}),
+	}
+	// PrecompiledAddressesPoS holds the addresses of PrecompiledContractsPoS
+	// in a fixed order.
+	PrecompiledAddressesPoS = append(append([]common.Address{}, PrecompiledAddressesByzantium...),
+		types.StakingAddress,
+	)
+	// PrecompiledAddressesYoloPos holds the addresses of PrecompiledContractsYoloPos
+	// in a fixed order.
+	PrecompiledAddressesYoloPos = append(append([]common.Address{}, PrecompiledAddressesByzantium...),
+		common.BytesToAddress([]byte{9}),
+		common.BytesToAddress([]byte{10}),
+		common.BytesToAddress([]byte{11}),
+		common.BytesToAddress([]byte{12}),
+		common.BytesToAddress([]byte{13}),
+		common.BytesToAddress([]byte{14}),
+		common.BytesToAddress([]byte{15}),
+		common.BytesToAddress([]byte{16}),
+		common.BytesToAddress([]byte{17}),
+		common.BytesToAddress([]byte{18}),
+		types.StakingAddress,
+	)
+)
+
+// ActivePrecompiles returns the addresses of the precompiles enabled with the
+// current configuration. They are warm from the start of a transaction once the
+// access lists are active.
+func ActivePrecompiles(rules params.Rules) []common.Address {
+	switch {
+	case rules.IsTIP11:
+		return PrecompiledAddressesYoloPos
+	case rules.IsTIP7:
+		return PrecompiledAddressesPoS
+	default:
+		return PrecompiledAddressesByzantium
+	}
+}
+
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
 // It returns
 // - the returned bytes,
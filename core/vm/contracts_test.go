@@ -371,3 +371,28 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 	}
 	benchmarkPrecompiled("0f", testcase, b)
 }
+
+func TestActivePrecompiles(t *testing.T) {
+	tests := []struct {
+		rules     params.Rules
+		contracts map[common.Address]PrecompiledContract
+	}{
+		{params.Rules{}, PrecompiledContractsByzantium},
+		{params.Rules{IsTIP7: true}, PrecompiledContractsPoS},
+		{params.Rules{IsTIP7: true, IsTIP11: true}, PrecompiledContractsYoloPos},
+	}
+	for i, test := range tests {
+		addrs := ActivePrecompiles(test.rules)
+		if len(addrs) != len(test.contracts) {
+			t.Fatalf("test %d: precompile count mismatch: have %d, want %d", i, len(addrs), len(test.contracts))
+		}
+		for j, addr := range addrs {
+			if _, ok := test.contracts[addr]; !ok {
+				t.Errorf("test %d: address %x is not a precompile", i, addr)
+			}
+			if j > 0 && bytes.Compare(addrs[j-1][:], addr[:]) >= 0 {
+				t.Errorf("test %d: address %x out of order", i, addr)
+			}
+		}
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/common/hexutil"
 	"github.com/iceming123/go-ice/core/types"
-	"github.com/iceming123/go-ice/params"
 	"github.com/iceming123/go-ice/rlp"
 )
 
@@ -19,6 +18,7 @@ type extImpawnImpl struct {
 	CurEpochID uint64
 	Array      []uint64
 	LastReward uint64
+	Slashes    []*SlashRecord `rlp:"tail"`
 }
 
 func (i *ImpawnImpl) DecodeRLP(s *rlp.Stream) error {
@@ -32,6 +32,7 @@ func (i *ImpawnImpl) DecodeRLP(s *rlp.Stream) error {
 	}
 
 	i.curEpochID, i.accounts, i.lastReward = ei.CurEpochID, accounts, ei.LastReward
+	i.slashes = ei.Slashes
 	return nil
 }
 
@@ -57,6 +58,7 @@ func (i *ImpawnImpl) EncodeRLP(w io.Writer) error {
 		Accounts:   accounts,
 		Array:      order,
 		LastReward: i.lastReward,
+		Slashes:    i.slashes,
 	})
 }
 
@@ -72,7 +74,7 @@ func (i *ImpawnImpl) GetAllStakingAccountRPC(height uint64) map[string]interface
 		attr["unit"] = unitDisplay(sa.Unit)
 		attr["votePubKey"] = hexutil.Bytes(sa.Votepubkey)
 		attr["fee"] = sa.Fee.Uint64()
-		if countCommittee <= params.CountInEpoch && isCommitteeMember(i, sa.Unit.Address) {
+		if countCommittee <= i.committeeSize(i.curEpochID) && isCommitteeMember(i, sa.Unit.Address) {
 			attr["committee"] = true
 			countCommittee++
 		} else {
@@ -361,6 +363,62 @@ func (i *ImpawnImpl) GetStakingAccountRPC(height uint64, address common.Address)
 	return attr
 }
 
+// IsCommitteeMember reports whether the staking account is elected into the
+// committee of the current epoch.
+func (i *ImpawnImpl) IsCommitteeMember(address common.Address) bool {
+	return isCommitteeMember(i, address)
+}
+
+// StakingAmount returns the amount staked by the account and its delegations
+// up to the height.
+func (s *StakingAccount) StakingAmount(height uint64) *big.Int {
+	return s.getAllStaking(height)
+}
+
+// ValidStakingAmount returns the staked amount of the account and its
+// delegations which counts for the election at the height.
+func (s *StakingAccount) ValidStakingAmount(height uint64) *big.Int {
+	return s.getValidStaking(height)
+}
+
+// StakingAmount returns the amount delegated up to the height.
+func (d *DelegationAccount) StakingAmount(height uint64) *big.Int {
+	return d.getAllStaking(height)
+}
+
+// ValidStakingAmount returns the delegated amount which counts for the
+// election at the height.
+func (d *DelegationAccount) ValidStakingAmount(height uint64) *big.Int {
+	return d.getValidStaking(height)
+}
+
+func (i *ImpawnImpl) GetSlashRecordRPC(evidence common.Hash) map[string]interface{} {
+	record := i.getSlashRecord(evidence)
+	if record == nil {
+		return nil
+	}
+	return slashDisplay(record)
+}
+
+func (i *ImpawnImpl) GetSlashRecordsRPC() []map[string]interface{} {
+	attrs := make([]map[string]interface{}, 0)
+	for _, record := range i.slashes {
+		attrs = append(attrs, slashDisplay(record))
+	}
+	return attrs
+}
+
+func slashDisplay(record *SlashRecord) map[string]interface{} {
+	attr := make(map[string]interface{})
+	attr["evidence"] = record.Evidence
+	attr["address"] = record.Address.String()
+	attr["height"] = record.Height
+	attr["epochID"] = record.EpochID
+	attr["jailUntil"] = record.JailUntil
+	attr["amount"] = weitoCoin(record.Amount)
+	return attr
+}
+
 func isCommitteeMember(i *ImpawnImpl, address common.Address) bool {
 	sas := i.getElections3(i.curEpochID)
 	if sas == nil {
@@ -382,6 +440,10 @@ func daSDisplay(das []*DelegationAccount, height uint64) []map[string]interface{
 		attr["delegate"] = weitoCoin(da.getAllStaking(height))
 		attr["validDelegate"] = weitoCoin(da.getValidStaking(height))
 		attr["unit"] = unitDisplay(da.Unit)
+		attr["autoCompound"] = da.isAutoCompound()
+		if c := da.compound(); c != nil {
+			attr["pendingCompound"] = weitoCoin(c.Pending)
+		}
 		attrs = append(attrs, attr)
 	}
 	return attrs
@@ -0,0 +1,347 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rlp"
+)
+
+// governanceKey is the key of the governance proposals in the state of the
+// staking address.
+var governanceKey = common.BytesToHash([]byte("governance"))
+
+// govCache keeps the decoded governance by the hash of its RLP and forbidCache
+// keeps the governed accounts of an epoch, the transactions check the accounts
+// without decoding the governance.
+var (
+	govCache, _    = lru.New(20)
+	forbidCache, _ = lru.New(20)
+)
+
+type forbidKey struct {
+	hash    common.Hash
+	epochid uint64
+}
+
+// The parameters which can be changed by the governance proposals
+const (
+	GovMinImpawn     uint8 = iota + 1 // the minimum amount of a deposit
+	GovMixEpochCount                  // the count of the epochs kept before the last rewarded one
+	GovCommitteeSize                  // the count of the elected validators in an epoch
+	GovForbidAddress                  // forbid the account to send transactions
+	GovAllowAddress                   // allow the account which is forbidden to send transactions
+)
+
+// Proposal is a change of a governed parameter, it takes effect from the
+// activation epoch once the validators approve it.
+type Proposal struct {
+	ID         uint64
+	Proposer   common.Address
+	Kind       uint8
+	Value      *big.Int       // the value of the numeric parameters
+	Account    common.Address // the account of the whitelist changes
+	EpochID    uint64         // the epoch the proposal is made in
+	Votes      []common.Address
+	Activation uint64 // the activation epoch, 0 before the proposal is approved
+}
+
+func (p *Proposal) approved() bool {
+	return p.Activation != 0
+}
+
+func (p *Proposal) expired(epochid uint64) bool {
+	return !p.approved() && epochid > p.EpochID+params.GovernanceVotingEpochs
+}
+
+// open returns whether the proposal can be voted in the epoch
+func (p *Proposal) open(epochid uint64) bool {
+	return !p.approved() && !p.expired(epochid)
+}
+
+// sameParameter returns the filter of the proposals changing the same
+// parameter as p.
+func (p *Proposal) sameParameter() func(*Proposal) bool {
+	if p.Kind == GovForbidAddress || p.Kind == GovAllowAddress {
+		return func(o *Proposal) bool {
+			return (o.Kind == GovForbidAddress || o.Kind == GovAllowAddress) && o.Account == p.Account
+		}
+	}
+	return func(o *Proposal) bool { return o.Kind == p.Kind }
+}
+
+func (p *Proposal) clone() *Proposal {
+	tmp := *p
+	tmp.Value = new(big.Int).Set(p.Value)
+	tmp.Votes = append([]common.Address{}, p.Votes...)
+	return &tmp
+}
+
+func (p *Proposal) voted(addr common.Address) bool {
+	for _, v := range p.Votes {
+		if v == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Governance keeps the proposals voted by the validators, the approved ones
+// are kept as the history of the governed parameters.
+type Governance struct {
+	NextID    uint64
+	Proposals []*Proposal
+}
+
+func NewGovernance() *Governance {
+	return &Governance{NextID: 1}
+}
+
+func (g *Governance) clone() *Governance {
+	tmp := &Governance{NextID: g.NextID}
+	for _, p := range g.Proposals {
+		tmp.Proposals = append(tmp.Proposals, p.clone())
+	}
+	return tmp
+}
+
+// prune removes the expired proposals and the applied ones which are replaced
+// by a later one in the epoch, the allowed accounts which are not in the
+// built-in whitelist are removed too as they are allowed without the governance.
+func (g *Governance) prune(epochid uint64) {
+	var proposals []*Proposal
+	for _, p := range g.Proposals {
+		if p.expired(epochid) {
+			continue
+		}
+		if p.approved() && p.Activation <= epochid {
+			if g.latest(epochid, p.sameParameter()) != p {
+				continue
+			}
+			if p.Kind == GovAllowAddress && types.ForbidAddress(p.Account) == nil {
+				continue
+			}
+		}
+		proposals = append(proposals, p)
+	}
+	g.Proposals = proposals
+}
+
+func validProposal(kind uint8, value *big.Int, account common.Address) bool {
+	switch kind {
+	case GovMinImpawn:
+		return value != nil && value.Sign() > 0
+	case GovMixEpochCount:
+		return value != nil && value.Cmp(big.NewInt(int64(types.MixEpochCount))) >= 0 && value.IsUint64()
+	case GovCommitteeSize:
+		return value != nil && value.Cmp(big.NewInt(int64(params.MinimumCommitteeNumber))) >= 0 &&
+			value.Cmp(params.MaximumCommitteeNumber) <= 0
+	case GovForbidAddress, GovAllowAddress:
+		return account != (common.Address{}) && account != types.StakingAddress
+	}
+	return false
+}
+
+// isVoter returns whether addr is a validator of the epoch
+func isVoter(impawn *ImpawnImpl, epochid uint64, addr common.Address) bool {
+	for _, sa := range impawn.getElections3(epochid) {
+		if sa.Unit.Address == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// Propose adds a proposal of the validator from in the current epoch of impawn,
+// a validator has GovernanceProposalLimit open proposals at most.
+func (g *Governance) Propose(impawn *ImpawnImpl, from common.Address, kind uint8, value *big.Int, account common.Address) (*Proposal, error) {
+	epochid := impawn.getCurrentEpoch()
+	if !validProposal(kind, value, account) {
+		return nil, types.ErrInvalidProposal
+	}
+	if !isVoter(impawn, epochid, from) {
+		return nil, types.ErrNotVoter
+	}
+	g.prune(epochid)
+	count := 0
+	for _, p := range g.Proposals {
+		if p.Proposer == from && p.open(epochid) {
+			count++
+		}
+	}
+	if count >= params.GovernanceProposalLimit {
+		return nil, types.ErrProposalLimit
+	}
+	p := &Proposal{
+		ID:       g.NextID,
+		Proposer: from,
+		Kind:     kind,
+		Value:    new(big.Int),
+		Account:  account,
+		EpochID:  epochid,
+	}
+	if value != nil {
+		p.Value.Set(value)
+	}
+	g.NextID++
+	g.Proposals = append(g.Proposals, p)
+	return p, nil
+}
+
+// Vote approves the proposal id by the validator from, the proposal is approved
+// with the quorum of the validators in the current epoch of impawn and it takes
+// effect after the time lock.
+func (g *Governance) Vote(impawn *ImpawnImpl, from common.Address, id uint64) (*Proposal, error) {
+	epochid := impawn.getCurrentEpoch()
+	p := g.GetProposal(id)
+	if p == nil {
+		return nil, types.ErrInvalidProposal
+	}
+	if !p.open(epochid) {
+		return nil, types.ErrProposalClosed
+	}
+	if !isVoter(impawn, epochid, from) {
+		return nil, types.ErrNotVoter
+	}
+	g.prune(epochid)
+	if !p.voted(from) {
+		p.Votes = append(p.Votes, from)
+	}
+	validators := impawn.getElections3(epochid)
+	votes := 0
+	for _, sa := range validators {
+		if p.voted(sa.Unit.Address) {
+			votes++
+		}
+	}
+	if uint64(votes)*types.Base.Uint64() >= uint64(len(validators))*params.GovernanceQuorum {
+		p.Activation = epochid + params.GovernanceTimeLock
+		log.Info("Governance proposal approved", "id", p.ID, "kind", p.Kind, "value", p.Value, "account", p.Account, "activation", p.Activation)
+	}
+	return p, nil
+}
+
+func (g *Governance) GetProposal(id uint64) *Proposal {
+	for _, p := range g.Proposals {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// latest returns the last approved proposal of kind which is active in the
+// epoch, match filters the proposals.
+func (g *Governance) latest(epochid uint64, match func(*Proposal) bool) *Proposal {
+	var last *Proposal
+	for _, p := range g.Proposals {
+		if !p.approved() || p.Activation > epochid || !match(p) {
+			continue
+		}
+		if last == nil || p.Activation > last.Activation || (p.Activation == last.Activation && p.ID > last.ID) {
+			last = p
+		}
+	}
+	return last
+}
+
+// Value returns the approved value of the numeric parameter kind in the epoch,
+// it is nil if the parameter is never changed.
+func (g *Governance) Value(kind uint8, epochid uint64) *big.Int {
+	p := g.latest(epochid, func(p *Proposal) bool { return p.Kind == kind })
+	if p == nil {
+		return nil
+	}
+	return new(big.Int).Set(p.Value)
+}
+
+// accounts returns the governed accounts in the epoch, they are forbidden if
+// the value is true.
+func (g *Governance) accounts(epochid uint64) map[common.Address]bool {
+	accounts := make(map[common.Address]bool)
+	for _, p := range g.Proposals {
+		if p.Kind != GovForbidAddress && p.Kind != GovAllowAddress {
+			continue
+		}
+		if _, ok := accounts[p.Account]; !ok {
+			if forbidden, ok := g.Forbidden(p.Account, epochid); ok {
+				accounts[p.Account] = forbidden
+			}
+		}
+	}
+	return accounts
+}
+
+// Forbidden returns whether the account is forbidden in the epoch, the second
+// value is false if it is never changed by the governance.
+func (g *Governance) Forbidden(addr common.Address, epochid uint64) (bool, bool) {
+	p := g.latest(epochid, func(p *Proposal) bool {
+		return (p.Kind == GovForbidAddress || p.Kind == GovAllowAddress) && p.Account == addr
+	})
+	if p == nil {
+		return false, false
+	}
+	return p.Kind == GovForbidAddress, true
+}
+
+func (g *Governance) Save(state StateDB, preAddress common.Address) error {
+	data, err := rlp.EncodeToBytes(g)
+	if err != nil {
+		log.Crit("Failed to RLP encode Governance", "err", err)
+	}
+	state.SetPOSState(preAddress, governanceKey, data)
+	return err
+}
+
+// Load restores the proposals from the state, it is empty before the first proposal.
+func (g *Governance) Load(state StateDB, preAddress common.Address) error {
+	data := state.GetPOSState(preAddress, governanceKey)
+	if len(data) == 0 {
+		g.NextID, g.Proposals = 1, nil
+		return nil
+	}
+	hash := types.RlpHash(data)
+	var temp *Governance
+	if cc, ok := govCache.Get(hash); ok {
+		temp = cc.(*Governance).clone()
+	} else {
+		temp = new(Governance)
+		if err := rlp.DecodeBytes(data, temp); err != nil {
+			log.Error("Invalid Governance entry RLP", "err", err)
+			return errors.New(fmt.Sprintf("Invalid Governance entry RLP %s", err.Error()))
+		}
+		govCache.Add(hash, temp.clone())
+	}
+	g.NextID, g.Proposals = temp.NextID, temp.Proposals
+	return nil
+}
+
+// GovernedForbidden returns whether the account is forbidden at height by the
+// governance, the second value is false if the governance never changed it.
+// The governed accounts are cached by the governance state and the epoch.
+func GovernedForbidden(state StateDB, addr common.Address, height uint64) (bool, bool) {
+	data := state.GetPOSState(types.StakingAddress, governanceKey)
+	if len(data) == 0 {
+		return false, false
+	}
+	key := forbidKey{types.RlpHash(data), types.GetEpochFromHeight(height).EpochID}
+	var accounts map[common.Address]bool
+	if cc, ok := forbidCache.Get(key); ok {
+		accounts = cc.(map[common.Address]bool)
+	} else {
+		g := NewGovernance()
+		if err := g.Load(state, types.StakingAddress); err != nil {
+			return false, false
+		}
+		accounts = g.accounts(key.epochid)
+		forbidCache.Add(key, accounts)
+	}
+	forbidden, ok := accounts[addr]
+	return forbidden, ok
+}
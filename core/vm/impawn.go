@@ -287,6 +287,54 @@ func (s *impawnUnit) clone() *impawnUnit {
 	}
 	return tmp
 }
+// slash cuts rate/types.Base of the staking and the redeeming amounts,
+// returns the amount burned from the locked balance. The redeem item of
+// epochid is still counted in the staking values.
+func (s *impawnUnit) slash(epochid, rate uint64) *big.Int {
+	all, r := big.NewInt(0), new(big.Int).SetUint64(rate)
+	for _, v := range s.Value {
+		cut := new(big.Int).Quo(new(big.Int).Mul(v.Amount, r), types.Base)
+		v.Amount = new(big.Int).Sub(v.Amount, cut)
+		all = all.Add(all, cut)
+	}
+	for _, v := range s.RedeemInof {
+		cut := new(big.Int).Quo(new(big.Int).Mul(v.Amount, r), types.Base)
+		v.Amount = new(big.Int).Sub(v.Amount, cut)
+		if v.EpochID < epochid {
+			all = all.Add(all, cut)
+		}
+	}
+	return all
+}
+
+// transfer cuts amount from the latest staking values and returns the cut
+// values sorted by height, they keep the heights so the staking of the SA
+// is not changed at any height.
+func (s *impawnUnit) transfer(amount *big.Int) []*PairstakingValue {
+	var moved, kept []*PairstakingValue
+	left := new(big.Int).Set(amount)
+	for pos := len(s.Value) - 1; pos >= 0 && left.Sign() > 0; pos-- {
+		v := s.Value[pos]
+		cut := new(big.Int).Set(left)
+		if v.Amount.Cmp(cut) < 0 {
+			cut.Set(v.Amount)
+		}
+		v.Amount = new(big.Int).Sub(v.Amount, cut)
+		left = left.Sub(left, cut)
+		moved = append([]*PairstakingValue{&PairstakingValue{
+			Amount: cut,
+			Height: new(big.Int).Set(v.Height),
+			State:  v.State,
+		}}, moved...)
+	}
+	for _, v := range s.Value {
+		if v.Amount.Sign() > 0 {
+			kept = append(kept, v)
+		}
+	}
+	s.Value = kept
+	return moved
+}
 func (s *impawnUnit) sort() {
 	sort.Sort(valuesByHeight(s.Value))
 	s.sortRedeemItems()
@@ -321,13 +369,51 @@ func (s *impawnUnit) redeemToMap() map[uint64]*big.Int {
 
 /////////////////////////////////////////////////////////////////////////////////
 
+// CompoundInfo is the auto compound setting of a delegation
+type CompoundInfo struct {
+	Auto    bool
+	Pending *big.Int // the locked reward which will be staked in the next epoch
+}
+
 type DelegationAccount struct {
 	SaAddress common.Address
 	Unit      *impawnUnit
+	Compound  []*CompoundInfo `rlp:"tail"` // at most one item, empty if it was never set
 }
 
 func (d *DelegationAccount) update(da *DelegationAccount, move bool) {
 	d.Unit.update(da.Unit, move)
+	if c := da.compound(); c != nil {
+		d.setAutoCompound(c.Auto)
+		d.addPending(c.Pending)
+	}
+}
+func (d *DelegationAccount) compound() *CompoundInfo {
+	if len(d.Compound) == 0 {
+		return nil
+	}
+	return d.Compound[0]
+}
+func (d *DelegationAccount) isAutoCompound() bool {
+	c := d.compound()
+	return c != nil && c.Auto
+}
+func (d *DelegationAccount) setAutoCompound(auto bool) {
+	if c := d.compound(); c != nil {
+		c.Auto = auto
+	} else {
+		d.Compound = []*CompoundInfo{&CompoundInfo{Auto: auto, Pending: big.NewInt(0)}}
+	}
+}
+func (d *DelegationAccount) addPending(amount *big.Int) {
+	if amount.Sign() <= 0 {
+		return
+	}
+	if d.compound() == nil {
+		d.setAutoCompound(false)
+	}
+	c := d.compound()
+	c.Pending = new(big.Int).Add(c.Pending, amount)
 }
 func (s *DelegationAccount) getAllStaking(hh uint64) *big.Int {
 	return s.Unit.getAllStaking(hh)
@@ -344,14 +430,28 @@ func (s *DelegationAccount) redeeming(hh uint64, amount *big.Int) (common.Addres
 func (s *DelegationAccount) finishRedeemed() {
 	s.Unit.finishRedeemed()
 }
+
+// merge stakes the pending reward of the auto compound with the merged staking
 func (s *DelegationAccount) merge(epochid, hh uint64) {
 	s.Unit.merge(epochid, hh)
+	if c := s.compound(); c != nil && c.Pending.Sign() > 0 {
+		v := s.Unit.Value[0]
+		v.Amount = new(big.Int).Add(v.Amount, c.Pending)
+		c.Pending = big.NewInt(0)
+	}
 }
 func (s *DelegationAccount) clone() *DelegationAccount {
-	return &DelegationAccount{
+	tmp := &DelegationAccount{
 		SaAddress: s.SaAddress,
 		Unit:      s.Unit.clone(),
 	}
+	for _, v := range s.Compound {
+		tmp.Compound = append(tmp.Compound, &CompoundInfo{
+			Auto:    v.Auto,
+			Pending: new(big.Int).Set(v.Pending),
+		})
+	}
+	return tmp
 }
 func (s *DelegationAccount) isValid() bool {
 	return s.Unit.isValid()
@@ -591,12 +691,31 @@ func (s *SAImpawns) update(sa1 *StakingAccount, hh uint64, next, move bool, effe
 	}
 }
 
+/////////////////////////////////////////////////////////////////////////////////
+// SlashRecord is the result of a double sign evidence
+type SlashRecord struct {
+	Evidence  common.Hash    // hash of the evidence
+	Address   common.Address // the staking account of the signer
+	Height    uint64         // fast height of the double sign
+	EpochID   uint64         // epoch of the double sign
+	JailUntil uint64         // the account can't be elected until the epoch
+	Amount    *big.Int       // all amount burned from the SA and DAs
+}
+
+func (r *SlashRecord) clone() *SlashRecord {
+	tmp := *r
+	tmp.Amount = new(big.Int).Set(r.Amount)
+	return &tmp
+}
+
 /////////////////////////////////////////////////////////////////////////////////
 // be thread-safe for caller locked
 type ImpawnImpl struct {
 	accounts   map[uint64]SAImpawns // key is epoch id,value is SA set
 	curEpochID uint64               // the new epochid of the current state
 	lastReward uint64               // the curnent reward height block
+	slashes    []*SlashRecord       // the double sign evidences in the recent epochs
+	governance *Governance          // the governed parameters, it is saved apart
 }
 
 func NewImpawnImpl() *ImpawnImpl {
@@ -624,6 +743,9 @@ func CloneImpawnImpl(ori *ImpawnImpl) *ImpawnImpl {
 		}
 		tmp.accounts[k] = items
 	}
+	for _, v := range ori.slashes {
+		tmp.slashes = append(tmp.slashes, v.clone())
+	}
 	return tmp
 }
 
@@ -635,6 +757,35 @@ func (i *ImpawnImpl) getCurrentEpoch() uint64 {
 func (i *ImpawnImpl) SetCurrentEpoch(eid uint64) {
 	i.curEpochID = eid
 }
+
+// committeeSize returns the count of the validators elected in the epoch
+func (i *ImpawnImpl) committeeSize(epochid uint64) int {
+	if i.governance != nil {
+		if v := i.governance.Value(GovCommitteeSize, epochid); v != nil {
+			return int(v.Int64())
+		}
+	}
+	return params.CountInEpoch
+}
+
+// mixEpochCount returns the count of the epochs kept before the last rewarded one
+func (i *ImpawnImpl) mixEpochCount(epochid uint64) uint64 {
+	if i.governance != nil {
+		if v := i.governance.Value(GovMixEpochCount, epochid); v != nil {
+			return v.Uint64()
+		}
+	}
+	return uint64(types.MixEpochCount)
+}
+
+// minImpawn returns the minimum amount of a deposit in the epoch, it is nil
+// without the governance.
+func (i *ImpawnImpl) minImpawn(epochid uint64) *big.Int {
+	if i.governance != nil {
+		return i.governance.Value(GovMinImpawn, epochid)
+	}
+	return nil
+}
 func (i *ImpawnImpl) getMinEpochID() uint64 {
 	eid := i.curEpochID
 	for k, _ := range i.accounts {
@@ -726,6 +877,51 @@ func (i *ImpawnImpl) getElections3(epochid uint64) []*StakingAccount {
 	}
 	return i.getElections2(eid)
 }
+func (i *ImpawnImpl) getSlashRecord(hash common.Hash) *SlashRecord {
+	for _, v := range i.slashes {
+		if v.Evidence == hash {
+			return v
+		}
+	}
+	return nil
+}
+func (i *ImpawnImpl) isSlashed(addr common.Address, height uint64) bool {
+	for _, v := range i.slashes {
+		if v.Address == addr && v.Height == height {
+			return true
+		}
+	}
+	return false
+}
+func (i *ImpawnImpl) isJailed(addr common.Address, epochid uint64) bool {
+	for _, v := range i.slashes {
+		if v.Address == addr && v.JailUntil >= epochid {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, v := range addrs {
+		if v == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneSlashes remove the records which can't be submitted again and
+// the account was released
+func (i *ImpawnImpl) pruneSlashes() {
+	var slashes []*SlashRecord
+	for _, v := range i.slashes {
+		if v.EpochID+1 >= i.curEpochID || v.JailUntil > i.curEpochID {
+			slashes = append(slashes, v)
+		}
+	}
+	i.slashes = slashes
+}
 func (i *ImpawnImpl) fetchAccountsInEpoch(epochid uint64, addrs []*StakingAccount) []*StakingAccount {
 	if accounts, ok := i.accounts[epochid]; !ok {
 		return addrs
@@ -901,6 +1097,12 @@ func (i *ImpawnImpl) move(prev, next, effectHeight uint64) error {
 
 // DoElections called by consensus while it closer the end of epoch,have 500~1000 fast block
 func (i *ImpawnImpl) DoElections(epochid, height uint64) ([]*StakingAccount, error) {
+	return i.DoElections2(epochid, height, nil)
+}
+
+// DoElections2 elects the committee like DoElections,the offline accounts
+// are excluded from the election
+func (i *ImpawnImpl) DoElections2(epochid, height uint64, offline []common.Address) ([]*StakingAccount, error) {
 	if epochid < params.FirstNewEpochID && epochid != i.getCurrentEpoch()+1 {
 		return nil, types.ErrOverEpochID
 	}
@@ -921,9 +1123,12 @@ func (i *ImpawnImpl) DoElections(epochid, height uint64) ([]*StakingAccount, err
 			if validStaking.Cmp(params.ElectionMinLimitForStaking) < 0 {
 				continue
 			}
+			if i.isJailed(v.Unit.GetRewardAddress(), epochid) || containsAddress(offline, v.Unit.GetRewardAddress()) {
+				continue
+			}
 			v.Committee = true
 			ee = append(ee, v)
-			if len(ee) >= params.CountInEpoch {
+			if len(ee) >= i.committeeSize(epochid) {
 				break
 			}
 		}
@@ -940,7 +1145,8 @@ func (i *ImpawnImpl) Shift(epochid, effectHeight uint64) error {
 	minEpoch := types.GetEpochFromHeight(lastReward)
 	min := i.getMinEpochID()
 	// fmt.Println("*** move min:", min, "minEpoch:", minEpoch.EpochID, "lastReward:", i.lastReward)
-	for ii := min; minEpoch.EpochID > 1 && ii < minEpoch.EpochID-1; ii++ {
+	count := i.mixEpochCount(epochid)
+	for ii := min; minEpoch.EpochID >= count && ii+count <= minEpoch.EpochID; ii++ {
 		delete(i.accounts, ii)
 		// fmt.Println("delete epoch:", ii)
 	}
@@ -949,6 +1155,7 @@ func (i *ImpawnImpl) Shift(epochid, effectHeight uint64) error {
 		return types.ErrOverEpochID
 	}
 	i.SetCurrentEpoch(epochid)
+	i.pruneSlashes()
 	prev := epochid - 1
 	return i.move(prev, epochid, effectHeight)
 }
@@ -997,6 +1204,141 @@ func (i *ImpawnImpl) CancelDAccount(curHeight uint64, addrSA, addrDA common.Addr
 	return err3
 }
 
+// TransferDAccount moves amount of the delegation from addrDA to the delegation
+// of addr in the same staking account, only the cancelable amount in the current
+// epoch can be moved.
+func (i *ImpawnImpl) TransferDAccount(curHeight uint64, addrSA, addrDA, addr common.Address, amount *big.Int) error {
+	if amount.Sign() <= 0 || curHeight <= 0 {
+		return types.ErrInvalidParam
+	}
+	if bytes.Equal(addrSA.Bytes(), addr.Bytes()) {
+		return types.ErrDelegationSelf
+	}
+	curEpoch := types.GetEpochFromHeight(curHeight)
+	if curEpoch == nil || curEpoch.EpochID != i.curEpochID {
+		return types.ErrInvalidParam
+	}
+	sa, err := i.GetStakingAccount(curEpoch.EpochID, addrSA)
+	if err != nil {
+		return err
+	}
+	da, _ := i.getDAfromSA(sa, addrDA)
+	if da == nil {
+		log.Error("TransferDAccount error", "height", curHeight, "SA", addrSA.String(), "DA", addrDA.String())
+		return types.ErrNotDelegation
+	}
+	if da.getValidStaking(curEpoch.EndHeight).Cmp(amount) < 0 {
+		return types.ErrAmountOver
+	}
+	if bytes.Equal(addrDA.Bytes(), addr.Bytes()) {
+		return nil
+	}
+	return i.insertDAccount(curHeight, &DelegationAccount{
+		SaAddress: addrSA,
+		Unit: &impawnUnit{
+			Address:    addr,
+			Value:      da.Unit.transfer(amount),
+			RedeemInof: make([]*RedeemItem, 0),
+		},
+	})
+}
+
+// SetDAccountCompound turns on or off the auto compound of the delegation,
+// the rewards after it are staked in the next epoch.
+func (i *ImpawnImpl) SetDAccountCompound(curHeight uint64, addrSA, addrDA common.Address, auto bool) error {
+	if curHeight <= 0 {
+		return types.ErrInvalidParam
+	}
+	curEpoch := types.GetEpochFromHeight(curHeight)
+	if curEpoch == nil || curEpoch.EpochID != i.curEpochID {
+		return types.ErrInvalidParam
+	}
+	sa, err := i.GetStakingAccount(curEpoch.EpochID, addrSA)
+	if err != nil {
+		return err
+	}
+	da, _ := i.getDAfromSA(sa, addrDA)
+	if da == nil {
+		log.Error("SetDAccountCompound error", "height", curHeight, "SA", addrSA.String(), "DA", addrDA.String())
+		return types.ErrNotDelegation
+	}
+	da.setAutoCompound(auto)
+	return nil
+}
+
+// CompoundRewards locks the rewards of the auto compound delegations in the
+// current epoch, they are staked at the next shift.
+func (i *ImpawnImpl) CompoundRewards(state StateDB, infos []*types.SARewardInfos) {
+	sas, ok := i.accounts[i.curEpochID]
+	if !ok {
+		return
+	}
+	for _, info := range infos {
+		if len(info.Items) < 2 {
+			continue
+		}
+		sa := sas.getSA(info.Items[0].Address)
+		if sa == nil {
+			continue
+		}
+		for _, item := range info.Items[1:] {
+			da := sa.getDA(item.Address)
+			if da == nil || !da.isAutoCompound() || item.Amount.Sign() <= 0 {
+				continue
+			}
+			da.addPending(item.Amount)
+			addLockedBalance(state, item.Address, item.Amount)
+		}
+	}
+}
+
+// SlashSAccount burns params.SlashRateForDoubleSign of the staking account
+// and all of its delegations in the current epoch for the double sign at height,
+// and jails the account for params.JailEpochForDoubleSign epochs.
+// It returns the burned amount of every address.
+func (i *ImpawnImpl) SlashSAccount(curHeight uint64, evidence common.Hash, addr common.Address, height uint64) (map[common.Address]*big.Int, error) {
+	curEpoch := types.GetEpochFromHeight(curHeight)
+	if curEpoch == nil || curEpoch.EpochID != i.curEpochID || height >= curHeight {
+		return nil, types.ErrInvalidParam
+	}
+	e := types.GetEpochFromHeight(height)
+	if e.EpochID+1 < i.curEpochID {
+		return nil, types.ErrExpiredEvidence
+	}
+	if i.getSlashRecord(evidence) != nil || i.isSlashed(addr, height) {
+		return nil, types.ErrRepeatEvidence
+	}
+	record := &SlashRecord{
+		Evidence:  evidence,
+		Address:   addr,
+		Height:    height,
+		EpochID:   e.EpochID,
+		JailUntil: i.curEpochID + params.JailEpochForDoubleSign,
+		Amount:    big.NewInt(0),
+	}
+	burned := make(map[common.Address]*big.Int)
+	// the account may be redeemed all,only jail it
+	if sa, err := i.GetStakingAccount(i.curEpochID, addr); err == nil {
+		if amount := sa.Unit.slash(i.curEpochID, params.SlashRateForDoubleSign); amount.Sign() > 0 {
+			burned[addr] = amount
+			record.Amount = record.Amount.Add(record.Amount, amount)
+		}
+		for _, da := range sa.Delegation {
+			daAddr := da.Unit.GetRewardAddress()
+			if amount := da.Unit.slash(i.curEpochID, params.SlashRateForDoubleSign); amount.Sign() > 0 {
+				if v, ok := burned[daAddr]; ok {
+					burned[daAddr] = v.Add(v, amount)
+				} else {
+					burned[daAddr] = amount
+				}
+				record.Amount = record.Amount.Add(record.Amount, amount)
+			}
+		}
+	}
+	i.slashes = append(i.slashes, record)
+	return burned, nil
+}
+
 // RedeemSAccount redeem amount of asset for staking account,it will locked for a certain time
 func (i *ImpawnImpl) RedeemSAccount(curHeight uint64, addr common.Address, amount *big.Int) error {
 	if amount.Sign() <= 0 || curHeight <= 0 {
@@ -1119,6 +1461,9 @@ func (i *ImpawnImpl) InsertSAccount2(height, effectHeight uint64, addr common.Ad
 	if err := types.ValidPk(pk); err != nil {
 		return err
 	}
+	if min := i.minImpawn(types.GetEpochFromHeight(height).EpochID); min != nil && val.Cmp(min) < 0 {
+		return types.ErrMinImpawn
+	}
 	if i.repeatPK(addr, pk) {
 		log.Error("Insert SA account repeat pk", "addr", addr.String(), "pk", pk)
 		return types.ErrRepeatPk
@@ -1384,7 +1729,9 @@ func (i *ImpawnImpl) Load(state StateDB, preAddress common.Address) error {
 	}
 	// log.Info("-----Load impawn---","len:",lenght,"count:",temp.Counts(),"cache",cache)
 	i.curEpochID, i.accounts, i.lastReward = temp.curEpochID, temp.accounts, temp.lastReward
-	return nil
+	i.slashes = temp.slashes
+	i.governance = NewGovernance()
+	return i.governance.Load(state, preAddress)
 }
 
 func GetCurrentValidators(state StateDB) []*types.CommitteeMember {
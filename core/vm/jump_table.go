@@ -51,11 +51,22 @@ type operation struct {
 var (
 	constantinopleInstructionSet = newConstantinopleInstructionSet()
 	yoloV1InstructionSet         = newYoloV1InstructionSet()
+	tip11InstructionSet          = newTIP11InstructionSet()
 )
 
 // JumpTable contains the EVM opcodes supported at a given fork.
 type JumpTable [256]*operation
 
+// newTIP11InstructionSet returns the yoloV1 instructions with the cold/warm
+// state access gas accounting of EIP-2929.
+func newTIP11InstructionSet() JumpTable {
+	instructionSet := newYoloV1InstructionSet()
+
+	enable2929(&instructionSet) // Access lists for trie accesses https://eips.ethereum.org/EIPS/eip-2929
+
+	return instructionSet
+}
+
 func newYoloV1InstructionSet() JumpTable {
 	instructionSet := newIstanbulInstructionSet()
 
@@ -0,0 +1,162 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
+)
+
+// receiptKey is the key of the receipt allowances in the state of the staking
+// address, the receipt balances are not saved as they follow the delegations.
+var receiptKey = common.BytesToHash([]byte("receipt"))
+
+// ReceiptBalance returns the receipt of the delegation from addrDA to addrSA,
+// it is the delegated amount which can be canceled in the current epoch.
+func (i *ImpawnImpl) ReceiptBalance(addrSA, addrDA common.Address) *big.Int {
+	sa, err := i.GetStakingAccount(i.curEpochID, addrSA)
+	if err != nil {
+		return big.NewInt(0)
+	}
+	da, _ := i.getDAfromSA(sa, addrDA)
+	if da == nil {
+		return big.NewInt(0)
+	}
+	return da.getValidStaking(types.GetEpochFromID(i.curEpochID).EndHeight)
+}
+
+// ReceiptSupply returns all receipts of the delegations to addrSA.
+func (i *ImpawnImpl) ReceiptSupply(addrSA common.Address) *big.Int {
+	all := big.NewInt(0)
+	sa, err := i.GetStakingAccount(i.curEpochID, addrSA)
+	if err != nil {
+		return all
+	}
+	end := types.GetEpochFromID(i.curEpochID).EndHeight
+	for _, da := range sa.Delegation {
+		all = all.Add(all, da.getValidStaking(end))
+	}
+	return all
+}
+
+func (i *ImpawnImpl) GetReceiptsRPC(addr common.Address) []map[string]interface{} {
+	attrs := make([]map[string]interface{}, 0)
+	sas, ok := i.accounts[i.curEpochID]
+	if !ok {
+		return attrs
+	}
+	for _, sa := range sas {
+		if da, _ := i.getDAfromSA(sa, addr); da != nil {
+			attr := make(map[string]interface{})
+			attr["holder"] = sa.Unit.GetRewardAddress().String()
+			attr["balance"] = weitoCoin(i.ReceiptBalance(sa.Unit.GetRewardAddress(), addr))
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+func (i *ImpawnImpl) GetReceiptSupplyRPC(addrSA common.Address) map[string]interface{} {
+	attr := make(map[string]interface{})
+	attr["holder"] = addrSA.String()
+	attr["supply"] = weitoCoin(i.ReceiptSupply(addrSA))
+	return attr
+}
+
+// ReceiptAllowance is the amount of the receipt of holder which spender can
+// transfer on behalf of owner
+type ReceiptAllowance struct {
+	Holder  common.Address // the staking account of the receipt
+	Owner   common.Address
+	Spender common.Address
+	Amount  *big.Int
+}
+
+// StakingReceipt keeps the allowances of the delegation receipts
+type StakingReceipt struct {
+	Allowances []*ReceiptAllowance
+}
+
+func NewStakingReceipt() *StakingReceipt {
+	return &StakingReceipt{}
+}
+
+func (r *StakingReceipt) getAllowance(holder, owner, spender common.Address) (*ReceiptAllowance, int) {
+	for i, v := range r.Allowances {
+		if bytes.Equal(v.Holder.Bytes(), holder.Bytes()) && bytes.Equal(v.Owner.Bytes(), owner.Bytes()) &&
+			bytes.Equal(v.Spender.Bytes(), spender.Bytes()) {
+			return v, i
+		}
+	}
+	return nil, -1
+}
+
+// Allowance returns the amount of the receipt of holder which spender can
+// transfer on behalf of owner.
+func (r *StakingReceipt) Allowance(holder, owner, spender common.Address) *big.Int {
+	if a, _ := r.getAllowance(holder, owner, spender); a != nil {
+		return new(big.Int).Set(a.Amount)
+	}
+	return big.NewInt(0)
+}
+
+// Approve sets the allowance of spender, a zero amount removes it.
+func (r *StakingReceipt) Approve(holder, owner, spender common.Address, amount *big.Int) {
+	a, pos := r.getAllowance(holder, owner, spender)
+	if amount.Sign() <= 0 {
+		if a != nil {
+			r.Allowances = append(r.Allowances[:pos], r.Allowances[pos+1:]...)
+		}
+		return
+	}
+	if a != nil {
+		a.Amount = new(big.Int).Set(amount)
+		return
+	}
+	r.Allowances = append(r.Allowances, &ReceiptAllowance{
+		Holder:  holder,
+		Owner:   owner,
+		Spender: spender,
+		Amount:  new(big.Int).Set(amount),
+	})
+}
+
+// Spend subtracts amount from the allowance of spender.
+func (r *StakingReceipt) Spend(holder, owner, spender common.Address, amount *big.Int) error {
+	allowance := r.Allowance(holder, owner, spender)
+	if allowance.Cmp(amount) < 0 {
+		return types.ErrReceiptAllowance
+	}
+	r.Approve(holder, owner, spender, allowance.Sub(allowance, amount))
+	return nil
+}
+
+func (r *StakingReceipt) Save(state StateDB, preAddress common.Address) error {
+	data, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		log.Crit("Failed to RLP encode StakingReceipt", "err", err)
+	}
+	state.SetPOSState(preAddress, receiptKey, data)
+	return err
+}
+
+// Load restores the allowances from the state, it is empty before the first approval.
+func (r *StakingReceipt) Load(state StateDB, preAddress common.Address) error {
+	data := state.GetPOSState(preAddress, receiptKey)
+	if len(data) == 0 {
+		r.Allowances = nil
+		return nil
+	}
+	var temp StakingReceipt
+	if err := rlp.DecodeBytes(data, &temp); err != nil {
+		log.Error("Invalid StakingReceipt entry RLP", "err", err)
+		return errors.New(fmt.Sprintf("Invalid StakingReceipt entry RLP %s", err.Error()))
+	}
+	r.Allowances = temp.Allowances
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
 )
 
 // StakingGas defines all method gas
@@ -40,6 +41,19 @@ var StakingGas = map[string]uint64{
 	"delegate":         1500000,
 	"undelegate":       1500000,
 	"withdrawDelegate": 1620000,
+	"submitEvidence":   2400000,
+	"setAutoCompound":  1500000,
+
+	"receiptBalanceOf":    360000,
+	"receiptTotalSupply":  360000,
+	"receiptAllowance":    30000,
+	"receiptTransfer":     1500000,
+	"receiptApprove":      60000,
+	"receiptTransferFrom": 1560000,
+
+	"propose":     2400000,
+	"vote":        2400000,
+	"getProposal": 360000,
 }
 
 // Staking contract ABI
@@ -64,6 +78,23 @@ func RunStaking(evm *EVM, contract *Contract, input []byte) (ret []byte, err err
 
 	data := input[4:]
 
+	if strings.HasPrefix(method.Name, "receipt") && !evm.ChainConfig().IsTIP12(evm.BlockNumber) {
+		log.Warn("Staking receipt before TIP12", "method", method.Name)
+		return nil, ErrExecutionReverted
+	}
+	if method.Name == "submitEvidence" && !evm.ChainConfig().IsTIP15(evm.BlockNumber) {
+		log.Warn("Staking evidence before TIP15")
+		return nil, ErrExecutionReverted
+	}
+	if method.Name == "setAutoCompound" && !evm.ChainConfig().IsTIP13(evm.BlockNumber) {
+		log.Warn("Staking auto compound before TIP13")
+		return nil, ErrExecutionReverted
+	}
+	if (method.Name == "propose" || method.Name == "vote" || method.Name == "getProposal") && !evm.ChainConfig().IsTIP14(evm.BlockNumber) {
+		log.Warn("Staking governance before TIP14", "method", method.Name)
+		return nil, ErrExecutionReverted
+	}
+
 	switch method.Name {
 	case "getDeposit":
 		ret, err = getDeposit(evm, contract, data)
@@ -89,6 +120,28 @@ func RunStaking(evm *EVM, contract *Contract, input []byte) (ret []byte, err err
 		ret, err = undelegate(evm, contract, data)
 	case "withdrawDelegate":
 		ret, err = withdrawDelegate(evm, contract, data)
+	case "submitEvidence":
+		ret, err = submitEvidence(evm, contract, data)
+	case "setAutoCompound":
+		ret, err = setAutoCompound(evm, contract, data)
+	case "receiptBalanceOf":
+		ret, err = receiptBalanceOf(evm, contract, data)
+	case "receiptTotalSupply":
+		ret, err = receiptTotalSupply(evm, contract, data)
+	case "receiptAllowance":
+		ret, err = receiptAllowance(evm, contract, data)
+	case "receiptTransfer":
+		ret, err = receiptTransfer(evm, contract, data)
+	case "receiptApprove":
+		ret, err = receiptApprove(evm, contract, data)
+	case "receiptTransferFrom":
+		ret, err = receiptTransferFrom(evm, contract, data)
+	case "propose":
+		ret, err = propose(evm, contract, data)
+	case "vote":
+		ret, err = vote(evm, contract, data)
+	case "getProposal":
+		ret, err = getProposal(evm, contract, data)
 	default:
 		log.Warn("Staking call fallback function")
 		err = ErrStakingInvalidInput
@@ -390,6 +443,11 @@ func delegate(evm *EVM, contract *Contract, input []byte) (ret []byte, err error
 		common.BytesToHash(args.Holder[:]),
 	}
 	logN(evm, contract, topics, logData)
+	if evm.ChainConfig().IsTIP12(evm.BlockNumber) {
+		if err = logReceiptTransfer(evm, contract, args.Holder, common.Address{}, from, args.Value); err != nil {
+			return nil, err
+		}
+	}
 	context := []interface{}{
 		"number", evm.Context.BlockNumber.Uint64(), "address", from, "holder", args.Holder, "value", args.Value,
 		"input", common.PrettyDuration(t1.Sub(t0)), "load", common.PrettyDuration(t2.Sub(t1)),
@@ -447,6 +505,11 @@ func undelegate(evm *EVM, contract *Contract, input []byte) (ret []byte, err err
 		common.BytesToHash(args.Holder[:]),
 	}
 	logN(evm, contract, topics, logData)
+	if evm.ChainConfig().IsTIP12(evm.BlockNumber) {
+		if err = logReceiptTransfer(evm, contract, args.Holder, from, common.Address{}, args.Value); err != nil {
+			return nil, err
+		}
+	}
 	return nil, nil
 }
 
@@ -602,37 +665,95 @@ func withdrawDelegate(evm *EVM, contract *Contract, input []byte) (ret []byte, e
 	return nil, nil
 }
 
-func getLocked(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
-	var depositAddr common.Address
+// setAutoCompound turns on or off staking the rewards of the delegation in
+// the next epoch
+func setAutoCompound(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Holder common.Address
+		Auto   bool
+	}{}
 
-	method, _ := abiStaking.Methods["lockedBalance"]
-	err = method.Inputs.Unpack(&depositAddr, input)
+	method, _ := abiStaking.Methods["setAutoCompound"]
+	err = method.Inputs.Unpack(&args, input)
 	if err != nil {
-		log.Error("Unpack get_deposit input error")
+		log.Error("Unpack set auto compound error", "err", err)
 		return nil, ErrStakingInvalidInput
 	}
+	from := contract.caller.Address()
 
-	locked := evm.StateDB.GetPOSLocked(depositAddr)
+	log.Info("Staking set auto compound", "number", evm.Context.BlockNumber.Uint64(), "address", from, "holder", args.Holder, "auto", args.Auto)
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+	err = impawn.SetDAccountCompound(evm.Context.BlockNumber.Uint64(), args.Holder, from, args.Auto)
+	if err != nil {
+		log.Error("Staking set auto compound error", "address", from, "holder", args.Holder, "err", err)
+		return nil, err
+	}
 
-	ret, err = method.Outputs.Pack(locked)
-	return ret, err
+	err = impawn.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking save state error", "error", err)
+		return nil, err
+	}
+
+	event := abiStaking.Events["AutoCompound"]
+	logData, err := event.Inputs.PackNonIndexed(args.Auto)
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(from[:]),
+		common.BytesToHash(args.Holder[:]),
+	}
+	logN(evm, contract, topics, logData)
+	return nil, nil
 }
 
-// getDeposit
-func getDeposit(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
-	var depositAddr common.Address
-	method, _ := abiStaking.Methods["getDeposit"]
-	var (
-		staked   = big.NewInt(0)
-		locked   = big.NewInt(0)
-		unlocked = big.NewInt(0)
-	)
+// submitEvidence slash the committee member who signed two different fast blocks at the same height
+func submitEvidence(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var data []byte
+	from := contract.caller.Address()
 
-	err = method.Inputs.Unpack(&depositAddr, input)
+	method, _ := abiStaking.Methods["submitEvidence"]
+	err = method.Inputs.Unpack(&data, input)
 	if err != nil {
-		log.Error("Unpack get_deposit input error")
+		log.Error("Unpack submit evidence input error")
 		return nil, ErrStakingInvalidInput
 	}
+	evidence := new(types.DoubleSignEvidence)
+	if err = rlp.DecodeBytes(data, evidence); err != nil {
+		log.Error("Decode evidence error", "error", err)
+		return nil, ErrStakingInvalidInput
+	}
+	signer, err := evidence.Verify()
+	if err != nil {
+		log.Error("Verify evidence error", "error", err)
+		return nil, err
+	}
+	height := evidence.Height()
+	if height >= evm.Context.BlockNumber.Uint64() {
+		log.Error("Evidence height over current height", "height", height, "number", evm.Context.BlockNumber)
+		return nil, types.ErrInvalidParam
+	}
+	var holder common.Address
+	e := types.GetEpochFromHeight(height)
+	members := GetValidatorsByEpoch(evm.StateDB, e.EpochID, height)
+	for _, m := range members {
+		if m.CommitteeBase == signer {
+			holder = m.Coinbase
+			break
+		}
+	}
+	if holder == (common.Address{}) {
+		log.Error("Evidence signer is not a committee member", "signer", signer, "epoch", e.EpochID)
+		return nil, types.ErrEvidenceNotCommittee
+	}
 
 	impawn := NewImpawnImpl()
 	err = impawn.Load(evm.StateDB, types.StakingAddress)
@@ -641,43 +762,218 @@ func getDeposit(evm *EVM, contract *Contract, input []byte) (ret []byte, err err
 		return nil, err
 	}
 
-	asset := impawn.GetAllCancelableAsset(depositAddr)
-	if stake, ok := asset[depositAddr]; ok {
-		staked.Add(staked, stake)
+	log.Info("Staking submit evidence", "number", evm.Context.BlockNumber.Uint64(), "address", from, "holder", holder, "height", height)
+	burned, err := impawn.SlashSAccount(evm.Context.BlockNumber.Uint64(), evidence.Hash(), holder, height)
+	if err != nil {
+		log.Error("Staking slash error", "holder", holder, "height", height, "err", err)
+		return nil, err
 	}
 
-	lockedAsset := impawn.GetLockedAsset2(depositAddr, evm.Context.BlockNumber.Uint64())
-	if stake, ok := lockedAsset[depositAddr]; ok {
-		for _, item := range stake.Value {
-			if item.Locked {
-				locked.Add(locked, item.Amount)
-			} else {
-				unlocked.Add(unlocked, item.Amount)
-			}
+	err = impawn.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking save state error", "error", err)
+		return nil, err
+	}
+	amount := big.NewInt(0)
+	for addr, value := range burned {
+		if locked := evm.StateDB.GetPOSLocked(addr); locked.Cmp(value) < 0 {
+			log.Error("Staking locked balance less than slashed", "address", addr, "locked", locked, "value", value)
+			value = locked
 		}
+		subLockedBalance(evm.StateDB, addr, value)
+		evm.StateDB.SubBalance(addr, value)
+		amount.Add(amount, value)
 	}
 
-	log.Info("Get staking get_deposit", "address", depositAddr, "staked", staked, "locked", locked, "unlocked", unlocked)
+	event := abiStaking.Events["Slash"]
+	logData, err := event.Inputs.PackNonIndexed(evidence.Hash(), amount)
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(from[:]),
+		common.BytesToHash(holder[:]),
+	}
+	logN(evm, contract, topics, logData)
+	return nil, nil
+}
 
-	ret, err = method.Outputs.Pack(staked, locked, unlocked)
-	return ret, err
+// logReceiptTransfer add the transfer event of the receipt of holder, the
+// zero from or to address means the receipt is minted or burned.
+func logReceiptTransfer(evm *EVM, contract *Contract, holder, from, to common.Address, value *big.Int) error {
+	event := abiStaking.Events["ReceiptTransfer"]
+	logData, err := event.Inputs.PackNonIndexed(value)
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(holder[:]),
+		common.BytesToHash(from[:]),
+		common.BytesToHash(to[:]),
+	}
+	logN(evm, contract, topics, logData)
+	return nil
 }
 
-func getDelegate(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+// transferReceipt moves the delegation to holder with its locked balance from
+// one address to another, the receiver can undelegate and withdraw it.
+func transferReceipt(evm *EVM, contract *Contract, holder, from, to common.Address, value *big.Int) error {
+	if evm.StateDB.GetPOSLocked(from).Cmp(value) < 0 {
+		log.Error("Staking balance insufficient", "address", from, "value", value)
+		return ErrStakingInsufficientBalance
+	}
+
+	impawn := NewImpawnImpl()
+	err := impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return err
+	}
+
+	log.Info("Staking receipt transfer", "number", evm.Context.BlockNumber.Uint64(), "holder", holder, "from", from, "to", to, "value", value)
+	err = impawn.TransferDAccount(evm.Context.BlockNumber.Uint64(), holder, from, to, value)
+	if err != nil {
+		log.Error("Staking receipt transfer error", "holder", holder, "from", from, "to", to, "value", value, "err", err)
+		return err
+	}
+
+	err = impawn.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking save state error", "error", err)
+		return err
+	}
+	if from != to {
+		subLockedBalance(evm.StateDB, from, value)
+		evm.StateDB.SubBalance(from, value)
+		evm.StateDB.AddBalance(to, value)
+		addLockedBalance(evm.StateDB, to, value)
+	}
+	return logReceiptTransfer(evm, contract, holder, from, to, value)
+}
+
+// receiptTransfer transfers the receipt of the caller's delegation to holder
+func receiptTransfer(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	args := struct {
-		Owner  common.Address
 		Holder common.Address
+		To     common.Address
+		Value  *big.Int
 	}{}
-	method, _ := abiStaking.Methods["getDelegate"]
-	var (
-		staked   = big.NewInt(0)
-		locked   = big.NewInt(0)
-		unlocked = big.NewInt(0)
-	)
 
+	method, _ := abiStaking.Methods["receiptTransfer"]
 	err = method.Inputs.Unpack(&args, input)
 	if err != nil {
-		log.Error("Unpack get_deposit input error")
+		log.Error("Unpack receipt transfer error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+	err = transferReceipt(evm, contract, args.Holder, contract.caller.Address(), args.To, args.Value)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(true)
+}
+
+// receiptTransferFrom transfers the receipt on behalf of the owner, the
+// caller must be approved by the owner.
+func receiptTransferFrom(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Holder common.Address
+		From   common.Address
+		To     common.Address
+		Value  *big.Int
+	}{}
+
+	method, _ := abiStaking.Methods["receiptTransferFrom"]
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack receipt transfer from error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+	spender := contract.caller.Address()
+
+	receipt := NewStakingReceipt()
+	err = receipt.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking receipt load error", "error", err)
+		return nil, err
+	}
+	if spender != args.From {
+		err = receipt.Spend(args.Holder, args.From, spender, args.Value)
+		if err != nil {
+			log.Error("Staking receipt allowance error", "holder", args.Holder, "owner", args.From, "spender", spender, "value", args.Value)
+			return nil, err
+		}
+		err = receipt.Save(evm.StateDB, types.StakingAddress)
+		if err != nil {
+			log.Error("Staking receipt save error", "error", err)
+			return nil, err
+		}
+	}
+	err = transferReceipt(evm, contract, args.Holder, args.From, args.To, args.Value)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(true)
+}
+
+// receiptApprove allows the spender to transfer the caller's receipt of holder
+func receiptApprove(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Holder  common.Address
+		Spender common.Address
+		Value   *big.Int
+	}{}
+
+	method, _ := abiStaking.Methods["receiptApprove"]
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack receipt approve error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+	from := contract.caller.Address()
+
+	receipt := NewStakingReceipt()
+	err = receipt.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking receipt load error", "error", err)
+		return nil, err
+	}
+	receipt.Approve(args.Holder, from, args.Spender, args.Value)
+	err = receipt.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking receipt save error", "error", err)
+		return nil, err
+	}
+
+	event := abiStaking.Events["ReceiptApproval"]
+	logData, err := event.Inputs.PackNonIndexed(args.Value)
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(args.Holder[:]),
+		common.BytesToHash(from[:]),
+		common.BytesToHash(args.Spender[:]),
+	}
+	logN(evm, contract, topics, logData)
+	return method.Outputs.Pack(true)
+}
+
+func receiptBalanceOf(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Holder common.Address
+		Owner  common.Address
+	}{}
+
+	method, _ := abiStaking.Methods["receiptBalanceOf"]
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack receipt balance error", "err", err)
 		return nil, ErrStakingInvalidInput
 	}
 
@@ -687,46 +983,294 @@ func getDelegate(evm *EVM, contract *Contract, input []byte) (ret []byte, err er
 		log.Error("Staking load error", "error", err)
 		return nil, err
 	}
+	return method.Outputs.Pack(impawn.ReceiptBalance(args.Holder, args.Owner))
+}
 
-	asset := impawn.GetAllCancelableAsset(args.Owner)
-	if stake, ok := asset[args.Holder]; ok {
-		staked.Add(staked, stake)
+func receiptTotalSupply(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var holder common.Address
+
+	method, _ := abiStaking.Methods["receiptTotalSupply"]
+	err = method.Inputs.Unpack(&holder, input)
+	if err != nil {
+		log.Error("Unpack receipt total supply error", "err", err)
+		return nil, ErrStakingInvalidInput
 	}
 
-	lockedAsset := impawn.GetLockedAsset2(args.Owner, evm.Context.BlockNumber.Uint64())
-	if stake, ok := lockedAsset[args.Holder]; ok {
-		for _, item := range stake.Value {
-			if item.Locked {
-				locked.Add(locked, item.Amount)
-			} else {
-				unlocked.Add(unlocked, item.Amount)
-			}
-		}
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
 	}
+	return method.Outputs.Pack(impawn.ReceiptSupply(holder))
+}
 
-	log.Info("Get staking get_delegate", "address", args.Owner, "holder", args.Holder,
-		"staked", staked, "locked", locked, "unlocked", unlocked)
+func receiptAllowance(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Holder  common.Address
+		Owner   common.Address
+		Spender common.Address
+	}{}
 
-	ret, err = method.Outputs.Pack(staked, locked, unlocked)
-	return ret, err
+	method, _ := abiStaking.Methods["receiptAllowance"]
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack receipt allowance error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+
+	receipt := NewStakingReceipt()
+	err = receipt.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking receipt load error", "error", err)
+		return nil, err
+	}
+	return method.Outputs.Pack(receipt.Allowance(args.Holder, args.Owner, args.Spender))
 }
 
-const StakeABIJSON = `
-[
-  {
-    "name": "Deposit",
-    "inputs": [
-      {
-        "type": "address",
-        "name": "from",
-        "indexed": true
-      },
-      {
-        "type": "bytes",
-        "name": "pubkey",
-        "indexed": false
-      },
-      {
+// propose makes a change of a governed parameter, the caller must be a
+// validator of the current epoch.
+func propose(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Kind    uint8
+		Value   *big.Int
+		Account common.Address
+	}{}
+
+	method, _ := abiStaking.Methods["propose"]
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack propose error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+	from := contract.caller.Address()
+
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+	proposal, err := impawn.governance.Propose(impawn, from, args.Kind, args.Value, args.Account)
+	if err != nil {
+		log.Error("Staking propose error", "address", from, "kind", args.Kind, "err", err)
+		return nil, err
+	}
+	err = impawn.governance.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking governance save error", "error", err)
+		return nil, err
+	}
+
+	event := abiStaking.Events["Propose"]
+	id := new(big.Int).SetUint64(proposal.ID)
+	logData, err := event.Inputs.PackNonIndexed(id, proposal.Kind, proposal.Value, proposal.Account)
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(from[:]),
+	}
+	logN(evm, contract, topics, logData)
+	log.Info("Staking propose", "number", evm.Context.BlockNumber.Uint64(), "address", from, "id", proposal.ID, "kind", proposal.Kind)
+	return method.Outputs.Pack(id)
+}
+
+// vote approves a proposal, the caller must be a validator of the current epoch.
+func vote(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var id *big.Int
+
+	method, _ := abiStaking.Methods["vote"]
+	err = method.Inputs.Unpack(&id, input)
+	if err != nil || !id.IsUint64() {
+		log.Error("Unpack vote error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+	from := contract.caller.Address()
+
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+	proposal, err := impawn.governance.Vote(impawn, from, id.Uint64())
+	if err != nil {
+		log.Error("Staking vote error", "address", from, "id", id, "err", err)
+		return nil, err
+	}
+	err = impawn.governance.Save(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking governance save error", "error", err)
+		return nil, err
+	}
+
+	event := abiStaking.Events["Vote"]
+	logData, err := event.Inputs.PackNonIndexed(id, new(big.Int).SetUint64(proposal.Activation))
+	if err != nil {
+		log.Error("Pack staking log error", "error", err)
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BytesToHash(from[:]),
+	}
+	logN(evm, contract, topics, logData)
+	log.Info("Staking vote", "number", evm.Context.BlockNumber.Uint64(), "address", from, "id", id, "activation", proposal.Activation)
+	return nil, nil
+}
+
+func getProposal(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var id *big.Int
+
+	method, _ := abiStaking.Methods["getProposal"]
+	err = method.Inputs.Unpack(&id, input)
+	if err != nil || !id.IsUint64() {
+		log.Error("Unpack get proposal error", "err", err)
+		return nil, ErrStakingInvalidInput
+	}
+
+	governance := NewGovernance()
+	err = governance.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking governance load error", "error", err)
+		return nil, err
+	}
+	proposal := governance.GetProposal(id.Uint64())
+	if proposal == nil {
+		return method.Outputs.Pack(uint8(0), big.NewInt(0), common.Address{}, big.NewInt(0), big.NewInt(0))
+	}
+	return method.Outputs.Pack(proposal.Kind, proposal.Value, proposal.Account,
+		big.NewInt(int64(len(proposal.Votes))), new(big.Int).SetUint64(proposal.Activation))
+}
+
+func getLocked(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var depositAddr common.Address
+
+	method, _ := abiStaking.Methods["lockedBalance"]
+	err = method.Inputs.Unpack(&depositAddr, input)
+	if err != nil {
+		log.Error("Unpack get_deposit input error")
+		return nil, ErrStakingInvalidInput
+	}
+
+	locked := evm.StateDB.GetPOSLocked(depositAddr)
+
+	ret, err = method.Outputs.Pack(locked)
+	return ret, err
+}
+
+// getDeposit
+func getDeposit(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	var depositAddr common.Address
+	method, _ := abiStaking.Methods["getDeposit"]
+	var (
+		staked   = big.NewInt(0)
+		locked   = big.NewInt(0)
+		unlocked = big.NewInt(0)
+	)
+
+	err = method.Inputs.Unpack(&depositAddr, input)
+	if err != nil {
+		log.Error("Unpack get_deposit input error")
+		return nil, ErrStakingInvalidInput
+	}
+
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	asset := impawn.GetAllCancelableAsset(depositAddr)
+	if stake, ok := asset[depositAddr]; ok {
+		staked.Add(staked, stake)
+	}
+
+	lockedAsset := impawn.GetLockedAsset2(depositAddr, evm.Context.BlockNumber.Uint64())
+	if stake, ok := lockedAsset[depositAddr]; ok {
+		for _, item := range stake.Value {
+			if item.Locked {
+				locked.Add(locked, item.Amount)
+			} else {
+				unlocked.Add(unlocked, item.Amount)
+			}
+		}
+	}
+
+	log.Info("Get staking get_deposit", "address", depositAddr, "staked", staked, "locked", locked, "unlocked", unlocked)
+
+	ret, err = method.Outputs.Pack(staked, locked, unlocked)
+	return ret, err
+}
+
+func getDelegate(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		Owner  common.Address
+		Holder common.Address
+	}{}
+	method, _ := abiStaking.Methods["getDelegate"]
+	var (
+		staked   = big.NewInt(0)
+		locked   = big.NewInt(0)
+		unlocked = big.NewInt(0)
+	)
+
+	err = method.Inputs.Unpack(&args, input)
+	if err != nil {
+		log.Error("Unpack get_deposit input error")
+		return nil, ErrStakingInvalidInput
+	}
+
+	impawn := NewImpawnImpl()
+	err = impawn.Load(evm.StateDB, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	asset := impawn.GetAllCancelableAsset(args.Owner)
+	if stake, ok := asset[args.Holder]; ok {
+		staked.Add(staked, stake)
+	}
+
+	lockedAsset := impawn.GetLockedAsset2(args.Owner, evm.Context.BlockNumber.Uint64())
+	if stake, ok := lockedAsset[args.Holder]; ok {
+		for _, item := range stake.Value {
+			if item.Locked {
+				locked.Add(locked, item.Amount)
+			} else {
+				unlocked.Add(unlocked, item.Amount)
+			}
+		}
+	}
+
+	log.Info("Get staking get_delegate", "address", args.Owner, "holder", args.Holder,
+		"staked", staked, "locked", locked, "unlocked", unlocked)
+
+	ret, err = method.Outputs.Pack(staked, locked, unlocked)
+	return ret, err
+}
+
+const StakeABIJSON = `
+[
+  {
+    "name": "Deposit",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "bytes",
+        "name": "pubkey",
+        "indexed": false
+      },
+      {
         "type": "uint256",
         "name": "value",
         "indexed": false
@@ -824,13 +1368,140 @@ const StakeABIJSON = `
     "type": "event"
   },
   {
-    "name": "Withdraw",
+    "name": "Withdraw",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "uint256",
+        "name": "value",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "Append",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "uint256",
+        "name": "value",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "SetFee",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "uint256",
+        "name": "fee",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "SetPubkey",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "bytes",
+        "name": "pubkey",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "Slash",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "address",
+        "name": "holder",
+        "indexed": true
+      },
+      {
+        "type": "bytes32",
+        "name": "evidence",
+        "indexed": false
+      },
+      {
+        "type": "uint256",
+        "name": "value",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "AutoCompound",
+    "inputs": [
+      {
+        "type": "address",
+        "name": "from",
+        "indexed": true
+      },
+      {
+        "type": "address",
+        "name": "holder",
+        "indexed": true
+      },
+      {
+        "type": "bool",
+        "name": "auto",
+        "indexed": false
+      }
+    ],
+    "anonymous": false,
+    "type": "event"
+  },
+  {
+    "name": "ReceiptTransfer",
     "inputs": [
+      {
+        "type": "address",
+        "name": "holder",
+        "indexed": true
+      },
       {
         "type": "address",
         "name": "from",
         "indexed": true
       },
+      {
+        "type": "address",
+        "name": "to",
+        "indexed": true
+      },
       {
         "type": "uint256",
         "name": "value",
@@ -841,11 +1512,21 @@ const StakeABIJSON = `
     "type": "event"
   },
   {
-    "name": "Append",
+    "name": "ReceiptApproval",
     "inputs": [
       {
         "type": "address",
-        "name": "from",
+        "name": "holder",
+        "indexed": true
+      },
+      {
+        "type": "address",
+        "name": "owner",
+        "indexed": true
+      },
+      {
+        "type": "address",
+        "name": "spender",
         "indexed": true
       },
       {
@@ -858,7 +1539,7 @@ const StakeABIJSON = `
     "type": "event"
   },
   {
-    "name": "SetFee",
+    "name": "Propose",
     "inputs": [
       {
         "type": "address",
@@ -867,7 +1548,22 @@ const StakeABIJSON = `
       },
       {
         "type": "uint256",
-        "name": "fee",
+        "name": "id",
+        "indexed": false
+      },
+      {
+        "type": "uint8",
+        "name": "kind",
+        "indexed": false
+      },
+      {
+        "type": "uint256",
+        "name": "value",
+        "indexed": false
+      },
+      {
+        "type": "address",
+        "name": "account",
         "indexed": false
       }
     ],
@@ -875,7 +1571,7 @@ const StakeABIJSON = `
     "type": "event"
   },
   {
-    "name": "SetPubkey",
+    "name": "Vote",
     "inputs": [
       {
         "type": "address",
@@ -883,8 +1579,13 @@ const StakeABIJSON = `
         "indexed": true
       },
       {
-        "type": "bytes",
-        "name": "pubkey",
+        "type": "uint256",
+        "name": "id",
+        "indexed": false
+      },
+      {
+        "type": "uint256",
+        "name": "activation",
         "indexed": false
       }
     ],
@@ -1111,6 +1812,257 @@ const StakeABIJSON = `
     "constant": false,
     "payable": false,
     "type": "function"
+  },
+  {
+    "name": "submitEvidence",
+    "outputs": [],
+    "inputs": [
+      {
+        "type": "bytes",
+        "name": "evidence"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "setAutoCompound",
+    "outputs": [],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "bool",
+        "name": "auto"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptBalanceOf",
+    "outputs": [
+      {
+        "type": "uint256",
+        "name": "balance"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "address",
+        "name": "owner"
+      }
+    ],
+    "constant": true,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptTotalSupply",
+    "outputs": [
+      {
+        "type": "uint256",
+        "name": "supply"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      }
+    ],
+    "constant": true,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptAllowance",
+    "outputs": [
+      {
+        "type": "uint256",
+        "name": "remaining"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "address",
+        "name": "owner"
+      },
+      {
+        "type": "address",
+        "name": "spender"
+      }
+    ],
+    "constant": true,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptTransfer",
+    "outputs": [
+      {
+        "type": "bool",
+        "name": "success"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "address",
+        "name": "to"
+      },
+      {
+        "type": "uint256",
+        "name": "value"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptApprove",
+    "outputs": [
+      {
+        "type": "bool",
+        "name": "success"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "address",
+        "name": "spender"
+      },
+      {
+        "type": "uint256",
+        "name": "value"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "receiptTransferFrom",
+    "outputs": [
+      {
+        "type": "bool",
+        "name": "success"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "address",
+        "name": "holder"
+      },
+      {
+        "type": "address",
+        "name": "from"
+      },
+      {
+        "type": "address",
+        "name": "to"
+      },
+      {
+        "type": "uint256",
+        "name": "value"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "propose",
+    "outputs": [
+      {
+        "type": "uint256",
+        "name": "id"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "uint8",
+        "name": "kind"
+      },
+      {
+        "type": "uint256",
+        "name": "value"
+      },
+      {
+        "type": "address",
+        "name": "account"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "vote",
+    "outputs": [],
+    "inputs": [
+      {
+        "type": "uint256",
+        "name": "id"
+      }
+    ],
+    "constant": false,
+    "payable": false,
+    "type": "function"
+  },
+  {
+    "name": "getProposal",
+    "outputs": [
+      {
+        "type": "uint8",
+        "name": "kind"
+      },
+      {
+        "type": "uint256",
+        "name": "value"
+      },
+      {
+        "type": "address",
+        "name": "account"
+      },
+      {
+        "type": "uint256",
+        "name": "votes"
+      },
+      {
+        "type": "uint256",
+        "name": "activation"
+      }
+    ],
+    "inputs": [
+      {
+        "type": "uint256",
+        "name": "id"
+      }
+    ],
+    "constant": true,
+    "payable": false,
+    "type": "function"
   }
 ]
 `
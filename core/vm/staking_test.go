@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"bytes"
+	"crypto/ecdsa"
 	"math/big"
 	"testing"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/log"
 	"github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rlp"
 )
 
 func TestDeposit(t *testing.T) {
@@ -34,3 +37,415 @@ func TestDeposit(t *testing.T) {
 	impawn1 := NewImpawnImpl()
 	impawn1.Load(evm.StateDB, types.StakingAddress)
 }
+
+func makeDoubleSignEvidence(t *testing.T, key *ecdsa.PrivateKey, height uint64) []byte {
+	signs := make([]*types.PbftSign, 2)
+	for i := range signs {
+		signs[i] = &types.PbftSign{
+			FastHeight: new(big.Int).SetUint64(height),
+			FastHash:   common.BytesToHash([]byte{byte(i + 1)}),
+			Result:     types.VoteAgree,
+		}
+		sign, err := crypto.Sign(signs[i].HashWithNoSign().Bytes(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signs[i].Sign = sign
+	}
+	data, err := rlp.EncodeToBytes(types.NewDoubleSignEvidence(signs[0], signs[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// stakingTestEnv is a state with a validator elected for the first epoch
+// and a delegation to it, both locked in the staking.
+type stakingTestEnv struct {
+	statedb *state.StateDB
+	voteKey *ecdsa.PrivateKey
+	saAddr  common.Address
+	daAddr  common.Address
+	saValue *big.Int
+	daValue *big.Int
+}
+
+func newStakingTestEnv(t *testing.T) *stakingTestEnv {
+	voteKey, _ := crypto.GenerateKey()
+	saKey, _ := crypto.GenerateKey()
+	daKey, _ := crypto.GenerateKey()
+	env := &stakingTestEnv{
+		voteKey: voteKey,
+		saAddr:  crypto.PubkeyToAddress(saKey.PublicKey),
+		daAddr:  crypto.PubkeyToAddress(daKey.PublicKey),
+		saValue: new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18)),
+		daValue: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+	}
+	env.statedb, _ = state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	env.statedb.GetOrNewStateObject(types.StakingAddress)
+	env.statedb.AddBalance(env.saAddr, env.saValue)
+	addLockedBalance(env.statedb, env.saAddr, env.saValue)
+	env.statedb.AddBalance(env.daAddr, env.daValue)
+	addLockedBalance(env.statedb, env.daAddr, env.daValue)
+
+	impawn := NewImpawnImpl()
+	pub := crypto.FromECDSAPub(&voteKey.PublicKey)
+	if err := impawn.InsertSAccount2(0, 0, env.saAddr, pub, env.saValue, big.NewInt(50), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := impawn.InsertDAccount2(0, env.saAddr, env.daAddr, env.daValue); err != nil {
+		t.Fatal(err)
+	}
+	if committee, _ := impawn.DoElections(1, 0); len(committee) != 1 {
+		t.Fatalf("committee mismatch: have %d, want 1", len(committee))
+	}
+	if err := impawn.Shift(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	impawn.Save(env.statedb, types.StakingAddress)
+	return env
+}
+
+// call runs the staking method from the account with the chain config.
+func (env *stakingTestEnv) call(config *params.ChainConfig, from common.Address, method string, args ...interface{}) error {
+	evm := NewEVM(Context{BlockNumber: big.NewInt(200)}, env.statedb, config, Config{})
+	contract := NewContract(AccountRef(from), AccountRef(types.StakingAddress), big.NewInt(0), StakingGas[method])
+	input, err := abiStaking.Pack(method, args...)
+	if err != nil {
+		return err
+	}
+	_, err = RunStaking(evm, contract, input)
+	return err
+}
+
+func TestSubmitEvidence(t *testing.T) {
+	env := newStakingTestEnv(t)
+	evidence := makeDoubleSignEvidence(t, env.voteKey, 100)
+
+	// the evidences are not slashed before TIP15
+	if err := env.call(params.TestChainConfig, common.Address{1}, "submitEvidence", evidence); err == nil {
+		t.Fatal("evidence submitted before TIP15")
+	}
+	if locked := env.statedb.GetPOSLocked(env.saAddr); locked.Cmp(env.saValue) != 0 {
+		t.Fatalf("slashed before TIP15: have %v, want %v", locked, env.saValue)
+	}
+
+	config := *params.TestChainConfig
+	config.TIP15 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	if err := env.call(&config, common.Address{1}, "submitEvidence", evidence); err != nil {
+		t.Fatalf("submit evidence failed: %v", err)
+	}
+
+	rate := new(big.Int).SetUint64(params.SlashRateForDoubleSign)
+	for addr, value := range map[common.Address]*big.Int{env.saAddr: env.saValue, env.daAddr: env.daValue} {
+		left := new(big.Int).Sub(value, new(big.Int).Quo(new(big.Int).Mul(value, rate), types.Base))
+		if locked := env.statedb.GetPOSLocked(addr); locked.Cmp(left) != 0 {
+			t.Errorf("locked mismatch of %x: have %v, want %v", addr, locked, left)
+		}
+		if balance := env.statedb.GetBalance(addr); balance.Cmp(left) != 0 {
+			t.Errorf("balance mismatch of %x: have %v, want %v", addr, balance, left)
+		}
+	}
+
+	impawn := NewImpawnImpl()
+	impawn.Load(env.statedb, types.StakingAddress)
+	if len(impawn.slashes) != 1 || impawn.slashes[0].Address != env.saAddr {
+		t.Fatalf("slash record mismatch: %v", impawn.slashes)
+	}
+	// the same double sign can't be slashed again
+	if err := env.call(&config, common.Address{1}, "submitEvidence", evidence); err == nil {
+		t.Fatal("submit the repeated evidence")
+	}
+	// the jailed account can't be elected
+	cur := types.GetEpochFromID(1)
+	if committee, _ := impawn.DoElections(2, cur.EndHeight-params.ElectionPoint); len(committee) != 0 {
+		t.Fatalf("jailed account was elected")
+	}
+}
+
+func TestReceiptTransfer(t *testing.T) {
+	env := newStakingTestEnv(t)
+	saAddr, daAddr, daValue := env.saAddr, env.daAddr, env.daValue
+	to, spender := common.Address{0x11}, common.Address{0x22}
+	half := new(big.Int).Div(daValue, big.NewInt(2))
+
+	// the receipts are not available before TIP12
+	if err := env.call(params.TestChainConfig, daAddr, "receiptTransfer", saAddr, to, half); err == nil {
+		t.Fatal("receipt transferred before TIP12")
+	}
+
+	config := *params.TestChainConfig
+	config.TIP12 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	if err := env.call(&config, daAddr, "receiptTransfer", saAddr, to, half); err != nil {
+		t.Fatalf("receipt transfer failed: %v", err)
+	}
+	// approve the spender to move the other half to the same receiver
+	if err := env.call(&config, daAddr, "receiptApprove", saAddr, spender, half); err != nil {
+		t.Fatalf("receipt approve failed: %v", err)
+	}
+	if err := env.call(&config, spender, "receiptTransferFrom", saAddr, daAddr, to, new(big.Int).Add(half, big.NewInt(1))); err == nil {
+		t.Fatal("receipt transferred over the allowance")
+	}
+	if err := env.call(&config, spender, "receiptTransferFrom", saAddr, daAddr, to, half); err != nil {
+		t.Fatalf("receipt transfer from failed: %v", err)
+	}
+
+	impawn := NewImpawnImpl()
+	impawn.Load(env.statedb, types.StakingAddress)
+	if balance := impawn.ReceiptBalance(saAddr, daAddr); balance.Sign() != 0 {
+		t.Errorf("sender receipt mismatch: have %v, want 0", balance)
+	}
+	if balance := impawn.ReceiptBalance(saAddr, to); balance.Cmp(daValue) != 0 {
+		t.Errorf("receiver receipt mismatch: have %v, want %v", balance, daValue)
+	}
+	if supply := impawn.ReceiptSupply(saAddr); supply.Cmp(daValue) != 0 {
+		t.Errorf("receipt supply mismatch: have %v, want %v", supply, daValue)
+	}
+	if locked := env.statedb.GetPOSLocked(to); locked.Cmp(daValue) != 0 {
+		t.Errorf("receiver locked mismatch: have %v, want %v", locked, daValue)
+	}
+	if locked := env.statedb.GetPOSLocked(daAddr); locked.Sign() != 0 {
+		t.Errorf("sender locked mismatch: have %v, want 0", locked)
+	}
+	receipt := NewStakingReceipt()
+	receipt.Load(env.statedb, types.StakingAddress)
+	if allowance := receipt.Allowance(saAddr, daAddr, spender); allowance.Sign() != 0 {
+		t.Errorf("allowance mismatch: have %v, want 0", allowance)
+	}
+	// the receiver holds the delegation now and can undelegate it
+	if err := env.call(&config, to, "undelegate", saAddr, daValue); err != nil {
+		t.Fatalf("undelegate the received receipt failed: %v", err)
+	}
+	impawn.Load(env.statedb, types.StakingAddress)
+	if balance := impawn.ReceiptBalance(saAddr, to); balance.Sign() != 0 {
+		t.Errorf("receipt not burned: have %v, want 0", balance)
+	}
+}
+
+func TestAutoCompound(t *testing.T) {
+	env := newStakingTestEnv(t)
+	saAddr, daAddr := env.saAddr, env.daAddr
+	reward := new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+
+	config := *params.TestChainConfig
+	config.TIP13 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	if err := env.call(&config, daAddr, "setAutoCompound", saAddr, true); err != nil {
+		t.Fatalf("set auto compound failed: %v", err)
+	}
+
+	impawn := NewImpawnImpl()
+	impawn.Load(env.statedb, types.StakingAddress)
+	env.statedb.AddBalance(daAddr, reward)
+	impawn.CompoundRewards(env.statedb, []*types.SARewardInfos{{
+		Items: []*types.RewardInfo{
+			{Address: saAddr, Amount: big.NewInt(1)},
+			{Address: daAddr, Amount: reward},
+		},
+	}})
+	all := new(big.Int).Add(env.daValue, reward)
+	if locked := env.statedb.GetPOSLocked(daAddr); locked.Cmp(all) != 0 {
+		t.Errorf("locked mismatch: have %v, want %v", locked, all)
+	}
+	impawn.Save(env.statedb, types.StakingAddress)
+	impawn = NewImpawnImpl()
+	impawn.Load(env.statedb, types.StakingAddress)
+
+	cur := types.GetEpochFromID(1)
+	impawn.DoElections(2, cur.EndHeight-params.ElectionPoint)
+	if err := impawn.Shift(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	next := types.GetEpochFromID(2)
+	sa, _ := impawn.GetStakingAccount(2, saAddr)
+	da := sa.getDA(daAddr)
+	if staking := da.getAllStaking(next.BeginHeight); staking.Cmp(all) != 0 {
+		t.Errorf("staking mismatch: have %v, want %v", staking, all)
+	}
+	if !da.isAutoCompound() || da.compound().Pending.Sign() != 0 {
+		t.Errorf("compound mismatch: auto %v, pending %v", da.isAutoCompound(), da.compound().Pending)
+	}
+}
+
+// Tests that the delegation without the auto compound setting keeps the
+// encoding before TIP13.
+func TestDelegationAccountRLP(t *testing.T) {
+	da := &DelegationAccount{
+		SaAddress: common.Address{1},
+		Unit: &impawnUnit{
+			Address:    common.Address{2},
+			Value:      []*PairstakingValue{{Amount: big.NewInt(100), Height: big.NewInt(1), State: types.StateStakingAuto}},
+			RedeemInof: []*RedeemItem{},
+		},
+	}
+	legacy := struct {
+		SaAddress common.Address
+		Unit      *impawnUnit
+	}{da.SaAddress, da.Unit}
+	have, _ := rlp.EncodeToBytes(da)
+	want, _ := rlp.EncodeToBytes(legacy)
+	if !bytes.Equal(have, want) {
+		t.Fatalf("encoding mismatch: have %x, want %x", have, want)
+	}
+	var dec DelegationAccount
+	if err := rlp.DecodeBytes(want, &dec); err != nil || dec.compound() != nil {
+		t.Fatalf("decode legacy delegation failed: %v", err)
+	}
+}
+
+// pinEpochParams sets the epoch parameters which other tests in the package
+// change, the returned function restores them.
+func pinEpochParams() func() {
+	fork, length, point, redeem := params.DposForkPoint, params.NewEpochLength, params.ElectionPoint, params.MaxRedeemHeight
+	params.DposForkPoint, params.NewEpochLength, params.ElectionPoint, params.MaxRedeemHeight = 0, 25000, 200, 250000
+	return func() {
+		params.DposForkPoint, params.NewEpochLength, params.ElectionPoint, params.MaxRedeemHeight = fork, length, point, redeem
+	}
+}
+
+func TestGovernance(t *testing.T) {
+	defer pinEpochParams()()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	statedb.GetOrNewStateObject(types.StakingAddress)
+
+	var (
+		validators []common.Address
+		amount     = new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18))
+		forbidden  = common.Address{0x33}
+		impawn     = NewImpawnImpl()
+	)
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		if err := impawn.InsertSAccount2(0, 0, addr, crypto.FromECDSAPub(&key.PublicKey), amount, big.NewInt(50), true); err != nil {
+			t.Fatal(err)
+		}
+		validators = append(validators, addr)
+	}
+	impawn.DoElections(1, 0)
+	if err := impawn.Shift(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	impawn.Save(statedb, types.StakingAddress)
+
+	// the governance is not available before TIP14
+	evm := NewEVM(Context{BlockNumber: big.NewInt(200)}, statedb, params.TestChainConfig, Config{})
+	contract := NewContract(AccountRef(validators[0]), AccountRef(types.StakingAddress), big.NewInt(0), StakingGas["propose"])
+	input, _ := abiStaking.Pack("propose", GovCommitteeSize, big.NewInt(int64(params.MinimumCommitteeNumber)), common.Address{})
+	if _, err := RunStaking(evm, contract, input); err == nil {
+		t.Fatal("proposal made before TIP14")
+	}
+
+	config := *params.TestChainConfig
+	config.TIP14 = &params.BlockConfig{FastNumber: big.NewInt(0)}
+	evm = NewEVM(Context{BlockNumber: big.NewInt(200)}, statedb, &config, Config{})
+	propose := func(from common.Address, kind uint8, value *big.Int, account common.Address) error {
+		contract := NewContract(AccountRef(from), AccountRef(types.StakingAddress), big.NewInt(0), StakingGas["propose"])
+		input, _ := abiStaking.Pack("propose", kind, value, account)
+		_, err := RunStaking(evm, contract, input)
+		return err
+	}
+	vote := func(from common.Address, id int64) error {
+		contract := NewContract(AccountRef(from), AccountRef(types.StakingAddress), big.NewInt(0), StakingGas["vote"])
+		input, _ := abiStaking.Pack("vote", big.NewInt(id))
+		_, err := RunStaking(evm, contract, input)
+		return err
+	}
+	if err := propose(common.Address{0x44}, GovCommitteeSize, big.NewInt(int64(params.MinimumCommitteeNumber)), common.Address{}); err == nil {
+		t.Fatal("proposal made by a non validator")
+	}
+	if err := propose(validators[0], GovCommitteeSize, big.NewInt(1), common.Address{}); err == nil {
+		t.Fatal("invalid committee size proposed")
+	}
+	minimum := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	proposals := []struct {
+		kind    uint8
+		value   *big.Int
+		account common.Address
+	}{
+		{GovCommitteeSize, big.NewInt(int64(params.MinimumCommitteeNumber)), common.Address{}},
+		{GovMinImpawn, minimum, common.Address{}},
+		{GovForbidAddress, big.NewInt(0), forbidden},
+	}
+	for i, p := range proposals {
+		if err := propose(validators[0], p.kind, p.value, p.account); err != nil {
+			t.Fatalf("proposal %d failed: %v", i+1, err)
+		}
+		// the quorum is not reached with two of the three validators
+		for _, v := range validators[:2] {
+			if err := vote(v, int64(i+1)); err != nil {
+				t.Fatalf("vote of proposal %d failed: %v", i+1, err)
+			}
+		}
+		g := NewGovernance()
+		g.Load(statedb, types.StakingAddress)
+		if g.GetProposal(uint64(i + 1)).approved() {
+			t.Fatalf("proposal %d approved without the quorum", i+1)
+		}
+		if err := vote(validators[2], int64(i+1)); err != nil {
+			t.Fatalf("vote of proposal %d failed: %v", i+1, err)
+		}
+	}
+	if err := vote(validators[2], 1); err == nil {
+		t.Error("approved proposal voted again")
+	}
+	// the open proposals of a validator are limited
+	for i := 0; i < params.GovernanceProposalLimit; i++ {
+		if err := propose(validators[1], GovMixEpochCount, big.NewInt(int64(types.MixEpochCount+i)), common.Address{}); err != nil {
+			t.Fatalf("proposal %d failed: %v", i, err)
+		}
+	}
+	if err := propose(validators[1], GovMixEpochCount, big.NewInt(int64(types.MixEpochCount)), common.Address{}); err == nil {
+		t.Error("proposals over the limit")
+	}
+
+	// the proposals take effect after the time lock
+	activation := 1 + params.GovernanceTimeLock
+	impawn = NewImpawnImpl()
+	impawn.Load(statedb, types.StakingAddress)
+	if size := impawn.committeeSize(activation - 1); size != params.CountInEpoch {
+		t.Errorf("committee size before activation mismatch: have %d, want %d", size, params.CountInEpoch)
+	}
+	if size := impawn.committeeSize(activation); size != params.MinimumCommitteeNumber {
+		t.Errorf("committee size mismatch: have %d, want %d", size, params.MinimumCommitteeNumber)
+	}
+	key, _ := crypto.GenerateKey()
+	height := types.GetEpochFromID(activation).BeginHeight
+	if err := impawn.InsertSAccount2(height, 0, crypto.PubkeyToAddress(key.PublicKey), crypto.FromECDSAPub(&key.PublicKey), big.NewInt(1), big.NewInt(50), true); err != types.ErrMinImpawn {
+		t.Errorf("deposit under the minimum mismatch: have %v, want %v", err, types.ErrMinImpawn)
+	}
+	if forbid, ok := GovernedForbidden(statedb, forbidden, height-1); ok || forbid {
+		t.Errorf("account forbidden before activation")
+	}
+	if forbid, ok := GovernedForbidden(statedb, forbidden, height); !ok || !forbid {
+		t.Errorf("account not forbidden after activation")
+	}
+}
+
+func TestGovernancePrune(t *testing.T) {
+	allowed, forbidden := common.Address{0x55}, common.Address{0x66}
+	g := &Governance{NextID: 6, Proposals: []*Proposal{
+		{ID: 1, Kind: GovCommitteeSize, Value: big.NewInt(10), EpochID: 1, Activation: 3},
+		{ID: 2, Kind: GovCommitteeSize, Value: big.NewInt(12), EpochID: 2, Activation: 4},
+		{ID: 3, Kind: GovMinImpawn, Value: big.NewInt(1), EpochID: 2},
+		{ID: 4, Kind: GovAllowAddress, Value: big.NewInt(0), Account: allowed, EpochID: 1, Activation: 3},
+		{ID: 5, Kind: GovForbidAddress, Value: big.NewInt(0), Account: forbidden, EpochID: 1, Activation: 3},
+	}}
+	g.prune(4)
+	var ids []uint64
+	for _, p := range g.Proposals {
+		ids = append(ids, p.ID)
+	}
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 5 {
+		t.Fatalf("pruned proposals mismatch: have %v, want [2 5]", ids)
+	}
+	if size := g.Value(GovCommitteeSize, 4); size == nil || size.Int64() != 12 {
+		t.Errorf("committee size mismatch: have %v, want 12", size)
+	}
+	if forbid, ok := g.Forbidden(forbidden, 4); !ok || !forbid {
+		t.Errorf("account not forbidden after pruning")
+	}
+	if _, ok := g.Forbidden(allowed, 4); ok {
+		t.Errorf("allowed account is governed after pruning")
+	}
+}
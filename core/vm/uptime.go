@@ -0,0 +1,168 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
+)
+
+// uptimeKey is the key of the validator uptime in the state of the staking address,
+// it is saved apart from the ImpawnImpl because it changes in every block.
+var uptimeKey = common.BytesToHash([]byte("uptime"))
+
+// uptimeEpochs is the count of the recent epochs kept in the state
+const uptimeEpochs = 2
+
+// MemberUptime is the count of the blocks signed by a committee member
+type MemberUptime struct {
+	Address       common.Address // the staking account
+	CommitteeBase common.Address // the address of the vote pubkey
+	Signed        uint64
+}
+
+// EpochUptime is the signing participation of the committee in an epoch
+type EpochUptime struct {
+	EpochID uint64
+	Blocks  uint64 // count of the blocks which signs were recorded
+	Members []*MemberUptime
+}
+
+func (e *EpochUptime) getMember(base common.Address) *MemberUptime {
+	for _, m := range e.Members {
+		if m.CommitteeBase == base {
+			return m
+		}
+	}
+	return nil
+}
+
+// uptime returns the part of signed blocks of the member based on types.Base
+func (e *EpochUptime) uptime(m *MemberUptime) uint64 {
+	if e.Blocks == 0 {
+		return types.Base.Uint64()
+	}
+	return m.Signed * types.Base.Uint64() / e.Blocks
+}
+
+// ValidatorUptime tracks the PbftSigns of the committee members in the recent epochs
+type ValidatorUptime struct {
+	Epochs []*EpochUptime
+}
+
+func NewValidatorUptime() *ValidatorUptime {
+	return &ValidatorUptime{}
+}
+
+func (u *ValidatorUptime) getEpoch(epochid uint64) *EpochUptime {
+	for _, e := range u.Epochs {
+		if e.EpochID == epochid {
+			return e
+		}
+	}
+	return nil
+}
+
+// Record counts the signs of the fast block at height for the committee members
+// of its epoch. The signs must be the committed ones, every member is counted
+// once at most.
+func (u *ValidatorUptime) Record(state StateDB, height uint64, signs []*types.PbftSign) {
+	epoch := types.GetEpochFromHeight(height)
+	e := u.getEpoch(epoch.EpochID)
+	if e == nil {
+		e = &EpochUptime{EpochID: epoch.EpochID}
+		for _, m := range GetValidatorsByEpoch(state, epoch.EpochID, height) {
+			e.Members = append(e.Members, &MemberUptime{
+				Address:       m.Coinbase,
+				CommitteeBase: m.CommitteeBase,
+			})
+		}
+		u.Epochs = append(u.Epochs, e)
+		if len(u.Epochs) > uptimeEpochs {
+			u.Epochs = u.Epochs[len(u.Epochs)-uptimeEpochs:]
+		}
+	}
+	e.Blocks++
+	signed := make(map[common.Address]bool)
+	for _, sign := range signs {
+		if sign.FastHeight == nil || sign.FastHeight.Uint64() != height {
+			continue
+		}
+		pubkey, err := crypto.SigToPub(sign.HashWithNoSign().Bytes(), sign.Sign)
+		if err != nil {
+			log.Warn("Uptime invalid sign", "height", height, "err", err)
+			continue
+		}
+		base := crypto.PubkeyToAddress(*pubkey)
+		if m := e.getMember(base); m != nil && !signed[base] {
+			m.Signed++
+			signed[base] = true
+		}
+	}
+}
+
+// OfflineMembers returns the staking accounts which signed less than threshold
+// (based on types.Base) of the blocks in the epoch.
+func (u *ValidatorUptime) OfflineMembers(epochid, threshold uint64) []common.Address {
+	e := u.getEpoch(epochid)
+	if e == nil || e.Blocks == 0 {
+		return nil
+	}
+	var addrs []common.Address
+	for _, m := range e.Members {
+		if e.uptime(m) < threshold {
+			addrs = append(addrs, m.Address)
+		}
+	}
+	return addrs
+}
+
+func (u *ValidatorUptime) Save(state StateDB, preAddress common.Address) error {
+	data, err := rlp.EncodeToBytes(u)
+	if err != nil {
+		log.Crit("Failed to RLP encode ValidatorUptime", "err", err)
+	}
+	state.SetPOSState(preAddress, uptimeKey, data)
+	return err
+}
+
+// Load restores the uptime from the state, it is empty before the first record.
+func (u *ValidatorUptime) Load(state StateDB, preAddress common.Address) error {
+	data := state.GetPOSState(preAddress, uptimeKey)
+	if len(data) == 0 {
+		u.Epochs = nil
+		return nil
+	}
+	var temp ValidatorUptime
+	if err := rlp.DecodeBytes(data, &temp); err != nil {
+		log.Error("Invalid ValidatorUptime entry RLP", "err", err)
+		return errors.New(fmt.Sprintf("Invalid ValidatorUptime entry RLP %s", err.Error()))
+	}
+	u.Epochs = temp.Epochs
+	return nil
+}
+
+func (u *ValidatorUptime) GetUptimeRPC() []map[string]interface{} {
+	attrs := make([]map[string]interface{}, 0)
+	for _, e := range u.Epochs {
+		attr := make(map[string]interface{})
+		attr["epochID"] = e.EpochID
+		attr["blocks"] = e.Blocks
+		var members []map[string]interface{}
+		for _, m := range e.Members {
+			member := make(map[string]interface{})
+			member["address"] = m.Address.String()
+			member["committeeBase"] = m.CommitteeBase.String()
+			member["signed"] = m.Signed
+			member["uptime"] = float64(e.uptime(m)) / float64(types.Base.Uint64())
+			members = append(members, member)
+		}
+		attr["members"] = members
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
@@ -0,0 +1,101 @@
+package vm
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/params"
+)
+
+func makeSign(t *testing.T, key *ecdsa.PrivateKey, height uint64) *types.PbftSign {
+	sign := &types.PbftSign{
+		FastHeight: new(big.Int).SetUint64(height),
+		FastHash:   common.BigToHash(new(big.Int).SetUint64(height)),
+		Result:     types.VoteAgree,
+	}
+	var err error
+	if sign.Sign, err = crypto.Sign(sign.HashWithNoSign().Bytes(), key); err != nil {
+		t.Fatal(err)
+	}
+	return sign
+}
+
+func TestValidatorUptime(t *testing.T) {
+	// other tests in the package move the epoch boundaries, pin them here
+	defer func(fork, length uint64) {
+		params.DposForkPoint, params.NewEpochLength = fork, length
+	}(params.DposForkPoint, params.NewEpochLength)
+	params.DposForkPoint, params.NewEpochLength = 0, 25000
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	statedb.GetOrNewStateObject(types.StakingAddress)
+
+	var (
+		keys   []*ecdsa.PrivateKey
+		addrs  []common.Address
+		amount = new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18))
+		impawn = NewImpawnImpl()
+	)
+	for i := 0; i < 2; i++ {
+		voteKey, _ := crypto.GenerateKey()
+		saKey, _ := crypto.GenerateKey()
+		addr := crypto.PubkeyToAddress(saKey.PublicKey)
+		if err := impawn.InsertSAccount2(0, 0, addr, crypto.FromECDSAPub(&voteKey.PublicKey), amount, big.NewInt(50), true); err != nil {
+			t.Fatal(err)
+		}
+		keys, addrs = append(keys, voteKey), append(addrs, addr)
+	}
+	impawn.DoElections(1, 0)
+	impawn.Shift(1, 0)
+	impawn.Save(statedb, types.StakingAddress)
+
+	// the first member signs every block, the second one signs a quarter
+	uptime := NewValidatorUptime()
+	for h := uint64(1); h <= 100; h++ {
+		signs := []*types.PbftSign{makeSign(t, keys[0], h), makeSign(t, keys[0], h)}
+		if h%4 == 0 {
+			signs = append(signs, makeSign(t, keys[1], h))
+		}
+		uptime.Record(statedb, h, signs)
+	}
+	uptime.Save(statedb, types.StakingAddress)
+
+	uptime = NewValidatorUptime()
+	if err := uptime.Load(statedb, types.StakingAddress); err != nil {
+		t.Fatal(err)
+	}
+	e := uptime.getEpoch(1)
+	if e == nil || e.Blocks != 100 || len(e.Members) != 2 {
+		t.Fatalf("epoch uptime mismatch: %v", e)
+	}
+	if m := e.getMember(crypto.PubkeyToAddress(keys[0].PublicKey)); m == nil || m.Signed != 100 {
+		t.Fatalf("signed blocks mismatch: %v", m)
+	}
+	offline := uptime.OfflineMembers(1, params.TestChainConfig.MinUptimeForElection())
+	if len(offline) != 1 || offline[0] != addrs[1] {
+		t.Fatalf("offline members mismatch: have %v, want %v", offline, addrs[1:])
+	}
+	// a network may lower the uptime the members need, the second one signed 25%
+	config := *params.TestChainConfig
+	config.MinUptime = 2000
+	if lax := uptime.OfflineMembers(1, config.MinUptimeForElection()); len(lax) != 0 {
+		t.Fatalf("offline members mismatch: have %v, want none", lax)
+	}
+
+	impawn = NewImpawnImpl()
+	impawn.Load(statedb, types.StakingAddress)
+	cur := types.GetEpochFromID(1)
+	committee, err := impawn.DoElections2(2, cur.EndHeight-params.ElectionPoint, offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(committee) != 1 || committee[0].Unit.GetRewardAddress() != addrs[0] {
+		t.Fatalf("offline member was elected")
+	}
+}
@@ -0,0 +1,1232 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to the fast and snail chain data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/core/rawdb"
+	snaildb "github.com/iceming123/go-ice/core/snailchain/rawdb"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/rlp"
+	"github.com/iceming123/go-ice/rpc"
+)
+
+var (
+	errBlockNotFound = errors.New("block not found")
+	errStateNotFound = errors.New("state not found")
+)
+
+// Backend is the chain access the resolvers need, it is implemented by
+// ice.ICEAPIBackend.
+type Backend interface {
+	ProtocolVersion() int
+	SuggestPrice(ctx context.Context) (*big.Int, error)
+	ChainDb() icedb.Database
+	CurrentBlock() *types.Block
+	CurrentSnailBlock() *types.SnailBlock
+	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error)
+	GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error)
+	SnailBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.SnailBlock, error)
+	GetSnailBlock(ctx context.Context, hash common.Hash) (*types.SnailBlock, error)
+	GetFruit(ctx context.Context, fastblockHash common.Hash) (*types.SnailBlock, error)
+	GetTd(blockHash common.Hash) *big.Int
+	StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error)
+	GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error)
+	GetCommitteeMembers(fastNumber *big.Int) []*types.CommitteeMember
+	GetPoolTransaction(hash common.Hash) *types.Transaction
+	SendTx(ctx context.Context, signedTx *types.Transaction) error
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *hexutil.Uint64
+}
+
+// NumberOr returns the provided block number argument, or the "current" block
+// number if none was provided.
+func (a BlockNumberArgs) NumberOr(current rpc.BlockNumber) rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return current
+}
+
+// Account represents an Icechain account at a particular fast block.
+type Account struct {
+	backend     Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the StateDB object for an account.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, *types.Header, error) {
+	statedb, header, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if statedb == nil {
+		return nil, nil, errStateNotFound
+	}
+	return statedb, header, nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*statedb.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(statedb.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(statedb.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	statedb, _, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return statedb.GetState(a.address, args.Slot), nil
+}
+
+func (a *Account) Staking(ctx context.Context) (*StakingAccount, error) {
+	statedb, header, err := a.getState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := loadStakingAccounts(statedb, header)
+	if err != nil {
+		return nil, err
+	}
+	for _, account := range accounts {
+		if account.account.Unit.Address == a.address {
+			return account, nil
+		}
+	}
+	return nil, nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents an Icechain transaction.
+// backend and hash are mandatory; all others will be fetched when required.
+type Transaction struct {
+	backend Backend
+	hash    common.Hash
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		tx, blockHash, _, index := rawdb.ReadTransaction(t.backend.ChainDb(), t.hash)
+		if tx != nil {
+			t.tx = tx
+			t.block = &Block{
+				backend: t.backend,
+				hash:    blockHash,
+			}
+			t.index = index
+		} else {
+			t.tx = t.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Fee(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.Fee() == nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(tx.Fee()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     *to,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.NewTIP1Signer(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     from,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}, nil
+}
+
+func (t *Transaction) Payer(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	payer := tx.Payer()
+	if payer == nil {
+		return nil, nil
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     *payer,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil || uint64(len(receipts)) <= t.index {
+		return nil, err
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.Status)
+	return &ret, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.GasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*hexutil.Uint64, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := hexutil.Uint64(receipt.CumulativeGasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     receipt.ContractAddress,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			backend:     t.backend,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+// Block represents a fast block. backend, and either num or hash are
+// mandatory. All other fields are lazily fetched when required.
+type Block struct {
+	backend  Backend
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	header   *types.Header
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	if b.hash != (common.Hash{}) {
+		b.block, err = b.backend.GetBlock(ctx, b.hash)
+	} else {
+		b.block, err = b.backend.BlockByNumber(ctx, *b.num)
+	}
+	if b.block != nil {
+		b.header = b.block.Header()
+		if b.hash == (common.Hash{}) {
+			b.hash = b.block.Hash()
+		}
+	}
+	return b.block, err
+}
+
+// resolveHeader returns the internal Header object for this block, fetching it
+// if necessary. Call this function instead of `resolve` unless you need the
+// additional data (transactions).
+func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
+	if b.header == nil {
+		var err error
+		if b.hash != (common.Hash{}) {
+			b.header, err = b.backend.HeaderByHash(ctx, b.hash)
+		} else {
+			b.header, err = b.backend.HeaderByNumber(ctx, *b.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.header == nil {
+			return nil, errBlockNotFound
+		}
+		if b.hash == (common.Hash{}) {
+			b.hash = b.header.Hash()
+		}
+	}
+	return b.header, nil
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.receipts == nil {
+		hash := b.hash
+		if hash == (common.Hash{}) {
+			header, err := b.resolveHeader(ctx)
+			if err != nil {
+				return nil, err
+			}
+			hash = header.Hash()
+		}
+		receipts, err := b.backend.GetReceipts(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = []*types.Receipt(receipts)
+	}
+	return b.receipts, nil
+}
+
+// numberOr returns the number of the block as a state selector.
+func (b *Block) numberOr(ctx context.Context) (rpc.BlockNumber, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return rpc.BlockNumber(header.Number.Uint64()), nil
+}
+
+func (b *Block) Number(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.Number.Uint64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if b.hash == (common.Hash{}) {
+		if _, err := b.resolveHeader(ctx); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	return b.hash, nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasLimit), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.GasUsed), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.Number.Uint64() == 0 {
+		return nil, nil
+	}
+	return &Block{
+		backend: b.backend,
+		hash:    header.ParentHash,
+	}, nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+func (b *Block) CommitteeRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.CommitteeHash, nil
+}
+
+func (b *Block) Proposer(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     header.Proposer,
+		blockNumber: args.NumberOr(rpc.LatestBlockNumber),
+	}, nil
+}
+
+func (b *Block) SnailHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.SnailHash, nil
+}
+
+func (b *Block) SnailNumber(ctx context.Context) (hexutil.Uint64, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(header.SnailNumber.Uint64()), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Time), nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Extra), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Bloom.Bytes()), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	count := int32(len(block.Transactions()))
+	return &count, err
+}
+
+func (b *Block) Transactions(ctx context.Context) (*[]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			backend: b.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+			block:   b,
+			index:   uint64(i),
+		})
+	}
+	return &ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		backend: b.backend,
+		hash:    tx.Hash(),
+		tx:      tx,
+		block:   b,
+		index:   uint64(args.Index),
+	}, nil
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.numberOr(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     args.Address,
+		blockNumber: number,
+	}, nil
+}
+
+func (b *Block) Fruit(ctx context.Context) (*Fruit, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fruit, err := b.backend.GetFruit(ctx, hash)
+	if err != nil || fruit == nil {
+		return nil, err
+	}
+	return &Fruit{backend: b.backend, fruit: fruit}, nil
+}
+
+func (b *Block) Committee(ctx context.Context) ([]*CommitteeMember, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := b.backend.GetCommitteeMembers(header.Number)
+	ret := make([]*CommitteeMember, 0, len(members))
+	for _, member := range members {
+		ret = append(ret, &CommitteeMember{member: member})
+	}
+	return ret, nil
+}
+
+// SnailBlock represents a snail block. backend, and either num or hash are
+// mandatory. The block is fetched when required.
+type SnailBlock struct {
+	backend Backend
+	num     *rpc.BlockNumber
+	hash    common.Hash
+	block   *types.SnailBlock
+}
+
+// resolve returns the internal snail block, fetching it if necessary.
+func (b *SnailBlock) resolve(ctx context.Context) (*types.SnailBlock, error) {
+	if b.block == nil {
+		var err error
+		if b.hash != (common.Hash{}) {
+			b.block, err = b.backend.GetSnailBlock(ctx, b.hash)
+		} else {
+			b.block, err = b.backend.SnailBlockByNumber(ctx, *b.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.block == nil {
+			return nil, errBlockNotFound
+		}
+		b.hash = b.block.Hash()
+	}
+	return b.block, nil
+}
+
+func (b *SnailBlock) Number(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.NumberU64()), nil
+}
+
+func (b *SnailBlock) Hash(ctx context.Context) (common.Hash, error) {
+	if _, err := b.resolve(ctx); err != nil {
+		return common.Hash{}, err
+	}
+	return b.hash, nil
+}
+
+func (b *SnailBlock) Parent(ctx context.Context) (*SnailBlock, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if block.NumberU64() == 0 {
+		return nil, nil
+	}
+	return &SnailBlock{
+		backend: b.backend,
+		hash:    block.ParentHash(),
+	}, nil
+}
+
+func (b *SnailBlock) Coinbase(ctx context.Context) (common.Address, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return block.Coinbase(), nil
+}
+
+func (b *SnailBlock) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.BlockDifficulty()), nil
+}
+
+func (b *SnailBlock) FruitDifficulty(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.FruitDifficulty()), nil
+}
+
+func (b *SnailBlock) TotalDifficulty(ctx context.Context) (*hexutil.Big, error) {
+	if _, err := b.resolve(ctx); err != nil {
+		return nil, err
+	}
+	td := b.backend.GetTd(b.hash)
+	if td == nil {
+		return nil, nil
+	}
+	return (*hexutil.Big)(td), nil
+}
+
+func (b *SnailBlock) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*block.Time()), nil
+}
+
+func (b *SnailBlock) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	nonce := block.Header().Nonce
+	return hexutil.Bytes(nonce[:]), nil
+}
+
+func (b *SnailBlock) MixHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.MixDigest(), nil
+}
+
+func (b *SnailBlock) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(block.Extra()), nil
+}
+
+func (b *SnailBlock) PointerHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.PointerHash(), nil
+}
+
+func (b *SnailBlock) PointerNumber(ctx context.Context) (hexutil.Uint64, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(block.PointNumber().Uint64()), nil
+}
+
+func (b *SnailBlock) FruitsHash(ctx context.Context) (common.Hash, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.FruitsHash(), nil
+}
+
+func (b *SnailBlock) FruitCount(ctx context.Context) (*int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	count := int32(len(block.Fruits()))
+	return &count, nil
+}
+
+func (b *SnailBlock) Fruits(ctx context.Context) (*[]*Fruit, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Fruit, 0, len(block.Fruits()))
+	for _, fruit := range block.Fruits() {
+		ret = append(ret, &Fruit{
+			backend: b.backend,
+			fruit:   fruit,
+			snail:   b,
+		})
+	}
+	return &ret, nil
+}
+
+// Fruit represents a fruit of a fast block, along with the snail block which
+// carries it if known.
+type Fruit struct {
+	backend Backend
+	fruit   *types.SnailBlock
+	snail   *SnailBlock
+}
+
+func (f *Fruit) Hash(ctx context.Context) common.Hash {
+	return f.fruit.Hash()
+}
+
+func (f *Fruit) FastNumber(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(f.fruit.FastNumber().Uint64())
+}
+
+func (f *Fruit) FastHash(ctx context.Context) common.Hash {
+	return f.fruit.FastHash()
+}
+
+func (f *Fruit) FastBlock(ctx context.Context) *Block {
+	return &Block{
+		backend: f.backend,
+		hash:    f.fruit.FastHash(),
+	}
+}
+
+func (f *Fruit) SnailBlock(ctx context.Context) *SnailBlock {
+	if f.snail == nil {
+		hash, _, _ := snaildb.ReadFtLookupEntry(f.backend.ChainDb(), f.fruit.FastHash())
+		if hash == (common.Hash{}) {
+			return nil
+		}
+		f.snail = &SnailBlock{backend: f.backend, hash: hash}
+	}
+	return f.snail
+}
+
+func (f *Fruit) PointerHash(ctx context.Context) common.Hash {
+	return f.fruit.PointerHash()
+}
+
+func (f *Fruit) PointerNumber(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(f.fruit.PointNumber().Uint64())
+}
+
+func (f *Fruit) Coinbase(ctx context.Context) common.Address {
+	return f.fruit.Coinbase()
+}
+
+func (f *Fruit) Difficulty(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*f.fruit.FruitDifficulty())
+}
+
+func (f *Fruit) Timestamp(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*f.fruit.Time())
+}
+
+func (f *Fruit) Nonce(ctx context.Context) hexutil.Bytes {
+	nonce := f.fruit.Header().Nonce
+	return hexutil.Bytes(nonce[:])
+}
+
+func (f *Fruit) MixHash(ctx context.Context) common.Hash {
+	return f.fruit.MixDigest()
+}
+
+// CommitteeMember represents a member of the committee running the fast chain.
+type CommitteeMember struct {
+	member *types.CommitteeMember
+}
+
+func (m *CommitteeMember) Coinbase(ctx context.Context) common.Address {
+	return m.member.Coinbase
+}
+
+func (m *CommitteeMember) CommitteeBase(ctx context.Context) common.Address {
+	return m.member.CommitteeBase
+}
+
+func (m *CommitteeMember) PublicKey(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(m.member.Publickey)
+}
+
+func (m *CommitteeMember) Flag(ctx context.Context) int32 {
+	return int32(m.member.Flag)
+}
+
+func (m *CommitteeMember) Type(ctx context.Context) int32 {
+	return int32(m.member.MType)
+}
+
+// Delegation represents an amount delegated to a staking account.
+type Delegation struct {
+	account *vm.DelegationAccount
+	height  uint64
+}
+
+func (d *Delegation) Address(ctx context.Context) common.Address {
+	return d.account.Unit.Address
+}
+
+func (d *Delegation) Staking(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*d.account.StakingAmount(d.height))
+}
+
+func (d *Delegation) ValidStaking(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*d.account.ValidStakingAmount(d.height))
+}
+
+// StakingAccount represents an impawn staking account at a fast block.
+type StakingAccount struct {
+	account   *vm.StakingAccount
+	height    uint64
+	committee bool
+}
+
+// loadStakingAccounts retrieves the staking accounts from the impawn state.
+func loadStakingAccounts(statedb *state.StateDB, header *types.Header) ([]*StakingAccount, error) {
+	impawn := vm.NewImpawnImpl()
+	if err := impawn.Load(statedb, types.StakingAddress); err != nil {
+		return nil, err
+	}
+	var (
+		height   = header.Number.Uint64()
+		accounts []*StakingAccount
+	)
+	for _, account := range impawn.GetAllStakingAccount() {
+		accounts = append(accounts, &StakingAccount{
+			account:   account,
+			height:    height,
+			committee: impawn.IsCommitteeMember(account.Unit.Address),
+		})
+	}
+	return accounts, nil
+}
+
+func (s *StakingAccount) Address(ctx context.Context) common.Address {
+	return s.account.Unit.Address
+}
+
+func (s *StakingAccount) VotePubKey(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(s.account.Votepubkey)
+}
+
+func (s *StakingAccount) Fee(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*s.account.Fee)
+}
+
+func (s *StakingAccount) Committee(ctx context.Context) bool {
+	return s.committee
+}
+
+func (s *StakingAccount) Staking(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*s.account.StakingAmount(s.height))
+}
+
+func (s *StakingAccount) ValidStaking(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*s.account.ValidStakingAmount(s.height))
+}
+
+func (s *StakingAccount) Delegations(ctx context.Context) []*Delegation {
+	ret := make([]*Delegation, 0, len(s.account.Delegation))
+	for _, account := range s.account.Delegation {
+		ret = append(ret, &Delegation{account: account, height: s.height})
+	}
+	return ret
+}
+
+// Resolver is the top level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	if args.Number != nil {
+		num := rpc.BlockNumber(uint64(*args.Number))
+		block = &Block{
+			backend: r.backend,
+			num:     &num,
+		}
+	} else if args.Hash != nil {
+		block = &Block{
+			backend: r.backend,
+			hash:    *args.Hash,
+		}
+	} else {
+		num := rpc.LatestBlockNumber
+		block = &Block{
+			backend: r.backend,
+			num:     &num,
+		}
+	}
+	// Resolve the header, return nil if it doesn't exist.
+	// Note we don't resolve block directly here since it will require an
+	// additional network request for light client.
+	h, err := block.resolveHeader(ctx)
+	if err == errBlockNotFound || h == nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	from := rpc.BlockNumber(args.From)
+
+	var to rpc.BlockNumber
+	if args.To != nil {
+		to = rpc.BlockNumber(*args.To)
+	} else {
+		to = rpc.BlockNumber(r.backend.CurrentBlock().Number().Int64())
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		num := i
+		block := &Block{backend: r.backend, num: &num}
+		if _, err := block.resolveHeader(ctx); err != nil {
+			if err == errBlockNotFound {
+				break
+			}
+			return nil, err
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) SnailBlock(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*SnailBlock, error) {
+	var block *SnailBlock
+	if args.Number != nil {
+		num := rpc.BlockNumber(uint64(*args.Number))
+		block = &SnailBlock{backend: r.backend, num: &num}
+	} else if args.Hash != nil {
+		block = &SnailBlock{backend: r.backend, hash: *args.Hash}
+	} else {
+		num := rpc.LatestBlockNumber
+		block = &SnailBlock{backend: r.backend, num: &num}
+	}
+	if _, err := block.resolve(ctx); err != nil {
+		if err == errBlockNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) SnailBlocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*SnailBlock, error) {
+	from := rpc.BlockNumber(args.From)
+
+	var to rpc.BlockNumber
+	if args.To != nil {
+		to = rpc.BlockNumber(*args.To)
+	} else {
+		to = rpc.BlockNumber(r.backend.CurrentSnailBlock().NumberU64())
+	}
+	if to < from {
+		return []*SnailBlock{}, nil
+	}
+	ret := make([]*SnailBlock, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		num := i
+		block := &SnailBlock{backend: r.backend, num: &num}
+		if _, err := block.resolve(ctx); err != nil {
+			if err == errBlockNotFound {
+				break
+			}
+			return nil, err
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		backend: r.backend,
+		hash:    args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if err != nil {
+		return nil, err
+	} else if t == nil {
+		return nil, nil
+	}
+	return tx, nil
+}
+
+func (r *Resolver) StakingAccount(ctx context.Context, args struct {
+	Address common.Address
+	Block   *hexutil.Uint64
+}) (*StakingAccount, error) {
+	account := &Account{
+		backend:     r.backend,
+		address:     args.Address,
+		blockNumber: BlockNumberArgs{Block: args.Block}.NumberOr(rpc.LatestBlockNumber),
+	}
+	return account.Staking(ctx)
+}
+
+func (r *Resolver) StakingAccounts(ctx context.Context, args BlockNumberArgs) ([]*StakingAccount, error) {
+	statedb, header, err := r.backend.StateAndHeaderByNumber(ctx, args.NumberOr(rpc.LatestBlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if statedb == nil {
+		return nil, errStateNotFound
+	}
+	accounts, err := loadStakingAccounts(statedb, header)
+	if err != nil {
+		return nil, err
+	}
+	if accounts == nil {
+		accounts = []*StakingAccount{}
+	}
+	return accounts, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
+
+func (r *Resolver) SendRawTransaction(ctx context.Context, args struct{ Data hexutil.Bytes }) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(args.Data, tx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := r.backend.SendTx(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
@@ -0,0 +1,294 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/consensus/minerva"
+	"github.com/iceming123/go-ice/core"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/node"
+	"github.com/iceming123/go-ice/params"
+	"github.com/iceming123/go-ice/rpc"
+)
+
+// Tests that the schema matches the resolvers and the service is mountable.
+func TestBuildSchema(t *testing.T) {
+	service, err := New(nil)
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	var _ node.HTTPService = service
+	if _, ok := service.HTTPHandlers()["/graphql"]; !ok {
+		t.Fatalf("graphql handler not exported")
+	}
+}
+
+// testBackend serves the fast chain of a local blockchain, it knows no snail
+// chain and has an empty transaction pool.
+type testBackend struct {
+	chain *core.BlockChain
+	db    icedb.Database
+}
+
+func (b *testBackend) ProtocolVersion() int { return 1 }
+func (b *testBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei), nil
+}
+func (b *testBackend) ChainDb() icedb.Database              { return b.db }
+func (b *testBackend) CurrentBlock() *types.Block           { return b.chain.CurrentBlock() }
+func (b *testBackend) CurrentSnailBlock() *types.SnailBlock { return nil }
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock().Header(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+func (b *testBackend) SnailBlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.SnailBlock, error) {
+	return nil, nil
+}
+func (b *testBackend) GetSnailBlock(ctx context.Context, hash common.Hash) (*types.SnailBlock, error) {
+	return nil, nil
+}
+func (b *testBackend) GetFruit(ctx context.Context, hash common.Hash) (*types.SnailBlock, error) {
+	return nil, nil
+}
+func (b *testBackend) GetTd(hash common.Hash) *big.Int { return nil }
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	if number := rawdb.ReadHeaderNumber(b.db, hash); number != nil {
+		return rawdb.ReadReceipts(b.db, hash, *number), nil
+	}
+	return nil, nil
+}
+func (b *testBackend) GetCommitteeMembers(number *big.Int) []*types.CommitteeMember { return nil }
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction       { return nil }
+func (b *testBackend) SendTx(ctx context.Context, tx *types.Transaction) error      { return nil }
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	testTo      = common.HexToAddress("0xdeadbeef")
+	// logCode creates a contract whose constructor emits one log with the
+	// topic 0xaa and 42 as the data.
+	logCode = common.Hex2Bytes("602a60005260aa60206000a100")
+)
+
+// newTestBackend creates a chain of two blocks, the first one transfers
+// some value and creates a contract which emits a log.
+func newTestBackend(t *testing.T) (*testBackend, []*types.Block) {
+	config := *params.TestChainConfig
+	config.TIP9 = &params.BlockConfig{FastNumber: big.NewInt(0), SnailNumber: big.NewInt(0)}
+	gspec := &core.Genesis{
+		Config: &config,
+		Alloc:  types.GenesisAlloc{testAddr: {Balance: testBalance}},
+	}
+	signer := types.NewTIP1Signer(config.ChainID)
+	engine := minerva.NewFaker()
+	genDb := icedb.NewMemDatabase()
+	genesis := gspec.MustFastCommit(genDb)
+	blocks, _ := core.GenerateChain(&config, genesis, engine, genDb, 2, func(i int, b *core.BlockGen) {
+		if i != 0 {
+			return
+		}
+		price := big.NewInt(params.GWei)
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(testAddr), testTo, big.NewInt(100), params.TxGas, price, nil), signer, testKey)
+		b.AddTx(tx)
+		tx, _ = types.SignTx(types.NewContractCreation(b.TxNonce(testAddr), big.NewInt(0), 100000, price, logCode), signer, testKey)
+		b.AddTx(tx)
+	})
+	db := icedb.NewMemDatabase()
+	gspec.MustFastCommit(db)
+	chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{chain: chain, db: db}, blocks
+}
+
+// query posts the query to the graphql handler and decodes the data of the
+// response into result.
+func query(t *testing.T, service *Service, q string, result interface{}) {
+	body, _ := json.Marshal(map[string]string{"query": q})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+	service.HTTPHandlers()["/graphql"].ServeHTTP(rec, req)
+
+	var resp struct {
+		Data   json.RawMessage
+		Errors []struct{ Message string }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query %s failed: %v", q, resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, result); err != nil {
+		t.Fatalf("invalid data %s: %v", resp.Data, err)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	backend, blocks := newTestBackend(t)
+	service, err := New(backend)
+	if err != nil {
+		t.Fatalf("could not create graphql service: %v", err)
+	}
+	block := blocks[0]
+	transfer, create := block.Transactions()[0], block.Transactions()[1]
+
+	// block by number with its transactions
+	var blockResult struct {
+		Block struct {
+			Number           string
+			Hash             common.Hash
+			Parent           struct{ Hash common.Hash }
+			GasUsed          string
+			TransactionCount int
+			Transactions     []struct{ Hash common.Hash }
+		}
+	}
+	query(t, service, `{ block(number: 1) { number hash parent { hash } gasUsed transactionCount transactions { hash } } }`, &blockResult)
+	b := blockResult.Block
+	if b.Number != "0x1" || b.Hash != block.Hash() || b.Parent.Hash != block.ParentHash() {
+		t.Errorf("block mismatch: have %v, want number 1 hash %x", b, block.Hash())
+	}
+	if b.GasUsed != hexUint(block.GasUsed()) {
+		t.Errorf("gas used mismatch: have %s, want %d", b.GasUsed, block.GasUsed())
+	}
+	if b.TransactionCount != 2 || len(b.Transactions) != 2 || b.Transactions[0].Hash != transfer.Hash() || b.Transactions[1].Hash != create.Hash() {
+		t.Errorf("transactions mismatch: have %v", b.Transactions)
+	}
+
+	// account at a block, before and after the transfer
+	var accountResult struct {
+		Before struct {
+			Account struct{ Balance, TransactionCount string }
+		}
+		After struct {
+			Account struct{ Balance, TransactionCount string }
+		}
+	}
+	query(t, service, `{
+		before: block(number: 0) { account(address: "`+testTo.Hex()+`") { balance transactionCount } }
+		after: block(number: 1) { account(address: "`+testAddr.Hex()+`") { balance transactionCount } }
+	}`, &accountResult)
+	if balance := accountResult.Before.Account.Balance; balance != "0x0" {
+		t.Errorf("balance before the transfer mismatch: have %s, want 0x0", balance)
+	}
+	statedb, _ := backend.chain.StateAt(block.Root())
+	if balance := accountResult.After.Account.Balance; balance != "0x"+statedb.GetBalance(testAddr).Text(16) {
+		t.Errorf("balance mismatch: have %s, want %v", balance, statedb.GetBalance(testAddr))
+	}
+	if nonce := accountResult.After.Account.TransactionCount; nonce != "0x2" {
+		t.Errorf("transaction count mismatch: have %s, want 0x2", nonce)
+	}
+
+	// transaction by hash with its receipt fields
+	var txResult struct {
+		Transaction struct {
+			Hash   common.Hash
+			Nonce  string
+			Index  int
+			Value  string
+			From   struct{ Address common.Address }
+			To     *struct{ Address common.Address }
+			Block  struct{ Number string }
+			Status string
+		}
+	}
+	query(t, service, `{ transaction(hash: "`+transfer.Hash().Hex()+`") { hash nonce index value from { address } to { address } block { number } status } }`, &txResult)
+	tx := txResult.Transaction
+	if tx.Hash != transfer.Hash() || tx.Nonce != "0x0" || tx.Index != 0 || tx.Value != "0x64" {
+		t.Errorf("transaction mismatch: have %+v", tx)
+	}
+	if tx.From.Address != testAddr || tx.To == nil || tx.To.Address != testTo {
+		t.Errorf("transaction accounts mismatch: have from %x to %v", tx.From.Address, tx.To)
+	}
+	if tx.Block.Number != "0x1" || tx.Status != "0x1" {
+		t.Errorf("transaction block or status mismatch: have %s %s", tx.Block.Number, tx.Status)
+	}
+
+	// logs of the contract creation
+	var logResult struct {
+		Transaction struct {
+			CreatedContract struct{ Address common.Address }
+			Logs            []struct {
+				Index   int
+				Account struct{ Address common.Address }
+				Topics  []common.Hash
+				Data    string
+			}
+		}
+	}
+	query(t, service, `{ transaction(hash: "`+create.Hash().Hex()+`") { createdContract { address } logs { index account { address } topics data } } }`, &logResult)
+	contract := crypto.CreateAddress(testAddr, create.Nonce())
+	if have := logResult.Transaction.CreatedContract.Address; have != contract {
+		t.Errorf("created contract mismatch: have %x, want %x", have, contract)
+	}
+	logs := logResult.Transaction.Logs
+	if len(logs) != 1 {
+		t.Fatalf("logs mismatch: have %d, want 1", len(logs))
+	}
+	if logs[0].Account.Address != contract || len(logs[0].Topics) != 1 || logs[0].Topics[0] != common.HexToHash("0xaa") {
+		t.Errorf("log mismatch: have %+v", logs[0])
+	}
+	if want := common.BigToHash(big.NewInt(42)).Hex(); logs[0].Data != want {
+		t.Errorf("log data mismatch: have %s, want %s", logs[0].Data, want)
+	}
+}
+
+func hexUint(n uint64) string {
+	return "0x" + new(big.Int).SetUint64(n).Text(16)
+}
@@ -0,0 +1,317 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # Address is a 20 byte Icechain address, represented as 0x-prefixed hexadecimal.
+    scalar Address
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed hexadecimal.
+    # An empty byte string is represented as '0x'. Byte strings must have an even number of hexadecimal nybbles.
+    scalar Bytes
+    # BigInt is a large integer. Input is accepted as either a JSON number or as a string.
+    # Strings may be either decimal or 0x-prefixed hexadecimal. Output values are all
+    # 0x-prefixed hexadecimal.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer.
+    scalar Long
+
+    schema {
+        query: Query
+        mutation: Mutation
+    }
+
+    # Account is an Icechain account at a particular fast block.
+    type Account {
+        # Address is the address owning the account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # TransactionCount is the number of transactions sent from this account,
+        # or in the case of a contract, the number of contracts created. Otherwise
+        # known as the nonce.
+        transactionCount: Long!
+        # Code contains the smart contract code for this account, if the account
+        # is a (non-self-destructed) contract.
+        code: Bytes!
+        # Storage provides access to the storage of a contract account, indexed
+        # by its 32 byte slot identifier.
+        storage(slot: Bytes32!): Bytes32!
+        # Staking is the impawn staking account of the address, if it stakes.
+        staking: StakingAccount
+    }
+
+    # Log is an Icechain event log.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the account which generated this log - this will always
+        # be a contract account.
+        account(block: Long): Account!
+        # Topics is a list of 0-4 indexed topics for the log.
+        topics: [Bytes32!]!
+        # Data is unindexed data for this log.
+        data: Bytes!
+        # Transaction is the transaction that generated this log entry.
+        transaction: Transaction!
+    }
+
+    # Transaction is an Icechain transaction.
+    type Transaction {
+        # Hash is the hash of this transaction.
+        hash: Bytes32!
+        # Nonce is the nonce of the account this transaction was generated with.
+        nonce: Long!
+        # Index is the index of this transaction in the parent block. This will
+        # be null if the transaction has not yet been mined.
+        index: Int
+        # From is the account that sent this transaction - this will always be
+        # an externally owned account.
+        from(block: Long): Account!
+        # To is the account the transaction was sent to. This is null for
+        # contract-creating transactions.
+        to(block: Long): Account
+        # Payer is the account paying the gas of a payment transaction. This is
+        # null for the transactions paid by the sender.
+        payer(block: Long): Account
+        # Value is the value, in wei, sent along with this transaction.
+        value: BigInt!
+        # GasPrice is the price offered to miners for gas, in wei per unit.
+        gasPrice: BigInt!
+        # Gas is the maximum amount of gas this transaction can consume.
+        gas: Long!
+        # Fee is the fee offered by the payer of a payment transaction, in wei.
+        fee: BigInt
+        # InputData is the data supplied to the target of the transaction.
+        inputData: Bytes!
+        # Block is the fast block this transaction was mined in. This will be
+        # null if the transaction has not yet been mined.
+        block: Block
+        # Status is the return status of the transaction. This will be 1 if the
+        # transaction succeeded, or 0 if it failed (due to a revert, or due to
+        # running out of gas). If the transaction has not yet been mined, this
+        # field will be null.
+        status: Long
+        # GasUsed is the amount of gas that was used processing this transaction.
+        # If the transaction has not yet been mined, this field will be null.
+        gasUsed: Long
+        # CumulativeGasUsed is the total gas used in the block up to and including
+        # this transaction. If the transaction has not yet been mined, this field
+        # will be null.
+        cumulativeGasUsed: Long
+        # CreatedContract is the account that was created by a contract creation
+        # transaction. If the transaction was not a contract creation transaction,
+        # or it has not yet been mined, this field will be null.
+        createdContract(block: Long): Account
+        # Logs is a list of log entries emitted by this transaction. If the
+        # transaction has not yet been mined, this field will be null.
+        logs: [Log!]
+    }
+
+    # Block is a fast block, carrying the transactions.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: Block
+        # StateRoot is the keccak256 hash of the state trie after this block was processed.
+        stateRoot: Bytes32!
+        # TransactionsRoot is the keccak256 hash of the root of the trie of transactions in this block.
+        transactionsRoot: Bytes32!
+        # ReceiptsRoot is the keccak256 hash of the trie of transaction receipts in this block.
+        receiptsRoot: Bytes32!
+        # CommitteeRoot is the hash of the committee switch information of this block.
+        committeeRoot: Bytes32!
+        # Proposer is the account of the committee member which proposed this block.
+        proposer(block: Long): Account!
+        # SnailHash is the hash of the snail block rewarded in this block.
+        snailHash: Bytes32!
+        # SnailNumber is the number of the snail block rewarded in this block.
+        snailNumber: Long!
+        # GasLimit is the maximum amount of gas that was available to transactions in this block.
+        gasLimit: Long!
+        # GasUsed is the amount of gas that was used executing transactions in this block.
+        gasUsed: Long!
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # ExtraData is an arbitrary data field supplied by the proposer.
+        extraData: Bytes!
+        # LogsBloom is a bloom filter that can be used to check if a block may
+        # contain log entries matching a filter.
+        logsBloom: Bytes!
+        # TransactionCount is the number of transactions in this block. if
+        # transactions are not available for this block, this field will be null.
+        transactionCount: Int
+        # Transactions is a list of transactions associated with this block. If
+        # transactions are unavailable for this block, this field will be null.
+        transactions: [Transaction!]
+        # TransactionAt returns the transaction at the specified index. If
+        # transactions are unavailable for this block, or if the index is out of
+        # bounds, this field will be null.
+        transactionAt(index: Int!): Transaction
+        # Account fetches an Icechain account at the current block's state.
+        account(address: Address!): Account!
+        # Fruit is the fruit pointing to this block, null until it is mined.
+        fruit: Fruit
+        # Committee is the list of the committee members in charge of this block.
+        committee: [CommitteeMember!]!
+    }
+
+    # SnailBlock is a snail block, carrying the fruits of the fast blocks.
+    type SnailBlock {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: SnailBlock
+        # Coinbase is the address of the miner of this block.
+        coinbase: Address!
+        # Difficulty is a measure of the difficulty of mining this block.
+        difficulty: BigInt!
+        # FruitDifficulty is the fruit difficulty of this block.
+        fruitDifficulty: BigInt!
+        # TotalDifficulty is the sum of all difficulty values up to and including
+        # this block.
+        totalDifficulty: BigInt
+        # Timestamp is the unix timestamp at which this block was mined.
+        timestamp: BigInt!
+        # Nonce is the block nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # MixHash is the hash that was used as an input to the PoW process.
+        mixHash: Bytes32!
+        # ExtraData is an arbitrary data field supplied by the miner.
+        extraData: Bytes!
+        # PointerHash is the hash of the snail block this block points to.
+        pointerHash: Bytes32!
+        # PointerNumber is the number of the snail block this block points to.
+        pointerNumber: Long!
+        # FruitsHash is the hash of the fruits of this block.
+        fruitsHash: Bytes32!
+        # FruitCount is the number of the fruits in this block.
+        fruitCount: Int
+        # Fruits is the list of the fruits in this block.
+        fruits: [Fruit!]
+    }
+
+    # Fruit is a mined fast block record carried by a snail block.
+    type Fruit {
+        # Hash is the hash of this fruit.
+        hash: Bytes32!
+        # FastNumber is the number of the fast block of this fruit.
+        fastNumber: Long!
+        # FastHash is the hash of the fast block of this fruit.
+        fastHash: Bytes32!
+        # FastBlock is the fast block of this fruit.
+        fastBlock: Block
+        # SnailBlock is the snail block carrying this fruit, null until it is
+        # included.
+        snailBlock: SnailBlock
+        # PointerHash is the hash of the snail block this fruit points to.
+        pointerHash: Bytes32!
+        # PointerNumber is the number of the snail block this fruit points to.
+        pointerNumber: Long!
+        # Coinbase is the address of the miner of this fruit.
+        coinbase: Address!
+        # Difficulty is the fruit difficulty of this fruit.
+        difficulty: BigInt!
+        # Timestamp is the unix timestamp at which this fruit was mined.
+        timestamp: BigInt!
+        # Nonce is the fruit nonce, an 8 byte sequence determined by the miner.
+        nonce: Bytes!
+        # MixHash is the hash that was used as an input to the PoW process.
+        mixHash: Bytes32!
+    }
+
+    # CommitteeMember is a member of the committee running the fast chain.
+    type CommitteeMember {
+        # Coinbase is the address receiving the rewards of the member.
+        coinbase: Address!
+        # CommitteeBase is the address derived from the public key of the member.
+        committeeBase: Address!
+        # PublicKey is the vote public key of the member.
+        publicKey: Bytes!
+        # Flag is the state flag of the member.
+        flag: Int!
+        # Type is the type of the member.
+        type: Int!
+    }
+
+    # Delegation is an amount delegated to a staking account.
+    type Delegation {
+        # Address is the address of the delegator.
+        address: Address!
+        # Staking is the delegated amount, in wei.
+        staking: BigInt!
+        # ValidStaking is the delegated amount counting for the election, in wei.
+        validStaking: BigInt!
+    }
+
+    # StakingAccount is an impawn staking account of the current epoch.
+    type StakingAccount {
+        # Address is the address of the staking account.
+        address: Address!
+        # VotePubKey is the vote public key of the account.
+        votePubKey: Bytes!
+        # Fee is the fee rate the account charges the delegations.
+        fee: BigInt!
+        # Committee is true if the account is elected into the current committee.
+        committee: Boolean!
+        # Staking is the amount staked by the account and its delegations, in wei.
+        staking: BigInt!
+        # ValidStaking is the staked amount counting for the election, in wei.
+        validStaking: BigInt!
+        # Delegations is the list of the delegations of the account.
+        delegations: [Delegation!]!
+    }
+
+    type Query {
+        # Block fetches a fast block by number or by hash. If neither is
+        # supplied, the most recent known block is returned.
+        block(number: Long, hash: Bytes32): Block
+        # Blocks returns all the fast blocks between two numbers, inclusive. If
+        # to is not supplied, it defaults to the most recent known block.
+        blocks(from: Long!, to: Long): [Block!]!
+        # SnailBlock fetches a snail block by number or by hash. If neither is
+        # supplied, the most recent known snail block is returned.
+        snailBlock(number: Long, hash: Bytes32): SnailBlock
+        # SnailBlocks returns all the snail blocks between two numbers, inclusive.
+        # If to is not supplied, it defaults to the most recent snail block.
+        snailBlocks(from: Long!, to: Long): [SnailBlock!]!
+        # Transaction returns a transaction specified by its hash.
+        transaction(hash: Bytes32!): Transaction
+        # StakingAccount returns the impawn staking account of the address at
+        # the fast block, the most recent one if not supplied.
+        stakingAccount(address: Address!, block: Long): StakingAccount
+        # StakingAccounts returns all the impawn staking accounts at the fast
+        # block, the most recent one if not supplied.
+        stakingAccounts(block: Long): [StakingAccount!]!
+        # GasPrice returns the node's estimate of a gas price sufficient to
+        # ensure a transaction is mined in a timely fashion.
+        gasPrice: BigInt!
+        # ProtocolVersion returns the current wire protocol version number.
+        protocolVersion: Int!
+    }
+
+    type Mutation {
+        # SendRawTransaction sends an RLP-encoded transaction to the network.
+        sendRawTransaction(data: Bytes!): Bytes32!
+    }
+`
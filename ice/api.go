@@ -42,6 +42,8 @@ import (
 	"github.com/iceming123/go-ice/trie"
 )
 
+var errTbftNotRunning = errors.New("tbft server is not running")
+
 // PublicIcechainAPI provides an API to access Icechain full node-related
 // information.
 type PublicIcechainAPI struct {
@@ -101,6 +103,30 @@ func (api *PublicIcechainAPI) ChainId() hexutil.Uint64 {
 	return (hexutil.Uint64)(chainID.Uint64())
 }
 
+// PublicTbftHealthAPI provides an API to access the health of the committee
+// members watched by the TBFT health manager.
+type PublicTbftHealthAPI struct {
+	e *Icechain
+}
+
+// NewPublicTbftHealthAPI creates a new TBFT health API.
+func NewPublicTbftHealthAPI(e *Icechain) *PublicTbftHealthAPI {
+	return &PublicTbftHealthAPI{e}
+}
+
+// Status returns the tick, state and role of the members and the pending and
+// recent switches of the committee, it's the current committee if id is nil.
+func (api *PublicTbftHealthAPI) Status(id *hexutil.Uint64) (map[string]interface{}, error) {
+	if api.e.pbftServer == nil {
+		return nil, errTbftNotRunning
+	}
+	cid := api.e.agent.CommitteeNumber()
+	if id != nil {
+		cid = uint64(*id)
+	}
+	return api.e.pbftServer.GetHealthStatus(new(big.Int).SetUint64(cid))
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only methods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
@@ -110,14 +136,7 @@ type PublicMinerAPI struct {
 
 // NewPublicMinerAPI create a new PublicMinerAPI instance.
 func NewPublicMinerAPI(e *Icechain) *PublicMinerAPI {
-
-	agent := miner.NewRemoteAgent(e.BlockChain(), e.SnailBlockChain(), e.Engine())
-
-	if e.Config().RemoteMine {
-		e.Miner().Register(agent)
-	}
-
-	return &PublicMinerAPI{e, agent}
+	return &PublicMinerAPI{e, e.remoteAgent}
 }
 
 // Mining returns an indication if this node is currently mining.
@@ -324,6 +343,22 @@ func NewPrivateAdminAPI(ice *Icechain) *PrivateAdminAPI {
 	return &PrivateAdminAPI{ice: ice}
 }
 
+// ProposeSwitch proposes to replace the member remove of the current committee
+// with add, it's picked from the back members if add is nil. The switch is
+// verified by the health manager like the ones made by the health ticks, it
+// fails with "health manager disabled" unless the node runs with --tbft.health.
+func (api *PrivateAdminAPI) ProposeSwitch(remove common.Address, add *common.Address, reason *string) (map[string]interface{}, error) {
+	if api.ice.pbftServer == nil {
+		return nil, errTbftNotRunning
+	}
+	resion := ""
+	if reason != nil {
+		resion = *reason
+	}
+	cid := new(big.Int).SetUint64(api.ice.agent.CommitteeNumber())
+	return api.ice.pbftServer.ProposeSwitch(cid, remove, add, resion)
+}
+
 // ExportChain exports the current blockchain into a local file.
 func (api *PrivateAdminAPI) ExportChain(file string) (bool, error) {
 	// Make sure we can create the file to export into
@@ -316,6 +316,11 @@ func (b *ICEAPIBackend) GetCommittee(number rpc.BlockNumber) (map[string]interfa
 	return b.ice.election.GetCommitteeById(big.NewInt(number.Int64())), nil
 }
 
+// GetCommitteeMembers returns the committee members in charge of the fast block.
+func (b *ICEAPIBackend) GetCommitteeMembers(fastNumber *big.Int) []*types.CommitteeMember {
+	return b.ice.election.GetCommittee(fastNumber)
+}
+
 func (b *ICEAPIBackend) GetCurrentCommitteeNumber() *big.Int {
 	return b.ice.election.GetCurrentCommitteeNumber()
 }
@@ -357,6 +362,11 @@ func (b *ICEAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.ice.TxPool().Content()
 }
 
+// TxPoolSponsored returns the pooled transactions grouped by their payer
+func (b *ICEAPIBackend) TxPoolSponsored() map[common.Address]types.Transactions {
+	return b.ice.TxPool().Sponsored()
+}
+
 // SubscribeNewTxsEvent returns the subscript event of new tx
 func (b *ICEAPIBackend) SubscribeNewTxsEvent(ch chan<- types.NewTxsEvent) event.Subscription {
 	return b.ice.TxPool().SubscribeNewTxsEvent(ch)
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync"
@@ -37,8 +38,9 @@ import (
 	ethash "github.com/iceming123/go-ice/consensus/minerva"
 	"github.com/iceming123/go-ice/core"
 	"github.com/iceming123/go-ice/core/bloombits"
+	"github.com/iceming123/go-ice/core/rawdb"
 	chain "github.com/iceming123/go-ice/core/snailchain"
-	"github.com/iceming123/go-ice/core/snailchain/rawdb"
+	snaildb "github.com/iceming123/go-ice/core/snailchain/rawdb"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/crypto"
@@ -50,6 +52,7 @@ import (
 	"github.com/iceming123/go-ice/internal/iceapi"
 	"github.com/iceming123/go-ice/log"
 	"github.com/iceming123/go-ice/miner"
+	"github.com/iceming123/go-ice/miner/stratum"
 	"github.com/iceming123/go-ice/node"
 	"github.com/iceming123/go-ice/p2p"
 	"github.com/iceming123/go-ice/params"
@@ -97,14 +100,17 @@ type Icechain struct {
 
 	APIBackend *ICEAPIBackend
 
-	miner     *miner.Miner
-	gasPrice  *big.Int
-	etherbase common.Address
+	miner       *miner.Miner
+	remoteAgent *miner.RemoteAgent
+	stratum     *stratum.Server
+	gasPrice    *big.Int
+	etherbase   common.Address
 
 	networkID     uint64
 	netRPCService *iceapi.PublicNetAPI
 
 	pbftServer *tbft.Node
+	pbftDir    string // root of the tbft consensus data, empty for ephemeral nodes
 
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
@@ -127,7 +133,12 @@ func New(ctx *node.ServiceContext, config *Config) (*Icechain, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	// The stratum miners work through the remote agent, as --stratum.addr implies
+	if config.StratumAddr != "" && !config.RemoteMine {
+		log.Info("Enabling remote mining for the stratum server", "addr", config.StratumAddr)
+		config.RemoteMine = true
+	}
+	chainDb, err := CreateChainDB(ctx, config, "chaindata")
 	//chainDb, err := CreateDB(ctx, config, path)
 	if err != nil {
 		return nil, err
@@ -158,20 +169,21 @@ func New(ctx *node.ServiceContext, config *Config) (*Icechain, error) {
 		etherbase:      config.Etherbase,
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms, false),
+		pbftDir:        ctx.ResolvePath(params.DefaultTBFTDir),
 	}
 
 	log.Info("Initialising Icechain protocol", "versions", ProtocolVersions, "network", config.NetworkId, "syncmode", config.SyncMode)
 
 	if !config.SkipBcVersionCheck {
-		bcVersion := rawdb.ReadDatabaseVersion(chainDb)
+		bcVersion := snaildb.ReadDatabaseVersion(chainDb)
 		if bcVersion != core.BlockChainVersion && bcVersion != 0 {
 			return nil, fmt.Errorf("Blockchain DB version mismatch (%d / %d). Run gice upgradedb.\n", bcVersion, core.BlockChainVersion)
 		}
-		rawdb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
+		snaildb.WriteDatabaseVersion(chainDb, core.BlockChainVersion)
 	}
 	var (
 		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Deleted: config.DeletedState, Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Deleted: config.DeletedState, Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, SnapshotLimit: config.SnapshotCache}
 	)
 
 	ice.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, ice.chainConfig, ice.engine, vmConfig)
@@ -188,14 +200,14 @@ func New(ctx *node.ServiceContext, config *Config) (*Icechain, error) {
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding chain to upgrade configuration", "err", compat)
 		ice.blockchain.SetHead(compat.RewindTo)
-		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
+		snaildb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 
 	//  rewind snail if case of incompatible config
 	if compat, ok := genesisErr.(*params.ConfigCompatError); ok {
 		log.Warn("Rewinding snail chain to upgrade configuration", "err", compat)
 		ice.snailblockchain.SetHead(compat.RewindTo)
-		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
+		snaildb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 
 	ice.bloomIndexer.Start(ice.blockchain)
@@ -237,6 +249,19 @@ func New(ctx *node.ServiceContext, config *Config) (*Icechain, error) {
 
 	ice.miner = miner.New(ice, ice.chainConfig, ice.EventMux(), ice.engine, ice.election, ice.Config().MineFruit, ice.Config().NodeType, ice.Config().RemoteMine, ice.Config().Mine)
 	ice.miner.SetExtra(makeExtraData(config.ExtraData))
+	if config.Developer {
+		ice.miner.SetInstantSeal(true)
+	}
+
+	ice.remoteAgent = miner.NewRemoteAgent(ice.BlockChain(), ice.SnailBlockChain(), ice.Engine())
+	if ice.config.RemoteMine {
+		ice.miner.Register(ice.remoteAgent)
+	}
+	if config.StratumAddr != "" {
+		if ice.stratum, err = stratum.NewServer(ice.remoteAgent, ice.engine, config.StratumDifficulty); err != nil {
+			return nil, err
+		}
+	}
 
 	committeeKey, err := crypto.ToECDSA(ice.config.CommitteeKey)
 	if err == nil {
@@ -281,6 +306,29 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (icedb.Data
 	return db, nil
 }
 
+// CreateChainDB creates the full node chain database, the immutable fast and
+// snail chain segments are moved out of it into the ancient freezers.
+func CreateChainDB(ctx *node.ServiceContext, config *Config, name string) (icedb.Database, error) {
+	db, err := CreateDB(ctx, config, name)
+	if err != nil {
+		return nil, err
+	}
+	ldb, ok := db.(*icedb.LDBDatabase)
+	if !ok {
+		return db, nil // Ephemeral node, nothing to freeze
+	}
+	ancient := filepath.Join(ctx.ResolvePath(name), "ancient")
+	if config.DatabaseFreezer != "" {
+		ancient = ctx.ResolvePath(config.DatabaseFreezer)
+	}
+	frdb, err := rawdb.NewDatabaseWithFreezer(ldb, ancient, "ice/db/chaindata/")
+	if err != nil {
+		ldb.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Icechain service
 func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chainConfig *params.ChainConfig,
 	db icedb.Database) consensus.Engine {
@@ -354,6 +402,11 @@ func (s *Icechain) APIs() []rpc.API {
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
+			Namespace: "tbfthealth",
+			Version:   "1.0",
+			Service:   NewPublicTbftHealthAPI(s),
+			Public:    true,
+		}, {
 			Namespace: "miner",
 			Version:   "1.0",
 			Service:   NewPrivateMinerAPI(s),
@@ -503,13 +556,16 @@ func (s *Icechain) Start(srvr *p2p.Server) error {
 	}
 	// Start the networking layer and the light server if requested
 	s.protocolManager.Start(maxPeers)
-	s.startPbftServer()
-	if s.pbftServer == nil {
-		log.Error("start pbft server failed.")
-		return errors.New("start pbft server failed.")
+	// The developer chain is sealed by the agent alone, without a TBFT network
+	if !s.config.Developer {
+		s.startPbftServer()
+		if s.pbftServer == nil {
+			log.Error("start pbft server failed.")
+			return errors.New("start pbft server failed.")
+		}
+		s.agent.server = s.pbftServer
+		log.Info("", "server", s.agent.server)
 	}
-	s.agent.server = s.pbftServer
-	log.Info("", "server", s.agent.server)
 	s.agent.Start()
 
 	s.election.Start()
@@ -522,6 +578,11 @@ func (s *Icechain) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	if s.stratum != nil {
+		if err := s.stratum.Start(s.config.StratumAddr); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -539,6 +600,9 @@ func (s *Icechain) Stop() error {
 	}
 	s.txPool.Stop()
 	s.snailPool.Stop()
+	if s.stratum != nil {
+		s.stratum.Stop()
+	}
 	s.miner.Stop()
 	s.eventMux.Stop()
 
@@ -555,8 +619,10 @@ func (s *Icechain) startPbftServer() error {
 	}
 
 	cfg := config.DefaultConfig()
+	cfg.Consensus.RootDir = s.pbftDir
 	cfg.P2P.ListenAddress1 = "tcp://0.0.0.0:" + strconv.Itoa(s.config.Port)
 	cfg.P2P.ListenAddress2 = "tcp://0.0.0.0:" + strconv.Itoa(s.config.StandbyPort)
+	cfg.Consensus.EnableHealthMgr = s.config.EnableHealthMgr
 
 	n1, err := tbft.NewNode(cfg, "1", priv, s.agent)
 	if err != nil {
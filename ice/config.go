@@ -35,6 +35,7 @@ import (
 	"github.com/iceming123/go-ice/core/snailchain"
 	"github.com/iceming123/go-ice/ice/downloader"
 	"github.com/iceming123/go-ice/ice/gasprice"
+	"github.com/iceming123/go-ice/miner/stratum"
 )
 
 // DefaultConfig contains default settings for use on the ICE chain main net.
@@ -52,6 +53,7 @@ var DefaultConfig = Config{
 	DatabaseCache: 768,
 	TrieCache:     256,
 	TrieTimeout:   60 * time.Minute,
+	SnapshotCache: 0,
 	MinerGasFloor: 16000000,
 	MinerGasCeil:  20000000,
 	GasPrice:      big.NewInt(10 * params.GWei),
@@ -64,9 +66,10 @@ var DefaultConfig = Config{
 		Blocks:     20,
 		Percentile: 60,
 	},
-	MinerThreads: 2,
-	Port:         30310,
-	StandbyPort:  30311,
+	MinerThreads:      2,
+	StratumDifficulty: stratum.DefaultDifficulty,
+	Port:              30310,
+	StandbyPort:       30311,
 }
 
 func init() {
@@ -121,6 +124,10 @@ type Config struct {
 
 	PrivateKey *ecdsa.PrivateKey `toml:"-"`
 
+	// EnableHealthMgr lets the TBFT health manager switch the unhealthy
+	// committee members, and the admin propose their switches by hand.
+	EnableHealthMgr bool `toml:",omitempty"`
+
 	// Host is the host interface on which to start the pbft server. If this
 	// field is empty, can't be a committee member.
 	Host string `toml:",omitempty"`
@@ -138,8 +145,10 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string
 	TrieCache          int
 	TrieTimeout        time.Duration
+	SnapshotCache      int // Megabytes of the snapshot read cache, 0 disables the snapshot
 
 	// Mining-related options
 	Etherbase     common.Address `toml:",omitempty"`
@@ -170,6 +179,12 @@ type Config struct {
 	// true indicate singlenode start
 	NodeType bool `toml:",omitempty"`
 
+	// true indicate developer mode, a single node chain without TBFT networking
+	Developer bool `toml:",omitempty"`
+
+	// Fast block period in developer mode, 0 seals only if transactions are pending
+	DeveloperPeriod uint64 `toml:",omitempty"`
+
 	//true indicate only mine fruit
 	MineFruit bool `toml:",omitempty"`
 
@@ -178,6 +193,12 @@ type Config struct {
 
 	//true indicate only remote mine
 	RemoteMine bool `toml:",omitempty"`
+
+	// Stratum server listening address, empty disables it
+	StratumAddr string `toml:",omitempty"`
+
+	// Share difficulty handed to the stratum miners
+	StratumDifficulty uint64 `toml:",omitempty"`
 }
 
 func (c *Config) GetNodeType() bool {
@@ -31,6 +31,7 @@ import (
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/event"
 	"github.com/iceming123/go-ice/ice/fastdownloader"
+	"github.com/iceming123/go-ice/ice/snap"
 	ice "github.com/iceming123/go-ice/ice/types"
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/log"
@@ -159,6 +160,10 @@ type Downloader struct {
 
 	fastDown     *fastdownloader.Downloader
 	remoteHeader *types.Header
+
+	snapSyncer *snap.Syncer   // Range retriever of the state in snap sync mode
+	snapState  *snapStateSync // Currently running snap state sync
+	snapLock   sync.Mutex     // Lock protecting the running snap state sync
 }
 
 // LightChain encapsulates functions required to synchronise a light chain.
@@ -249,6 +254,11 @@ func (d *Downloader) SetHeader(remote *types.Header) {
 	d.remoteHeader = remote
 }
 
+// SetSnapSyncer sets the state range retriever used in snap sync mode.
+func (d *Downloader) SetSnapSyncer(syncer *snap.Syncer) {
+	d.snapSyncer = syncer
+}
+
 // Progress retrieves the synchronisation boundaries, specifically the origin
 // block where synchronisation started at (may have failed/suspended); the block
 // or header sync is currently at; and the latest known block which the sync targets.
@@ -458,7 +468,7 @@ func (d *Downloader) syncWithPeer(p ice.PeerConnection, hash common.Hash, td *bi
 	pivot := uint64(0)
 
 	d.committed = 1
-	if d.mode == FastSync && pivot != 0 {
+	if (d.mode == FastSync || d.mode == SnapSync) && pivot != 0 {
 		d.committed = 0
 	}
 
@@ -581,7 +591,7 @@ func (d *Downloader) fetchHeight(p ice.PeerConnection) (*types.SnailHeader, erro
 				return nil, errBadPeer
 			}
 			head := headers[0]
-			if d.mode == FastSync && head.Number.Uint64() < d.checkpoint {
+			if (d.mode == FastSync || d.mode == SnapSync) && head.Number.Uint64() < d.checkpoint {
 				p.GetLog().Warn("Remote head below checkpoint", "number", head.Number, "hash", head.Hash())
 				return nil, errUnsyncedPeer
 			}
@@ -1405,11 +1415,11 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 func (d *Downloader) processFullSyncContent(p ice.PeerConnection, hash common.Hash, td *big.Int, remoteHeader *types.SnailHeader) error {
 
 	var (
-		stateSync *stateSync
+		stateSync ice.StateSyncInter
 	)
 
-	if d.mode == FastSync || d.mode == SnapShotSync {
-		stateSync = d.SyncState(d.remoteHeader.Root)
+	if d.mode == FastSync || d.mode == SnapShotSync || d.mode == SnapSync {
+		stateSync = d.SyncStateFd(d.remoteHeader.Root)
 		d.fastDown.SetSync(stateSync)
 		defer stateSync.Cancel()
 		go func() {
@@ -1510,7 +1520,7 @@ func (d *Downloader) importBlockAndSyncFast(blocks []*types.SnailBlock, p ice.Pe
 	}
 
 	switch d.mode {
-	case SnapShotSync, FastSync:
+	case SnapShotSync, FastSync, SnapSync:
 		if index, err := d.blockchain.FastInsertChain(blocks); err != nil {
 			log.Error("Snail Fastdownloaded item processing failed", "number", blocks[index].NumberU64(), "hash", blocks[index].Hash(), "err", err)
 			if err == types.ErrSnailHeightNotYet {
@@ -1584,7 +1594,7 @@ func (d *Downloader) SyncFast(peer string, remoteHeadHash common.Hash, remoteNum
 		currentNumber = d.fastDown.GetLightChain().CurrentHeader().Number.Uint64()
 	} else {
 		currentNumber = d.fastDown.GetBlockChain().CurrentBlock().NumberU64()
-		if mode == FastSync {
+		if mode == FastSync || mode == SnapSync {
 			currentNumber = d.fastDown.GetBlockChain().CurrentFastBlock().NumberU64()
 		} else if mode == SnapShotSync {
 			currentNumber = d.fastDown.GetBlockChain().CurrentHeader().Number.Uint64()
@@ -1601,7 +1611,13 @@ func (d *Downloader) SyncFast(peer string, remoteHeadHash common.Hash, remoteNum
 			mode = FastSync
 		}
 
-		errs := d.fastDown.Synchronise(peer, remoteHeadHash, fastdownloader.SyncMode(mode), currentNumber, remoteNumber)
+		// The fast chain is synced the same in snap mode, only the state
+		// retrieval at the pivot differs
+		fmode := fastdownloader.SyncMode(mode)
+		if mode == SnapSync {
+			fmode = fastdownloader.FastSync
+		}
+		errs := d.fastDown.Synchronise(peer, remoteHeadHash, fmode, currentNumber, remoteNumber)
 
 		if errs != nil {
 			log.Error("SyncFast failed", "err", errs, "remote fast NumLast", remoteNumber, "currentNum", currentNumber)
@@ -26,10 +26,11 @@ const (
 	FastSync                     // Quickly download the headers, full sync only at the chain head
 	LightSync                    // Download only the headers and terminate afterwards
 	SnapShotSync                 // Download only the headers and terminate afterwards
+	SnapSync                     // Like fast sync, retrieving the pivot state as verified ranges
 )
 
 func (mode SyncMode) IsValid() bool {
-	return mode >= FullSync && mode <= SnapShotSync
+	return mode >= FullSync && mode <= SnapSync
 }
 
 // String implements the stringer interface.
@@ -43,6 +44,8 @@ func (mode SyncMode) String() string {
 		return "light"
 	case SnapShotSync:
 		return "snapshot"
+	case SnapSync:
+		return "snap"
 	default:
 		return "unknown"
 	}
@@ -58,6 +61,8 @@ func (mode SyncMode) MarshalText() ([]byte, error) {
 		return []byte("light"), nil
 	case SnapShotSync:
 		return []byte("snapshot"), nil
+	case SnapSync:
+		return []byte("snap"), nil
 	default:
 		return nil, fmt.Errorf("Snail unknown sync mode %d", mode)
 	}
@@ -73,8 +78,10 @@ func (mode *SyncMode) UnmarshalText(text []byte) error {
 		*mode = LightSync
 	case "snapshot":
 		*mode = SnapShotSync
+	case "snap":
+		*mode = SnapSync
 	default:
-		return fmt.Errorf(`Snail unknown sync mode %q, want "full", "fast" or "light"`, text)
+		return fmt.Errorf(`Snail unknown sync mode %q, want "full", "fast", "snap" or "light"`, text)
 	}
 	return nil
 }
@@ -0,0 +1,135 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package downloader
+
+import (
+	"sync"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/ice/snap"
+	ice "github.com/iceming123/go-ice/ice/types"
+	"github.com/iceming123/go-ice/log"
+)
+
+// snapStateSync retrieves a state trie in snap sync mode. The leaves of the
+// account and storage tries are downloaded as ranges over the snap protocol
+// first, after which the trie nodes at the range boundaries are healed with
+// the trie node sync. If the ranges can't be retrieved, the healing falls back
+// to downloading the whole trie.
+type snapStateSync struct {
+	d    *Downloader
+	root common.Hash
+
+	heal *stateSync // Trie node sync healing the retrieved ranges
+	lock sync.Mutex // Lock protecting the healing sync
+
+	cancel     chan struct{} // Channel to signal a termination request
+	cancelOnce sync.Once     // Ensures cancel only ever gets called once
+	done       chan struct{} // Channel to signal termination completion
+	err        error         // Any error hit during sync (set before completion)
+}
+
+// syncSnapState starts retrieving the state with the given root through the
+// snap protocol, switching over from any snap state sync already running.
+func (d *Downloader) syncSnapState(root common.Hash) *snapStateSync {
+	s := &snapStateSync{
+		d:      d,
+		root:   root,
+		cancel: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	d.snapLock.Lock()
+	if d.snapState != nil {
+		d.snapState.Cancel()
+	}
+	d.snapState = s
+	d.snapLock.Unlock()
+
+	d.cancelLock.RLock()
+	cancelCh := d.cancelCh
+	d.cancelLock.RUnlock()
+
+	go s.run()
+	go func() {
+		select {
+		case <-cancelCh:
+			s.Cancel()
+		case <-d.quitCh:
+			s.Cancel()
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+// run retrieves the state ranges and heals them afterwards, notifying any
+// goroutines waiting for the sync to finish.
+func (s *snapStateSync) run() {
+	defer close(s.done)
+
+	switch err := s.d.snapSyncer.Sync(s.root, s.cancel); err {
+	case nil:
+	case snap.ErrCancelled:
+		s.err = ice.ErrCancelStateFetch
+		return
+	default:
+		log.Warn("Snap state retrieval failed, syncing the trie nodes", "root", s.root, "err", err)
+	}
+	s.lock.Lock()
+	select {
+	case <-s.cancel:
+		s.lock.Unlock()
+		s.err = ice.ErrCancelStateFetch
+		return
+	default:
+	}
+	s.heal = s.d.SyncState(s.root)
+	s.lock.Unlock()
+
+	s.err = s.heal.Wait()
+}
+
+// Wait blocks until the sync is done or canceled.
+func (s *snapStateSync) Wait() error {
+	<-s.done
+	return s.err
+}
+
+// Cancel cancels the sync and waits until it has shut down.
+func (s *snapStateSync) Cancel() error {
+	s.cancelOnce.Do(func() {
+		s.lock.Lock()
+		close(s.cancel)
+		heal := s.heal
+		s.lock.Unlock()
+
+		if heal != nil {
+			heal.Cancel()
+		}
+	})
+	return s.Wait()
+}
+
+// Done returns a channel closed when the sync terminates.
+func (s *snapStateSync) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err returns the error the sync terminated with.
+func (s *snapStateSync) Err() error {
+	return s.err
+}
@@ -74,6 +74,9 @@ func (d *Downloader) SyncState(root common.Hash) *stateSync {
 }
 
 func (d *Downloader) SyncStateFd(root common.Hash) ice.StateSyncInter {
+	if d.mode == SnapSync && d.snapSyncer != nil {
+		return d.syncSnapState(root)
+	}
 	s := newStateSync(d, root)
 	select {
 	case d.stateSyncStart <- s:
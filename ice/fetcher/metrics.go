@@ -44,3 +44,21 @@ var (
 	bodyFilterInMeter    = metrics.NewRegisteredMeter("ice/fetcher/filter/bodies/in", nil)
 	bodyFilterOutMeter   = metrics.NewRegisteredMeter("ice/fetcher/filter/bodies/out", nil)
 )
+
+var (
+	txAnnounceInMeter     = metrics.NewRegisteredMeter("ice/fetcher/transaction/announces/in", nil)
+	txAnnounceKnownMeter  = metrics.NewRegisteredMeter("ice/fetcher/transaction/announces/known", nil)
+	txAnnounceDOSMeter    = metrics.NewRegisteredMeter("ice/fetcher/transaction/announces/dos", nil)
+	txBroadcastInMeter    = metrics.NewRegisteredMeter("ice/fetcher/transaction/broadcasts/in", nil)
+	txRequestOutMeter     = metrics.NewRegisteredMeter("ice/fetcher/transaction/request/out", nil)
+	txRequestFailMeter    = metrics.NewRegisteredMeter("ice/fetcher/transaction/request/fail", nil)
+	txRequestTimeoutMeter = metrics.NewRegisteredMeter("ice/fetcher/transaction/request/timeout", nil)
+	txReplyInMeter        = metrics.NewRegisteredMeter("ice/fetcher/transaction/replies/in", nil)
+
+	txFetcherWaitingPeers   = metrics.NewRegisteredGauge("ice/fetcher/transaction/waiting/peers", nil)
+	txFetcherWaitingHashes  = metrics.NewRegisteredGauge("ice/fetcher/transaction/waiting/hashes", nil)
+	txFetcherQueueingPeers  = metrics.NewRegisteredGauge("ice/fetcher/transaction/queueing/peers", nil)
+	txFetcherQueueingHashes = metrics.NewRegisteredGauge("ice/fetcher/transaction/queueing/hashes", nil)
+	txFetcherFetchingPeers  = metrics.NewRegisteredGauge("ice/fetcher/transaction/fetching/peers", nil)
+	txFetcherFetchingHashes = metrics.NewRegisteredGauge("ice/fetcher/transaction/fetching/hashes", nil)
+)
@@ -0,0 +1,545 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+
+package fetcher
+
+import (
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/mclock"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/log"
+)
+
+const (
+	// maxTxAnnounces is the maximum number of unique transactions a peer can
+	// have waiting or queued for retrieval (prevent DOS).
+	maxTxAnnounces = 4096
+
+	// maxTxRetrievals is the maximum number of transactions requested from a
+	// peer in a single round trip.
+	maxTxRetrievals = 256
+
+	// txArriveTimeout is the time allowance before an announced transaction is
+	// explicitly requested, giving a chance for a direct broadcast to arrive.
+	txArriveTimeout = 500 * time.Millisecond
+
+	// txGatherSlack is the interval used to collate almost-expired announces
+	// with network fetches.
+	txGatherSlack = 100 * time.Millisecond
+
+	// txFetchTimeout is the maximum allotted time to return an explicitly
+	// requested transaction.
+	txFetchTimeout = 5 * time.Second
+)
+
+// txAnnounce is the notification of the availability of a batch of new
+// transactions in the network.
+type txAnnounce struct {
+	origin string        // Identifier of the peer originating the notification
+	hashes []common.Hash // Batch of transaction hashes being announced
+}
+
+// txRequest represents an in-flight transaction retrieval request destined to
+// a specific peer.
+type txRequest struct {
+	hashes []common.Hash  // Transactions having been requested
+	time   mclock.AbsTime // Timestamp of the request
+}
+
+// txDelivery is the notification that a batch of transactions have been added
+// to the pool and should be untracked.
+type txDelivery struct {
+	origin string        // Identifier of the peer originating the notification
+	hashes []common.Hash // Batch of transaction hashes having been delivered
+	direct bool          // Whether this is a direct reply or a broadcast
+}
+
+// TxFetcher is responsible for retrieving new transactions based on hash
+// announcements. Announced transactions first wait a short while for a
+// direct broadcast to arrive, after which they are queued up and fetched
+// from one of the announcing peers at a time. Retrievals which aren't
+// answered in time are rescheduled to an alternate source.
+type TxFetcher struct {
+	notify  chan *txAnnounce
+	cleanup chan *txDelivery
+	drop    chan string
+	quit    chan struct{}
+
+	// Stage 1: Waiting lists for newly discovered transactions that might be
+	// broadcast without needing explicit request/reply round trips.
+	waitlist  map[common.Hash]map[string]struct{} // Transactions waiting for a potential broadcast
+	waittime  map[common.Hash]mclock.AbsTime      // Timestamps when transactions were added to the waitlist
+	waitslots map[string]map[common.Hash]struct{} // Waiting announcements grouped by peer (DOS protection)
+
+	// Stage 2: Queue of transactions waiting to be allocated to some peer to
+	// be retrieved directly.
+	announces map[string]map[common.Hash]struct{} // Set of announced transactions, grouped by origin peer
+	announced map[common.Hash]map[string]struct{} // Set of download locations, grouped by transaction hash
+
+	// Stage 3: Set of transactions currently being retrieved.
+	fetching map[common.Hash]string // Transaction set currently being retrieved
+	requests map[string]*txRequest  // In-flight transaction retrievals
+
+	// Callbacks
+	hasTx    func(common.Hash) bool             // Retrieves a tx from the local txpool
+	addTxs   func([]*types.Transaction) []error // Insert a batch of transactions into the local txpool
+	fetchTxs func(string, []common.Hash) error  // Retrieves a set of txs from a remote peer
+
+	step  chan struct{} // Notification channel when the fetcher loop iterates
+	clock mclock.Clock  // Time wrapper to simulate in tests
+}
+
+// NewTxFetcher creates a transaction fetcher to retrieve transactions
+// based on hash announcements.
+func NewTxFetcher(hasTx func(common.Hash) bool, addTxs func([]*types.Transaction) []error, fetchTxs func(string, []common.Hash) error) *TxFetcher {
+	return newTxFetcher(hasTx, addTxs, fetchTxs, mclock.System{})
+}
+
+// newTxFetcher creates a transaction fetcher running on the given clock.
+func newTxFetcher(hasTx func(common.Hash) bool, addTxs func([]*types.Transaction) []error, fetchTxs func(string, []common.Hash) error, clock mclock.Clock) *TxFetcher {
+	return &TxFetcher{
+		notify:    make(chan *txAnnounce),
+		cleanup:   make(chan *txDelivery),
+		drop:      make(chan string),
+		quit:      make(chan struct{}),
+		waitlist:  make(map[common.Hash]map[string]struct{}),
+		waittime:  make(map[common.Hash]mclock.AbsTime),
+		waitslots: make(map[string]map[common.Hash]struct{}),
+		announces: make(map[string]map[common.Hash]struct{}),
+		announced: make(map[common.Hash]map[string]struct{}),
+		fetching:  make(map[common.Hash]string),
+		requests:  make(map[string]*txRequest),
+		hasTx:     hasTx,
+		addTxs:    addTxs,
+		fetchTxs:  fetchTxs,
+		clock:     clock,
+	}
+}
+
+// Notify announces the fetcher of the potential availability of a new batch
+// of transactions in the network.
+func (f *TxFetcher) Notify(peer string, hashes []common.Hash) error {
+	txAnnounceInMeter.Mark(int64(len(hashes)))
+
+	// Skip any transaction announcements that we already know of
+	unknowns := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if f.hasTx(hash) {
+			txAnnounceKnownMeter.Mark(1)
+			continue
+		}
+		unknowns = append(unknowns, hash)
+	}
+	if len(unknowns) == 0 {
+		return nil
+	}
+	select {
+	case f.notify <- &txAnnounce{origin: peer, hashes: unknowns}:
+		return nil
+	case <-f.quit:
+		return errTerminated
+	}
+}
+
+// Enqueue imports a batch of received transactions into the transaction pool
+// and the fetcher. This method may be called by both transaction broadcasts
+// and direct request replies.
+func (f *TxFetcher) Enqueue(peer string, txs []*types.Transaction, direct bool) error {
+	if direct {
+		txReplyInMeter.Mark(int64(len(txs)))
+	} else {
+		txBroadcastInMeter.Mark(int64(len(txs)))
+	}
+	// Push all the transactions into the pool. Rejected ones are untracked too,
+	// there's no point in fetching them over and over again.
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	rejected := 0
+	for _, err := range f.addTxs(txs) {
+		if err != nil {
+			rejected++
+		}
+	}
+	if rejected > 0 {
+		log.Trace("Rejected pooled transactions", "peer", peer, "count", rejected, "direct", direct)
+	}
+	select {
+	case f.cleanup <- &txDelivery{origin: peer, hashes: hashes, direct: direct}:
+		return nil
+	case <-f.quit:
+		return errTerminated
+	}
+}
+
+// Drop should be called when a peer disconnects. It cleans up all the internal
+// data structures of the given node.
+func (f *TxFetcher) Drop(peer string) error {
+	select {
+	case f.drop <- peer:
+		return nil
+	case <-f.quit:
+		return errTerminated
+	}
+}
+
+// Start boots up the announcement based synchroniser, accepting and processing
+// hash notifications and transaction fetches until termination requested.
+func (f *TxFetcher) Start() {
+	go f.loop()
+}
+
+// Stop terminates the announcement based synchroniser, canceling all pending
+// operations.
+func (f *TxFetcher) Stop() {
+	close(f.quit)
+}
+
+func (f *TxFetcher) loop() {
+	var (
+		waitTimer    mclock.Event
+		timeoutTimer mclock.Event
+
+		waitTrigger    = make(chan struct{}, 1)
+		timeoutTrigger = make(chan struct{}, 1)
+	)
+	defer func() {
+		if waitTimer != nil {
+			waitTimer.Cancel()
+		}
+		if timeoutTimer != nil {
+			timeoutTimer.Cancel()
+		}
+	}()
+	for {
+		select {
+		case ann := <-f.notify:
+			// Drop part of the announcements if too many have accumulated from
+			// that peer
+			used := len(f.waitslots[ann.origin]) + len(f.announces[ann.origin])
+			if used >= maxTxAnnounces {
+				txAnnounceDOSMeter.Mark(int64(len(ann.hashes)))
+				break
+			}
+			if want := used + len(ann.hashes); want > maxTxAnnounces {
+				txAnnounceDOSMeter.Mark(int64(want - maxTxAnnounces))
+				ann.hashes = ann.hashes[:maxTxAnnounces-used]
+			}
+			var (
+				idleWait   = len(f.waittime) == 0
+				_, oldPeer = f.announces[ann.origin]
+			)
+			for _, hash := range ann.hashes {
+				// If the transaction is already queued or being fetched, track
+				// the peer as an alternate source
+				if sources := f.announced[hash]; sources != nil {
+					sources[ann.origin] = struct{}{}
+					addPeerHash(f.announces, ann.origin, hash)
+					continue
+				}
+				// If the transaction is already waiting, track the peer too
+				if sources := f.waitlist[hash]; sources != nil {
+					sources[ann.origin] = struct{}{}
+					addPeerHash(f.waitslots, ann.origin, hash)
+					continue
+				}
+				// Transaction unknown to the fetcher, start waiting for a broadcast
+				f.waitlist[hash] = map[string]struct{}{ann.origin: {}}
+				f.waittime[hash] = f.clock.Now()
+				addPeerHash(f.waitslots, ann.origin, hash)
+			}
+			// If a new item was added to the waitlist, schedule it into the fetcher
+			if idleWait && len(f.waittime) > 0 {
+				f.rescheduleWait(&waitTimer, waitTrigger)
+			}
+			// If this peer is new and announced something already queued, maybe
+			// request transactions from them
+			if !oldPeer && len(f.announces[ann.origin]) > 0 {
+				f.scheduleFetches(&timeoutTimer, timeoutTrigger, map[string]struct{}{ann.origin: {}})
+			}
+
+		case <-waitTrigger:
+			// At least one transaction's waiting time ran out, push all expired
+			// ones into the retrieval queues
+			actives := make(map[string]struct{})
+			for hash, instance := range f.waittime {
+				if time.Duration(f.clock.Now()-instance)+txGatherSlack > txArriveTimeout {
+					// Transaction expired without propagation, schedule for retrieval
+					for peer := range f.waitlist[hash] {
+						addPeerHash(f.announces, peer, hash)
+						if f.announced[hash] == nil {
+							f.announced[hash] = make(map[string]struct{})
+						}
+						f.announced[hash][peer] = struct{}{}
+						removePeerHash(f.waitslots, peer, hash)
+						actives[peer] = struct{}{}
+					}
+					delete(f.waittime, hash)
+					delete(f.waitlist, hash)
+				}
+			}
+			// If transactions are still waiting for propagation, reschedule the wait timer
+			if len(f.waittime) > 0 {
+				f.rescheduleWait(&waitTimer, waitTrigger)
+			}
+			// If any peers became active and are idle, request transactions from them
+			if len(actives) > 0 {
+				f.scheduleFetches(&timeoutTimer, timeoutTrigger, actives)
+			}
+
+		case <-timeoutTrigger:
+			// Clean up any expired retrievals and avoid re-requesting them from the
+			// same peer (either overloaded or malicious, useless in both cases).
+			for peer, req := range f.requests {
+				if time.Duration(f.clock.Now()-req.time)+txGatherSlack > txFetchTimeout {
+					txRequestTimeoutMeter.Mark(int64(len(req.hashes)))
+					f.forgetRequest(peer, req.hashes)
+				}
+			}
+			// Schedule a new transaction retrieval
+			f.scheduleFetches(&timeoutTimer, timeoutTrigger, nil)
+
+			// Trigger timeout for new schedule
+			if len(f.requests) > 0 {
+				f.rescheduleTimeout(&timeoutTimer, timeoutTrigger)
+			}
+
+		case delivery := <-f.cleanup:
+			// Independent if the delivery was direct or broadcast, remove all
+			// traces of the hash from internal trackers
+			for _, hash := range delivery.hashes {
+				if sources, ok := f.waitlist[hash]; ok {
+					for peer := range sources {
+						removePeerHash(f.waitslots, peer, hash)
+					}
+					delete(f.waitlist, hash)
+					delete(f.waittime, hash)
+					continue
+				}
+				for peer := range f.announced[hash] {
+					removePeerHash(f.announces, peer, hash)
+				}
+				delete(f.announced, hash)
+				delete(f.fetching, hash)
+			}
+			// In case of a direct delivery, also reschedule anything missing
+			// from the original query
+			if delivery.direct {
+				if req := f.requests[delivery.origin]; req != nil {
+					delivered := make(map[common.Hash]struct{}, len(delivery.hashes))
+					for _, hash := range delivery.hashes {
+						delivered[hash] = struct{}{}
+					}
+					var missing []common.Hash
+					for _, hash := range req.hashes {
+						if _, ok := delivered[hash]; !ok {
+							missing = append(missing, hash)
+						}
+					}
+					f.forgetRequest(delivery.origin, missing)
+					delete(f.requests, delivery.origin)
+				}
+				f.scheduleFetches(&timeoutTimer, timeoutTrigger, nil)
+			}
+
+		case peer := <-f.drop:
+			// A peer was dropped, remove all traces of it
+			if _, ok := f.waitslots[peer]; ok {
+				for hash := range f.waitslots[peer] {
+					delete(f.waitlist[hash], peer)
+					if len(f.waitlist[hash]) == 0 {
+						delete(f.waitlist, hash)
+						delete(f.waittime, hash)
+					}
+				}
+				delete(f.waitslots, peer)
+			}
+			// Release any in-flight retrievals so alternate sources can pick them up
+			if req, ok := f.requests[peer]; ok {
+				for _, hash := range req.hashes {
+					if f.fetching[hash] == peer {
+						delete(f.fetching, hash)
+					}
+				}
+				delete(f.requests, peer)
+			}
+			for hash := range f.announces[peer] {
+				delete(f.announced[hash], peer)
+				if len(f.announced[hash]) == 0 {
+					delete(f.announced, hash)
+				}
+			}
+			delete(f.announces, peer)
+
+			f.scheduleFetches(&timeoutTimer, timeoutTrigger, nil)
+
+		case <-f.quit:
+			return
+		}
+		// Update the tracker gauges with whatever the event changed
+		txFetcherWaitingPeers.Update(int64(len(f.waitslots)))
+		txFetcherWaitingHashes.Update(int64(len(f.waitlist)))
+		txFetcherQueueingPeers.Update(int64(len(f.announces) - len(f.requests)))
+		txFetcherQueueingHashes.Update(int64(len(f.announced)))
+		txFetcherFetchingPeers.Update(int64(len(f.requests)))
+		txFetcherFetchingHashes.Update(int64(len(f.fetching)))
+
+		// Loop did something, ping the step notifier if needed (tests)
+		if f.step != nil {
+			f.step <- struct{}{}
+		}
+	}
+}
+
+// forgetRequest removes the in-flight request of a peer and drops the peer
+// as a source of the given hashes, which it failed to deliver. Hashes left
+// without any source are forgotten altogether.
+func (f *TxFetcher) forgetRequest(peer string, hashes []common.Hash) {
+	for _, hash := range hashes {
+		if f.fetching[hash] == peer {
+			delete(f.fetching, hash)
+		}
+		if sources := f.announced[hash]; sources != nil {
+			delete(sources, peer)
+			if len(sources) == 0 {
+				delete(f.announced, hash)
+			}
+		}
+		removePeerHash(f.announces, peer, hash)
+	}
+	delete(f.requests, peer)
+}
+
+// rescheduleWait iterates over all the transactions currently in the waitlist
+// and schedules the movement into the fetcher for the earliest.
+func (f *TxFetcher) rescheduleWait(timer *mclock.Event, trigger chan struct{}) {
+	if *timer != nil {
+		(*timer).Cancel()
+	}
+	now := f.clock.Now()
+
+	earliest := now
+	for _, instance := range f.waittime {
+		if earliest > instance {
+			earliest = instance
+		}
+	}
+	*timer = f.clock.AfterFunc(txArriveTimeout-time.Duration(now-earliest), func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// rescheduleTimeout iterates over all the transactions currently in flight and
+// schedules a cleanup run when the first would trigger.
+func (f *TxFetcher) rescheduleTimeout(timer *mclock.Event, trigger chan struct{}) {
+	if *timer != nil {
+		(*timer).Cancel()
+	}
+	now := f.clock.Now()
+
+	earliest := now
+	for _, req := range f.requests {
+		if earliest > req.time {
+			earliest = req.time
+		}
+	}
+	*timer = f.clock.AfterFunc(txFetchTimeout-time.Duration(now-earliest), func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// scheduleFetches starts a batch of retrievals for all available idle peers,
+// restricted to the whitelist if one is given. Each transaction is only ever
+// requested from a single peer at a time.
+func (f *TxFetcher) scheduleFetches(timer *mclock.Event, timeout chan struct{}, whitelist map[string]struct{}) {
+	// Gather the set of peers we want to retrieve from (default to all)
+	actives := whitelist
+	if actives == nil {
+		actives = make(map[string]struct{})
+		for peer := range f.announces {
+			actives[peer] = struct{}{}
+		}
+	}
+	if len(actives) == 0 {
+		return
+	}
+	// For each active peer, try to schedule some transaction fetches
+	idle := len(f.requests) == 0
+
+	for peer := range actives {
+		if f.requests[peer] != nil {
+			continue // Request already active
+		}
+		var hashes []common.Hash
+		for hash := range f.announces[peer] {
+			if _, ok := f.fetching[hash]; ok {
+				continue // Already being retrieved from someone else
+			}
+			f.fetching[hash] = peer
+			hashes = append(hashes, hash)
+			if len(hashes) >= maxTxRetrievals {
+				break
+			}
+		}
+		if len(hashes) == 0 {
+			continue
+		}
+		f.requests[peer] = &txRequest{hashes: hashes, time: f.clock.Now()}
+		txRequestOutMeter.Mark(int64(len(hashes)))
+
+		go func(peer string, hashes []common.Hash) {
+			// Try to fetch the transactions, but in case of a request
+			// failure (e.g. peer disconnected), reschedule the hashes.
+			if err := f.fetchTxs(peer, hashes); err != nil {
+				txRequestFailMeter.Mark(int64(len(hashes)))
+				f.Drop(peer)
+			}
+		}(peer, hashes)
+	}
+	// If a new request was fired, schedule a timeout timer
+	if idle && len(f.requests) > 0 {
+		f.rescheduleTimeout(timer, timeout)
+	}
+}
+
+// addPeerHash adds a hash to the set tracked for the given peer.
+func addPeerHash(sets map[string]map[common.Hash]struct{}, peer string, hash common.Hash) {
+	if sets[peer] == nil {
+		sets[peer] = make(map[common.Hash]struct{})
+	}
+	sets[peer][hash] = struct{}{}
+}
+
+// removePeerHash removes a hash from the set tracked for the given peer,
+// deleting the set altogether if it becomes empty.
+func removePeerHash(sets map[string]map[common.Hash]struct{}, peer string, hash common.Hash) {
+	if set := sets[peer]; set != nil {
+		delete(set, hash)
+		if len(set) == 0 {
+			delete(sets, peer)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+
+package fetcher
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/mclock"
+	"github.com/iceming123/go-ice/core/types"
+)
+
+// txFetchRequest is a retrieval issued by the fetcher towards a peer.
+type txFetchRequest struct {
+	peer   string
+	hashes []common.Hash
+}
+
+// txFetcherTester is a test simulator for mocking out the local transaction
+// pool and the remote peers.
+type txFetcherTester struct {
+	fetcher  *TxFetcher
+	clock    *mclock.Simulated
+	requests chan txFetchRequest
+
+	pool map[common.Hash]*types.Transaction
+	lock sync.RWMutex
+}
+
+// newTxFetcherTester creates a new transaction fetcher test mocker running on
+// a simulated clock.
+func newTxFetcherTester() *txFetcherTester {
+	tester := &txFetcherTester{
+		clock:    new(mclock.Simulated),
+		requests: make(chan txFetchRequest, 16),
+		pool:     make(map[common.Hash]*types.Transaction),
+	}
+	tester.fetcher = newTxFetcher(tester.hasTx, tester.addTxs, tester.fetchTxs, tester.clock)
+	tester.fetcher.step = make(chan struct{})
+	tester.fetcher.Start()
+
+	return tester
+}
+
+func (f *txFetcherTester) hasTx(hash common.Hash) bool {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.pool[hash] != nil
+}
+
+func (f *txFetcherTester) addTxs(txs []*types.Transaction) []error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, tx := range txs {
+		f.pool[tx.Hash()] = tx
+	}
+	return make([]error, len(txs))
+}
+
+func (f *txFetcherTester) fetchTxs(peer string, hashes []common.Hash) error {
+	f.requests <- txFetchRequest{peer: peer, hashes: hashes}
+	return nil
+}
+
+// step waits for the fetcher loop to finish processing an event.
+func (f *txFetcherTester) step(t *testing.T) {
+	select {
+	case <-f.fetcher.step:
+	case <-time.After(time.Second):
+		t.Fatalf("fetcher loop did not iterate")
+	}
+}
+
+// notify announces a batch of hashes and waits for them to be processed.
+func (f *txFetcherTester) notify(t *testing.T, peer string, hashes ...common.Hash) {
+	if err := f.fetcher.Notify(peer, hashes); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	f.step(t)
+}
+
+// enqueue delivers a batch of transactions and waits for them to be processed.
+func (f *txFetcherTester) enqueue(t *testing.T, peer string, direct bool, txs ...*types.Transaction) {
+	if err := f.fetcher.Enqueue(peer, txs, direct); err != nil {
+		t.Fatalf("failed to enqueue: %v", err)
+	}
+	f.step(t)
+}
+
+// run advances the simulated clock and waits for the fired timer to be
+// processed.
+func (f *txFetcherTester) run(t *testing.T, d time.Duration) {
+	f.clock.Run(d)
+	f.step(t)
+}
+
+// expectRequest waits for a retrieval and checks its contents.
+func (f *txFetcherTester) expectRequest(t *testing.T, peers []string, hashes ...common.Hash) string {
+	select {
+	case req := <-f.requests:
+		found := false
+		for _, peer := range peers {
+			found = found || req.peer == peer
+		}
+		if !found {
+			t.Fatalf("request peer mismatch: have %s, want one of %v", req.peer, peers)
+		}
+		if !sameHashes(req.hashes, hashes) {
+			t.Fatalf("request hashes mismatch: have %x, want %x", req.hashes, hashes)
+		}
+		return req.peer
+	case <-time.After(time.Second):
+		t.Fatalf("retrieval not requested")
+	}
+	return ""
+}
+
+// expectNoRequest checks that no retrieval was started.
+func (f *txFetcherTester) expectNoRequest(t *testing.T) {
+	select {
+	case req := <-f.requests:
+		t.Fatalf("unexpected request to %s: %x", req.peer, req.hashes)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// expectIdle checks that the fetcher does not track anything anymore.
+func (f *txFetcherTester) expectIdle(t *testing.T) {
+	fetcher := f.fetcher
+	if len(fetcher.waitlist) != 0 || len(fetcher.waittime) != 0 || len(fetcher.waitslots) != 0 {
+		t.Errorf("waitlist not empty: %d hashes, %d times, %d peers", len(fetcher.waitlist), len(fetcher.waittime), len(fetcher.waitslots))
+	}
+	if len(fetcher.announces) != 0 || len(fetcher.announced) != 0 {
+		t.Errorf("queue not empty: %d peers, %d hashes", len(fetcher.announces), len(fetcher.announced))
+	}
+	if len(fetcher.fetching) != 0 || len(fetcher.requests) != 0 {
+		t.Errorf("retrievals not empty: %d hashes, %d requests", len(fetcher.fetching), len(fetcher.requests))
+	}
+}
+
+func sameHashes(a, b []common.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]common.Hash{}, a...), append([]common.Hash{}, b...)
+	sort.Slice(a, func(i, j int) bool { return a[i].Big().Cmp(a[j].Big()) < 0 })
+	sort.Slice(b, func(i, j int) bool { return b[i].Big().Cmp(b[j].Big()) < 0 })
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// makeTxs creates a batch of distinct transactions.
+func makeTxs(n int) []*types.Transaction {
+	txs := make([]*types.Transaction, n)
+	for i := range txs {
+		txs[i] = types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	}
+	return txs
+}
+
+// Tests that announced transactions are only requested after the arrival
+// timeout and that deliveries clean up all the trackers.
+func TestTxFetcherWaitAndFetch(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(2)
+	tester.notify(t, "A", txs[0].Hash(), txs[1].Hash())
+	if len(tester.fetcher.waitlist) != 2 {
+		t.Fatalf("waitlist size mismatch: have %d, want 2", len(tester.fetcher.waitlist))
+	}
+	tester.expectNoRequest(t)
+
+	tester.run(t, txArriveTimeout)
+	tester.expectRequest(t, []string{"A"}, txs[0].Hash(), txs[1].Hash())
+
+	tester.enqueue(t, "A", true, txs...)
+	tester.expectIdle(t)
+
+	// Transactions already in the pool should not be tracked at all
+	if err := tester.fetcher.Notify("A", []common.Hash{txs[0].Hash()}); err != nil {
+		t.Fatalf("failed to notify: %v", err)
+	}
+	tester.expectIdle(t)
+}
+
+// Tests that transactions broadcast while waiting are not requested.
+func TestTxFetcherBroadcastCancelsFetch(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(1)
+	tester.notify(t, "A", txs[0].Hash())
+	tester.enqueue(t, "B", false, txs[0])
+	tester.expectIdle(t)
+
+	tester.run(t, txArriveTimeout)
+	tester.expectNoRequest(t)
+}
+
+// Tests that a transaction announced by multiple peers is only requested
+// from one of them.
+func TestTxFetcherDeduplication(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(1)
+	tester.notify(t, "A", txs[0].Hash())
+	tester.notify(t, "B", txs[0].Hash())
+	if sources := len(tester.fetcher.waitlist[txs[0].Hash()]); sources != 2 {
+		t.Fatalf("source count mismatch: have %d, want 2", sources)
+	}
+	tester.run(t, txArriveTimeout)
+	tester.expectRequest(t, []string{"A", "B"}, txs[0].Hash())
+	tester.expectNoRequest(t)
+
+	// A late announcement should not trigger a new request either
+	tester.notify(t, "C", txs[0].Hash())
+	tester.expectNoRequest(t)
+	if sources := len(tester.fetcher.announced[txs[0].Hash()]); sources != 3 {
+		t.Fatalf("source count mismatch: have %d, want 3", sources)
+	}
+}
+
+// Tests that timed out retrievals are rescheduled to alternate sources.
+func TestTxFetcherTimeoutReschedule(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(1)
+	tester.notify(t, "A", txs[0].Hash())
+	tester.notify(t, "B", txs[0].Hash())
+	tester.run(t, txArriveTimeout)
+	first := tester.expectRequest(t, []string{"A", "B"}, txs[0].Hash())
+
+	tester.run(t, txFetchTimeout)
+	second := tester.expectRequest(t, []string{"A", "B"}, txs[0].Hash())
+	if first == second {
+		t.Fatalf("timed out request rescheduled to the same peer %s", first)
+	}
+	if _, ok := tester.fetcher.announces[first]; ok {
+		t.Errorf("timed out peer %s still tracked as a source", first)
+	}
+	// Without any alternates left the transaction should be forgotten
+	tester.run(t, txFetchTimeout)
+	tester.expectNoRequest(t)
+	tester.expectIdle(t)
+}
+
+// Tests that transactions missing from a reply are requested from other peers.
+func TestTxFetcherPartialDelivery(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(2)
+	tester.notify(t, "A", txs[0].Hash(), txs[1].Hash())
+	tester.run(t, txArriveTimeout)
+	tester.expectRequest(t, []string{"A"}, txs[0].Hash(), txs[1].Hash())
+
+	tester.notify(t, "B", txs[1].Hash())
+	tester.expectNoRequest(t)
+
+	tester.enqueue(t, "A", true, txs[0])
+	tester.expectRequest(t, []string{"B"}, txs[1].Hash())
+
+	tester.enqueue(t, "B", true, txs[1])
+	tester.expectIdle(t)
+}
+
+// Tests that in-flight retrievals of dropped peers are rescheduled.
+func TestTxFetcherDrop(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	txs := makeTxs(1)
+	tester.notify(t, "A", txs[0].Hash())
+	tester.notify(t, "B", txs[0].Hash())
+	tester.run(t, txArriveTimeout)
+	first := tester.expectRequest(t, []string{"A", "B"}, txs[0].Hash())
+
+	if err := tester.fetcher.Drop(first); err != nil {
+		t.Fatalf("failed to drop peer: %v", err)
+	}
+	tester.step(t)
+	second := tester.expectRequest(t, []string{"A", "B"}, txs[0].Hash())
+	if first == second {
+		t.Fatalf("dropped peer %s requested again", first)
+	}
+	if err := tester.fetcher.Drop(second); err != nil {
+		t.Fatalf("failed to drop peer: %v", err)
+	}
+	tester.step(t)
+	tester.expectIdle(t)
+}
+
+// Tests that a peer cannot make the fetcher track an unbounded number of
+// announcements.
+func TestTxFetcherDOSProtection(t *testing.T) {
+	tester := newTxFetcherTester()
+	defer tester.fetcher.Stop()
+
+	hashes := make([]common.Hash, maxTxAnnounces+100)
+	for i := range hashes {
+		hashes[i] = common.BigToHash(big.NewInt(int64(i + 1)))
+	}
+	tester.notify(t, "A", hashes[:maxTxAnnounces/2]...)
+	tester.notify(t, "A", hashes[maxTxAnnounces/2:]...)
+	if have := len(tester.fetcher.waitslots["A"]); have != maxTxAnnounces {
+		t.Fatalf("tracked announcements mismatch: have %d, want %d", have, maxTxAnnounces)
+	}
+	// Other peers should not be affected
+	tester.notify(t, "B", hashes[maxTxAnnounces:]...)
+	if have := len(tester.fetcher.waitslots["B"]); have != 100 {
+		t.Fatalf("tracked announcements mismatch: have %d, want %d", have, 100)
+	}
+}
@@ -39,6 +39,7 @@ import (
 	"github.com/iceming123/go-ice/ice/fastdownloader"
 	"github.com/iceming123/go-ice/ice/fetcher"
 	snailfetcher "github.com/iceming123/go-ice/ice/fetcher/snail"
+	"github.com/iceming123/go-ice/ice/snap"
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/log"
 	"github.com/iceming123/go-ice/p2p"
@@ -76,8 +77,9 @@ func errResp(code errCode, format string, v ...interface{}) error {
 type ProtocolManager struct {
 	networkID uint64
 
-	fastSync uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
-	snapSync uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
+	fastSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
+	snapState uint32 // Flag whether fast sync retrieves the state over the snap protocol
+	snapSync  uint32 // Flag whether fast sync is enabled (gets disabled if we already have blocks)
 
 	acceptTxs        uint32 // Flag whether we're considered synchronised (enables transaction processing)
 	acceptFruits     uint32
@@ -93,8 +95,10 @@ type ProtocolManager struct {
 
 	downloader   *downloader.Downloader
 	fdownloader  *fastdownloader.Downloader
+	snapSyncer   *snap.Syncer
 	fetcherFast  *fetcher.Fetcher
 	fetcherSnail *snailfetcher.Fetcher
+	txFetcher    *fetcher.TxFetcher
 	peers        *peerSet
 
 	SubProtocols []p2p.Protocol
@@ -161,15 +165,19 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	// Figure out whether to allow fast sync or not
 	// TODO: add downloader func later
 
-	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
+	if (mode == downloader.FastSync || mode == downloader.SnapSync) && blockchain.CurrentBlock().NumberU64() > 0 {
 		log.Warn("Blockchain not empty, fast sync disabled")
 		mode = downloader.FullSync
 	}
 
-	if mode == downloader.FastSync {
+	if mode == downloader.FastSync || mode == downloader.SnapSync {
 		manager.fastSync = uint32(1)
 	}
 
+	if mode == downloader.SnapSync {
+		manager.snapState = uint32(1)
+	}
+
 	if mode == downloader.SnapShotSync {
 		manager.snapSync = uint32(1)
 	}
@@ -214,11 +222,36 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	if len(manager.SubProtocols) == 0 {
 		return nil, errIncompatibleConfig
 	}
+	// Serve and retrieve state ranges over the snap protocol
+	manager.snapSyncer = snap.NewSyncer(chaindb)
+	for i, version := range snap.ProtocolVersions {
+		version := version // Closure for the run
+		manager.SubProtocols = append(manager.SubProtocols, p2p.Protocol{
+			Name:    snap.ProtocolName,
+			Version: version,
+			Length:  snap.ProtocolLengths[i],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				peer := snap.NewPeer(version, p, rw)
+				if err := manager.snapSyncer.Register(peer); err != nil {
+					return err
+				}
+				defer manager.snapSyncer.Unregister(peer.ID())
+
+				manager.wg.Add(1)
+				defer manager.wg.Done()
+				return snap.Handle(blockchain.StateCache(), manager.snapSyncer, peer)
+			},
+		})
+	}
 	// Construct the different synchronisation mechanisms
 	// TODO: support downloader func.
 	fmode := fastdownloader.SyncMode(mode)
+	if mode == downloader.SnapSync {
+		fmode = fastdownloader.FastSync
+	}
 	manager.fdownloader = fastdownloader.New(fmode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
 	manager.downloader = downloader.New(mode, manager.checkpointNumber, chaindb, manager.eventMux, snailchain, nil, manager.removePeer, manager.fdownloader)
+	manager.downloader.SetSnapSyncer(manager.snapSyncer)
 	manager.fdownloader.SetSD(manager.downloader)
 
 	fastValidator := func(header *types.Header) error {
@@ -269,6 +302,18 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 	manager.fetcherFast = fetcher.New(blockchain.GetBlockByHash, fastValidator, manager.BroadcastFastBlock, fastHeighter, fastInserter, manager.removePeer, agent, manager.BroadcastPbSign)
 	manager.fetcherSnail = snailfetcher.New(snailchain.GetBlockByHash, snailValidator, manager.BroadcastSnailBlock, snailHeighter, snailInserter, manager.removePeer, fruitHash)
 
+	hasTx := func(hash common.Hash) bool {
+		return txpool.Get(hash) != nil
+	}
+	fetchTx := func(peer string, hashes []common.Hash) error {
+		p := manager.peers.Peer(peer)
+		if p == nil {
+			return errNotRegistered
+		}
+		return p.RequestTxs(hashes)
+	}
+	manager.txFetcher = fetcher.NewTxFetcher(hasTx, txpool.AddRemotes, fetchTx)
+
 	return manager, nil
 }
 
@@ -288,6 +333,8 @@ func (pm *ProtocolManager) removePeer(id string, call uint32) {
 	if err := pm.fdownloader.UnregisterPeer(id); err != nil {
 		log.Error("fdownloaderPeer removal failed", "peer", id, "err", err)
 	}
+	pm.txFetcher.Drop(id)
+
 	if err := pm.peers.Unregister(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
 	}
@@ -1011,7 +1058,78 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			p.MarkTransaction(tx.Hash())
 		}
 		log.Trace("Receive tx", "peer", p.id, "txs", len(txs), "ip", p.RemoteAddr())
-		go pm.txpool.AddRemotes(txs)
+		go pm.txFetcher.Enqueue(p.id, txs, false)
+
+	case msg.Code == NewPooledTransactionHashesMsg && p.version >= ice65:
+		// New transaction announcement arrived, make sure we have
+		// a valid and fresh chain to handle them
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+			break
+		}
+		var hashes []common.Hash
+		if err := msg.Decode(&hashes); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		// Schedule all the unknown hashes for retrieval
+		for _, hash := range hashes {
+			p.MarkTransaction(hash)
+		}
+		pm.txFetcher.Notify(p.id, hashes)
+
+	case msg.Code == GetPooledTransactionsMsg && p.version >= ice65:
+		// Decode the retrieval message
+		msgStream := rlp.NewStream(msg.Payload, uint64(msg.Size))
+		if _, err := msgStream.List(); err != nil {
+			return err
+		}
+		// Gather transactions until the fetch or network limits is reached
+		var (
+			hash   common.Hash
+			bytes  int
+			hashes []common.Hash
+			txs    []rlp.RawValue
+		)
+		for bytes < softResponseLimit {
+			// Retrieve the hash of the next transaction
+			if err := msgStream.Decode(&hash); err == rlp.EOL {
+				break
+			} else if err != nil {
+				return errResp(ErrDecode, "msg %v: %v", msg, err)
+			}
+			// Retrieve the requested transaction, skipping if unknown to us
+			tx := pm.txpool.Get(hash)
+			if tx == nil {
+				continue
+			}
+			// If known, encode and queue for response packet
+			if encoded, err := rlp.EncodeToBytes(tx); err != nil {
+				log.Error("Failed to encode transaction", "err", err)
+			} else {
+				hashes = append(hashes, hash)
+				txs = append(txs, encoded)
+				bytes += len(encoded)
+			}
+		}
+		return p.SendPooledTransactionsRLP(hashes, txs)
+
+	case msg.Code == PooledTransactionsMsg && p.version >= ice65:
+		// Transactions arrived, make sure we have a valid and fresh chain to handle them
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 {
+			break
+		}
+		// Transactions can be processed, parse all of them and deliver to the pool
+		var txs []*types.Transaction
+		if err := msg.Decode(&txs); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		for i, tx := range txs {
+			// Validate and mark the remote transaction
+			if tx == nil {
+				return errResp(ErrDecode, "transaction %d is nil", i)
+			}
+			p.MarkTransaction(tx.Hash())
+		}
+		go pm.txFetcher.Enqueue(p.id, txs, true)
 
 	case msg.Code == TbftNodeInfoMsg:
 		// EncryptNodeMessage can be processed, parse all of them and deliver to the queue
@@ -1265,22 +1383,33 @@ func (pm *ProtocolManager) BroadcastSnailBlock(snailBlock *types.SnailBlock, pro
 }
 
 // BroadcastTxs will propagate a batch of transactions to all peers which are not known to
-// already have the given transaction.
+// already have the given transaction. Full transactions are only sent to the square root
+// of those peers, the rest just get the hashes announced and fetch what they miss. Peers
+// predating ice/65 cannot fetch transactions, so they always get the full ones.
 func (pm *ProtocolManager) BroadcastTxs(txs types.Transactions) {
-	var txset = make(map[*peer]types.Transactions)
-
+	var (
+		txset = make(map[*peer]types.Transactions)
+		annos = make(map[*peer][]common.Hash)
+	)
 	// Broadcast transactions to a batch of peers not knowing about it
 	for _, tx := range txs {
 		peers := pm.peers.PeersWithoutTx(tx.Hash())
-		for _, peer := range peers {
-			txset[peer] = append(txset[peer], tx)
+		direct := int(math.Sqrt(float64(len(peers))))
+		for i, peer := range peers {
+			if i < direct || peer.version < ice65 {
+				txset[peer] = append(txset[peer], tx)
+			} else {
+				annos[peer] = append(annos[peer], tx.Hash())
+			}
 		}
-		log.Trace("BroadcastTxs", "hash", tx.Hash(), "recipients", len(peers), "nonce", tx.Nonce(), "size", tx.Size())
+		log.Trace("BroadcastTxs", "hash", tx.Hash(), "recipients", len(peers), "direct", direct, "nonce", tx.Nonce(), "size", tx.Size())
 	}
-	// FIXME include this again: peers = peers[:int(math.Sqrt(float64(len(peers))))]
 	for peer, txs := range txset {
 		peer.AsyncSendTransactions(txs)
 	}
+	for peer, hashes := range annos {
+		peer.AsyncSendPooledTransactionHashes(hashes)
+	}
 }
 
 // BroadcastFruits will propagate a batch of fruits to all peers which are not known to
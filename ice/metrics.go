@@ -48,6 +48,11 @@ var (
 	propSBlockOutPacketsMeter = metrics.NewRegisteredMeter("ice/prop/sblocks/out/packets", nil)
 	propSBlockOutTrafficMeter = metrics.NewRegisteredMeter("ice/prop/sblocks/out/traffic", nil)
 
+	propTxnHashInPacketsMeter  = metrics.NewRegisteredMeter("ice/prop/txhashes/in/packets", nil)
+	propTxnHashInTrafficMeter  = metrics.NewRegisteredMeter("ice/prop/txhashes/in/traffic", nil)
+	propTxnHashOutPacketsMeter = metrics.NewRegisteredMeter("ice/prop/txhashes/out/packets", nil)
+	propTxnHashOutTrafficMeter = metrics.NewRegisteredMeter("ice/prop/txhashes/out/traffic", nil)
+
 	propNodeInfoInPacketsMeter  = metrics.NewRegisteredMeter("ice/prop/nodeinfo/in/packets", nil)
 	propNodeInfoInTrafficMeter  = metrics.NewRegisteredMeter("ice/prop/nodeinfo/in/traffic", nil)
 	propNodeInfoOutPacketsMeter = metrics.NewRegisteredMeter("ice/prop/nodeinfo/out/packets", nil)
@@ -76,6 +81,11 @@ var (
 	reqSBodyOutPacketsMeter = metrics.NewRegisteredMeter("ice/req/sbodies/out/packets", nil)
 	reqSBodyOutTrafficMeter = metrics.NewRegisteredMeter("ice/req/sbodies/out/traffic", nil)
 
+	reqTxnInPacketsMeter  = metrics.NewRegisteredMeter("ice/req/txns/in/packets", nil)
+	reqTxnInTrafficMeter  = metrics.NewRegisteredMeter("ice/req/txns/in/traffic", nil)
+	reqTxnOutPacketsMeter = metrics.NewRegisteredMeter("ice/req/txns/out/packets", nil)
+	reqTxnOutTrafficMeter = metrics.NewRegisteredMeter("ice/req/txns/out/traffic", nil)
+
 	reqStateInPacketsMeter    = metrics.NewRegisteredMeter("ice/req/states/in/packets", nil)
 	reqStateInTrafficMeter    = metrics.NewRegisteredMeter("ice/req/states/in/traffic", nil)
 	reqStateOutPacketsMeter   = metrics.NewRegisteredMeter("ice/req/states/out/packets", nil)
@@ -160,6 +170,10 @@ func (rw *meteredMsgReadWriter) ReadMsg() (p2p.Msg, error) {
 		packets, traffic = propSBlockInPacketsMeter, propSBlockInTrafficMeter
 	case msg.Code == TxMsg:
 		packets, traffic = propTxnInPacketsMeter, propTxnInTrafficMeter
+	case rw.version >= ice65 && msg.Code == NewPooledTransactionHashesMsg:
+		packets, traffic = propTxnHashInPacketsMeter, propTxnHashInTrafficMeter
+	case rw.version >= ice65 && msg.Code == PooledTransactionsMsg:
+		packets, traffic = reqTxnInPacketsMeter, reqTxnInTrafficMeter
 	case msg.Code == NewFruitMsg:
 		packets, traffic = propFtnInPacketsMeter, propFtnInTrafficMeter
 	case msg.Code == TbftNodeInfoMsg:
@@ -211,6 +225,10 @@ func (rw *meteredMsgReadWriter) WriteMsg(msg p2p.Msg) error {
 		packets, traffic = propSBlockOutPacketsMeter, propSBlockOutTrafficMeter
 	case msg.Code == TxMsg:
 		packets, traffic = propTxnOutPacketsMeter, propTxnOutTrafficMeter
+	case rw.version >= ice65 && msg.Code == NewPooledTransactionHashesMsg:
+		packets, traffic = propTxnHashOutPacketsMeter, propTxnHashOutTrafficMeter
+	case rw.version >= ice65 && msg.Code == PooledTransactionsMsg:
+		packets, traffic = reqTxnOutPacketsMeter, reqTxnOutTrafficMeter
 	case msg.Code == NewFruitMsg:
 		packets, traffic = propFtnOutPacketsMeter, propFtnOutTrafficMeter
 	case msg.Code == TbftNodeInfoMsg:
@@ -121,6 +121,8 @@ type PbftAgent struct {
 
 	cacheBlock map[*big.Int]*types.Block //prevent receive same block
 	singleNode bool
+	developer  bool
+	devPeriod  time.Duration
 
 	nodeInfoWorks      []*nodeInfoWork
 	knownRecievedNodes *utils.OrderedMap
@@ -193,6 +195,8 @@ func (agent *PbftAgent) initNodeInfo(ice Backend) {
 	coinbase, _ := ice.Etherbase()
 	agent.initNodeWork()
 	agent.singleNode = config.NodeType
+	agent.developer = config.Developer
+	agent.devPeriod = time.Duration(config.DeveloperPeriod) * time.Second
 	agent.privateKey = config.PrivateKey
 	agent.committeeNode = &types.CommitteeNode{
 		IP:        config.Host,
@@ -234,7 +238,9 @@ func (agent *PbftAgent) initNodeWork() {
 
 //Start means receive events from election and send pbftNode infomation
 func (agent *PbftAgent) Start() {
-	if agent.singleNode { //single node model start
+	if agent.developer {
+		go agent.devloop()
+	} else if agent.singleNode { //single node model start
 		go agent.singleloop()
 	} else {
 		go agent.loop()
@@ -1523,17 +1529,74 @@ func (agent *PbftAgent) singleloop() {
 				break
 			}
 		}
-		_, err = agent.VerifyFastBlock(block, true)
-		if err != nil {
-			log.Error("VerifyFastBlock error", "err", err)
-		}
-		err = agent.BroadcastConsensus(block)
-		if err != nil {
-			log.Error("BroadcastConsensus error", "err", err)
+		agent.commitSingleBlock(block)
+	}
+}
+
+// devloop seals a fast block as soon as transactions are pending, or every
+// period if one is configured, as the only committee member of a developer chain.
+func (agent *PbftAgent) devloop() {
+	txsCh := make(chan types.NewTxsEvent, txChanSize)
+	txsSub := agent.eth.TxPool().SubscribeNewTxsEvent(txsCh)
+	defer txsSub.Unsubscribe()
+
+	var period <-chan time.Time
+	if agent.devPeriod > 0 {
+		ticker := time.NewTicker(agent.devPeriod)
+		defer ticker.Stop()
+		period = ticker.C
+	}
+	log.Info("Developer mode sealing started", "period", agent.devPeriod)
+	for {
+		select {
+		case <-txsCh:
+			if period != nil {
+				continue
+			}
+			// Keep sealing until all pending transactions are included
+			for agent.sealDevBlock(false) {
+			}
+		case <-period:
+			agent.sealDevBlock(true)
+		case <-txsSub.Err():
+			return
 		}
 	}
 }
 
+// sealDevBlock seals a fast block with the pending transactions, returning
+// whether any transaction was included.
+func (agent *PbftAgent) sealDevBlock(allowEmpty bool) bool {
+	block, err := agent.FetchFastBlock(nil, nil)
+	if err != nil {
+		log.Error("sealDevBlock FetchFastBlock error", "err", err)
+		return false
+	}
+	if len(block.Transactions()) == 0 && !allowEmpty {
+		return false
+	}
+	if err := agent.commitSingleBlock(block); err != nil {
+		return false
+	}
+	return len(block.Transactions()) > 0
+}
+
+// commitSingleBlock signs the block as the only committee member and inserts
+// it into the chain.
+func (agent *PbftAgent) commitSingleBlock(block *types.Block) error {
+	sign, err := agent.VerifyFastBlock(block, true)
+	if err != nil {
+		log.Error("VerifyFastBlock error", "err", err)
+		return err
+	}
+	block.SetSign([]*types.PbftSign{sign})
+	err = agent.BroadcastConsensus(block)
+	if err != nil {
+		log.Error("BroadcastConsensus error", "err", err)
+	}
+	return err
+}
+
 //GetCurrentCommittee return committee member's pubkey information
 func (agent *PbftAgent) GetCurrentCommittee() []string {
 	members := agent.currentCommitteeInfo.Members
@@ -51,6 +51,12 @@ const (
 	// dropping broadcasts. This is a sensitive number as a transaction list might
 	// contain a single transaction, or thousands.
 	maxQueuedTxs = 256
+	// maxQueuedTxAnns is the maximum number of transaction announcement lists to
+	// queue up before dropping broadcasts.
+	maxQueuedTxAnns = 256
+	// txAnnouncePackSize is the maximum number of transaction hashes to pack
+	// into a single announcement.
+	txAnnouncePackSize = 4096
 	// maxQueuedSigns is the maximum number of sign lists to queue up before
 	// dropping broadcasts. This is a sensitive number as a transaction list might
 	// contain a single transaction, or thousands.
@@ -141,6 +147,7 @@ type peer struct {
 	knownSnailBlocks   mapset.Set                     // Set of snailBlocks hashes known to be known by this peer
 	knownFastBlocks    mapset.Set                     // Set of fast block hashes known to be known by this peer
 	queuedTxs          chan []*types.Transaction      // Queue of transactions to broadcast to the peer
+	queuedTxAnns       chan []common.Hash             // Queue of transaction hashes to announce to the peer
 	queuedSign         chan []*types.PbftSign         // Queue of sign to broadcast to the peer
 	queuedNodeInfo     chan *types.EncryptNodeMessage // a node info to broadcast to the peer
 	queuedNodeInfoHash chan *types.EncryptNodeMessage // a node info to broadcast to the peer
@@ -169,6 +176,7 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter, dropPeer peerDropFn
 		knownSnailBlocks:   mapset.NewSet(),
 		knownFastBlocks:    mapset.NewSet(),
 		queuedTxs:          make(chan []*types.Transaction, maxQueuedTxs),
+		queuedTxAnns:       make(chan []common.Hash, maxQueuedTxAnns),
 		queuedSign:         make(chan []*types.PbftSign, maxQueuedSigns),
 		queuedNodeInfo:     make(chan *types.EncryptNodeMessage, maxQueuedNodeInfo),
 		queuedNodeInfoHash: make(chan *types.EncryptNodeMessage, maxQueuedNodeInfoHash),
@@ -217,6 +225,23 @@ func (p *peer) broadcast() {
 			}
 			p.Log().Trace("Broadcast transactions", "count", len(txs))
 
+		case hashes := <-p.queuedTxAnns:
+			for len(p.queuedTxAnns) > 0 && len(hashes) < txAnnouncePackSize {
+				hashes = append(hashes, <-p.queuedTxAnns...)
+			}
+			count := len(hashes)
+			for len(hashes) > 0 {
+				pack := hashes
+				if len(pack) > txAnnouncePackSize {
+					pack = pack[:txAnnouncePackSize]
+				}
+				if err := p.SendPooledTransactionHashes(pack); err != nil {
+					return
+				}
+				hashes = hashes[len(pack):]
+			}
+			p.Log().Trace("Announced transactions", "count", count)
+
 			//add for sign
 		case signs := <-p.queuedSign:
 			p.Log().Trace("Broadcast sign", "signs", signs)
@@ -411,6 +436,42 @@ func (p *peer) AsyncSendTransactions(txs []*types.Transaction) {
 	}
 }
 
+// SendPooledTransactionHashes sends transaction hashes to the peer and includes
+// them in its transaction hash set for future reference.
+//
+// This method is a helper used by the async transaction announcer. Don't call it
+// directly as the queueing (memory) and transmission (bandwidth) costs should
+// not be managed directly.
+func (p *peer) SendPooledTransactionHashes(hashes []common.Hash) error {
+	for _, hash := range hashes {
+		p.MarkTransaction(hash)
+	}
+	return p.Send(NewPooledTransactionHashesMsg, hashes)
+}
+
+// AsyncSendPooledTransactionHashes queues a list of transaction hashes to be
+// announced to a remote peer. If the peer's announce queue is full, the event
+// is silently dropped.
+func (p *peer) AsyncSendPooledTransactionHashes(hashes []common.Hash) {
+	select {
+	case p.queuedTxAnns <- hashes:
+		for _, hash := range hashes {
+			p.MarkTransaction(hash)
+		}
+	default:
+		p.Log().Debug("Dropping transaction announcement", "count", len(hashes), "queuedTxAnns", len(p.queuedTxAnns))
+	}
+}
+
+// SendPooledTransactionsRLP sends requested transactions to the peer and adds
+// the hashes in its transaction hash set for future reference.
+func (p *peer) SendPooledTransactionsRLP(hashes []common.Hash, txs []rlp.RawValue) error {
+	for _, hash := range hashes {
+		p.MarkTransaction(hash)
+	}
+	return p.Send(PooledTransactionsMsg, txs)
+}
+
 func (p *peer) AsyncSendSign(signs []*types.PbftSign) {
 	select {
 	case p.queuedSign <- signs:
@@ -657,6 +718,12 @@ func (p *peer) RequestNodeData(hashes []common.Hash, isFastchain bool) error {
 	return p.Send(GetNodeDataMsg, hashes)
 }
 
+// RequestTxs fetches a batch of transactions from a remote node.
+func (p *peer) RequestTxs(hashes []common.Hash) error {
+	p.Log().Debug("Fetching batch of transactions  GetPooledTransactionsMsg", "count", len(hashes))
+	return p.Send(GetPooledTransactionsMsg, hashes)
+}
+
 // RequestReceipts fetches a batch of transaction receipts from a remote node.
 func (p *peer) RequestReceipts(hashes []common.Hash, isFastchain bool) error {
 	p.Log().Debug("Fetching batch of receipts  GetReceiptsMsg", "count", len(hashes))
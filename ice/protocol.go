@@ -34,16 +34,17 @@ import (
 const (
 	ice63 = 63
 	ice64 = 64
+	ice65 = 65
 )
 
 // ProtocolName is the official short name of the protocol used during capability negotiation.
 var ProtocolName = "ice"
 
 // ProtocolVersions are the upported versions of the ice protocol (first is primary).
-var ProtocolVersions = []uint{ice64, ice63}
+var ProtocolVersions = []uint{ice65, ice64, ice63}
 
 // ProtocolLengths are the number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{32, 20}
+var ProtocolLengths = []uint64{32, 32, 20}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -76,6 +77,11 @@ const (
 
 	TbftNodeInfoHashMsg = 0x15
 	GetTbftNodeInfoMsg  = 0x16
+
+	// Protocol messages belonging to ice/65
+	NewPooledTransactionHashesMsg = 0x17
+	GetPooledTransactionsMsg      = 0x18
+	PooledTransactionsMsg         = 0x19
 )
 
 type errCode int
@@ -113,6 +119,9 @@ type txPool interface {
 	// AddRemotes should add the given transactions to the pool.
 	AddRemotes([]*types.Transaction) []error
 
+	// Get retrieves the transaction from the local pool with the given hash.
+	Get(hash common.Hash) *types.Transaction
+
 	// Pending should return pending transactions.
 	// The slice should be modifiable by the caller.
 	Pending() (map[common.Address]types.Transactions, error)
@@ -68,7 +68,7 @@ func testStatusMsgErrors(t *testing.T, protocol int) {
 			},
 			{
 				code: StatusMsg, data: statusData{uint32(protocol), 999, td, fastHeight, head.Hash(), genesis.Hash(), fastHash},
-				wantError: errResp(ErrNetworkIdMismatch, "999 (!= 19330)"),
+				wantError: errResp(ErrNetworkIdMismatch, "999 (!= %d)", pm.networkID),
 			},
 			{
 				code: StatusMsg, data: statusData{uint32(protocol), DefaultConfig.NetworkId, td, fastHeight, head.Hash(), common.Hash{3}, fastHash},
@@ -109,7 +109,7 @@ func testStatusMsgErrors(t *testing.T, protocol int) {
 			},
 			{
 				code: StatusMsg, data: statusSnapData{uint32(protocol), 999, td, fastHeight, head.Hash(), genesis.Hash(), fastHash, gcHeight, committHeight},
-				wantError: errResp(ErrNetworkIdMismatch, "999 (!= 19330)"),
+				wantError: errResp(ErrNetworkIdMismatch, "999 (!= %d)", pm.networkID),
 			},
 			{
 				code: StatusMsg, data: statusSnapData{uint32(protocol), DefaultConfig.NetworkId, td, fastHeight, head.Hash(), common.Hash{3}, fastHash, gcHeight, committHeight},
@@ -140,6 +140,7 @@ func testStatusMsgErrors(t *testing.T, protocol int) {
 
 // Tests that handshake failures are detected and reported correctly.
 func TestStatusMsgErrors64(t *testing.T) { testStatusMsgErrors(t, 64) }
+func TestStatusMsgErrors65(t *testing.T) { testStatusMsgErrors(t, 65) }
 
 // This test checks that received transactions are added to the local pool.
 func TestRecvTransactions63(t *testing.T) { testRecvTransactions(t, 63) }
@@ -0,0 +1,715 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/rlp"
+	"github.com/iceming123/go-ice/trie"
+)
+
+const (
+	// maxRequestSize is the maximum number of bytes to request from a remote peer.
+	maxRequestSize = 512 * 1024
+
+	// maxStorageSetFetch is the maximum number of contracts to request the
+	// storage of in a single query.
+	maxStorageSetFetch = 128
+
+	// maxCodeRequestCount is the maximum number of bytecode blobs to request
+	// in a single query.
+	maxCodeRequestCount = 64
+
+	// accountConcurrency is the number of chunks to split the account trie into
+	// to allow concurrent retrievals.
+	accountConcurrency = 16
+
+	// requestTimeout is the maximum time a peer is allowed to spend on serving
+	// a single network request.
+	requestTimeout = 10 * time.Second
+
+	// peerWaitTimeout is the maximum time to wait for a peer able to serve the
+	// requested state before giving up.
+	peerWaitTimeout = 30 * time.Second
+
+	// logInterval is the time between two progress reports.
+	logInterval = 8 * time.Second
+)
+
+var (
+	// ErrCancelled is returned from snap syncing if the operation was prematurely
+	// terminated.
+	ErrCancelled = errors.New("sync cancelled")
+
+	// errNoPeers is returned if no peer served the requested state for too long.
+	errNoPeers = errors.New("no peers serving the state")
+)
+
+// request tracks a pending network request of any kind.
+type request struct {
+	id   uint64       // Request ID of this request
+	peer string       // Peer to which this request is assigned
+	task *accountTask // Account range task the request is serving
+
+	origin   []byte        // Slot origin of a large contract storage request
+	accounts []common.Hash // Accounts of a storage or the range bounds of an account request
+	hashes   []common.Hash // Code hashes of a bytecode request
+
+	timeout *time.Timer   // Timer to track delivery timeout
+	stale   chan struct{} // Channel to signal the sync cycle of the request ended
+}
+
+// response is a delivered network packet along with the request it answers.
+// The packet is nil if the request timed out or the peer dropped.
+type response struct {
+	req    *request
+	packet interface{}
+}
+
+// storageJob is a contract whose storage trie is being retrieved.
+type storageJob struct {
+	account common.Hash // Hash of the account owning the storage
+	root    common.Hash // Root hash of the storage trie
+	next    []byte      // Next slot to retrieve of a large contract
+	trie    *trie.Trie  // Partially retrieved storage trie of a large contract
+}
+
+// accountTask represents the sync task for a chunk of the account snapshot.
+type accountTask struct {
+	Next common.Hash // Next account to sync in this interval
+	Last common.Hash // Last account to sync in this interval
+
+	trie *trie.Trie // Account trie of the interval, committed after each batch
+	req  *request   // Pending network request of the task
+
+	// Progress of the last delivered batch of accounts, waiting for the
+	// storage and the bytecodes of its contracts.
+	batch   bool
+	cont    bool
+	next    common.Hash
+	storage []*storageJob
+	codes   []common.Hash
+
+	done bool // Flag whether the interval has been fully retrieved
+}
+
+// Syncer is an Icechain state retriever downloading the leaves of the account
+// and storage tries as contiguous ranges, verified with Merkle range proofs.
+// Nodes at the boundaries of the ranges are left for the trie node sync to
+// heal afterwards.
+type Syncer struct {
+	db     icedb.Database // Database to store the trie nodes and bytecodes into
+	triedb *trie.Database // Trie node cache to build the tries in
+
+	root      common.Hash         // Current state trie root being synced
+	tasks     []*accountTask      // Current account task set being synced
+	stateless map[string]struct{} // Peers unable to serve the current root
+
+	peers    map[string]*Peer    // Currently active peers to download from
+	idlers   map[string]struct{} // Peers not serving any request right now
+	requests map[uint64]*request // Requests currently running
+	nextID   uint64              // Request ID to assign next
+
+	update    chan struct{}  // Notification channel for possible sync progression
+	responses chan *response // Delivered responses and failures
+	quit      chan struct{}  // Channel closed when the current sync cycle ends
+	running   sync.Mutex     // Lock ensuring a single sync at a time
+	lock      sync.RWMutex   // Protects fields that can change outside of sync
+
+	requestBytes uint64 // Soft limit of the data requested in a single query
+
+	accountSynced  uint64 // Number of accounts downloaded
+	accountBytes   uint64 // Number of account trie bytes persisted to disk
+	storageSynced  uint64 // Number of storage slots downloaded
+	storageBytes   uint64 // Number of storage trie bytes persisted to disk
+	bytecodeSynced uint64 // Number of bytecodes downloaded
+	bytecodeBytes  uint64 // Number of bytecode bytes downloaded
+	logTime        time.Time
+}
+
+// NewSyncer creates a new snapshot syncer to download the state into db.
+func NewSyncer(db icedb.Database) *Syncer {
+	return &Syncer{
+		db:           db,
+		triedb:       trie.NewDatabase(db),
+		peers:        make(map[string]*Peer),
+		idlers:       make(map[string]struct{}),
+		requests:     make(map[uint64]*request),
+		update:       make(chan struct{}, 1),
+		responses:    make(chan *response),
+		requestBytes: maxRequestSize,
+	}
+}
+
+// Register injects a new data source into the syncer's peerset.
+func (s *Syncer) Register(peer *Peer) error {
+	id := peer.ID()
+
+	s.lock.Lock()
+	if _, ok := s.peers[id]; ok {
+		s.lock.Unlock()
+		log.Error("Snap peer already registered", "id", id)
+		return errors.New("already registered")
+	}
+	s.peers[id] = peer
+	s.idlers[id] = struct{}{}
+	s.lock.Unlock()
+
+	// Notify any active syncs that a new peer can be assigned data
+	s.notify()
+	return nil
+}
+
+// Unregister removes a data source from the syncer's peerset. Any request
+// pending on the peer is failed, to be rescheduled to another one.
+func (s *Syncer) Unregister(id string) error {
+	s.lock.Lock()
+	if _, ok := s.peers[id]; !ok {
+		s.lock.Unlock()
+		log.Error("Snap peer not registered", "id", id)
+		return errors.New("not registered")
+	}
+	delete(s.peers, id)
+	delete(s.idlers, id)
+
+	var failed []*request
+	for reqid, req := range s.requests {
+		if req.peer == id {
+			delete(s.requests, reqid)
+			failed = append(failed, req)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, req := range failed {
+		req.timeout.Stop()
+		s.fail(req)
+	}
+	s.notify()
+	return nil
+}
+
+// Sync starts (or resumes a previous) sync cycle to iterate over the state
+// trie with the given root and reconstruct the leaves of the account and
+// storage tries. The trie nodes straddling the retrieved ranges are not
+// completed, those need to be healed by a trie node sync.
+func (s *Syncer) Sync(root common.Hash, cancel chan struct{}) error {
+	s.running.Lock()
+	defer s.running.Unlock()
+
+	s.lock.Lock()
+	if s.root != root || s.tasks == nil {
+		s.root = root
+		s.tasks = newAccountTasks(s.triedb)
+		s.stateless = make(map[string]struct{})
+	}
+	for id := range s.peers {
+		s.idlers[id] = struct{}{}
+	}
+	s.quit = make(chan struct{})
+	s.lock.Unlock()
+
+	log.Debug("Starting snap sync cycle", "root", root)
+	defer s.release()
+
+	var (
+		start   = time.Now()
+		waiting <-chan time.Time
+	)
+	s.logTime = start
+	for {
+		// Remove all completed tasks and terminate sync if everything's done
+		s.commitTasks()
+		if len(s.tasks) == 0 {
+			log.Info("Snap sync state ranges retrieved", "root", root, "accounts", s.accountSynced, "slots", s.storageSynced,
+				"codes", s.bytecodeSynced, "elapsed", common.PrettyDuration(time.Since(start)))
+			return nil
+		}
+		s.reportProgress()
+
+		// Assign all the data retrieval tasks to any free peers, waiting for
+		// a while for new ones if nothing is being retrieved
+		if s.assignTasks() == 0 {
+			if waiting == nil {
+				waiting = time.After(peerWaitTimeout)
+			}
+		} else {
+			waiting = nil
+		}
+		// Wait for something to happen
+		select {
+		case <-s.update:
+			// Something happened (new peer, delivery, timeout), recheck tasks
+		case <-waiting:
+			return errNoPeers
+		case <-cancel:
+			return ErrCancelled
+		case res := <-s.responses:
+			s.process(res)
+		}
+	}
+}
+
+// newAccountTasks splits the account hash space into evenly sized chunks.
+func newAccountTasks(triedb *trie.Database) []*accountTask {
+	var (
+		tasks []*accountTask
+		next  common.Hash
+		step  = new(big.Int).Exp(common.Big2, common.Big256, nil)
+	)
+	step = step.Div(step, big.NewInt(accountConcurrency))
+	for i := 0; i < accountConcurrency; i++ {
+		last := common.BigToHash(new(big.Int).Sub(new(big.Int).Add(next.Big(), step), common.Big1))
+		if i == accountConcurrency-1 {
+			// Make sure we don't overflow if the step is not a proper divisor
+			last = maxHash
+		}
+		tr, _ := trie.New(common.Hash{}, triedb)
+		tasks = append(tasks, &accountTask{Next: next, Last: last, trie: tr})
+		next = common.BigToHash(new(big.Int).Add(last.Big(), common.Big1))
+	}
+	return tasks
+}
+
+// release drops all the requests still in flight, the tasks are resumed from
+// where they were by the next sync cycle.
+func (s *Syncer) release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for id, req := range s.requests {
+		req.timeout.Stop()
+		delete(s.requests, id)
+	}
+	for _, task := range s.tasks {
+		task.req = nil
+	}
+	close(s.quit)
+}
+
+// notify pings the sync loop that something might have changed.
+func (s *Syncer) notify() {
+	select {
+	case s.update <- struct{}{}:
+	default:
+	}
+}
+
+// idlePeer picks a peer not serving any requests that might have the state.
+func (s *Syncer) idlePeer() *Peer {
+	for id := range s.idlers {
+		if _, ok := s.stateless[id]; ok {
+			continue
+		}
+		delete(s.idlers, id)
+		return s.peers[id]
+	}
+	return nil
+}
+
+// assignTasks attempts to match idle peers to pending retrievals, returning the
+// number of requests in flight.
+func (s *Syncer) assignTasks() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, task := range s.tasks {
+		if task.req != nil || task.done {
+			continue
+		}
+		// Only pull new accounts if the previous batch is fully retrieved
+		if task.batch && len(task.codes) == 0 && len(task.storage) == 0 {
+			continue
+		}
+		peer := s.idlePeer()
+		if peer == nil {
+			break
+		}
+		req := &request{
+			id:    s.nextID,
+			peer:  peer.ID(),
+			task:  task,
+			stale: s.quit,
+		}
+		s.nextID++
+
+		var err error
+		switch {
+		case len(task.codes) > 0:
+			req.hashes = task.codes
+			if len(req.hashes) > maxCodeRequestCount {
+				req.hashes = req.hashes[:maxCodeRequestCount]
+			}
+			err = peer.RequestByteCodes(req.id, req.hashes, s.requestBytes)
+
+		case len(task.storage) > 0:
+			// Large contracts are retrieved one by one from where they were
+			// left, small ones are batched together
+			if task.storage[0].next != nil {
+				req.accounts = []common.Hash{task.storage[0].account}
+				req.origin = task.storage[0].next
+			} else {
+				for _, job := range task.storage {
+					if job.next != nil || len(req.accounts) == maxStorageSetFetch {
+						break
+					}
+					req.accounts = append(req.accounts, job.account)
+				}
+			}
+			err = peer.RequestStorageRanges(req.id, s.root, req.accounts, req.origin, nil, s.requestBytes)
+
+		default:
+			err = peer.RequestAccountRange(req.id, s.root, task.Next, task.Last, s.requestBytes)
+		}
+		if err != nil {
+			peer.Log().Debug("Failed to request snap data", "err", err)
+			continue
+		}
+		req.timeout = time.AfterFunc(requestTimeout, func() {
+			s.lock.Lock()
+			if _, ok := s.requests[req.id]; !ok {
+				s.lock.Unlock()
+				return
+			}
+			delete(s.requests, req.id)
+			s.lock.Unlock()
+
+			peer.Log().Debug("Snap request timed out", "reqid", req.id)
+			s.fail(req)
+		})
+		s.requests[req.id] = req
+		task.req = req
+	}
+	return len(s.requests)
+}
+
+// fail hands a failed request to the sync loop to be rescheduled.
+func (s *Syncer) fail(req *request) {
+	select {
+	case s.responses <- &response{req: req}:
+	case <-req.stale:
+	}
+}
+
+// deliver hands a response packet to the sync loop, dropping it if it wasn't
+// requested from the peer.
+func (s *Syncer) deliver(peer *Peer, id uint64, packet interface{}) error {
+	if s == nil {
+		return nil
+	}
+	s.lock.Lock()
+	req, ok := s.requests[id]
+	if !ok || req.peer != peer.ID() {
+		s.lock.Unlock()
+		peer.Log().Debug("Unrequested snap response", "reqid", id)
+		return nil
+	}
+	delete(s.requests, id)
+	s.lock.Unlock()
+
+	req.timeout.Stop()
+	select {
+	case s.responses <- &response{req: req, packet: packet}:
+	case <-req.stale:
+	}
+	return nil
+}
+
+// process handles a response (or a failure) for one of the requests of the
+// sync cycle.
+func (s *Syncer) process(res *response) {
+	req := res.req
+
+	s.lock.Lock()
+	req.task.req = nil
+	if _, ok := s.peers[req.peer]; ok {
+		s.idlers[req.peer] = struct{}{}
+	}
+	s.lock.Unlock()
+
+	var err error
+	switch packet := res.packet.(type) {
+	case nil:
+		return
+	case *AccountRangePacket:
+		err = s.processAccounts(req, packet)
+	case *StorageRangesPacket:
+		err = s.processStorage(req, packet)
+	case *ByteCodesPacket:
+		err = s.processByteCodes(req, packet)
+	}
+	if err != nil {
+		// The peer either doesn't have the state or served invalid data,
+		// don't ask it again for this root
+		log.Debug("Snap peer cannot serve state", "peer", req.peer, "root", s.root, "err", err)
+		s.lock.Lock()
+		s.stateless[req.peer] = struct{}{}
+		s.lock.Unlock()
+	}
+}
+
+// processAccounts verifies a range of accounts and schedules the retrieval of
+// the storage and code of its contracts.
+func (s *Syncer) processAccounts(req *request, res *AccountRangePacket) error {
+	task := req.task
+	if task.batch || len(req.accounts) != 0 || len(req.hashes) != 0 || req.origin != nil {
+		return errors.New("unexpected account range")
+	}
+	hashes, accounts := res.Unpack()
+	if len(hashes) == 0 && len(res.Proof) == 0 {
+		return errors.New("state unavailable")
+	}
+	keys := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		keys[i] = common.CopyBytes(hash[:])
+	}
+	end := task.Last
+	if len(hashes) > 0 {
+		end = hashes[len(hashes)-1]
+	}
+	cont, err := trie.VerifyRangeProof(s.root, task.Next[:], end[:], keys, accounts, proofDB(res.Proof))
+	if err != nil {
+		return err
+	}
+	// Ensure that the response doesn't overflow into the subsequent task
+	for i, hash := range hashes {
+		cmp := bytes.Compare(hash[:], task.Last[:])
+		if cmp == 0 {
+			cont = false
+			continue
+		}
+		if cmp > 0 {
+			// Chunk overflown, cut off excess
+			hashes, accounts, keys = hashes[:i], accounts[:i], keys[:i]
+			cont = false
+			break
+		}
+	}
+	// Insert the accounts and gather their contract data still missing
+	var (
+		storage []*storageJob
+		codes   []common.Hash
+	)
+	for i, blob := range accounts {
+		var acc state.Account
+		if err := rlp.DecodeBytes(blob, &acc); err != nil {
+			return err
+		}
+		if acc.Root != emptyRoot {
+			if ok, _ := s.db.Has(acc.Root[:]); !ok {
+				storage = append(storage, &storageJob{account: hashes[i], root: acc.Root})
+			}
+		}
+		if codeHash := common.BytesToHash(acc.CodeHash); codeHash != emptyCode {
+			if ok, _ := s.db.Has(codeHash[:]); !ok {
+				codes = append(codes, codeHash)
+			}
+		}
+		if err := task.trie.TryUpdate(keys[i], blob); err != nil {
+			return err
+		}
+	}
+	s.accountSynced += uint64(len(accounts))
+
+	task.batch, task.cont, task.storage, task.codes = true, cont, storage, codes
+	if len(hashes) > 0 {
+		task.next = incHash(hashes[len(hashes)-1])
+	}
+	return nil
+}
+
+// processStorage verifies the storage ranges of the contracts of an account
+// batch and writes them into their tries.
+func (s *Syncer) processStorage(req *request, res *StorageRangesPacket) error {
+	task := req.task
+	if len(req.accounts) == 0 || len(task.storage) < len(req.accounts) {
+		return errors.New("unexpected storage ranges")
+	}
+	hashes, slots := res.Unpack()
+	if len(hashes) == 0 {
+		return errors.New("state unavailable")
+	}
+	if len(hashes) > len(req.accounts) {
+		return errors.New("too many storage ranges")
+	}
+	var done int
+	for i := range hashes {
+		job := task.storage[i]
+
+		keys := make([][]byte, len(hashes[i]))
+		for j, hash := range hashes[i] {
+			keys[j] = common.CopyBytes(hash[:])
+		}
+		// Only the last range and ranges not starting at the beginning of the
+		// storage are proven with the edge proofs, the rest must be complete
+		var (
+			cont bool
+			err  error
+		)
+		if i == len(hashes)-1 && (len(res.Proof) > 0 || req.origin != nil) {
+			origin := common.Hash{}
+			if req.origin != nil {
+				origin = common.BytesToHash(req.origin)
+			}
+			end := maxHash
+			if len(keys) > 0 {
+				end = hashes[i][len(keys)-1]
+			}
+			cont, err = trie.VerifyRangeProof(job.root, origin[:], end[:], keys, slots[i], proofDB(res.Proof))
+		} else {
+			_, err = trie.VerifyRangeProof(job.root, nil, nil, keys, slots[i], nil)
+		}
+		if err != nil {
+			return err
+		}
+		// Write the slots of the range into the storage trie. Values are the
+		// raw trie leaves and must not be re-encoded.
+		tr := job.trie
+		if tr == nil {
+			tr, _ = trie.New(common.Hash{}, s.triedb)
+		}
+		for j, key := range keys {
+			if err := tr.TryUpdate(key, slots[i][j]); err != nil {
+				return err
+			}
+		}
+		s.storageSynced += uint64(len(keys))
+
+		root, err := tr.Commit(nil)
+		if err != nil {
+			return err
+		}
+		nodes, _ := s.triedb.Size()
+		if err := s.triedb.Commit(root, false); err != nil {
+			return err
+		}
+		s.storageBytes += uint64(nodes)
+
+		if cont {
+			job.trie, job.next = tr, common.CopyBytes(incHash(hashes[i][len(keys)-1]).Bytes())
+			break
+		}
+		if root != job.root {
+			return errors.New("storage root mismatch")
+		}
+		done++
+	}
+	task.storage = task.storage[done:]
+	return nil
+}
+
+// processByteCodes verifies and stores the bytecodes of the contracts of an
+// account batch.
+func (s *Syncer) processByteCodes(req *request, res *ByteCodesPacket) error {
+	task := req.task
+	if len(req.hashes) == 0 {
+		return errors.New("unexpected byte codes")
+	}
+	if len(res.Codes) == 0 {
+		return errors.New("state unavailable")
+	}
+	delivered := make(map[common.Hash]struct{})
+	for _, code := range res.Codes {
+		hash := crypto.Keccak256Hash(code)
+		delivered[hash] = struct{}{}
+		if err := s.db.Put(hash[:], code); err != nil {
+			return err
+		}
+		s.bytecodeSynced++
+		s.bytecodeBytes += uint64(len(code))
+	}
+	var missing []common.Hash
+	for _, hash := range task.codes {
+		if _, ok := delivered[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+	if len(missing) == len(task.codes) {
+		return errors.New("no requested byte codes delivered")
+	}
+	task.codes = missing
+	return nil
+}
+
+// commitTasks persists the account tries of the batches whose contract data is
+// fully retrieved, moving their tasks forward and dropping the completed ones.
+func (s *Syncer) commitTasks() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tasks := s.tasks[:0]
+	for _, task := range s.tasks {
+		if task.req == nil && task.batch && len(task.storage) == 0 && len(task.codes) == 0 {
+			root, err := task.trie.Commit(nil)
+			if err == nil {
+				nodes, _ := s.triedb.Size()
+				err = s.triedb.Commit(root, false)
+				s.accountBytes += uint64(nodes)
+			}
+			if err != nil {
+				log.Error("Failed to commit account range", "err", err)
+			} else {
+				task.batch, task.Next = false, task.next
+				task.done = !task.cont
+			}
+		}
+		if !task.done {
+			tasks = append(tasks, task)
+		}
+	}
+	s.tasks = tasks
+}
+
+// reportProgress logs the progress of the sync cycle at regular intervals.
+func (s *Syncer) reportProgress() {
+	if time.Since(s.logTime) < logInterval {
+		return
+	}
+	s.logTime = time.Now()
+
+	log.Info("Syncing state ranges", "accounts", s.accountSynced, "accountsize", common.StorageSize(s.accountBytes),
+		"slots", s.storageSynced, "storagesize", common.StorageSize(s.storageBytes),
+		"codes", s.bytecodeSynced, "codesize", common.StorageSize(s.bytecodeBytes), "chunks", len(s.tasks))
+}
+
+// proofDB collects the proof nodes into a database keyed by their hashes, a
+// nil database is returned for a missing proof.
+func proofDB(proof [][]byte) trie.DatabaseReader {
+	if len(proof) == 0 {
+		return nil
+	}
+	db := icedb.NewMemDatabase()
+	for _, node := range proof {
+		db.Put(crypto.Keccak256(node), node)
+	}
+	return db
+}
+
+// incHash returns the next hash, in lexicographical order (a.k.a plus one).
+func incHash(h common.Hash) common.Hash {
+	return common.BigToHash(new(big.Int).Add(h.Big(), common.Big1))
+}
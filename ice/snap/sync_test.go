@@ -0,0 +1,177 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package snap
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/p2p"
+	"github.com/iceming123/go-ice/p2p/enode"
+	"github.com/iceming123/go-ice/trie"
+)
+
+// makeTestState creates a state with plain accounts, contracts with code and
+// storage (one of them large) and impawn records under the staking address.
+func makeTestState(t *testing.T) (common.Hash, state.Database) {
+	db := state.NewDatabase(icedb.NewMemDatabase())
+	statedb, _ := state.New(common.Hash{}, db)
+
+	for i := 0; i < 1000; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		statedb.AddBalance(addr, big.NewInt(int64(i+1)))
+		statedb.SetNonce(addr, uint64(i))
+	}
+	for i := 0; i < 20; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(100000 + i)))
+		statedb.SetCode(addr, []byte{byte(i), 0x60, 0x00})
+
+		slots := 5
+		if i == 0 {
+			slots = 500
+		}
+		for j := 0; j < slots; j++ {
+			statedb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(i*j+1))))
+		}
+	}
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256Hash([]byte{byte(i)})
+		statedb.SetPOSState(types.StakingAddress, key, bytes.Repeat([]byte{byte(i), 0xff}, 100+i))
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to commit trie: %v", err)
+	}
+	return root, db
+}
+
+// newTestPeers connects a syncer to a peer serving the given state.
+func newTestPeers(syncer *Syncer, src state.Database, dst icedb.Database) {
+	app, net := p2p.MsgPipe()
+
+	server := NewPeer(snap1, p2p.NewPeer(enode.ID{1}, "server", nil), net)
+	client := NewPeer(snap1, p2p.NewPeer(enode.ID{2}, "client", nil), app)
+
+	go Handle(src, nil, server)
+	go Handle(state.NewDatabase(dst), syncer, client)
+	syncer.Register(client)
+}
+
+// Tests that a state is fully reconstructed by syncing its ranges and healing
+// the boundaries with the trie node sync, impawn records included.
+func TestSync(t *testing.T) {
+	root, src := makeTestState(t)
+
+	db := icedb.NewMemDatabase()
+	syncer := NewSyncer(db)
+	syncer.requestBytes = 4096 // Force many batches and large contract retrievals
+	newTestPeers(syncer, src, db)
+
+	if err := syncer.Sync(root, make(chan struct{})); err != nil {
+		t.Fatalf("sync failed: %v", err)
+	}
+	// Heal the boundaries of the ranges with the trie node sync
+	sched := state.NewStateSync(root, db)
+	for sched.Pending() > 0 {
+		var results []trie.SyncResult
+		for _, hash := range sched.Missing(0) {
+			data, err := src.TrieDB().Node(hash)
+			if err != nil {
+				t.Fatalf("failed to retrieve node %x: %v", hash, err)
+			}
+			results = append(results, trie.SyncResult{Hash: hash, Data: data})
+		}
+		if _, _, err := sched.Process(results); err != nil {
+			t.Fatalf("failed to process results: %v", err)
+		}
+		batch := db.NewBatch()
+		if _, err := sched.Commit(batch); err != nil {
+			t.Fatalf("failed to commit data: %v", err)
+		}
+		batch.Write()
+	}
+	// Check that the whole state is available and matches the source
+	srcState, _ := state.New(root, src)
+	dstState, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		t.Fatalf("failed to open synced state: %v", err)
+	}
+	it := state.NewNodeIterator(dstState)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("synced state incomplete: %v", it.Error)
+	}
+	for i := 0; i < 1000; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		if have, want := dstState.GetBalance(addr), srcState.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Errorf("account %d: balance mismatch: have %v, want %v", i, have, want)
+		}
+	}
+	addr := common.BigToAddress(big.NewInt(100000))
+	if have, want := dstState.GetCode(addr), srcState.GetCode(addr); !bytes.Equal(have, want) {
+		t.Errorf("code mismatch: have %x, want %x", have, want)
+	}
+	for j := 0; j < 500; j++ {
+		key := common.BigToHash(big.NewInt(int64(j)))
+		if have, want := dstState.GetState(addr, key), srcState.GetState(addr, key); have != want {
+			t.Errorf("slot %d: mismatch: have %x, want %x", j, have, want)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256Hash([]byte{byte(i)})
+		if have, want := dstState.GetPOSState(types.StakingAddress, key), srcState.GetPOSState(types.StakingAddress, key); !bytes.Equal(have, want) {
+			t.Errorf("impawn record %d: mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
+
+// Tests that a range of accounts is served with proofs for its edges and that
+// unknown states are answered with empty responses.
+func TestServiceAccountRange(t *testing.T) {
+	root, db := makeTestState(t)
+
+	req := &GetAccountRangePacket{Root: root, Limit: maxHash, Bytes: 1024}
+	accounts, proof := serviceAccountRange(db, req)
+	if len(accounts) == 0 || len(proof) == 0 {
+		t.Fatalf("empty response: %d accounts, %d proof nodes", len(accounts), len(proof))
+	}
+	keys, values := make([][]byte, len(accounts)), make([][]byte, len(accounts))
+	for i, acc := range accounts {
+		keys[i], values[i] = acc.Hash[:], acc.Body
+	}
+	cont, err := trie.VerifyRangeProof(root, req.Origin[:], keys[len(keys)-1], keys, values, proofDB(proof))
+	if err != nil {
+		t.Fatalf("invalid range proof: %v", err)
+	}
+	if !cont {
+		t.Errorf("range reported complete")
+	}
+	req.Root = common.Hash{1}
+	if accounts, proof := serviceAccountRange(db, req); len(accounts) != 0 || len(proof) != 0 {
+		t.Errorf("unknown state served: %d accounts, %d proof nodes", len(accounts), len(proof))
+	}
+}
@@ -60,6 +60,15 @@ func (pm *ProtocolManager) syncTransactions(p *peer) {
 	if len(txs) == 0 {
 		return
 	}
+	// Peers supporting ice/65 fetch whatever they miss, announce the hashes only
+	if p.version >= ice65 {
+		hashes := make([]common.Hash, len(txs))
+		for i, tx := range txs {
+			hashes[i] = tx.Hash()
+		}
+		p.AsyncSendPooledTransactionHashes(hashes)
+		return
+	}
 	select {
 	case pm.txsyncCh <- &txsync{p, txs}:
 	case <-pm.quitSync:
@@ -231,8 +240,10 @@ func (pm *ProtocolManager) syncer() {
 	// Start and ensure cleanup of sync mechanisms
 	pm.fetcherFast.Start()
 	pm.fetcherSnail.Start()
+	pm.txFetcher.Start()
 	defer pm.fetcherFast.Stop()
 	defer pm.fetcherSnail.Stop()
+	defer pm.txFetcher.Stop()
 	defer pm.downloader.Terminate()
 	defer pm.fdownloader.Terminate()
 
@@ -307,6 +318,7 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 				return
 			}
 			atomic.StoreUint32(&pm.fastSync, 0)
+			atomic.StoreUint32(&pm.snapState, 0)
 			atomic.StoreUint32(&pm.snapSync, 0)
 			atomic.StoreUint32(&pm.acceptTxs, 1)    // Mark initial sync done
 			atomic.StoreUint32(&pm.acceptFruits, 1) // Mark initial sync done on any fetcher import
@@ -319,6 +331,9 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 	if atomic.LoadUint32(&pm.fastSync) == 1 {
 		// Fast sync was explicitly requested, and explicitly granted
 		mode = downloader.FastSync
+		if atomic.LoadUint32(&pm.snapState) == 1 {
+			mode = downloader.SnapSync
+		}
 
 		//else if atomic.LoadUint32(&pm.snapSync) == 1 {
 		//	mode = downloader.SnapShotSync
@@ -334,7 +349,7 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 
 	}
 
-	if mode == downloader.FastSync || mode == downloader.SnapShotSync {
+	if mode == downloader.FastSync || mode == downloader.SnapShotSync || mode == downloader.SnapSync {
 		var pivotHeader *types.Header
 
 		// Make sure the peer's total difficulty we are synchronizing is higher.
@@ -372,6 +387,7 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 		}
 
 		atomic.StoreUint32(&pm.fastSync, 0)
+		atomic.StoreUint32(&pm.snapState, 0)
 		atomic.StoreUint32(&pm.snapSync, 0)
 		atomic.StoreUint32(&pm.acceptTxs, 1)    // Mark initial sync done
 		atomic.StoreUint32(&pm.acceptFruits, 1) // Mark initial sync done on any fetcher import
@@ -0,0 +1,302 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+)
+
+// stakingFrame is the frame type of the calls into the staking precompile.
+const stakingFrame = "STAKING"
+
+// callFrame is a call reported by the call tracer. The fields are formatted
+// and ordered the same way the JavaScript callTracer does.
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Value   string       `json:"value,omitempty"`
+	Gas     string       `json:"gas,omitempty"`
+	GasUsed string       `json:"gasUsed,omitempty"`
+	Input   string       `json:"input,omitempty"`
+	Output  string       `json:"output,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Time    string       `json:"time,omitempty"`
+	Calls   []*callFrame `json:"calls,omitempty"`
+
+	gasIn      int64  // Gas available before the call opcode
+	gasCost    int64  // Cost of the call opcode
+	gas        *int64 // Gas available inside the call, if known
+	outOff     int64  // Memory offset of the call output
+	outLen     int64  // Memory size of the call output
+	stakingGas uint64 // Gas consumed by a staking precompile call
+}
+
+// callTracer is a native implementation of the JavaScript callTracer, which
+// extracts and reports all the internal calls made by a transaction. Calls
+// into the staking precompile are reported as STAKING frames instead of being
+// skipped like the other precompiles.
+type callTracer struct {
+	interrupter
+
+	callstack []*callFrame // Current recursive call stack of the EVM execution
+	descended bool         // Whether we've just descended into an inner call
+
+	typ     string
+	from    common.Address
+	to      common.Address
+	input   []byte
+	gas     uint64
+	value   *big.Int
+	output  []byte
+	gasUsed uint64
+	time    time.Duration
+	callErr error
+
+	err error // Error interrupting the tracing
+}
+
+func newCallTracer() TxTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.typ = "CALL"
+	if create {
+		t.typ = "CREATE"
+	} else if to == types.StakingAddress {
+		t.typ = stakingFrame
+	}
+	t.from, t.to, t.input, t.gas, t.value = from, to, input, gas, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	if t.err = t.interrupted(); t.err != nil {
+		return nil
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(err)
+		return nil
+	}
+	// We only care about system opcodes
+	syscall := op&0xf0 == 0xf0
+
+	switch {
+	case syscall && op == vm.CREATE:
+		// If a new contract is being created, add to the call stack
+		inOff := peekUint(stack, 1)
+		inEnd := inOff + peekUint(stack, 2)
+
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    hexBytes(contract.Address().Bytes()),
+			Input:   hexBytes(memorySlice(memory, inOff, inEnd)),
+			gasIn:   int64(gas),
+			gasCost: int64(cost),
+			Value:   toHex(peek(stack, 0).ToBig()),
+		})
+		t.descended = true
+		return nil
+
+	case syscall && op == vm.SELFDESTRUCT:
+		// If a contract is being self destructed, gather that as a subcall too
+		top := t.callstack[len(t.callstack)-1]
+		top.Calls = append(top.Calls, &callFrame{Type: op.String()})
+		return nil
+
+	case syscall && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL):
+		// Skip any pre-compile invocations apart from the staking one, those
+		// are just fancy opcodes
+		to := peekAddress(stack, 1)
+		staking := to == types.StakingAddress
+		if !staking && isPrecompiled(to) {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		inOff := peekUint(stack, 2+off)
+		inEnd := inOff + peekUint(stack, 3+off)
+
+		call := &callFrame{
+			Type:    op.String(),
+			From:    hexBytes(contract.Address().Bytes()),
+			To:      hexBytes(to.Bytes()),
+			Input:   hexBytes(memorySlice(memory, inOff, inEnd)),
+			gasIn:   int64(gas),
+			gasCost: int64(cost),
+			outOff:  peekUint(stack, 4+off),
+			outLen:  peekUint(stack, 5+off),
+		}
+		if op != vm.DELEGATECALL && op != vm.STATICCALL {
+			call.Value = toHex(peek(stack, 2).ToBig())
+		}
+		if staking {
+			// The precompile runs without any steps, its cost is known upfront
+			call.Type = stakingFrame
+			call.stakingGas = vm.PrecompiledContractsYoloPos[types.StakingAddress].RequiredGas(env, memorySlice(memory, inOff, inEnd))
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	if t.descended {
+		if depth >= len(t.callstack) {
+			inner := int64(gas)
+			t.callstack[len(t.callstack)-1].gas = &inner
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if syscall && op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = "execution reverted"
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		ret := peek(stack, 0)
+		switch {
+		case call.Type == "CREATE":
+			// If the call was a CREATE, retrieve the contract address and output code
+			call.GasUsed = toHexInt(call.gasIn - call.gasCost - int64(gas))
+
+			if !ret.IsZero() {
+				addr := common.Address(ret.Bytes20())
+				call.To = hexBytes(addr.Bytes())
+				call.Output = hexBytes(env.StateDB.GetCode(addr))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+
+		case call.gas != nil:
+			// If the call was a contract call, retrieve the gas usage and output
+			call.GasUsed = toHexInt(call.gasIn - call.gasCost + *call.gas - int64(gas))
+
+			if !ret.IsZero() {
+				call.Output = hexBytes(memorySlice(memory, call.outOff, call.outOff+call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+
+		case call.Type == stakingFrame:
+			// The staking precompile consumes its required gas on success
+			if !ret.IsZero() {
+				call.GasUsed = toHexInt(int64(call.stakingGas))
+				call.Output = hexBytes(memorySlice(memory, call.outOff, call.outOff+call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.gas != nil {
+			call.Gas = toHexInt(*call.gas)
+		}
+		// Inject the call into the previous one
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	if t.err == nil {
+		t.fault(err)
+	}
+	return nil
+}
+
+// fault handles the failure of the current call.
+func (t *callTracer) fault(err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+
+	// Consume all available gas
+	if call.gas != nil {
+		call.Gas = toHexInt(*call.gas)
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output, t.gasUsed, t.time, t.callErr = output, gasUsed, d, err
+	return nil
+}
+
+// GetResult returns the top level call with all the internal calls nested in
+// it, or any accumulated error.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	result := &callFrame{
+		Type:    t.typ,
+		From:    hexBytes(t.from.Bytes()),
+		To:      hexBytes(t.to.Bytes()),
+		Value:   toHex(t.value),
+		Gas:     toHexInt(int64(t.gas)),
+		GasUsed: toHexInt(int64(t.gasUsed)),
+		Input:   hexBytes(t.input),
+		Output:  hexBytes(t.output),
+		Time:    t.time.String(),
+		Calls:   t.callstack[0].Calls,
+	}
+	if t.callstack[0].Error != "" {
+		result.Error = t.callstack[0].Error
+	} else if t.callErr != nil {
+		result.Error = t.callErr.Error()
+	}
+	if result.Error != "" {
+		result.Output = ""
+	}
+	return marshal(result)
+}
@@ -0,0 +1,113 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/vm"
+)
+
+// fourByteTracer is a native implementation of the JavaScript 4byteTracer,
+// which collects the 4 byte method identifiers of all the calls made by a
+// transaction, along with the size of the supplied call data.
+type fourByteTracer struct {
+	interrupter
+
+	ids   *orderedObject // Number of calls per identifier and data size
+	input []byte         // Call data of the outer call
+
+	err error // Error interrupting the tracing
+}
+
+func newFourByteTracer() TxTracer {
+	return &fourByteTracer{ids: newOrderedObject()}
+}
+
+// store saves the given identifier and data size.
+func (t *fourByteTracer) store(id []byte, size int64) {
+	key := hexBytes(id) + "-" + strconv.FormatInt(size, 10)
+	count, _ := t.ids.get(key)
+	if count == nil {
+		count = 0
+	}
+	t.ids.set(key, count.(int)+1)
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.input = input
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	if t.err = t.interrupted(); t.err != nil {
+		return nil
+	}
+	// Skip any opcodes that are not internal calls, the offset is the stack
+	// position of the call data after the value
+	var offset int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		offset = 3
+	case vm.DELEGATECALL, vm.STATICCALL:
+		offset = 2
+	default:
+		return nil
+	}
+	// Skip any pre-compile invocations, those are just fancy opcodes
+	if isPrecompiled(peekAddress(stack, 1)) {
+		return nil
+	}
+	// Gather internal call details
+	if size := peekUint(stack, offset+1); size >= 4 {
+		inOff := peekUint(stack, offset)
+		t.store(memorySlice(memory, inOff, inOff+4), size-4)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *fourByteTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the collected identifiers, or any accumulated error.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	// Save the outer calldata also
+	if len(t.input) >= 4 {
+		t.store(t.input[:4], int64(len(t.input)-4))
+	}
+	return marshal(t.ids)
+}
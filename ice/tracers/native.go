@@ -0,0 +1,179 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/holiman/uint256"
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/log"
+)
+
+// interrupter implements the interruption of the native tracers.
+type interrupter struct {
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (i *interrupter) Stop(err error) {
+	i.reason = err
+	atomic.StoreUint32(&i.interrupt, 1)
+}
+
+// interrupted returns the interruption reason if the tracer was stopped.
+func (i *interrupter) interrupted() error {
+	if atomic.LoadUint32(&i.interrupt) > 0 {
+		return i.reason
+	}
+	return nil
+}
+
+// peek returns the n-th item from the top of the stack, or zero if the stack
+// is not deep enough, matching the stack access of the JavaScript tracers.
+func peek(stack *vm.Stack, n int) *uint256.Int {
+	if len(stack.Data()) <= n {
+		log.Warn("Tracer accessed out of bound stack", "size", len(stack.Data()), "index", n)
+		return new(uint256.Int)
+	}
+	return stack.Back(n)
+}
+
+// peekUint returns the n-th stack item as a memory offset or size.
+func peekUint(stack *vm.Stack, n int) int64 {
+	return int64(peek(stack, n).Uint64())
+}
+
+// peekAddress returns the n-th stack item as an account address.
+func peekAddress(stack *vm.Stack, n int) common.Address {
+	return common.Address(peek(stack, n).Bytes20())
+}
+
+// memorySlice returns a copy of the memory in [begin, end), or nil if it is
+// out of bounds, matching the memory access of the JavaScript tracers.
+func memorySlice(memory *vm.Memory, begin, end int64) []byte {
+	if int64(memory.Len()) < end {
+		log.Warn("Tracer accessed out of bound memory", "available", memory.Len(), "offset", begin, "size", end-begin)
+		return nil
+	}
+	return memory.GetCopy(begin, end-begin)
+}
+
+// isPrecompiled reports whether the address is one of the precompiles the
+// JavaScript tracers skip.
+func isPrecompiled(addr common.Address) bool {
+	_, ok := vm.PrecompiledContractsByzantium[addr]
+	return ok
+}
+
+// toHex formats a big integer the way the JavaScript tracers do, negative
+// values included.
+func toHex(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+// toHexInt formats a gas amount the way the JavaScript tracers do.
+func toHexInt(n int64) string {
+	return "0x" + strconv.FormatInt(n, 16)
+}
+
+// marshal encodes a tracer result without escaping HTML characters, the same
+// way the JavaScript engine encodes it.
+func marshal(v interface{}) (json.RawMessage, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(bytes.TrimSuffix(buf.Bytes(), []byte("\n"))), nil
+}
+
+// orderedObject is a JSON object keeping the insertion order of its keys, as
+// the objects of the JavaScript tracers do.
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedObject() *orderedObject {
+	return &orderedObject{values: make(map[string]interface{})}
+}
+
+// get retrieves the value stored under the key.
+func (o *orderedObject) get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// set stores the value under the key, appending the key if it is new.
+func (o *orderedObject) set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// delete removes the key from the object.
+func (o *orderedObject) delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// MarshalJSON implements json.Marshaler, encoding the keys in insertion order.
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// hexBytes formats a byte slice the way the JavaScript tracers do.
+func hexBytes(b []byte) string {
+	return hexutil.Encode(b)
+}
@@ -0,0 +1,193 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/core/vm/runtime"
+	"github.com/iceming123/go-ice/icedb"
+)
+
+// teeTracer forwards all the tracing events to several tracers, so they all
+// see the exact same execution.
+type teeTracer []vm.Tracer
+
+func (t teeTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	for _, tracer := range t {
+		tracer.CaptureStart(from, to, create, input, gas, value)
+	}
+	return nil
+}
+
+func (t teeTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	for _, tracer := range t {
+		tracer.CaptureState(env, pc, op, gas, cost, memory, stack, rStack, rData, contract, depth, err)
+	}
+	return nil
+}
+
+func (t teeTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	for _, tracer := range t {
+		tracer.CaptureFault(env, pc, op, gas, cost, memory, stack, rStack, contract, depth, err)
+	}
+	return nil
+}
+
+func (t teeTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	for _, tracer := range t {
+		tracer.CaptureEnd(output, gasUsed, d, err)
+	}
+	return nil
+}
+
+var (
+	callerAddr   = common.HexToAddress("0xaa")
+	storeAddr    = common.HexToAddress("0xbb")
+	revertAddr   = common.HexToAddress("0xcc")
+	destructAddr = common.HexToAddress("0xdd")
+	invalidAddr  = common.HexToAddress("0xee")
+)
+
+// newTraceState creates a state with a contract calling into a storing, a
+// reverting, a self destructing and a faulting contract, a precompile, and
+// creating a new contract.
+func newTraceState(t *testing.T, staking bool) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(icedb.NewMemDatabase()))
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	caller := hexutil.MustDecode("0x" +
+		// Store a method identifier at memory 0
+		"7f1234567800000000000000000000000000000000000000000000000000000000" + "600052" +
+		// Call the storing contract with the identifier and an argument
+		"6020604060246000600060bb5af150" +
+		// Call the reverting contract
+		"6000600060046000600060cc5af150" +
+		// Static call the sha256 precompile
+		"602060606004600060025afa50" +
+		// Delegate call the storing contract
+		"602060806004600060bb5af450" +
+		// Call the self destructing contract with some value
+		"6000600060006000600160dd5af150" +
+		// Call the faulting contract
+		"6000600060006000600060ee611000f150")
+	if staking {
+		// Call the staking precompile with the identifier
+		caller = append(caller, hexutil.MustDecode("0x60006000600460006000"+"73"+types.StakingAddress.Hex()[2:]+"5af150")...)
+	}
+	caller = append(caller, hexutil.MustDecode("0x"+
+		// Create a contract returning empty code
+		"6460006000f3600052"+"6005601b6000f050"+
+		// Return the output of the storing contract
+		"60206040f3")...)
+
+	statedb.SetCode(callerAddr, caller)
+	statedb.SetBalance(callerAddr, big.NewInt(10))
+	statedb.SetCode(storeAddr, hexutil.MustDecode("0x602a60005560005460005260206000f3"))
+	statedb.SetCode(revertAddr, hexutil.MustDecode("0x60006000fd"))
+	statedb.SetCode(destructAddr, hexutil.MustDecode("0x60aaff"))
+	statedb.SetCode(invalidAddr, hexutil.MustDecode("0xfe"))
+	return statedb
+}
+
+// trace runs the caller contract with the given tracers attached.
+func trace(t *testing.T, statedb *state.StateDB, to common.Address, tracers ...vm.Tracer) {
+	cfg := &runtime.Config{
+		Origin:    common.HexToAddress("0x01"),
+		GasLimit:  1000000,
+		State:     statedb,
+		EVMConfig: vm.Config{Debug: true, Tracer: teeTracer(tracers)},
+	}
+	input := hexutil.MustDecode("0x12345678000000000000000000000000000000000000000000000000000000000000002a")
+	runtime.Call(to, input, cfg)
+}
+
+// Tests that the native tracers produce the same results as the JavaScript
+// tracers of the same name.
+func TestNativeTracers(t *testing.T) {
+	for name := range natives {
+		jst, err := New(name)
+		if err != nil {
+			t.Fatalf("%s: failed to create JavaScript tracer: %v", name, err)
+		}
+		native := natives[name]()
+		trace(t, newTraceState(t, false), callerAddr, jst, native)
+
+		want, err := jst.GetResult()
+		if err != nil {
+			t.Fatalf("%s: JavaScript tracer failed: %v", name, err)
+		}
+		have, err := native.GetResult()
+		if err != nil {
+			t.Fatalf("%s: native tracer failed: %v", name, err)
+		}
+		if string(have) != string(want) {
+			t.Errorf("%s: result mismatch\nhave %s\nwant %s", name, have, want)
+		}
+	}
+}
+
+// Tests that the calls into the staking precompile are reported as distinct
+// frames by the native call tracer.
+func TestCallTracerStaking(t *testing.T) {
+	tracer := newCallTracer()
+	trace(t, newTraceState(t, true), callerAddr, tracer)
+
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to trace: %v", err)
+	}
+	var result callFrame
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	var staking *callFrame
+	for _, call := range result.Calls {
+		if call.Type == stakingFrame {
+			staking = call
+		}
+	}
+	if staking == nil {
+		t.Fatalf("staking frame missing: %s", res)
+	}
+	if staking.To != hexBytes(types.StakingAddress.Bytes()) || staking.Input != "0x12345678" {
+		t.Errorf("staking frame mismatch: %+v", staking)
+	}
+	// Calls sent to the precompile directly are staking frames too
+	tracer = newCallTracer()
+	trace(t, newTraceState(t, false), types.StakingAddress, tracer)
+
+	res, err = tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to trace: %v", err)
+	}
+	if err := json.Unmarshal(res, &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if result.Type != stakingFrame {
+		t.Errorf("top level frame type mismatch: have %s, want %s", result.Type, stakingFrame)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
+)
+
+// prestateAccount is an account of the prestate, formatted and ordered the
+// same way the JavaScript prestateTracer does.
+type prestateAccount struct {
+	Balance string         `json:"balance"`
+	Nonce   int64          `json:"nonce"`
+	Code    string         `json:"code"`
+	Storage *orderedObject `json:"storage"`
+
+	balance *big.Int
+}
+
+// prestateTracer is a native implementation of the JavaScript prestateTracer,
+// which assembles the state accessed by a transaction as it was before the
+// transaction was executed.
+type prestateTracer struct {
+	interrupter
+
+	prestate *orderedObject // Accounts of the prestate, nil until the first step
+	db       vm.StateDB     // State database of the traced transaction
+
+	create bool
+	from   common.Address
+	to     common.Address
+	value  *big.Int
+
+	err error // Error interrupting the tracing
+}
+
+func newPrestateTracer() TxTracer {
+	return new(prestateTracer)
+}
+
+// lookupAccount injects the specified account into the prestate object.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	key := hexBytes(addr.Bytes())
+	if _, ok := t.prestate.get(key); ok {
+		return
+	}
+	balance := t.db.GetBalance(addr)
+	t.prestate.set(key, &prestateAccount{
+		Balance: toHex(balance),
+		Nonce:   int64(t.db.GetNonce(addr)),
+		Code:    hexBytes(t.db.GetCode(addr)),
+		Storage: newOrderedObject(),
+		balance: new(big.Int).Set(balance),
+	})
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate object.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+	account, _ := t.prestate.get(hexBytes(addr.Bytes()))
+	storage := account.(*prestateAccount).Storage
+
+	idx := hexBytes(key.Bytes())
+	if _, ok := storage.get(idx); ok {
+		return
+	}
+	if val := t.db.GetState(addr, key); val != (common.Hash{}) {
+		storage.set(idx, hexBytes(val.Bytes()))
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create, t.from, t.to, t.value = create, from, to, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, rData []byte, contract *vm.Contract, depth int, err error) error {
+	if t.err != nil {
+		return nil
+	}
+	if t.err = t.interrupted(); t.err != nil {
+		return nil
+	}
+	// Add the current account if we just started tracing
+	if t.prestate == nil {
+		t.prestate = newOrderedObject()
+		t.db = env.StateDB
+
+		// Balance will potentially be wrong here, since this will include the value
+		// sent along with the message. We fix that in GetResult.
+		t.lookupAccount(contract.Address())
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		t.lookupAccount(peekAddress(stack, 0))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(peekAddress(stack, 1))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.Hash(peek(stack, 0).Bytes32()))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, rStack *vm.ReturnStack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the assembled prestate, or any accumulated error.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.prestate == nil {
+		return nil, errors.New("no code executed, prestate unavailable")
+	}
+	// At this point, we need to deduct the 'value' from the outer transaction,
+	// and move it back to the origin
+	t.lookupAccount(t.from)
+
+	fromAcc, _ := t.prestate.get(hexBytes(t.from.Bytes()))
+	toAcc, ok := t.prestate.get(hexBytes(t.to.Bytes()))
+	if !ok {
+		return nil, fmt.Errorf("prestate of recipient %x missing", t.to)
+	}
+	from, to := fromAcc.(*prestateAccount), toAcc.(*prestateAccount)
+
+	to.balance.Sub(to.balance, t.value)
+	to.Balance = toHex(to.balance)
+	from.balance.Add(from.balance, t.value)
+	from.Balance = toHex(from.balance)
+
+	// Decrement the caller's nonce, and remove empty create targets
+	from.Nonce--
+	if t.create {
+		// We can blindly delete the contract prestate, as any existing state would
+		// have caused the transaction to be rejected as invalid in the first place.
+		t.prestate.delete(hexBytes(t.to.Bytes()))
+	}
+	return marshal(t.prestate)
+}
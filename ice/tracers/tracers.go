@@ -14,13 +14,16 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native Go transaction
+// tracers.
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/ice/tracers/internal/tracers"
 )
 
@@ -51,3 +54,33 @@ func tracer(name string) (string, bool) {
 	}
 	return "", false
 }
+
+// TxTracer is a transaction tracer assembling a JSON result, which can be
+// interrupted from the outside. It is implemented by both the JavaScript and
+// the native tracers.
+type TxTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON result of the trace, or any accumulated error.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates the tracing at the first opportune moment.
+	Stop(err error)
+}
+
+// natives contains the built in native tracers by name. They produce the same
+// results as the JavaScript tracers of the same name, so they take precedence.
+var natives = map[string]func() TxTracer{
+	"callTracer":     newCallTracer,
+	"prestateTracer": newPrestateTracer,
+	"4byteTracer":    newFourByteTracer,
+}
+
+// NewTxTracer creates the native tracer with the given name, or falls back to
+// a JavaScript tracer for any other name or code snippet.
+func NewTxTracer(code string) (TxTracer, error) {
+	if ctor, ok := natives[code]; ok {
+		return ctor(), nil
+	}
+	return New(code)
+}
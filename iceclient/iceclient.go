@@ -29,7 +29,6 @@ import (
 	"github.com/iceming123/go-ice/common/hexutil"
 	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/core/vm"
-	"github.com/iceming123/go-ice/rlp"
 	"github.com/iceming123/go-ice/rpc"
 )
 
@@ -680,12 +679,28 @@ func (ec *Client) EstimateGas(ctx context.Context, msg icechain.CallMsg) (uint64
 	return uint64(hex), nil
 }
 
+// CreateAccessList tries to create an access list for a specific transaction
+// based on the current pending state of the blockchain. It returns the list
+// together with the gas used and the error message of a failed execution.
+func (ec *Client) CreateAccessList(ctx context.Context, msg icechain.CallMsg) (*types.AccessList, uint64, string, error) {
+	type accessListResult struct {
+		Accesslist *types.AccessList `json:"accessList"`
+		Error      string            `json:"error,omitempty"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	}
+	var result accessListResult
+	if err := ec.c.CallContext(ctx, &result, "ice_createAccessList", toCallArg(msg)); err != nil {
+		return nil, 0, "", err
+	}
+	return result.Accesslist, uint64(result.GasUsed), result.Error, nil
+}
+
 // SendTransaction injects a signed transaction into the pending pool for execution.
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -697,7 +712,7 @@ func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) er
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendPayTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -724,6 +739,9 @@ func toCallArg(msg icechain.CallMsg) interface{} {
 	if msg.Fee != nil {
 		arg["fee"] = (*hexutil.Big)(msg.Fee)
 	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
 	return arg
 }
 
@@ -0,0 +1,285 @@
+// Copyright 2019 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package icedb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/metrics"
+)
+
+var (
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+const (
+	// FreezerBatchLimit is the maximum number of items a chain migrates into
+	// its freezer in one run before yielding.
+	FreezerBatchLimit = 2000
+
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+)
+
+// FreezeFunc moves the next finalized segment of a chain out of the key-value
+// store into its freezer, and returns the number of the items moved.
+type FreezeFunc func(db Database, f *Freezer) (int, error)
+
+// Freezer is an append-only database to store immutable chain data into flat
+// files, the append only nature ensures that disk writes are minimized.
+//
+// Every chain (fast and snail) keeps its own freezer, the tables of a freezer
+// always contain the same number of items.
+type Freezer struct {
+	// WARNING: The `frozen` field is accessed atomically. On 32 bit platforms, only
+	// 64-bit aligned fields can be atomic. The struct is guaranteed to be so aligned,
+	// so take advantage of that (https://golang.org/pkg/sync/atomic/#pkg-note-BUG).
+	frozen uint64 // Number of blocks already frozen
+
+	tables map[string]*freezerTable // Data tables for storing everything
+
+	quit      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers. The tables map lists the table names
+// along with whether snappy compression is disabled for them.
+func NewFreezer(datadir string, namespace string, tables map[string]bool) (*Freezer, error) {
+	// Create the initial freezer object
+	var (
+		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
+		writeMeter = metrics.NewRegisteredMeter(namespace+"ancient/write", nil)
+		sizeGauge  = metrics.NewRegisteredGauge(namespace+"ancient/size", nil)
+	)
+	if info, err := os.Lstat(datadir); !os.IsNotExist(err) {
+		if info.Mode()&os.ModeSymlink != 0 {
+			log.Warn("Symbolic link ancient database is not supported", "path", datadir)
+			return nil, errors.New("symbolic link datadir is not supported")
+		}
+	}
+	freezer := &Freezer{
+		tables: make(map[string]*freezerTable),
+		quit:   make(chan struct{}),
+	}
+	for name, disableSnappy := range tables {
+		table, err := newTable(datadir, name, readMeter, writeMeter, sizeGauge, disableSnappy)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "items", freezer.frozen)
+	return freezer, nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *Freezer) Close() error {
+	var errs []error
+	f.closeOnce.Do(func() {
+		close(f.quit)
+		f.wg.Wait()
+
+		for _, table := range f.tables {
+			if err := table.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *Freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *Freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the length of the frozen items.
+func (f *Freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AncientSize returns the ancient size of the specified category.
+func (f *Freezer) AncientSize(kind string) (uint64, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.size()
+	}
+	return 0, errUnknownTable
+}
+
+// AppendAncient injects the items of a chain element into the immutable
+// ancient data store, one blob per table. The number is a precautionary
+// parameter to ensure data correctness, already frozen data is rejected.
+//
+// Notably, this function is lock free but kind of thread-safe. All out-of-order
+// injection will be rejected. But if two injections with same number happen at
+// the same time, we can get into the trouble.
+func (f *Freezer) AppendAncient(number uint64, items map[string][]byte) (err error) {
+	// Ensure the binary blobs we are appending is continuous with freezer.
+	if atomic.LoadUint64(&f.frozen) != number {
+		return errOutOrderInsertion
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			rerr := f.repair()
+			if rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Info("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	for name, table := range f.tables {
+		blob, ok := items[name]
+		if !ok {
+			return fmt.Errorf("missing %s item of %d", name, number)
+		}
+		if err := table.Append(f.frozen, blob); err != nil {
+			log.Error("Failed to append ancient item", "table", name, "number", f.frozen, "err", err)
+			return err
+		}
+	}
+	atomic.AddUint64(&f.frozen, 1) // Only modify atomically
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *Freezer) TruncateAncients(items uint64) error {
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *Freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Freeze starts a background thread that keeps calling fn to move the
+// finalized chain segments from db into the freezer. The thread backs off
+// for a while once fn runs out of data or fails, and terminates on Close.
+func (f *Freezer) Freeze(db Database, fn FreezeFunc) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		backoff := false
+		for {
+			select {
+			case <-f.quit:
+				log.Info("Freezer shutting down")
+				return
+			default:
+			}
+			if backoff {
+				timer := time.NewTimer(freezerRecheckInterval)
+				select {
+				case <-timer.C:
+				case <-f.quit:
+					timer.Stop()
+					return
+				}
+			}
+			start := time.Now()
+			frozen, err := fn(db, f)
+			if err != nil {
+				log.Error("Failed to freeze chain segment", "err", err)
+			}
+			if frozen > 0 {
+				items, _ := f.Ancients()
+				log.Info("Moved ancient chain segment into freezer", "items", frozen, "frozen", items, "elapsed", common.PrettyDuration(time.Since(start)))
+			}
+			backoff = err != nil || frozen < FreezerBatchLimit
+		}
+	}()
+}
+
+// repair truncates all data tables to the same length.
+func (f *Freezer) repair() error {
+	min := uint64(0)
+	first := true
+	for _, table := range f.tables {
+		items := atomic.LoadUint64(&table.items)
+		if first || min > items {
+			min, first = items, false
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
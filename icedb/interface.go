@@ -16,6 +16,8 @@
 
 package icedb
 
+import "github.com/syndtr/goleveldb/leveldb/iterator"
+
 // Code using batches should try to add this much data to the batch.
 // The value was determined empirically.
 const IdealBatchSize = 100 * 1024
@@ -30,6 +32,32 @@ type Deleter interface {
 	Delete(key []byte) error
 }
 
+// Iteratee wraps the prefix iteration supported by the persistent and memory
+// databases. The keys are iterated in ascending order.
+type Iteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// AncientReader wraps the retrieval of the immutable chain data which was
+// moved out of the key-value store into the freezer of the named chain.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified data exists in the
+	// ancient store of the chain.
+	HasAncient(chain, kind string, number uint64) (bool, error)
+
+	// Ancient retrieves an ancient binary blob from the chain freezer.
+	Ancient(chain, kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of the items frozen for the chain.
+	Ancients(chain string) (uint64, error)
+}
+
+// AncientWriter wraps the rewinding of a chain freezer.
+type AncientWriter interface {
+	// TruncateAncients discards all but the first n ancient items of the chain.
+	TruncateAncients(chain string, n uint64) error
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
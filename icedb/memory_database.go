@@ -17,10 +17,14 @@
 package icedb
 
 import (
+	"bytes"
 	"errors"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/iceming123/go-ice/common"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
 )
 
 /*
@@ -96,8 +100,35 @@ func (db *MemDatabase) NewBatch() Batch {
 
 func (db *MemDatabase) Len() int { return len(db.db) }
 
+// NewIteratorWithPrefix returns a iterator over a copy of the database content
+// with a particular prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) iterator.Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var entries memEntries
+	for key, value := range db.db {
+		if strings.HasPrefix(key, string(prefix)) {
+			entries = append(entries, kv{[]byte(key), common.CopyBytes(value)})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].k, entries[j].k) < 0 })
+	return iterator.NewArrayIterator(entries)
+}
+
 type kv struct{ k, v []byte }
 
+// memEntries is a sorted list of entries implementing iterator.Array.
+type memEntries []kv
+
+func (e memEntries) Len() int { return len(e) }
+
+func (e memEntries) Search(key []byte) int {
+	return sort.Search(len(e), func(i int) bool { return bytes.Compare(e[i].k, key) >= 0 })
+}
+
+func (e memEntries) Index(i int) (key, value []byte) { return e[i].k, e[i].v }
+
 type memBatch struct {
 	db     *MemDatabase
 	writes []kv
@@ -25,9 +25,11 @@ import (
 	"runtime"
 
 	"github.com/fjl/memsize/memsizeui"
+	_ "github.com/iceming123/go-ice/consensus/tbft/metrics" // Register the TBFT metrics for the exporters
 	"github.com/iceming123/go-ice/log"
 	"github.com/iceming123/go-ice/metrics"
 	"github.com/iceming123/go-ice/metrics/exp"
+	"github.com/iceming123/go-ice/metrics/prometheus"
 	colorable "github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"gopkg.in/urfave/cli.v1"
@@ -157,8 +159,10 @@ func StartPProf(address string) {
 	// Hook go-metrics into expvar on any /debug/metrics request, load all vars
 	// from the registry into expvar, and execute regular expvar handler.
 	exp.Exp(metrics.DefaultRegistry)
+	http.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
 	http.Handle("/memsize/", http.StripPrefix("/memsize", &Memsize))
 	log.Info("Starting pprof server", "addr", fmt.Sprintf("http://%s/debug/pprof", address))
+	log.Info("Serving Prometheus metrics", "addr", fmt.Sprintf("http://%s/debug/metrics/prometheus", address))
 	go func() {
 		if err := http.ListenAndServe(address, nil); err != nil {
 			log.Error("Failure in running pprof server", "err", err)
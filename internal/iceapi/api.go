@@ -153,11 +153,13 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
-// easily inspectable list.
+// easily inspectable list. Payers are listed with the number of transactions
+// they sponsor and the gas cost they are liable for.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	content := map[string]map[string]map[string]string{
 		"pending": make(map[string]map[string]string),
 		"queued":  make(map[string]map[string]string),
+		"payers":  make(map[string]map[string]string),
 	}
 	pending, queue := s.b.TxPoolContent()
 
@@ -184,6 +186,17 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 		}
 		content["queued"][account.Hex()] = dump
 	}
+	// Summarize the sponsored transactions
+	for payer, txs := range s.b.TxPoolSponsored() {
+		cost := new(big.Int)
+		for _, tx := range txs {
+			cost.Add(cost, tx.GasCost())
+		}
+		content["payers"][payer.Hex()] = map[string]string{
+			"slots": fmt.Sprintf("%d", len(txs)),
+			"cost":  fmt.Sprintf("%v wei", cost),
+		}
+	}
 	return content
 }
 
@@ -484,7 +497,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 	if err != nil {
 		return nil, err
 	}
-	if args.Fee == nil { //normal ethereum transaction
+	if args.Fee == nil && signed.Type() == types.LegacyTxType { //normal ethereum transaction
 		//fmt.Println("into no fee")
 		raw_tx_signed := signed.ConvertRawTransaction()
 		if err != nil {
@@ -497,7 +510,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 		return &SignTransactionResult{data, signed}, nil
 	} else { //fee not nil
 		//fmt.Println("into not nil of fee")
-		data, err := rlp.EncodeToBytes(signed)
+		data, err := signed.MarshalBinary()
 		if err != nil {
 			return nil, err
 		}
@@ -844,6 +857,8 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 	Payer    common.Address  `json:"payer"`
 	Fee      hexutil.Big     `json:"fee"`
+
+	AccessList *types.AccessList `json:"accessList,omitempty"`
 }
 
 func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockHr rpc.BlockNumberOrHash, vmCfg vm.Config, timeout time.Duration) (*core.ExecutionResult, error) {
@@ -872,7 +887,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockHr
 	}
 
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, args.Payer, 0, args.Value.ToInt(), args.Fee.ToInt(), gas, gasPrice, args.Data, false)
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	msg := types.NewMessage(addr, args.To, args.Payer, 0, args.Value.ToInt(), args.Fee.ToInt(), gas, gasPrice, args.Data, accessList, false)
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -1003,6 +1022,98 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	return hexutil.Uint64(hi), nil
 }
 
+// accessListResult returns an optional accesslist
+// Its the result of the `eth_createAccessList` RPC call.
+// It contains an error if the transaction itself failed.
+type accessListResult struct {
+	Accesslist *types.AccessList `json:"accessList"`
+	Error      string            `json:"error,omitempty"`
+	GasUsed    hexutil.Uint64    `json:"gasUsed"`
+}
+
+// CreateAccessList creates an access list for the given transaction.
+// If the accesslist creation fails an error is returned.
+// If the transaction itself fails, an vmErr is returned.
+func (s *PublicBlockChainAPI) CreateAccessList(ctx context.Context, args SendTxArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*accessListResult, error) {
+	bNrOrHash := rpc.BlockNumberOrHashWithNumber(rpc.PendingBlockNumber)
+	if blockNrOrHash != nil {
+		bNrOrHash = *blockNrOrHash
+	}
+	acl, gasUsed, vmerr, err := AccessList(ctx, s.b, bNrOrHash, args)
+	if err != nil {
+		return nil, err
+	}
+	result := &accessListResult{Accesslist: &acl, GasUsed: hexutil.Uint64(gasUsed)}
+	if vmerr != nil {
+		result.Error = vmerr.Error()
+	}
+	return result, nil
+}
+
+// AccessList creates an access list for the given transaction. The
+// transaction is executed repeatedly with the access list collected in the
+// previous run until the list no longer changes.
+func AccessList(ctx context.Context, b Backend, blockNrOrHash rpc.BlockNumberOrHash, args SendTxArgs) (acl types.AccessList, gasUsed uint64, vmErr error, err error) {
+	// Retrieve the execution context
+	db, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if db == nil || err != nil {
+		return nil, 0, nil, err
+	}
+	// Without an explicit gas amount the whole block gas limit is available
+	if args.Gas == nil {
+		args.Gas = (*hexutil.Uint64)(&header.GasLimit)
+	}
+	// Ensure any missing fields are filled, extract the recipient and input data
+	if err := args.setDefaults(ctx, b); err != nil {
+		return nil, 0, nil, err
+	}
+	var to common.Address
+	if args.To != nil {
+		to = *args.To
+	} else {
+		to = crypto.CreateAddress(args.From, uint64(*args.Nonce))
+	}
+	var input []byte
+	if args.Input != nil {
+		input = *args.Input
+	} else if args.Data != nil {
+		input = *args.Data
+	}
+	// Retrieve the precompiles since they don't need to be added to the access list
+	precompiles := vm.ActivePrecompiles(b.ChainConfig().Rules(header.Number))
+
+	// Create an initial tracer
+	prevTracer := vm.NewAccessListTracer(nil, args.From, to, precompiles)
+	if args.AccessList != nil {
+		prevTracer = vm.NewAccessListTracer(*args.AccessList, args.From, to, precompiles)
+	}
+	for {
+		// Retrieve the current access list to expand
+		accessList := prevTracer.AccessList()
+		log.Trace("Creating access list", "input", accessList)
+
+		// Copy the original db so we don't modify it
+		statedb := db.Copy()
+		msg := types.NewMessage(args.From, args.To, args.Payment, uint64(*args.Nonce), args.Value.ToInt(), args.Fee.ToInt(), uint64(*args.Gas), args.GasPrice.ToInt(), input, accessList, false)
+
+		// Apply the transaction with the access list tracer
+		tracer := vm.NewAccessListTracer(accessList, args.From, to, precompiles)
+		config := vm.Config{Tracer: tracer, Debug: true}
+		vmenv, _, err := b.GetEVM(ctx, msg, statedb, header, config)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		res, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to apply transaction: %v err: %v", args.toTransaction().Hash(), err)
+		}
+		if tracer.Equal(prevTracer) {
+			return accessList, res.UsedGas, res.Err, nil
+		}
+		prevTracer = tracer
+	}
+}
+
 func (s *PublicBlockChainAPI) GetCommittee(id rpc.BlockNumber) (map[string]interface{}, error) {
 	detail, err := s.b.GetCommittee(id)
 	return detail, err
@@ -1335,48 +1446,54 @@ func (s *PublicBlockChainAPI) GetChainRewardContent(blockNr rpc.BlockNumber, add
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        common.Hash     `json:"blockHash"`
-	BlockNumber      *hexutil.Big    `json:"blockNumber"`
-	From             common.Address  `json:"from"`
-	Gas              hexutil.Uint64  `json:"gas"`
-	GasPrice         *hexutil.Big    `json:"gasPrice"`
-	Hash             common.Hash     `json:"hash"`
-	Input            hexutil.Bytes   `json:"input"`
-	Nonce            hexutil.Uint64  `json:"nonce"`
-	To               *common.Address `json:"to"`
-	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
-	Value            *hexutil.Big    `json:"value"`
-	V                *hexutil.Big    `json:"v"`
-	R                *hexutil.Big    `json:"r"`
-	S                *hexutil.Big    `json:"s"`
-	Payer            *common.Address `json:"payer"`
-	Fee              *hexutil.Big    `json:"fee"`
-	PV               *hexutil.Big    `json:"pv"`
-	PR               *hexutil.Big    `json:"pr"`
-	PS               *hexutil.Big    `json:"ps"`
+	BlockHash        common.Hash       `json:"blockHash"`
+	BlockNumber      *hexutil.Big      `json:"blockNumber"`
+	From             common.Address    `json:"from"`
+	Gas              hexutil.Uint64    `json:"gas"`
+	GasPrice         *hexutil.Big      `json:"gasPrice"`
+	Hash             common.Hash       `json:"hash"`
+	Input            hexutil.Bytes     `json:"input"`
+	Nonce            hexutil.Uint64    `json:"nonce"`
+	To               *common.Address   `json:"to"`
+	TransactionIndex hexutil.Uint      `json:"transactionIndex"`
+	Value            *hexutil.Big      `json:"value"`
+	V                *hexutil.Big      `json:"v"`
+	R                *hexutil.Big      `json:"r"`
+	S                *hexutil.Big      `json:"s"`
+	Payer            *common.Address   `json:"payer"`
+	Fee              *hexutil.Big      `json:"fee"`
+	PV               *hexutil.Big      `json:"pv"`
+	PR               *hexutil.Big      `json:"pr"`
+	PS               *hexutil.Big      `json:"ps"`
+	Type             hexutil.Uint64    `json:"type"`
+	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	Accesses         *types.AccessList `json:"accessList,omitempty"`
 }
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction2 struct {
-	BlockHash        common.Hash     `json:"blockHash"`
-	BlockNumber      *hexutil.Big    `json:"blockNumber"`
-	From             common.Address  `json:"from"`
-	Gas              hexutil.Uint64  `json:"gas"`
-	GasPrice         *hexutil.Big    `json:"gasPrice"`
-	Hash             common.Hash     `json:"hash"`
-	Input            hexutil.Bytes   `json:"input"`
-	Nonce            hexutil.Uint64  `json:"nonce"`
-	To               *common.Address `json:"to"`
-	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
-	Value            *hexutil.Big    `json:"value"`
-	V                *hexutil.Big    `json:"v"`
-	R                *hexutil.Big    `json:"r"`
-	S                *hexutil.Big    `json:"s"`
-	Payer            *common.Address `json:"payer"`
-	Fee              *hexutil.Big    `json:"fee"`
-	PV               *hexutil.Big    `json:"pv"`
-	PR               *hexutil.Big    `json:"pr"`
-	PS               *hexutil.Big    `json:"ps"`
+	BlockHash        common.Hash       `json:"blockHash"`
+	BlockNumber      *hexutil.Big      `json:"blockNumber"`
+	From             common.Address    `json:"from"`
+	Gas              hexutil.Uint64    `json:"gas"`
+	GasPrice         *hexutil.Big      `json:"gasPrice"`
+	Hash             common.Hash       `json:"hash"`
+	Input            hexutil.Bytes     `json:"input"`
+	Nonce            hexutil.Uint64    `json:"nonce"`
+	To               *common.Address   `json:"to"`
+	TransactionIndex hexutil.Uint      `json:"transactionIndex"`
+	Value            *hexutil.Big      `json:"value"`
+	V                *hexutil.Big      `json:"v"`
+	R                *hexutil.Big      `json:"r"`
+	S                *hexutil.Big      `json:"s"`
+	Payer            *common.Address   `json:"payer"`
+	Fee              *hexutil.Big      `json:"fee"`
+	PV               *hexutil.Big      `json:"pv"`
+	PR               *hexutil.Big      `json:"pr"`
+	PS               *hexutil.Big      `json:"ps"`
+	Type             hexutil.Uint64    `json:"type"`
+	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	Accesses         *types.AccessList `json:"accessList,omitempty"`
 }
 
 func newRPCTransaction2(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64, IsAbey bool) *RPCTransaction2 {
@@ -1396,6 +1513,12 @@ func newRPCTransaction2(tx *types.Transaction, blockHash common.Hash, blockNumbe
 		V:        (*hexutil.Big)(v),
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
+		Type:     hexutil.Uint64(tx.Type()),
+	}
+	if tx.Type() != types.LegacyTxType {
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
 	}
 	if tx.Payer() != nil {
 		result.Payer = tx.Payer()
@@ -1434,6 +1557,12 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		V:        (*hexutil.Big)(v),
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
+		Type:     hexutil.Uint64(tx.Type()),
+	}
+	if tx.Type() != types.LegacyTxType {
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
 	}
 	if tx.Payer() != nil {
 		result.Payer = tx.Payer()
@@ -1489,7 +1618,7 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
+	blob, _ := txs[index].MarshalBinary()
 	return blob
 }
 
@@ -1646,8 +1775,8 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to the canonical encoding and return
+	return tx.MarshalBinary()
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
@@ -1736,6 +1865,10 @@ type SendTxArgs struct {
 	// newer name and should be preferred by clients.
 	Data  *hexutil.Bytes `json:"data"`
 	Input *hexutil.Bytes `json:"input"`
+
+	// For typed access list transactions
+	AccessList *types.AccessList `json:"accessList,omitempty"`
+	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
 }
 
 // setDefaults is a helper function that fills in default values for unspecified tx fields.
@@ -1776,6 +1909,14 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 			return errors.New(`contract creation without any data provided`)
 		}
 	}
+	if args.AccessList != nil {
+		if args.ChainID == nil {
+			args.ChainID = (*hexutil.Big)(b.ChainConfig().ChainID)
+		}
+		if !b.ChainConfig().IsTIP11(new(big.Int).Add(b.CurrentBlock().Number(), big.NewInt(1))) {
+			return types.ErrTxTypeNotSupported
+		}
+	}
 	return nil
 }
 
@@ -1786,6 +1927,9 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Input != nil {
 		input = *args.Input
 	}
+	if args.AccessList != nil {
+		return types.NewAccessListTransaction_Payment((*big.Int)(args.ChainID), uint64(*args.Nonce), args.To, (*big.Int)(args.Value), (*big.Int)(args.Fee), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, *args.AccessList, args.Payment)
+	}
 	if args.To == nil {
 		return types.NewContractCreation_Payment(uint64(*args.Nonce), (*big.Int)(args.Value), (*big.Int)(args.Fee), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, args.Payment)
 	}
@@ -1897,6 +2041,10 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 // SendRawTransaction will add the signed transaction to the transaction pool.
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	if len(encodedTx) > 0 && encodedTx[0] <= 0x7f {
+		// Typed transactions have no raw ethereum layout
+		return s.sendIceRawTransaction(ctx, encodedTx)
+	}
 	raw_tx := new(types.RawTransaction)
 	if err := rlp.DecodeBytes(encodedTx, raw_tx); err != nil {
 		log.Error("api method SendRawTransaction error", "error", err)
@@ -1909,7 +2057,7 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 
 func (s *PublicTransactionPoolAPI) sendIceRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
 		log.Error("api method sendIceRawTransaction error", "error", err)
 		return common.Hash{}, err
 	}
@@ -1969,7 +2117,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return nil, err
 	}
-	if args.Payment == (common.Address{}) && args.Fee == nil { //normal ethereum transaction
+	if args.Payment == (common.Address{}) && args.Fee == nil && signed.Type() == types.LegacyTxType { //normal ethereum transaction
 		raw_tx_signed := signed.ConvertRawTransaction()
 		if err != nil {
 			return nil, err
@@ -1980,7 +2128,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 		}
 		return &SignTransactionResult{data, signed}, nil
 	} else if args.Payment == (common.Address{}) { //pay is nil
-		data, err := rlp.EncodeToBytes(signed)
+		data, err := signed.MarshalBinary()
 		if err != nil {
 			return nil, err
 		}
@@ -1995,7 +2143,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 		return nil, err
 	}
 
-	data, err := rlp.EncodeToBytes(signed_payment)
+	data, err := signed_payment.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -2198,12 +2346,13 @@ func (s *PublicNetAPI) Version() string {
 
 // PublicImpawnAPI offers and API for the snail pool. It only operates on data that is non confidential.
 type PublicImpawnAPI struct {
-	b Backend
+	b         Backend
+	nonceLock *AddrLocker
 }
 
 // NewPublicFruitPoolAPI creates a new snail pool service that gives information about the snail pool.
-func NewPublicImpawnAPI(b Backend) *PublicImpawnAPI {
-	return &PublicImpawnAPI{b}
+func NewPublicImpawnAPI(b Backend, nonceLock *AddrLocker) *PublicImpawnAPI {
+	return &PublicImpawnAPI{b, nonceLock}
 }
 
 // GetAllStakingAccount returns the pendingFruits contained within the snail pool.
@@ -2300,6 +2449,130 @@ func (s *PublicImpawnAPI) GetImpawnSummay(ctx context.Context, blockNr rpc.Block
 	return types.ToJSON(impawn.Summay()), nil
 }
 
+// SubmitEvidence sends a transaction from the account to submit the RLP encoded
+// double sign evidence to the staking contract, the offender will be slashed.
+func (s *PublicImpawnAPI) SubmitEvidence(ctx context.Context, from common.Address, evidence hexutil.Bytes) (common.Hash, error) {
+	ev := new(types.DoubleSignEvidence)
+	if err := rlp.DecodeBytes(evidence, ev); err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := ev.Verify(); err != nil {
+		return common.Hash{}, err
+	}
+	abiStaking, err := abi.JSON(strings.NewReader(vm.StakeABIJSON))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	input, err := abiStaking.Pack("submitEvidence", []byte(evidence))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	gas, to := hexutil.Uint64(vm.StakingGas["submitEvidence"]), types.StakingAddress
+	args := SendTxArgs{
+		From:  from,
+		To:    &to,
+		Gas:   &gas,
+		Input: (*hexutil.Bytes)(&input),
+	}
+	return NewPublicTransactionPoolAPI(s.b, s.nonceLock).SendTransaction(ctx, args)
+}
+
+// GetEvidence returns the slash record of the evidence hash.
+func (s *PublicImpawnAPI) GetEvidence(ctx context.Context, hash common.Hash, blockNr rpc.BlockNumber) (map[string]interface{}, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	impawn := vm.NewImpawnImpl()
+	err = impawn.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	return impawn.GetSlashRecordRPC(hash), nil
+}
+
+// GetAllEvidence returns the slash records in the recent epochs.
+func (s *PublicImpawnAPI) GetAllEvidence(ctx context.Context, blockNr rpc.BlockNumber) ([]map[string]interface{}, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	impawn := vm.NewImpawnImpl()
+	err = impawn.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	return impawn.GetSlashRecordsRPC(), nil
+}
+
+// GetValidatorUptime returns the signing participation of the committee members in the recent epochs.
+func (s *PublicImpawnAPI) GetValidatorUptime(ctx context.Context, blockNr rpc.BlockNumber) ([]map[string]interface{}, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	uptime := vm.NewValidatorUptime()
+	err = uptime.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Uptime load error", "error", err)
+		return nil, err
+	}
+
+	return uptime.GetUptimeRPC(), nil
+}
+
+// GetReceipts returns the delegation receipts held by the address.
+func (s *PublicImpawnAPI) GetReceipts(ctx context.Context, addr common.Address, blockNr rpc.BlockNumber) ([]map[string]interface{}, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	impawn := vm.NewImpawnImpl()
+	err = impawn.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	return impawn.GetReceiptsRPC(addr), nil
+}
+
+// GetReceiptSupply returns all delegation receipts of the staking account.
+func (s *PublicImpawnAPI) GetReceiptSupply(ctx context.Context, holder common.Address, blockNr rpc.BlockNumber) (map[string]interface{}, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	impawn := vm.NewImpawnImpl()
+	err = impawn.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking load error", "error", err)
+		return nil, err
+	}
+
+	return impawn.GetReceiptSupplyRPC(holder), nil
+}
+
+// GetReceiptAllowance returns the receipt of holder which spender can transfer on behalf of owner.
+func (s *PublicImpawnAPI) GetReceiptAllowance(ctx context.Context, holder, owner, spender common.Address, blockNr rpc.BlockNumber) (*hexutil.Big, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	receipt := vm.NewStakingReceipt()
+	err = receipt.Load(state, types.StakingAddress)
+	if err != nil {
+		log.Error("Staking receipt load error", "error", err)
+		return nil, err
+	}
+
+	return (*hexutil.Big)(receipt.Allowance(holder, owner, spender)), nil
+}
+
 // NewPublicTransactionPoolAPI creates a new RPC service with methods specific for the transaction pool.
 func NewPublicTransactionPoolAPI2(b Backend, nonceLock *AddrLocker) *PublicTransactionPoolAPI2 {
 	return &PublicTransactionPoolAPI2{b, nonceLock}
@@ -2437,8 +2710,8 @@ func (s *PublicTransactionPoolAPI2) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to the canonical encoding and return
+	return tx.MarshalBinary()
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -2524,6 +2797,10 @@ func (s *PublicTransactionPoolAPI2) SendTransaction(ctx context.Context, args Se
 // SendRawTransaction will add the signed transaction to the transaction pool.
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI2) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	if len(encodedTx) > 0 && encodedTx[0] <= 0x7f {
+		// Typed transactions have no raw ethereum layout
+		return s.sendIceRawTransaction(ctx, encodedTx)
+	}
 	raw_tx := new(types.RawTransaction)
 	if err := rlp.DecodeBytes(encodedTx, raw_tx); err != nil {
 		log.Error("api method SendRawTransaction error", "error", err)
@@ -2536,7 +2813,7 @@ func (s *PublicTransactionPoolAPI2) SendRawTransaction(ctx context.Context, enco
 
 func (s *PublicTransactionPoolAPI2) sendIceRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
 		log.Error("api method sendIceRawTransaction error", "error", err)
 		return common.Hash{}, err
 	}
@@ -2583,7 +2860,7 @@ func (s *PublicTransactionPoolAPI2) SignTransaction(ctx context.Context, args Se
 	if err != nil {
 		return nil, err
 	}
-	if args.Payment == (common.Address{}) && args.Fee == nil { //normal ethereum transaction
+	if args.Payment == (common.Address{}) && args.Fee == nil && signed.Type() == types.LegacyTxType { //normal ethereum transaction
 		raw_tx_signed := signed.ConvertRawTransaction()
 		if err != nil {
 			return nil, err
@@ -2594,7 +2871,7 @@ func (s *PublicTransactionPoolAPI2) SignTransaction(ctx context.Context, args Se
 		}
 		return &SignTransactionResult{data, signed}, nil
 	} else if args.Payment == (common.Address{}) { //pay is nil
-		data, err := rlp.EncodeToBytes(signed)
+		data, err := signed.MarshalBinary()
 		if err != nil {
 			return nil, err
 		}
@@ -2609,7 +2886,7 @@ func (s *PublicTransactionPoolAPI2) SignTransaction(ctx context.Context, args Se
 		return nil, err
 	}
 
-	data, err := rlp.EncodeToBytes(signed_payment)
+	data, err := signed_payment.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +81,7 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolSponsored() map[common.Address]types.Transactions
 	SubscribeNewTxsEvent(chan<- types.NewTxsEvent) event.Subscription
 
 	ChainConfig() *params.ChainConfig
@@ -161,7 +162,7 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 		}, {
 			Namespace: "impawn",
 			Version:   "1.0",
-			Service:   NewPublicImpawnAPI(apiBackend),
+			Service:   NewPublicImpawnAPI(apiBackend, nonceLock),
 			Public:    true,
 		},
 	}...)
@@ -18,19 +18,20 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":     Admin_JS,
-	"clique":    Clique_JS,
-	"debug":     Debug_JS,
-	"ice":       Ice_JS,
-	"miner":     Miner_JS,
-	"net":       Net_JS,
-	"personal":  Personal_JS,
-	"rpc":       RPC_JS,
-	"shh":       Shh_JS,
-	"swarmfs":   SWARMFS_JS,
-	"txpool":    TxPool_JS,
-	"fruitpool": FruitPool_JS,
-	"impawn":    Impawn_JS,
+	"admin":      Admin_JS,
+	"clique":     Clique_JS,
+	"debug":      Debug_JS,
+	"ice":        Ice_JS,
+	"miner":      Miner_JS,
+	"net":        Net_JS,
+	"personal":   Personal_JS,
+	"rpc":        RPC_JS,
+	"shh":        Shh_JS,
+	"swarmfs":    SWARMFS_JS,
+	"txpool":     TxPool_JS,
+	"fruitpool":  FruitPool_JS,
+	"impawn":     Impawn_JS,
+	"tbfthealth": TbftHealth_JS,
 }
 
 const Clique_JS = `
@@ -129,6 +130,12 @@ web3._extend({
 			name: 'stopWS',
 			call: 'admin_stopWS'
 		}),
+		new web3._extend.Method({
+			name: 'proposeSwitch',
+			call: 'admin_proposeSwitch',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -406,6 +413,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'createAccessList',
+			call: 'ice_createAccessList',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -647,6 +660,20 @@ web3._extend({
 });
 `
 
+const TbftHealth_JS = `
+web3._extend({
+	property: 'tbfthealth',
+	methods: [
+		new web3._extend.Method({
+			name: 'status',
+			call: 'tbfthealth_status',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	]
+});
+`
+
 const Impawn_JS = `
 web3._extend({
 	property: 'impawn',
@@ -806,6 +833,49 @@ web3._extend({
 				return infos;
 			}
 		}),
+		new web3._extend.Method({
+			name: 'submitEvidence',
+			call: 'impawn_submitEvidence',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'getEvidence',
+			call: 'impawn_getEvidence',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAllEvidence',
+			call: 'impawn_getAllEvidence',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorUptime',
+			call: 'impawn_getValidatorUptime',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getReceipts',
+			call: 'impawn_getReceipts',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter,web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getReceiptSupply',
+			call: 'impawn_getReceiptSupply',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter,web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getReceiptAllowance',
+			call: 'impawn_getReceiptAllowance',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter,web3._extend.formatters.inputAddressFormatter,web3._extend.formatters.inputAddressFormatter,web3._extend.formatters.inputDefaultBlockNumberFormatter],
+			outputFormatter: web3._extend.utils.toBigNumber
+		}),
 	]
 });
 `
@@ -207,6 +207,11 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.ice.txPool.Content()
 }
 
+// TxPoolSponsored returns nothing, the light pool doesn't account for payers.
+func (b *LesApiBackend) TxPoolSponsored() map[common.Address]types.Transactions {
+	return make(map[common.Address]types.Transactions)
+}
+
 func (b *LesApiBackend) SubscribeNewTxsEvent(ch chan<- types.NewTxsEvent) event.Subscription {
 	return b.ice.txPool.SubscribeNewTxsEvent(ch)
 }
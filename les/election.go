@@ -45,7 +45,6 @@ var (
 
 type Election struct {
 	genesisCommittee []*types.CommitteeMember
-	defaultMembers   []*types.CommitteeMember
 
 	fastchain *light.LightChain
 
@@ -69,11 +68,6 @@ func NewLightElection(fastBlockChain *light.LightChain) *Election {
 
 	// Genesis committee is stroed on block 0
 	election.genesisCommittee = election.getGenesisCommittee()
-	for _, m := range election.genesisCommittee {
-		var member = *m
-		//member.Flag = types.StateUnusedFlag
-		election.defaultMembers = append(election.defaultMembers, &member)
-	}
 	return election
 }
 
@@ -214,6 +208,9 @@ func (e *Election) GetCommittee(fastNumber *big.Int) []*types.CommitteeMember {
 	begin, _, epochid := LesEpochFromHeight(fastNumber.Uint64())
 
 	c := e.getCommittee(big.NewInt(int64(epochid)))
+	if c == nil {
+		return nil
+	}
 
 	// Load switch block to calculate committee members
 	switches := e.loadSwitchPoint(big.NewInt(int64(epochid)), big.NewInt(int64(begin)), fastNumber)
@@ -302,32 +299,35 @@ func (e *Election) filterWithSwitchInfo(c *types.ElectionCommittee, fastNumber *
 
 	return
 }
+
+// GetCommitteeFromFullnode retrieves the committee of the epoch id from the
+// full node, it is trusted only if the previous committee signed the last block
+// of the previous epoch and the members match the impawn state of that block.
 func (e *Election) GetCommitteeFromFullnode(id *big.Int) *types.ElectionCommittee {
-	// TODO get the committee from the full node by rpc
-	height, _ := LesEpochToHeight(id.Uint64())
-	if block, err := e.fastchain.GetBlockByNumber(context.Background(), height); err != nil {
-		log.Error("light chain GetBlockByNumber err", "height", height, "err", err)
-		return &types.ElectionCommittee{Members: e.defaultMembers}
-	} else {
-		infos := block.SwitchInfos()
-		if infos != nil {
-			return &types.ElectionCommittee{Members: infos}
-		} else {
-			return &types.ElectionCommittee{Members: e.defaultMembers}
-		}
+	_, end := LesEpochToHeight(id.Uint64() - 1)
+	header := e.fastchain.GetHeaderByNumber(end)
+	if header == nil {
+		log.Error("Light chain missing the switch header", "committee", id, "number", end)
+		return nil
+	}
+	signers := e.GetCommittee(header.Number)
+	committee, err := light.GetCommittee(context.Background(), e.fastchain.Odr(), header.Hash(), end, id.Uint64(), signers)
+	if err != nil {
+		log.Error("Failed to verify committee from full node", "committee", id, "number", end, "err", err)
+		return nil
 	}
+	return committee
 }
 func (e *Election) GetCommitteeFromFullnodeByNumber(height *big.Int) *types.ElectionCommittee {
 	if block, err := e.fastchain.GetBlockByNumber(context.Background(), height.Uint64()); err != nil {
 		log.Error("light chain GetBlockByNumber err", "height", height.Uint64(), "err", err)
-		return &types.ElectionCommittee{Members: e.defaultMembers}
+		return nil
 	} else {
 		infos := block.SwitchInfos()
 		if infos != nil {
 			return &types.ElectionCommittee{Members: infos}
-		} else {
-			return &types.ElectionCommittee{Members: e.defaultMembers}
 		}
+		return nil
 	}
 }
 func (e *Election) getCommittee(id *big.Int) *types.ElectionCommittee {
@@ -337,11 +337,14 @@ func (e *Election) getCommittee(id *big.Int) *types.ElectionCommittee {
 	}
 
 	var c *types.ElectionCommittee
-	if id.Cmp(common.Big0) == 0 {
-		// genesis committee for committee 0
+	if id.Uint64() <= LesFirstEpochID {
+		// the committee of the light genesis is trusted
 		c = &types.ElectionCommittee{Members: e.genesisCommittee}
 	} else {
 		c = e.GetCommitteeFromFullnode(id)
+		if c == nil {
+			return nil
+		}
 		log.Info("Committee members", "committee", id, "count", len(c.Members), "backup", len(c.Backups))
 	}
 	e.commiteeCache.Add(id.Uint64(), c)
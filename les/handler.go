@@ -33,6 +33,7 @@ import (
 	"github.com/iceming123/go-ice/core/rawdb"
 	"github.com/iceming123/go-ice/core/state"
 	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/crypto"
 	"github.com/iceming123/go-ice/event"
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/light"
@@ -60,6 +61,7 @@ const (
 	MaxHelperTrieProofsFetch = 64  // Amount of merkle proofs to be fetched per retrieval request
 	MaxTxSend                = 64  // Amount of transactions to be send per request
 	MaxTxStatus              = 256 // Amount of transactions to queried per request
+	MaxCommitteeProofsFetch  = 4   // Amount of committee proofs to be fetched per retrieval request
 
 	disableClientRemovePeer = false
 )
@@ -313,7 +315,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 }
 
 var (
-	reqList   = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg}
+	reqList   = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg, GetCommitteeProofsMsg}
 	reqListV1 = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, GetHeaderProofsMsg}
 	reqListV2 = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, SendTxV2Msg, GetTxStatusMsg, GetProofsV2Msg, GetHelperTrieProofsMsg}
 )
@@ -1128,6 +1130,53 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 		p.fcServer.GotReply(resp.ReqID, resp.BV)
 
+	case GetCommitteeProofsMsg:
+		p.Log().Trace("Received committee proofs request")
+		// Decode the retrieval message
+		var req struct {
+			ReqID uint64
+			Reqs  []CommitteeReq
+		}
+		if err := msg.Decode(&req); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		reqCnt := len(req.Reqs)
+		if reject(uint64(reqCnt), MaxCommitteeProofsFetch) {
+			return errResp(ErrRequestRejected, "")
+		}
+		resps := make([]CommitteeResp, 0, reqCnt)
+		for _, req := range req.Reqs {
+			resp, err := pm.getCommitteeProof(req.BHash)
+			if err != nil {
+				p.Log().Debug("Failed to prove committee", "hash", req.BHash, "err", err)
+				continue
+			}
+			resps = append(resps, *resp)
+		}
+		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
+		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
+		return p.SendCommitteeProofs(req.ReqID, bv, resps)
+
+	case CommitteeProofsMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received committee proofs response")
+		var resp struct {
+			ReqID, BV uint64
+			Data      []CommitteeResp
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		deliverMsg = &Msg{
+			MsgType: MsgCommitteeProofs,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Data,
+		}
+
 	default:
 		p.Log().Trace("Received unknown message", "code", msg.Code)
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
@@ -1162,6 +1211,82 @@ func (pm *ProtocolManager) getAccount(statedb *state.StateDB, root, hash common.
 	return account, nil
 }
 
+// getCommitteeProof returns the signs over the block of hash, the switch infos
+// of the next block and the merkle proof of the impawn state of the block.
+func (pm *ProtocolManager) getCommitteeProof(hash common.Hash) (*CommitteeResp, error) {
+	number := rawdb.ReadHeaderNumber(pm.chainDb, hash)
+	if number == nil {
+		return nil, errHeaderUnavailable
+	}
+	header := rawdb.ReadHeader(pm.chainDb, hash, *number)
+	body := rawdb.ReadBody(pm.chainDb, hash, *number)
+	if header == nil || body == nil {
+		return nil, errHeaderUnavailable
+	}
+	next := pm.blockchain.GetHeaderByNumber(*number + 1)
+	if next == nil {
+		return nil, errHeaderUnavailable
+	}
+	nextBody := rawdb.ReadBody(pm.chainDb, next.Hash(), *number+1)
+	if nextBody == nil {
+		return nil, errHeaderUnavailable
+	}
+	statedb, err := pm.blockchain.State()
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := proveImpawnState(statedb.Database(), header.Root)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitteeResp{
+		Signs:  body.Signs,
+		Infos:  nextBody.Infos,
+		Proofs: nodes.NodeList(),
+	}, nil
+}
+
+// proveImpawnState returns the merkle proof of the impawn state and the
+// governance in the state of root, the committee is loaded from both. It
+// returns errStatePruned if the state of root is not kept.
+func proveImpawnState(db state.Database, root common.Hash) (*light.NodeSet, error) {
+	nodes, err := proveStakingStorage(db, root)
+	if _, missing := err.(*trie.MissingNodeError); missing {
+		return nil, errStatePruned
+	}
+	return nodes, err
+}
+
+func proveStakingStorage(db state.Database, root common.Hash) (*light.NodeSet, error) {
+	nodes := light.NewNodeSet()
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	addrHash := crypto.Keccak256Hash(types.StakingAddress[:])
+	if err := tr.Prove(addrHash[:], 0, nodes); err != nil {
+		return nil, err
+	}
+	var account state.Account
+	enc, err := tr.TryGet(types.StakingAddress[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		return nil, err
+	}
+	str, err := db.OpenStorageTrie(addrHash, account.Root)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range []common.Hash{impawnKey, governanceKey} {
+		if err := str.Prove(crypto.Keccak256(key[:]), 0, nodes); err != nil {
+			return nil, err
+		}
+	}
+	return nodes, nil
+}
+
 // getHelperTrie returns the post-processed trie root for the given trie ID and section index
 func (pm *ProtocolManager) getHelperTrie(id uint, idx uint64) (common.Hash, string) {
 	switch id {
@@ -17,13 +17,16 @@
 package les
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state"
 	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
 	"github.com/iceming123/go-ice/crypto"
 	"github.com/iceming123/go-ice/icedb"
 	"github.com/iceming123/go-ice/light"
@@ -43,6 +46,21 @@ var (
 	errCHTHashMismatch     = errors.New("cht hash mismatch")
 	errCHTNumberMismatch   = errors.New("cht number mismatch")
 	errUselessNodes        = errors.New("useless nodes in merkle proof nodeset")
+	errNoCommittee         = errors.New("no committee to verify the signs")
+	errSignHashMismatch    = errors.New("sign hash mismatch")
+	errInsufficientSigns   = errors.New("insufficient committee signs")
+	errCommitteeMismatch   = errors.New("committee mismatch with the impawn state")
+	errStatePruned         = errors.New("state of the block is pruned")
+	errNoCommitteeProof    = errors.New("no committee proof, the state may be pruned by the server")
+)
+
+var (
+	// impawnKey is the key of the impawn state in the storage of the staking
+	// address, see vm.ImpawnImpl.Save.
+	impawnKey = common.BytesToHash(types.StakingAddress[:])
+	// governanceKey is the key of the governance in the storage of the staking
+	// address, see vm.Governance.Save.
+	governanceKey = common.BytesToHash([]byte("governance"))
 )
 
 type LesOdrRequest interface {
@@ -66,6 +84,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ChtRequest)(r)
 	case *light.BloomRequest:
 		return (*BloomRequest)(r)
+	case *light.CommitteeRequest:
+		return (*CommitteeRequest)(r)
 	default:
 		return nil
 	}
@@ -552,6 +572,150 @@ func (r *BloomRequest) Validate(db icedb.Database, msg *Msg) error {
 	return nil
 }
 
+type CommitteeReq struct {
+	BHash common.Hash
+}
+
+type CommitteeResp struct {
+	Signs  []*types.PbftSign
+	Infos  []*types.CommitteeMember
+	Proofs light.NodeList
+}
+
+// ODR request type for requesting the committee of an epoch, see LesOdrRequest interface
+type CommitteeRequest light.CommitteeRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *CommitteeRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetCommitteeProofsMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *CommitteeRequest) CanSend(peer *peer) bool {
+	peer.lock.RLock()
+	supported := peer.fcCosts[GetCommitteeProofsMsg] != nil
+	peer.lock.RUnlock()
+
+	return supported && peer.HasBlock(r.Hash, r.Number, true)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *CommitteeRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting committee proof", "epoch", r.EpochID, "number", r.Number)
+	return peer.RequestCommitteeProofs(reqID, r.GetCost(peer), []CommitteeReq{{BHash: r.Hash}})
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *CommitteeRequest) Validate(db icedb.Database, msg *Msg) error {
+	log.Debug("Validating committee proof", "epoch", r.EpochID, "number", r.Number)
+
+	// Ensure we have a correct message with a single committee proof
+	if msg.MsgType != MsgCommitteeProofs {
+		return errInvalidMessageType
+	}
+	resps := msg.Obj.([]CommitteeResp)
+	if len(resps) == 0 {
+		// The server skips the blocks it can't prove, such as the pruned ones
+		return errNoCommitteeProof
+	}
+	if len(resps) != 1 {
+		return errInvalidEntryCount
+	}
+	resp := resps[0]
+
+	// The block should be agreed by more than 2/3 of the previous committee
+	header := rawdb.ReadHeader(db, r.Hash, r.Number)
+	if header == nil {
+		return errHeaderUnavailable
+	}
+	if err := verifyCommitteeSigns(header, resp.Signs, r.Signers); err != nil {
+		return err
+	}
+	// Verify the impawn state of the staking address in the state of the block
+	nodeSet := resp.Proofs.NodeSet()
+	reads := &readTraceDB{db: nodeSet}
+	addrHash := crypto.Keccak256Hash(types.StakingAddress[:])
+	data, _, err := trie.VerifyProof(header.Root, addrHash[:], reads)
+	if err != nil {
+		return fmt.Errorf("merkle proof verification failed: %v", err)
+	}
+	var account state.Account
+	if err := rlp.DecodeBytes(data, &account); err != nil {
+		return err
+	}
+	for _, key := range []common.Hash{impawnKey, governanceKey} {
+		if _, _, err := trie.VerifyProof(account.Root, crypto.Keccak256(key[:]), reads); err != nil {
+			return fmt.Errorf("merkle proof verification failed: %v", err)
+		}
+	}
+	if len(reads.reads) != nodeSet.KeyCount() {
+		return errUselessNodes
+	}
+	// Elect the committee from the proven state and compare it with the switch infos
+	proofDb := icedb.NewMemDatabase()
+	nodeSet.Store(proofDb)
+	statedb, err := state.New(header.Root, state.NewDatabase(proofDb))
+	if err != nil {
+		return err
+	}
+	validators := vm.GetValidatorsByEpoch(statedb, r.EpochID, r.Number)
+
+	var members []*types.CommitteeMember
+	for _, m := range resp.Infos {
+		if m.Flag == types.StateUsedFlag {
+			members = append(members, m)
+		}
+	}
+	if len(validators) == 0 || len(validators) != len(members) {
+		return errCommitteeMismatch
+	}
+	for i := range validators {
+		if !validators[i].Compared(members[i]) {
+			return errCommitteeMismatch
+		}
+	}
+	// Verifications passed, store and return
+	r.Committee = &types.ElectionCommittee{Members: validators}
+	r.Proof = nodeSet
+	return nil
+}
+
+// verifyCommitteeSigns checks that more than 2/3 of the committee agree with the header.
+func verifyCommitteeSigns(header *types.Header, signs []*types.PbftSign, committee []*types.CommitteeMember) error {
+	if len(committee) == 0 {
+		return errNoCommittee
+	}
+	hash := header.Hash()
+	agreed := make(map[common.Address]bool)
+	for _, sign := range signs {
+		if sign.FastHash != hash || sign.FastHeight == nil || sign.FastHeight.Cmp(header.Number) != 0 {
+			return errSignHashMismatch
+		}
+		if sign.Result != types.VoteAgree {
+			continue
+		}
+		pubkey, err := crypto.SigToPub(sign.HashWithNoSign().Bytes(), sign.Sign)
+		if err != nil {
+			return err
+		}
+		pubkeyByte := crypto.FromECDSAPub(pubkey)
+		for _, member := range committee {
+			if bytes.Equal(pubkeyByte, member.Publickey) {
+				agreed[member.CommitteeBase] = true
+				break
+			}
+		}
+	}
+	if len(agreed) <= len(committee)*2/3 {
+		log.Warn("Committee signs not enough", "number", header.Number, "agree", len(agreed), "members", len(committee))
+		return errInsufficientSigns
+	}
+	return nil
+}
+
 // readTraceDB stores the keys of database reads. We use this to check that received node
 // sets contain only the trie nodes necessary to make proofs pass.
 type readTraceDB struct {
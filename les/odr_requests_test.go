@@ -0,0 +1,171 @@
+package les
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/core/rawdb"
+	"github.com/iceming123/go-ice/core/state"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/core/vm"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/icedb"
+	"github.com/iceming123/go-ice/light"
+	"github.com/iceming123/go-ice/rlp"
+)
+
+// newCommitteeProof creates a block with the elected committee in its impawn
+// state, and returns the block header, the committee and the response of it.
+func newCommitteeProof(t *testing.T, keys []*ecdsa.PrivateKey) (*types.Header, []*types.CommitteeMember, *CommitteeResp) {
+	db := state.NewDatabase(icedb.NewMemDatabase())
+	statedb, _ := state.New(common.Hash{}, db)
+	statedb.GetOrNewStateObject(types.StakingAddress)
+
+	impawn := vm.NewImpawnImpl()
+	value := new(big.Int).Mul(big.NewInt(300000), big.NewInt(1e18))
+	for _, key := range keys {
+		pub := crypto.FromECDSAPub(&key.PublicKey)
+		if err := impawn.InsertSAccount2(0, 0, crypto.PubkeyToAddress(key.PublicKey), pub, value, big.NewInt(50), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := impawn.DoElections(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	impawn.Save(statedb, types.StakingAddress)
+	gov := vm.NewGovernance()
+	gov.Proposals = append(gov.Proposals, &vm.Proposal{
+		Proposer: crypto.PubkeyToAddress(keys[0].PublicKey),
+		Kind:     vm.GovCommitteeSize,
+		Value:    big.NewInt(int64(len(keys))),
+		EpochID:  1,
+	})
+	gov.Save(statedb, types.StakingAddress)
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.TrieDB().Commit(root, false)
+
+	header := &types.Header{Number: big.NewInt(100), Root: root}
+	committee := vm.GetValidatorsByEpoch(statedb, 1, 100)
+	if len(committee) != len(keys) {
+		t.Fatalf("committee mismatch: have %d, want %d", len(committee), len(keys))
+	}
+	nodes, err := proveImpawnState(db, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proveImpawnState(db, common.HexToHash("0x01")); err != errStatePruned {
+		t.Fatalf("pruned state error mismatch: have %v, want %v", err, errStatePruned)
+	}
+	resp := &CommitteeResp{Infos: committee, Proofs: nodes.NodeList()}
+	for _, key := range keys {
+		sign := &types.PbftSign{FastHeight: header.Number, FastHash: header.Hash(), Result: types.VoteAgree}
+		sign.Sign, _ = crypto.Sign(sign.HashWithNoSign().Bytes(), key)
+		resp.Signs = append(resp.Signs, sign)
+	}
+	return header, committee, resp
+}
+
+func TestCommitteeRequestValidate(t *testing.T) {
+	var keys []*ecdsa.PrivateKey
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+	}
+	header, committee, resp := newCommitteeProof(t, keys)
+	db := icedb.NewMemDatabase()
+	rawdb.WriteHeader(db, header)
+
+	validate := func(resp *CommitteeResp) (*CommitteeRequest, error) {
+		r := &CommitteeRequest{Hash: header.Hash(), Number: header.Number.Uint64(), EpochID: 1, Signers: committee}
+		return r, r.Validate(db, &Msg{MsgType: MsgCommitteeProofs, Obj: []CommitteeResp{*resp}})
+	}
+	r, err := validate(resp)
+	if err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if len(r.Committee.Members) != len(committee) {
+		t.Fatalf("committee mismatch: have %d, want %d", len(r.Committee.Members), len(committee))
+	}
+
+	// Two of four signs are not more than 2/3 of the committee
+	few := *resp
+	few.Signs = resp.Signs[:2]
+	if _, err := validate(&few); err != errInsufficientSigns {
+		t.Errorf("signs error mismatch: have %v, want %v", err, errInsufficientSigns)
+	}
+	// The signs of the others are not counted
+	other, _ := crypto.GenerateKey()
+	sign := &types.PbftSign{FastHeight: header.Number, FastHash: header.Hash(), Result: types.VoteAgree}
+	sign.Sign, _ = crypto.Sign(sign.HashWithNoSign().Bytes(), other)
+	forged := *resp
+	forged.Signs = append(resp.Signs[:2:2], sign)
+	if _, err := validate(&forged); err != errInsufficientSigns {
+		t.Errorf("signs error mismatch: have %v, want %v", err, errInsufficientSigns)
+	}
+	// The signs should be over the header
+	_, _, fake := newCommitteeProof(t, []*ecdsa.PrivateKey{other})
+	wrong := *resp
+	wrong.Signs = append(resp.Signs[:3:3], fake.Signs[0])
+	if _, err := validate(&wrong); err != errSignHashMismatch {
+		t.Errorf("signs error mismatch: have %v, want %v", err, errSignHashMismatch)
+	}
+	// The members should match the impawn state
+	member := *committee[0]
+	member.Coinbase = crypto.PubkeyToAddress(other.PublicKey)
+	fakeInfos := *resp
+	fakeInfos.Infos = append([]*types.CommitteeMember{&member}, committee[1:]...)
+	if _, err := validate(&fakeInfos); err != errCommitteeMismatch {
+		t.Errorf("committee error mismatch: have %v, want %v", err, errCommitteeMismatch)
+	}
+	// The proof should cover the governance the committee is loaded with
+	proofDb := icedb.NewMemDatabase()
+	resp.Proofs.NodeSet().Store(proofDb)
+	impawnOnly := *resp
+	impawnOnly.Proofs = proveImpawnOnly(t, state.NewDatabase(proofDb), header.Root)
+	if _, err := validate(&impawnOnly); err == nil {
+		t.Errorf("validate without the governance proof succeeded")
+	}
+	// The server skips the blocks whose state is pruned
+	r = &CommitteeRequest{Hash: header.Hash(), Number: header.Number.Uint64(), EpochID: 1, Signers: committee}
+	if err := r.Validate(db, &Msg{MsgType: MsgCommitteeProofs, Obj: []CommitteeResp{}}); err != errNoCommitteeProof {
+		t.Errorf("empty response error mismatch: have %v, want %v", err, errNoCommitteeProof)
+	}
+	// The proof should be rooted in the header
+	fakeProof := *resp
+	fakeProof.Proofs = fake.Proofs
+	if _, err := validate(&fakeProof); err == nil {
+		t.Errorf("validate with the proof of another state succeeded")
+	}
+}
+
+// proveImpawnOnly returns the merkle proof of the impawn state in the state
+// of root without the governance.
+func proveImpawnOnly(t *testing.T, db state.Database, root common.Hash) light.NodeList {
+	nodes := light.NewNodeSet()
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addrHash := crypto.Keccak256Hash(types.StakingAddress[:])
+	if err := tr.Prove(addrHash[:], 0, nodes); err != nil {
+		t.Fatal(err)
+	}
+	var account state.Account
+	enc, _ := tr.TryGet(types.StakingAddress[:])
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		t.Fatal(err)
+	}
+	str, err := db.OpenStorageTrie(addrHash, account.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := str.Prove(crypto.Keccak256(impawnKey[:]), 0, nodes); err != nil {
+		t.Fatal(err)
+	}
+	return nodes.NodeList()
+}
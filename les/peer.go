@@ -274,6 +274,11 @@ func (p *peer) SendTxStatus(reqID, bv uint64, stats []txStatus) error {
 	return sendResponse(p.rw, TxStatusMsg, reqID, bv, stats)
 }
 
+// SendCommitteeProofs sends a batch of committee proofs, corresponding to the ones requested.
+func (p *peer) SendCommitteeProofs(reqID, bv uint64, resps []CommitteeResp) error {
+	return sendResponse(p.rw, CommitteeProofsMsg, reqID, bv, resps)
+}
+
 // RequestHeadersByHash fetches a batch of blocks' headers corresponding to the
 // specified header query, based on the hash of an origin block.
 func (p *peer) RequestHeadersByHash(reqID, cost uint64, origin common.Hash, amount int, skip int, reverse bool) error {
@@ -343,6 +348,12 @@ func (p *peer) RequestHelperTrieProofs(reqID, cost uint64, data interface{}) err
 	}
 }
 
+// RequestCommitteeProofs fetches a batch of committee proofs from a remote node.
+func (p *peer) RequestCommitteeProofs(reqID, cost uint64, reqs []CommitteeReq) error {
+	p.Log().Debug("Fetching batch of committee proofs", "count", len(reqs))
+	return sendRequest(p.rw, GetCommitteeProofsMsg, reqID, cost, reqs)
+}
+
 // RequestTxStatus fetches a batch of transaction status records from a remote node.
 func (p *peer) RequestTxStatus(reqID, cost uint64, txHashes []common.Hash) error {
 	p.Log().Debug("Requesting transaction status", "count", len(txHashes))
@@ -135,6 +135,24 @@ func (req *ReceiptsRequest) StoreResult(db icedb.Database) {
 	rawdb.WriteReceipts(db, req.Hash, req.Number, req.Receipts)
 }
 
+// CommitteeRequest is the ODR request type for retrieving the committee of an
+// epoch. The committee is proven by the signs of the previous committee over
+// the last block of the previous epoch and the impawn state of that block.
+type CommitteeRequest struct {
+	OdrRequest
+	Hash      common.Hash // hash of the last block of the previous epoch
+	Number    uint64
+	EpochID   uint64
+	Signers   []*types.CommitteeMember // the previous committee
+	Committee *types.ElectionCommittee
+	Proof     *NodeSet
+}
+
+// StoreResult stores the retrieved data in local database
+func (req *CommitteeRequest) StoreResult(db icedb.Database) {
+	req.Proof.Store(db)
+}
+
 // ChtRequest is the ODR request type for state/storage trie entries
 type ChtRequest struct {
 	OdrRequest
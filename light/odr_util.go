@@ -126,6 +126,16 @@ func GetBlock(ctx context.Context, odr OdrBackend, hash common.Hash, number uint
 	return types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Signs, body.Infos), nil
 }
 
+// GetCommittee retrieves the committee of the epoch id, which is verified with
+// the signs of signers over the last block of the previous epoch given by hash.
+func GetCommittee(ctx context.Context, odr OdrBackend, hash common.Hash, number uint64, id uint64, signers []*types.CommitteeMember) (*types.ElectionCommittee, error) {
+	r := &CommitteeRequest{Hash: hash, Number: number, EpochID: id, Signers: signers}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, err
+	}
+	return r.Committee, nil
+}
+
 // GetBlockReceipts retrieves the receipts generated by the transactions included
 // in a block given by its hash.
 func GetBlockReceipts(ctx context.Context, odr OdrBackend, hash common.Hash, number uint64) (types.Receipts, error) {
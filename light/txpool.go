@@ -19,6 +19,7 @@ package light
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 	"time"
 
@@ -374,8 +375,13 @@ func (pool *TxPool) validateTx(ctx context.Context, tx *types.Transaction) error
 		return core.ErrInsufficientFunds
 	}
 
+	// Accept only legacy transactions until the access lists are enabled
+	if tx.Type() != types.LegacyTxType && !pool.config.IsTIP11(new(big.Int).Add(header.Number, big.NewInt(1))) {
+		return types.ErrTxTypeNotSupported
+	}
+
 	// Should supply enough intrinsic gas
-	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, true)
+	gas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true)
 	if err != nil {
 		return err
 	}
@@ -381,6 +381,12 @@ func (miner *Miner) SetFruitOnly(FruitOnly bool) {
 	miner.worker.SetFruitOnly(FruitOnly)
 }
 
+// SetInstantSeal makes the miner seal snail blocks as soon as the minimum
+// number of fruits is pending, used by the developer chain.
+func (miner *Miner) SetInstantSeal(instant bool) {
+	miner.worker.SetInstantSeal(instant)
+}
+
 // GetCurrentBlock return the fruit or block it is mining
 func (miner *Miner) GetCurrentBlock() *types.SnailBlock {
 	return miner.worker.current.Block
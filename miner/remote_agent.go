@@ -27,6 +27,7 @@ import (
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/consensus"
 	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/event"
 	"github.com/iceming123/go-ice/log"
 	"math/big"
 )
@@ -54,6 +55,7 @@ type RemoteAgent struct {
 	engine      consensus.Engine
 	currentWork *Work
 	work        map[common.Hash]*Work
+	workFeed    event.Feed
 
 	hashrateMu sync.RWMutex
 	hashrate   map[common.Hash]hashrate
@@ -81,6 +83,35 @@ func (a *RemoteAgent) SubmitHashrate(id common.Hash, rate uint64) {
 	a.hashrate[id] = hashrate{time.Now(), rate}
 }
 
+// SubscribeWork registers a subscription for every new work package the
+// worker hands to the remote agent.
+func (a *RemoteAgent) SubscribeWork(ch chan<- *Work) event.Subscription {
+	return a.workFeed.Subscribe(ch)
+}
+
+// TrackWork makes the given work eligible for SubmitWork without going
+// through GetWork, for pushing work to miners instead of being polled.
+func (a *RemoteAgent) TrackWork(work *Work) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.work[work.Block.HashNoNonce()] = work
+}
+
+// Targets returns the fruit and block targets a remote miner has to meet for
+// the given block. A zero target means that kind of seal can not be found.
+func Targets(block *types.SnailBlock) (fruitTarget *big.Int, blockTarget *big.Int) {
+	if block.IsFruit() {
+		// is fruit  so the block target set zore
+		return new(big.Int).Div(maxUint128, block.FruitDifficulty()), new(big.Int)
+	}
+	if block.FastNumber().Sign() == 0 {
+		// only block
+		return new(big.Int), new(big.Int).Div(maxUint128, block.BlockDifficulty())
+	}
+	return new(big.Int).Div(maxUint128, block.FruitDifficulty()), new(big.Int).Div(maxUint128, block.BlockDifficulty())
+}
+
 // Work return a work chan
 func (a *RemoteAgent) Work() chan<- *Work {
 	return a.workCh
@@ -128,8 +159,6 @@ func (a *RemoteAgent) GetWork() ([4]string, error) {
 	defer a.mu.Unlock()
 
 	var res [4]string
-	var fruitTarget *big.Int
-	var blockTarget *big.Int
 
 	if a.currentWork != nil {
 		block := a.currentWork.Block
@@ -141,22 +170,7 @@ func (a *RemoteAgent) GetWork() ([4]string, error) {
 		//res[1] = "0x" + hex.EncodeToString(DatasetHash)
 		res[1] = a.engine.DataSetHash(epoch)
 		// Calculate the "target" to be returned to the external miner
-		block.Fruits()
-		if block.IsFruit() {
-			// is fruit  so the block target set zore
-			fruitTarget = new(big.Int).Div(maxUint128, block.FruitDifficulty())
-			blockTarget = new(big.Int).SetInt64(0)
-		} else {
-
-			if block.FastNumber().Cmp(big.NewInt(0)) == 0 {
-				// only block
-				fruitTarget = new(big.Int).SetInt64(0)
-				blockTarget = new(big.Int).Div(maxUint128, block.BlockDifficulty())
-			} else {
-				fruitTarget = new(big.Int).Div(maxUint128, block.FruitDifficulty())
-				blockTarget = new(big.Int).Div(maxUint128, block.BlockDifficulty())
-			}
-		}
+		fruitTarget, blockTarget := Targets(block)
 		res[2] = a.CompletionHexString(32, hex.EncodeToString(fruitTarget.Bytes()))
 		res[3] = a.CompletionHexString(32, hex.EncodeToString(blockTarget.Bytes()))
 		a.work[block.HashNoNonce()] = a.currentWork
@@ -330,6 +344,7 @@ func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}) {
 			a.mu.Lock()
 			a.currentWork = work
 			a.mu.Unlock()
+			a.workFeed.Send(work)
 		case <-ticker.C:
 			// cleanup
 			a.mu.Lock()
@@ -0,0 +1,390 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stratum implements a Stratum mining server on top of the remote
+// agent, pushing fruit and snail block work to external miners.
+package stratum
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iceming123/go-ice/common"
+	"github.com/iceming123/go-ice/consensus"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/crypto"
+	"github.com/iceming123/go-ice/event"
+	"github.com/iceming123/go-ice/log"
+	"github.com/iceming123/go-ice/miner"
+)
+
+const (
+	// DefaultDifficulty is the share difficulty handed to miners if none is configured.
+	DefaultDifficulty = 1 << 20
+
+	workChanSize     = 10              // Size of the channel receiving new work from the agent
+	maxJobs          = 16              // Number of recent jobs solutions are accepted for
+	hashrateInterval = 5 * time.Second // Interval of reporting worker hashrates to the agent
+)
+
+var (
+	maxUint128 = new(big.Int).Exp(big.NewInt(2), big.NewInt(128), big.NewInt(0))
+
+	errNoSealVerifier = errors.New("consensus engine can not verify remote seals")
+	errZeroDifficulty = errors.New("share difficulty must be positive")
+	errRunning        = errors.New("stratum server already running")
+)
+
+// sealVerifier is the part of the minerva engine the server needs to check
+// the solutions submitted by miners.
+type sealVerifier interface {
+	VerifySnailSeal2(hight *big.Int, nonce string, headNoNoncehash string, ftarg *big.Int, btarg *big.Int, haveFruits bool) (bool, bool, []byte)
+	DataSetHash(epoch uint64) string
+}
+
+// job is a work package sent out to the miners.
+type job struct {
+	id          string
+	work        *miner.Work
+	seedHash    string
+	fruitTarget *big.Int
+	blockTarget *big.Int
+	shareTarget *big.Int
+	shareDiff   *big.Int // difficulty credited to the worker for an accepted share
+}
+
+// workerStats tracks the shares a single worker submitted.
+type workerStats struct {
+	id       common.Hash
+	shares   uint64   // accepted shares
+	invalid  uint64   // rejected shares
+	fruits   uint64   // fruits found
+	blocks   uint64   // snail blocks found
+	done     *big.Int // share difficulty accumulated since the last report
+	since    time.Time
+	hashrate uint64
+}
+
+// Server is a Stratum server feeding the work of a remote agent to external
+// miners over newline delimited JSON on TCP.
+type Server struct {
+	agent      *miner.RemoteAgent
+	verifier   sealVerifier
+	difficulty *big.Int
+	target     *big.Int
+
+	mu       sync.RWMutex
+	listener net.Listener
+	current  *job
+	jobs     map[string]*job
+	order    []string
+	sessions map[*session]struct{}
+	workers  map[string]*workerStats
+
+	workCh  chan *miner.Work
+	workSub event.Subscription
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewServer creates a Stratum server handing out the work of the given agent,
+// accepting shares of the given difficulty.
+func NewServer(agent *miner.RemoteAgent, engine consensus.Engine, difficulty uint64) (*Server, error) {
+	verifier, ok := engine.(sealVerifier)
+	if !ok {
+		return nil, errNoSealVerifier
+	}
+	return newServer(agent, verifier, difficulty)
+}
+
+func newServer(agent *miner.RemoteAgent, verifier sealVerifier, difficulty uint64) (*Server, error) {
+	if difficulty == 0 {
+		return nil, errZeroDifficulty
+	}
+	diff := new(big.Int).SetUint64(difficulty)
+	return &Server{
+		agent:      agent,
+		verifier:   verifier,
+		difficulty: diff,
+		target:     new(big.Int).Div(maxUint128, diff),
+		jobs:       make(map[string]*job),
+		sessions:   make(map[*session]struct{}),
+		workers:    make(map[string]*workerStats),
+	}, nil
+}
+
+// Start listens for miners on the given TCP address and starts pushing work.
+func (s *Server) Start(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		return errRunning
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.quit = make(chan struct{})
+	s.workCh = make(chan *miner.Work, workChanSize)
+	s.workSub = s.agent.SubscribeWork(s.workCh)
+
+	s.wg.Add(2)
+	go s.accept(listener)
+	go s.loop()
+
+	log.Info("Stratum server started", "addr", listener.Addr(), "difficulty", s.difficulty)
+	return nil
+}
+
+// Stop closes the listener and all miner connections.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	if s.listener == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.listener.Close()
+	s.listener = nil
+	s.workSub.Unsubscribe()
+	close(s.quit)
+	for sess := range s.sessions {
+		sess.close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	log.Info("Stratum server stopped")
+}
+
+// accept handles incoming miner connections until the listener is closed.
+func (s *Server) accept(listener net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Warn("Stratum accept failed", "err", err)
+			}
+			return
+		}
+		sess := newSession(s, conn)
+
+		s.mu.Lock()
+		s.sessions[sess] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			sess.serve()
+
+			s.mu.Lock()
+			delete(s.sessions, sess)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// loop pushes the new work to the miners and reports the worker hashrates
+// to the agent.
+func (s *Server) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(hashrateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case work := <-s.workCh:
+			if work != nil && work.Block != nil {
+				s.dispatch(work)
+			}
+		case <-ticker.C:
+			s.reportHashrate()
+		case <-s.workSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// dispatch turns the work into the current job and notifies every subscribed
+// miner about it.
+func (s *Server) dispatch(work *miner.Work) {
+	block := work.Block
+	fruitTarget, blockTarget := miner.Targets(block)
+
+	// A fruit easier than the share target makes every fruit count as share
+	shareTarget := s.target
+	if fruitTarget.Cmp(shareTarget) > 0 {
+		shareTarget = fruitTarget
+	}
+	j := &job{
+		id:          block.HashNoNonce().Hex(),
+		work:        work,
+		seedHash:    s.verifier.DataSetHash(0),
+		fruitTarget: fruitTarget,
+		blockTarget: blockTarget,
+		shareTarget: shareTarget,
+		shareDiff:   new(big.Int).Div(maxUint128, shareTarget),
+	}
+	s.agent.TrackWork(work)
+
+	s.mu.Lock()
+	if _, ok := s.jobs[j.id]; !ok {
+		s.order = append(s.order, j.id)
+	}
+	s.jobs[j.id] = j
+	for len(s.order) > maxJobs {
+		delete(s.jobs, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.current = j
+
+	sessions := make([]*session, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.mu.Unlock()
+
+	log.Debug("Stratum job dispatched", "number", block.Number(), "job", j.id, "fruit", block.IsFruit(), "miners", len(sessions))
+	for _, sess := range sessions {
+		sess.notify(j, true)
+	}
+}
+
+// currentJob returns the most recent job, if any.
+func (s *Server) currentJob() *job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.current
+}
+
+// submit checks a nonce found by the given worker. A nonce sealing a fruit or
+// a snail block is handed to the agent, any other nonce has to at least meet
+// the share target.
+func (s *Server) submit(worker string, jobID string, nonce string) error {
+	s.mu.RLock()
+	j := s.jobs[jobID]
+	s.mu.RUnlock()
+
+	if j == nil {
+		s.recordInvalid(worker)
+		return errStaleJob
+	}
+	nonce = strings.TrimPrefix(nonce, "0x")
+	raw, err := hex.DecodeString(nonce)
+	if err != nil || len(raw) != len(types.BlockNonce{}) {
+		s.recordInvalid(worker)
+		return errInvalidNonce
+	}
+	block := j.work.Block
+
+	found, isFruit, digest := s.verifier.VerifySnailSeal2(block.Number(), nonce, j.id, j.fruitTarget, j.blockTarget, !block.IsFruit())
+	if found {
+		var seal types.BlockNonce
+		copy(seal[:], raw)
+
+		if !s.agent.SubmitWork(seal, common.BytesToHash(digest), block.HashNoNonce()) {
+			log.Warn("Stratum solution rejected by agent", "worker", worker, "job", jobID, "fruit", isFruit)
+		} else {
+			log.Info("Stratum solution found", "worker", worker, "number", block.Number(), "fruit", isFruit)
+		}
+		s.recordShare(worker, j.shareDiff, found, isFruit)
+		return nil
+	}
+	if ok, _, _ := s.verifier.VerifySnailSeal2(block.Number(), nonce, j.id, j.shareTarget, new(big.Int), false); !ok {
+		s.recordInvalid(worker)
+		return errLowDifficulty
+	}
+	s.recordShare(worker, j.shareDiff, false, false)
+	return nil
+}
+
+// stats returns the statistics of a worker, creating them if needed. The
+// lock must be held.
+func (s *Server) stats(worker string) *workerStats {
+	stats := s.workers[worker]
+	if stats == nil {
+		stats = &workerStats{
+			id:    crypto.Keccak256Hash([]byte(worker)),
+			done:  new(big.Int),
+			since: time.Now(),
+		}
+		s.workers[worker] = stats
+	}
+	return stats
+}
+
+func (s *Server) recordShare(worker string, diff *big.Int, found bool, isFruit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats(worker)
+	stats.shares++
+	stats.done.Add(stats.done, diff)
+	if found {
+		if isFruit {
+			stats.fruits++
+		} else {
+			stats.blocks++
+		}
+	}
+}
+
+func (s *Server) recordInvalid(worker string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats(worker).invalid++
+}
+
+// reportHashrate estimates the hashrate of every worker from the share
+// difficulty done since the last report and submits it to the agent.
+func (s *Server) reportHashrate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for worker, stats := range s.workers {
+		elapsed := now.Sub(stats.since)
+		if elapsed <= 0 {
+			continue
+		}
+		rate := new(big.Int).Mul(stats.done, big.NewInt(int64(time.Second)))
+		rate.Div(rate, big.NewInt(int64(elapsed)))
+		stats.hashrate = rate.Uint64()
+		stats.done.SetUint64(0)
+		stats.since = now
+
+		if stats.hashrate > 0 {
+			s.agent.SubmitHashrate(stats.id, stats.hashrate)
+		}
+		log.Trace("Stratum worker hashrate", "worker", worker, "hashrate", stats.hashrate, "shares", stats.shares, "invalid", stats.invalid)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/iceming123/go-ice/consensus/minerva"
+	"github.com/iceming123/go-ice/core/types"
+	"github.com/iceming123/go-ice/miner"
+)
+
+const (
+	fruitNonce = "0x0000000000000001" // seals a fruit
+	shareNonce = "0x0000000000000002" // only meets the share target
+	badNonce   = "0x0000000000000003" // meets nothing
+)
+
+// testVerifier accepts the seals of the nonces above instead of running
+// truehash.
+type testVerifier struct{}
+
+func (testVerifier) VerifySnailSeal2(hight *big.Int, nonce string, headNoNoncehash string, ftarg *big.Int, btarg *big.Int, haveFruits bool) (bool, bool, []byte) {
+	switch "0x" + nonce {
+	case fruitNonce:
+		return ftarg.Sign() > 0, true, make([]byte, 32)
+	case shareNonce:
+		// Only meets targets easier than the fruit target of the test block
+		if ftarg.Cmp(new(big.Int).Div(maxUint128, big.NewInt(1000))) > 0 {
+			return true, true, make([]byte, 32)
+		}
+	}
+	return false, false, nil
+}
+
+func (testVerifier) DataSetHash(epoch uint64) string {
+	return fmt.Sprintf("0x%064x", epoch)
+}
+
+type testMiner struct {
+	t    *testing.T
+	conn net.Conn
+	in   *bufio.Scanner
+	id   int
+}
+
+func dialMiner(t *testing.T, addr net.Addr) *testMiner {
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	return &testMiner{t: t, conn: conn, in: bufio.NewScanner(conn)}
+}
+
+// call sends a request and returns the response to it, collecting any
+// notification arriving meanwhile.
+func (m *testMiner) call(method string, params ...string) (json.RawMessage, json.RawMessage) {
+	m.id++
+	req, _ := json.Marshal(map[string]interface{}{"id": m.id, "method": method, "params": params})
+	if _, err := m.conn.Write(append(req, '\n')); err != nil {
+		m.t.Fatalf("failed to send %s: %v", method, err)
+	}
+	for {
+		msg := m.read()
+		if msg["method"] != nil {
+			continue
+		}
+		return msg["result"], msg["error"]
+	}
+}
+
+// expect waits for the notification of the given method.
+func (m *testMiner) expect(method string) []json.RawMessage {
+	for {
+		msg := m.read()
+		var name string
+		json.Unmarshal(msg["method"], &name)
+		if name == method {
+			var params []json.RawMessage
+			json.Unmarshal(msg["params"], &params)
+			return params
+		}
+	}
+}
+
+func (m *testMiner) read() map[string]json.RawMessage {
+	m.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if !m.in.Scan() {
+		m.t.Fatalf("failed to read message: %v", m.in.Err())
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(m.in.Bytes(), &msg); err != nil {
+		m.t.Fatalf("invalid message %s: %v", m.in.Bytes(), err)
+	}
+	return msg
+}
+
+func newTestServer(t *testing.T) (*Server, *miner.RemoteAgent, chan *miner.Result) {
+	agent := miner.NewRemoteAgent(nil, nil, minerva.NewFaker())
+	results := make(chan *miner.Result, 10)
+	agent.SetReturnCh(results)
+	agent.Start()
+
+	server, err := newServer(agent, testVerifier{}, 10)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	return server, agent, results
+}
+
+func newTestWork(number int64) *miner.Work {
+	return &miner.Work{Block: types.NewSnailBlockWithHeader(&types.SnailHeader{
+		Number:          big.NewInt(number),
+		Difficulty:      big.NewInt(100000),
+		FruitDifficulty: big.NewInt(1000),
+		FastNumber:      big.NewInt(number),
+	})}
+}
+
+// Tests that work is pushed to subscribed miners and that their solutions
+// and shares are checked against the job targets.
+func TestServerMining(t *testing.T) {
+	server, agent, results := newTestServer(t)
+	defer agent.Stop()
+	defer server.Stop()
+
+	m := dialMiner(t, server.listener.Addr())
+	defer m.conn.Close()
+
+	if _, err := m.call("mining.submit", "worker", "0x00", fruitNonce); string(err) == "null" {
+		t.Fatalf("submit accepted before subscription")
+	}
+	if res, err := m.call("mining.subscribe", "test/1.0"); string(res) != "true" {
+		t.Fatalf("subscribe failed: %s", err)
+	}
+	if params := m.expect("mining.set_difficulty"); len(params) != 1 || string(params[0]) != "10" {
+		t.Fatalf("difficulty mismatch: %s", params)
+	}
+	if res, err := m.call("mining.authorize", "worker", "x"); string(res) != "true" {
+		t.Fatalf("authorize failed: %s", err)
+	}
+	work := newTestWork(1)
+	agent.Work() <- work
+
+	params := m.expect("mining.notify")
+	if len(params) != 6 {
+		t.Fatalf("notify params count mismatch: have %d, want 6", len(params))
+	}
+	var jobID, fruitTarget, blockTarget string
+	json.Unmarshal(params[0], &jobID)
+	json.Unmarshal(params[3], &fruitTarget)
+	json.Unmarshal(params[4], &blockTarget)
+
+	if jobID != work.Block.HashNoNonce().Hex() {
+		t.Errorf("job id mismatch: have %s, want %s", jobID, work.Block.HashNoNonce().Hex())
+	}
+	if want := formatTarget(new(big.Int).Div(maxUint128, big.NewInt(1000))); fruitTarget != want {
+		t.Errorf("fruit target mismatch: have %s, want %s", fruitTarget, want)
+	}
+	if want := formatTarget(new(big.Int)); blockTarget != want {
+		t.Errorf("block target mismatch: have %s, want %s", blockTarget, want)
+	}
+	// Shares meeting only the share target are accepted but not sealed
+	if res, err := m.call("mining.submit", "worker", jobID, shareNonce); string(res) != "true" {
+		t.Fatalf("share rejected: %s", err)
+	}
+	if res, _ := m.call("mining.submit", "worker", jobID, badNonce); string(res) == "true" {
+		t.Fatalf("low difficulty share accepted")
+	}
+	if res, _ := m.call("mining.submit", "worker", "0x1234", shareNonce); string(res) == "true" {
+		t.Fatalf("share of unknown job accepted")
+	}
+	select {
+	case <-results:
+		t.Fatalf("share sealed a fruit")
+	default:
+	}
+	// Fruits are handed to the agent
+	if res, err := m.call("mining.submit", "worker", jobID, fruitNonce); string(res) != "true" {
+		t.Fatalf("fruit rejected: %s", err)
+	}
+	select {
+	case result := <-results:
+		if result.Block.Nonce() != 1 {
+			t.Errorf("sealed nonce mismatch: have %d, want 1", result.Block.Nonce())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("fruit not submitted to agent")
+	}
+	stats := server.workers["worker"]
+	if stats.shares != 2 || stats.invalid != 2 || stats.fruits != 1 {
+		t.Errorf("worker stats mismatch: shares %d, invalid %d, fruits %d", stats.shares, stats.invalid, stats.fruits)
+	}
+	server.reportHashrate()
+	if rate := agent.GetHashRate(); rate == 0 {
+		t.Errorf("worker hashrate not reported")
+	}
+}
+
+// Tests that the share target is relaxed to the fruit target if the fruits
+// are easier than the configured share difficulty.
+func TestServerShareTarget(t *testing.T) {
+	agent := miner.NewRemoteAgent(nil, nil, minerva.NewFaker())
+	server, err := newServer(agent, testVerifier{}, 1<<30)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	server.dispatch(newTestWork(1))
+
+	j := server.currentJob()
+	if j.shareTarget.Cmp(j.fruitTarget) != 0 {
+		t.Errorf("share target mismatch: have %x, want %x", j.shareTarget, j.fruitTarget)
+	}
+	if j.shareDiff.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("share difficulty mismatch: have %v, want 1000", j.shareDiff)
+	}
+	if _, err := newServer(agent, testVerifier{}, 0); err != errZeroDifficulty {
+		t.Errorf("zero difficulty error mismatch: have %v, want %v", err, errZeroDifficulty)
+	}
+}
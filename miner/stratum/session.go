@@ -0,0 +1,233 @@
+// Copyright 2019 The go-ice Authors
+// This file is part of the go-ice library.
+//
+// The go-ice library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ice library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ice library. If not, see <http://www.gnu.org/licenses/>.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/iceming123/go-ice/log"
+)
+
+const (
+	maxRequestSize = 4096             // Maximum size of a single request line
+	readTimeout    = 10 * time.Minute // Idle time after which a miner is dropped
+	writeTimeout   = 10 * time.Second // Time allowed for a single message write
+)
+
+// Stratum error codes as used by the common mining pool implementations.
+var (
+	errOther         = &stratumError{20, "Other/Unknown"}
+	errStaleJob      = &stratumError{21, "Job not found (=stale)"}
+	errLowDifficulty = &stratumError{23, "Low difficulty share"}
+	errUnauthorized  = &stratumError{24, "Unauthorized worker"}
+	errNotSubscribed = &stratumError{25, "Not subscribed"}
+	errInvalidNonce  = &stratumError{20, "Invalid nonce"}
+	errUnknownMethod = &stratumError{20, "Method not found"}
+	errInvalidParams = &stratumError{20, "Invalid params"}
+)
+
+// stratumError is an error reported to the miner as [code, message, null].
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (e *stratumError) Error() string { return e.message }
+
+// MarshalJSON implements json.Marshaler.
+func (e *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.code, e.message, nil})
+}
+
+// request is a method call sent by the miner.
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// response is the answer to a miner request.
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  interface{}     `json:"error"`
+}
+
+// notification is a message pushed to the miner without a request.
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// session is a single miner connection.
+type session struct {
+	server *Server
+	conn   net.Conn
+
+	mu         sync.Mutex // protects the writes and the fields below
+	enc        *json.Encoder
+	subscribed bool
+	worker     string
+}
+
+func newSession(server *Server, conn net.Conn) *session {
+	return &session{
+		server: server,
+		conn:   conn,
+		enc:    json.NewEncoder(conn),
+	}
+}
+
+// serve handles the requests of the miner until the connection is closed.
+func (sess *session) serve() {
+	defer sess.conn.Close()
+
+	log.Debug("Stratum miner connected", "addr", sess.conn.RemoteAddr())
+	scanner := bufio.NewScanner(sess.conn)
+	scanner.Buffer(make([]byte, maxRequestSize), maxRequestSize)
+	for {
+		sess.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if !scanner.Scan() {
+			break
+		}
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			log.Debug("Stratum malformed request", "addr", sess.conn.RemoteAddr(), "err", err)
+			break
+		}
+		result, err := sess.handle(&req)
+		if err != nil {
+			sess.send(&response{ID: req.ID, Error: err})
+			continue
+		}
+		sess.send(&response{ID: req.ID, Result: result})
+
+		// Hand out the share difficulty and the current job right after
+		// the subscription was confirmed.
+		if req.Method == "mining.subscribe" {
+			sess.setDifficulty()
+			if j := sess.server.currentJob(); j != nil {
+				sess.notify(j, true)
+			}
+		}
+	}
+	log.Debug("Stratum miner disconnected", "addr", sess.conn.RemoteAddr(), "worker", sess.worker)
+}
+
+// handle executes a single miner request.
+func (sess *session) handle(req *request) (interface{}, *stratumError) {
+	var params []string
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, errInvalidParams
+		}
+	}
+	switch req.Method {
+	case "mining.subscribe":
+		sess.mu.Lock()
+		sess.subscribed = true
+		sess.mu.Unlock()
+		return true, nil
+
+	case "mining.authorize":
+		if len(params) < 1 || params[0] == "" {
+			return nil, errInvalidParams
+		}
+		sess.mu.Lock()
+		sess.worker = params[0]
+		sess.mu.Unlock()
+
+		log.Info("Stratum worker authorized", "addr", sess.conn.RemoteAddr(), "worker", params[0])
+		return true, nil
+
+	case "mining.submit":
+		sess.mu.Lock()
+		subscribed, worker := sess.subscribed, sess.worker
+		sess.mu.Unlock()
+
+		if !subscribed {
+			return nil, errNotSubscribed
+		}
+		if worker == "" {
+			return nil, errUnauthorized
+		}
+		if len(params) < 3 {
+			return nil, errInvalidParams
+		}
+		if err := sess.server.submit(worker, params[1], params[2]); err != nil {
+			if serr, ok := err.(*stratumError); ok {
+				return nil, serr
+			}
+			return nil, errOther
+		}
+		return true, nil
+	}
+	return nil, errUnknownMethod
+}
+
+// setDifficulty tells the miner the difficulty shares have to meet.
+func (sess *session) setDifficulty() {
+	sess.send(&notification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{sess.server.difficulty},
+	})
+}
+
+// notify pushes a job to the miner if it subscribed for work.
+func (sess *session) notify(j *job, clean bool) {
+	sess.mu.Lock()
+	subscribed := sess.subscribed
+	sess.mu.Unlock()
+
+	if !subscribed {
+		return
+	}
+	sess.send(&notification{
+		Method: "mining.notify",
+		Params: []interface{}{j.id, j.seedHash, j.id, formatTarget(j.fruitTarget), formatTarget(j.blockTarget), clean},
+	})
+}
+
+// send writes a single message to the miner, dropping the connection if the
+// write fails.
+func (sess *session) send(msg interface{}) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	sess.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := sess.enc.Encode(msg); err != nil {
+		log.Debug("Stratum write failed", "addr", sess.conn.RemoteAddr(), "err", err)
+		sess.conn.Close()
+	}
+}
+
+// close drops the miner connection.
+func (sess *session) close() {
+	sess.conn.Close()
+}
+
+// formatTarget encodes a target the same way as ice_getWork does.
+func formatTarget(target *big.Int) string {
+	return fmt.Sprintf("0x%032x", target)
+}
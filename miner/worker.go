@@ -133,6 +133,8 @@ type worker struct {
 	fruitOnly bool   // only miner fruit
 	publickey []byte // for publickey
 
+	instantSeal bool // seal a snail block as soon as enough fruits are pending
+
 	currentMu sync.Mutex
 	current   *Work
 
@@ -228,6 +230,15 @@ func (w *worker) SetFruitOnly(FruitOnly bool) {
 	w.fruitOnly = FruitOnly
 }
 
+// SetInstantSeal makes the worker seal a snail block as soon as the minimum
+// number of fruits is pending, without waiting for the fruit time interval.
+func (w *worker) SetInstantSeal(instant bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.instantSeal = instant
+}
+
 func (w *worker) setExtra(extra []byte) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -812,7 +823,7 @@ func (w *worker) CommitFruits(fruits []*types.SnailBlock, bc *chain.SnailBlockCh
 			}
 			currentFastNumber.Add(currentFastNumber, common.Big1)
 		}
-		if len(fruitset) >= params.MaximumFruits {
+		if len(fruitset) >= params.MaximumFruits || (w.instantSeal && len(fruitset) >= params.MinimumFruits) {
 			w.current.fruits = fruitset
 			return nil
 		}
@@ -17,6 +17,7 @@
 package node
 
 import (
+	"net/http"
 	"reflect"
 
 	"github.com/iceming123/go-ice/accounts"
@@ -96,3 +97,12 @@ type Service interface {
 	// are all terminated.
 	Stop() error
 }
+
+// HTTPService is a Service which serves additional HTTP paths on the HTTP RPC
+// endpoint of the node, next to the JSON-RPC API mounted at the root.
+type HTTPService interface {
+	Service
+
+	// HTTPHandlers retrieves the HTTP handlers of the service keyed by path.
+	HTTPHandlers() map[string]http.Handler
+}
@@ -144,11 +144,45 @@ var (
 			MinimumFruitDifficulty: big.NewInt(2),
 			DurationLimit:          big.NewInt(120),
 		}),
-		TIP3: &BlockConfig{FastNumber: big.NewInt(0)},
-		TIP5: &BlockConfig{SnailNumber: big.NewInt(0)},
-		TIP7: &BlockConfig{FastNumber: big.NewInt(0)},
-		TIP8: &BlockConfig{FastNumber: big.NewInt(0), CID: big.NewInt(-1)},
-		TIP9: &BlockConfig{FastNumber: big.NewInt(0), SnailNumber: big.NewInt(0)},
+		TIP3:  &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP5:  &BlockConfig{SnailNumber: big.NewInt(0)},
+		TIP7:  &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP8:  &BlockConfig{FastNumber: big.NewInt(0), CID: big.NewInt(-1)},
+		TIP9:  &BlockConfig{FastNumber: big.NewInt(0), SnailNumber: big.NewInt(0)},
+		TIP10: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP11: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP12: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP13: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP14: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP15: &BlockConfig{FastNumber: big.NewInt(0)},
+
+		// the members signing less than half of the epoch are not elected after TIP10
+		MinUptime: DefaultMinUptimeForElection,
+	}
+
+	// DeveloperChainConfig contains the chain parameters of the developer mode chain,
+	// keeping the snail chain mining as TIP9 never activates.
+	DeveloperChainConfig = &ChainConfig{
+		ChainID: big.NewInt(1337),
+		Minerva: &(MinervaConfig{
+			MinimumDifficulty:      big.NewInt(200),
+			MinimumFruitDifficulty: big.NewInt(2),
+			DurationLimit:          big.NewInt(120),
+		}),
+		TIP3:  &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP5:  &BlockConfig{SnailNumber: big.NewInt(0)},
+		TIP7:  &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP8:  &BlockConfig{FastNumber: big.NewInt(0), CID: big.NewInt(-1)},
+		TIP9:  &BlockConfig{FastNumber: big.NewInt(math.MaxInt64), SnailNumber: big.NewInt(math.MaxInt64)},
+		TIP10: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP11: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP12: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP13: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP14: &BlockConfig{FastNumber: big.NewInt(0)},
+		TIP15: &BlockConfig{FastNumber: big.NewInt(0)},
+
+		// the members signing less than half of the epoch are not elected after TIP10
+		MinUptime: DefaultMinUptimeForElection,
 	}
 
 	// TestnetTrustedCheckpoint contains the light client trusted checkpoint for the Ropsten test network.
@@ -240,6 +274,26 @@ type ChainConfig struct {
 	TIP7 *BlockConfig `json:"tip7"`
 	TIP8 *BlockConfig `json:"tip8"`
 	TIP9 *BlockConfig `json:"tip9"`
+	// TIP10 tracks the committee uptime and excludes the offline members from election
+	TIP10 *BlockConfig `json:"tip10"`
+	// MinUptime is the share of the signed blocks of an epoch a committee
+	// member needs to be elected after TIP10, the base is 10000. It's fixed
+	// by the network config along with TIP10, zero takes
+	// DefaultMinUptimeForElection.
+	MinUptime uint64 `json:"-"`
+	// TIP11 enables the typed access list transactions and the cold/warm state
+	// access gas accounting (EIP-2718, EIP-2929 and EIP-2930)
+	TIP11 *BlockConfig `json:"tip11"`
+	// TIP12 enables the transferable receipts of the delegations in the
+	// staking precompile
+	TIP12 *BlockConfig `json:"tip12"`
+	// TIP13 enables the auto compound of the delegation rewards
+	TIP13 *BlockConfig `json:"tip13"`
+	// TIP14 enables the governance of the staking and election parameters
+	TIP14 *BlockConfig `json:"tip14"`
+	// TIP15 enables the slashing of the double sign evidences in the staking
+	// precompile
+	TIP15 *BlockConfig `json:"tip15"`
 
 	TIPStake *BlockConfig `json:"tipstake"`
 }
@@ -434,8 +488,8 @@ func (err *ConfigCompatError) Error() string {
 // Rules is a one time interface meaning that it shouldn't be used in between transition
 // phases.
 type Rules struct {
-	ChainID        *big.Int
-	IsTIP3, IsTIP7 bool
+	ChainID                 *big.Int
+	IsTIP3, IsTIP7, IsTIP11 bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -448,6 +502,7 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		ChainID: new(big.Int).Set(chainID),
 		IsTIP3:  c.IsTIP3(num),
 		IsTIP7:  c.IsTIP7(num),
+		IsTIP11: c.IsTIP11(num),
 	}
 }
 
@@ -492,3 +547,60 @@ func (c *ChainConfig) IsTIP9(num *big.Int) bool {
 	}
 	return isForked(c.TIP9.FastNumber, num)
 }
+
+// IsTIP10 returns whether num is either equal to the TIP10 fork block or greater.
+func (c *ChainConfig) IsTIP10(num *big.Int) bool {
+	if c.TIP10 == nil {
+		return false
+	}
+	return isForked(c.TIP10.FastNumber, num)
+}
+
+// MinUptimeForElection returns the share of the signed blocks of an epoch,
+// the base is 10000, below which a committee member is not elected again.
+func (c *ChainConfig) MinUptimeForElection() uint64 {
+	if c.MinUptime == 0 {
+		return DefaultMinUptimeForElection
+	}
+	return c.MinUptime
+}
+
+// IsTIP11 returns whether num is either equal to the TIP11 fork block or greater.
+func (c *ChainConfig) IsTIP11(num *big.Int) bool {
+	if c.TIP11 == nil {
+		return false
+	}
+	return isForked(c.TIP11.FastNumber, num)
+}
+
+// IsTIP12 returns whether num is either equal to the TIP12 fork block or greater.
+func (c *ChainConfig) IsTIP12(num *big.Int) bool {
+	if c.TIP12 == nil {
+		return false
+	}
+	return isForked(c.TIP12.FastNumber, num)
+}
+
+// IsTIP13 returns whether num is either equal to the TIP13 fork block or greater.
+func (c *ChainConfig) IsTIP13(num *big.Int) bool {
+	if c.TIP13 == nil {
+		return false
+	}
+	return isForked(c.TIP13.FastNumber, num)
+}
+
+// IsTIP14 returns whether num is either equal to the TIP14 fork block or greater.
+func (c *ChainConfig) IsTIP14(num *big.Int) bool {
+	if c.TIP14 == nil {
+		return false
+	}
+	return isForked(c.TIP14.FastNumber, num)
+}
+
+// IsTIP15 returns whether num is either equal to the TIP15 fork block or greater.
+func (c *ChainConfig) IsTIP15(num *big.Int) bool {
+	if c.TIP15 == nil {
+		return false
+	}
+	return isForked(c.TIP15.FastNumber, num)
+}
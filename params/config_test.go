@@ -17,6 +17,7 @@
 package params
 
 import (
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -48,3 +49,25 @@ func TestFork(t *testing.T) {
 	forked := isForked(Tip, cur)
 	fmt.Println("fork:", forked)
 }
+
+func TestMinUptimeForElection(t *testing.T) {
+	var config ChainConfig
+	if err := json.Unmarshal([]byte(`{"chainId": 19330}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if have := config.MinUptimeForElection(); have != DefaultMinUptimeForElection {
+		t.Errorf("default uptime mismatch: have %d, want %d", have, DefaultMinUptimeForElection)
+	}
+	// the threshold is fixed by the network, not by the node
+	if err := json.Unmarshal([]byte(`{"chainId": 19330, "minUptime": 2000}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	if have := config.MinUptimeForElection(); have != DefaultMinUptimeForElection {
+		t.Errorf("decoded uptime mismatch: have %d, want %d", have, DefaultMinUptimeForElection)
+	}
+	for _, c := range []*ChainConfig{SingleNodeChainConfig, DeveloperChainConfig} {
+		if c.TIP10 != nil && c.MinUptime == 0 {
+			t.Errorf("chain %v enables TIP10 without the uptime threshold", c.ChainID)
+		}
+	}
+}
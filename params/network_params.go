@@ -54,4 +54,13 @@ const (
 	// Eventually this can be merged back with the client version, but that requires a
 	// full database upgrade, so that should be left for a suitable moment.
 	CHTFrequencyServer = 4096
+
+	// FastImmutabilityThreshold is the number of fast blocks after which a block
+	// is considered immutable and moved out of the key-value store into the
+	// ancient freezer.
+	FastImmutabilityThreshold uint64 = 90000
+
+	// SnailImmutabilityThreshold is the number of snail blocks after which a
+	// block is considered immutable and moved into the ancient freezer.
+	SnailImmutabilityThreshold uint64 = 2048
 )
@@ -68,6 +68,13 @@ const (
 	SstoreCleanRefundEIP2200 uint64 = 4200  // Once per SSTORE operation for resetting to the original non-zero value
 	SstoreClearRefundEIP2200 uint64 = 15000 // Once per SSTORE operation for clearing an originally existing storage slot
 
+	ColdAccountAccessCostEIP2929 uint64 = 2600 // Cost of the first access of an account within a transaction
+	ColdSloadCostEIP2929         uint64 = 2100 // Cost of the first access of a storage slot within a transaction
+	WarmStorageReadCostEIP2929   uint64 = 100  // Cost of accessing an already accessed account or storage slot
+
+	TxAccessListAddressGas    uint64 = 2400 // Per address specified in EIP 2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in EIP 2930 access list
+
 	JumpdestGas   uint64 = 1     // Once per JUMPDEST operation.
 	EpochDuration uint64 = 30000 // Duration between proof-of-work epochs.		EpochDuration uint64 = 30000 // Duration between proof-of-work epochs.
 
@@ -192,13 +199,20 @@ var (
 )
 
 var (
-	CountInEpoch                      = 20
-	MaxRedeemHeight            uint64 = 250000 // about 15 days
-	NewEpochLength             uint64 = 25000  // about 1.5 days
-	ElectionPoint              uint64 = 200
-	FirstNewEpochID            uint64 = 1
-	DposForkPoint              uint64 = 0
-	ElectionMinLimitForStaking        = new(big.Int).Mul(big.NewInt(200000), big.NewInt(1e18))
+	CountInEpoch                       = 20
+	MaxRedeemHeight             uint64 = 250000 // about 15 days
+	NewEpochLength              uint64 = 25000  // about 1.5 days
+	ElectionPoint               uint64 = 200
+	FirstNewEpochID             uint64 = 1
+	DposForkPoint               uint64 = 0
+	ElectionMinLimitForStaking         = new(big.Int).Mul(big.NewInt(200000), big.NewInt(1e18))
+	SlashRateForDoubleSign      uint64 = 1000 // 10% of the staking, the base is 10000
+	JailEpochForDoubleSign      uint64 = 2    // count of epochs the slashed account can't be elected
+	DefaultMinUptimeForElection uint64 = 5000 // 50% signed blocks in the epoch, the base is 10000
+	GovernanceQuorum            uint64 = 6667 // 2/3 of the validators approve a proposal, the base is 10000
+	GovernanceVotingEpochs      uint64 = 1    // count of epochs after the proposed one the proposal can be voted
+	GovernanceTimeLock          uint64 = 2    // count of epochs an approved proposal takes effect after
+	GovernanceProposalLimit            = 3    // count of the open proposals of a validator
 )
 var (
 	// 361 epoch begin=9000001,end=9025000
@@ -3,6 +3,7 @@ package params
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 )
 
@@ -27,6 +28,8 @@ var (
 	defaultConfigFileName = "config.toml"
 	defaultAddrBookName   = "addrbook.json"
 
+	defaultPrivValStateName = "priv_validator_state.json"
+
 	defaultConfigFilePath = filepath.Join(defaultConfigDir, defaultConfigFileName)
 	defaultAddrBookPath   = filepath.Join(defaultConfigDir, defaultAddrBookName)
 )
@@ -217,6 +220,9 @@ type ConsensusConfig struct {
 	WalPath string `mapstructure:"wal_file"`
 	walFile string // overrides WalPath if set
 
+	// File holding the last signed height/round/step of the local validator
+	PrivValidatorState string `mapstructure:"priv_validator_state_file"`
+
 	// All timeouts are in milliseconds
 	TimeoutPropose        int `mapstructure:"timeout_propose"`
 	TimeoutProposeDelta   int `mapstructure:"timeout_propose_delta"`
@@ -237,12 +243,16 @@ type ConsensusConfig struct {
 	// Reactor sleep duration parameters are in milliseconds
 	PeerGossipSleepDuration     int `mapstructure:"peer_gossip_sleep_duration"`
 	PeerQueryMaj23SleepDuration int `mapstructure:"peer_query_maj23_sleep_duration"`
+
+	// Switch the unhealthy committee members with the back ones
+	EnableHealthMgr bool `mapstructure:"enable_health_mgr"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service
 func DefaultConsensusConfig() *ConsensusConfig {
 	return &ConsensusConfig{
 		WalPath:                     filepath.Join(defaultDataDir, "cs.wal", "wal"),
+		PrivValidatorState:          filepath.Join(defaultDataDir, defaultPrivValStateName),
 		TimeoutPropose:              30000,
 		TimeoutProposeDelta:         5000,
 		TimeoutPrevote:              3000,
@@ -361,6 +371,27 @@ func (cfg *ConsensusConfig) SetWalFile(walFile string) {
 	cfg.walFile = walFile
 }
 
+// CommitteeWalFile returns the full path to the write-ahead log file of the
+// committee cid, or an empty string if the consensus has no root dir and
+// runs without a WAL
+func (cfg *ConsensusConfig) CommitteeWalFile(cid uint64) string {
+	if cfg.RootDir == "" && cfg.walFile == "" {
+		return ""
+	}
+	walFile := cfg.WalFile()
+	return filepath.Join(filepath.Dir(walFile), strconv.FormatUint(cid, 10), filepath.Base(walFile))
+}
+
+// PrivValidatorStateFile returns the full path to the last signed state of the
+// local validator, or an empty string if the consensus has no root dir and the
+// state is kept in memory only
+func (cfg *ConsensusConfig) PrivValidatorStateFile() string {
+	if cfg.RootDir == "" || cfg.PrivValidatorState == "" {
+		return ""
+	}
+	return rootify(cfg.PrivValidatorState, cfg.RootDir)
+}
+
 //-----------------------------------------------------------------------------
 // Utils
 
@@ -18,12 +18,14 @@ package rpc
 
 import (
 	"net"
+	"net/http"
 
 	"github.com/iceming123/go-ice/log"
 )
 
-// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
-func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string) (net.Listener, *Server, error) {
+// StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules.
+// The extra handlers are served at their paths next to the RPC API.
+func StartHTTPEndpoint(endpoint string, apis []API, handlers map[string]http.Handler, modules []string, cors []string, vhosts []string) (net.Listener, *Server, error) {
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -47,7 +49,7 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, handler).Serve(listener)
+	go newHTTPServer(cors, vhosts, handler, handlers).Serve(listener)
 	return listener, handler, err
 }
 
@@ -162,8 +162,23 @@ func (t *httpReadWriteNopCloser) Close() error {
 //
 // Deprecated: Server implements http.Handler
 func NewHTTPServer(cors []string, vhosts []string, srv *Server) *http.Server {
+	return newHTTPServer(cors, vhosts, srv, nil)
+}
+
+// newHTTPServer creates a new HTTP server around an API provider, serving the
+// extra handlers at their paths.
+func newHTTPServer(cors []string, vhosts []string, srv *Server, handlers map[string]http.Handler) *http.Server {
+	var handler http.Handler = srv
+	if len(handlers) > 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/", srv)
+		for path, h := range handlers {
+			mux.Handle(path, h)
+		}
+		handler = mux
+	}
 	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	handler = newCorsHandler(handler, cors)
 	handler = newVHostHandler(vhosts, handler)
 	return &http.Server{
 		Handler:      handler,
@@ -217,7 +232,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
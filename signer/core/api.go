@@ -30,6 +30,7 @@ import (
 	"github.com/iceming123/go-ice/accounts/usbwallet"
 	"github.com/iceming123/go-ice/common"
 	"github.com/iceming123/go-ice/common/hexutil"
+	"github.com/iceming123/go-ice/core/types"
 	"github.com/iceming123/go-ice/crypto"
 	"github.com/iceming123/go-ice/internal/iceapi"
 	"github.com/iceming123/go-ice/log"
@@ -135,6 +136,8 @@ type (
 		Transaction SendTxArgs `json:"transaction"`
 		Approved    bool       `json:"approved"`
 		Password    string     `json:"password"`
+		// PaymentPassword unlocks the payer account of a sponsored transaction
+		PaymentPassword string `json:"payment_password,omitempty"`
 	}
 	// ExportRequest info about query to export accounts
 	ExportRequest struct {
@@ -189,6 +192,12 @@ type (
 
 var ErrRequestDenied = errors.New("Request denied")
 
+// paymentSigner is implemented by the wallets able to sign a transaction as
+// its payer.
+type paymentSigner interface {
+	SignTx_PaymentWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
 // NewSignerAPI creates a new API that can be used for Account management.
 // ksLocation specifies the directory where to store the password protected private
 // key that is generated when a new Account is created.
@@ -308,6 +317,14 @@ func logDiff(original *SignTxRequest, new *SignTxResponse) bool {
 		modified = true
 		log.Info("Nonce changed by UI", "was", n0, "is", n1)
 	}
+	if f0, f1 := original.Transaction.Fee, new.Transaction.Fee; !reflect.DeepEqual(f0, f1) {
+		modified = true
+		log.Info("Fee changed by UI", "was", f0, "is", f1)
+	}
+	if p0, p1 := original.Transaction.Payment, new.Transaction.Payment; !reflect.DeepEqual(p0, p1) {
+		modified = true
+		log.Info("Payment-account changed by UI", "was", p0, "is", p1)
+	}
 	return modified
 }
 
@@ -355,7 +372,13 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args SendTxArgs, meth
 		api.UI.ShowError(err.Error())
 		return nil, err
 	}
-
+	// Sponsored transactions are additionally signed by the payer
+	if payment := result.Transaction.Payment; payment != nil {
+		if signedTx, err = api.signPayment(payment.Address(), result.PaymentPassword, signedTx); err != nil {
+			api.UI.ShowError(err.Error())
+			return nil, err
+		}
+	}
 	rlpdata, err := rlp.EncodeToBytes(signedTx)
 	response := iceapi.SignTransactionResult{Raw: rlpdata, Tx: signedTx}
 
@@ -366,6 +389,20 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args SendTxArgs, meth
 
 }
 
+// signPayment signs the transaction as its payer with the given payment account.
+func (api *SignerAPI) signPayment(payment common.Address, password string, tx *types.Transaction) (*types.Transaction, error) {
+	acc := accounts.Account{Address: payment}
+	wallet, err := api.am.Find(acc)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := wallet.(paymentSigner)
+	if !ok {
+		return nil, fmt.Errorf("payment account %v can not sign sponsored transactions", payment.Hex())
+	}
+	return signer.SignTx_PaymentWithPassphrase(acc, password, tx, api.chainID)
+}
+
 // Sign calculates an Ethereum ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message))
 //
@@ -52,16 +52,24 @@ func (ui *HeadlessUI) ApproveTx(request *SignTxRequest) (SignTxResponse, error)
 
 	switch <-ui.controller {
 	case "Y":
-		return SignTxResponse{request.Transaction, true, <-ui.controller}, nil
+		return ui.approveTx(request.Transaction), nil
 	case "M": //Modify
 		old := big.Int(request.Transaction.Value)
 		newVal := big.NewInt(0).Add(&old, big.NewInt(1))
 		request.Transaction.Value = hexutil.Big(*newVal)
-		return SignTxResponse{request.Transaction, true, <-ui.controller}, nil
+		return ui.approveTx(request.Transaction), nil
 	default:
-		return SignTxResponse{request.Transaction, false, ""}, nil
+		return SignTxResponse{Transaction: request.Transaction}, nil
 	}
 }
+
+func (ui *HeadlessUI) approveTx(tx SendTxArgs) SignTxResponse {
+	resp := SignTxResponse{Transaction: tx, Approved: true, Password: <-ui.controller}
+	if tx.Payment != nil {
+		resp.PaymentPassword = <-ui.controller
+	}
+	return resp
+}
 func (ui *HeadlessUI) ApproveSignData(request *SignDataRequest) (SignDataResponse, error) {
 	if "Y" == <-ui.controller {
 		return SignDataResponse{true, <-ui.controller}, nil
@@ -357,6 +365,55 @@ func TestSignTx(t *testing.T) {
 
 }
 
+func TestSignTxPayment(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	createAccount(control, api, t)
+	control <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, payer := list[0].Address, list[1].Address
+
+	tx := mkTestTx(common.NewMixedcaseAddress(from))
+	payment := common.NewMixedcaseAddress(payer)
+	fee := (hexutil.Big)(*big.NewInt(1000))
+	tx.Payment, tx.Fee = &payment, &fee
+
+	control <- "Y"
+	control <- "apassword"
+	control <- "wrongpassword"
+	res, err := api.SignTransaction(context.Background(), tx, nil)
+	if res != nil {
+		t.Errorf("Expected nil-response, got %v", res)
+	}
+	if err != keystore.ErrDecrypt {
+		t.Errorf("Expected ErrDecrypt! %v", err)
+	}
+
+	control <- "Y"
+	control <- "apassword"
+	control <- "apassword"
+	if res, err = api.SignTransaction(context.Background(), tx, nil); err != nil {
+		t.Fatal(err)
+	}
+	parsedTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(res.Raw, parsedTx); err != nil {
+		t.Fatal(err)
+	}
+	signer := types.NewTIP1Signer(big.NewInt(1))
+	if sender, err := types.Sender(signer, parsedTx); err != nil || sender != from {
+		t.Errorf("sender mismatch: have %x, want %x (err %v)", sender, from, err)
+	}
+	if have, err := types.Payer(signer, parsedTx); err != nil || have != payer {
+		t.Errorf("payer mismatch: have %x, want %x (err %v)", have, payer, err)
+	}
+	if parsedTx.Fee().Cmp(fee.ToInt()) != 0 {
+		t.Errorf("fee mismatch: have %v, want %v", parsedTx.Fee(), fee.ToInt())
+	}
+}
+
 /*
 func TestAsyncronousResponses(t *testing.T){
 
@@ -113,6 +113,12 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 	}
 	fmt.Printf("from:  %v\n", request.Transaction.From.String())
 	fmt.Printf("value: %v wei\n", weival)
+	if fee := request.Transaction.Fee; fee != nil {
+		fmt.Printf("fee:   %v wei\n", fee.ToInt())
+	}
+	if payment := request.Transaction.Payment; payment != nil {
+		fmt.Printf("payer: %v\n", payment.String())
+	}
 	if request.Transaction.Data != nil {
 		d := *request.Transaction.Data
 		if len(d) > 0 {
@@ -131,9 +137,13 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 	showMetadata(request.Meta)
 	fmt.Printf("-------------------------------------------\n")
 	if !ui.confirm() {
-		return SignTxResponse{request.Transaction, false, ""}, nil
+		return SignTxResponse{Transaction: request.Transaction}, nil
+	}
+	resp := SignTxResponse{Transaction: request.Transaction, Approved: true, Password: ui.readPassword()}
+	if request.Transaction.Payment != nil {
+		resp.PaymentPassword = ui.readPasswordText("password of the payer")
 	}
-	return SignTxResponse{request.Transaction, true, ui.readPassword()}, nil
+	return resp, nil
 }
 
 // ApproveSignData prompt the user for confirmation to request to sign data
@@ -71,6 +71,9 @@ type SendTxArgs struct {
 	// We accept "data" and "input" for backwards-compatibility reasons.
 	Data  *hexutil.Bytes `json:"data"`
 	Input *hexutil.Bytes `json:"input"`
+	// Fee and Payment are set for transactions sponsored by a payer.
+	Fee     *hexutil.Big             `json:"fee,omitempty"`
+	Payment *common.MixedcaseAddress `json:"payment,omitempty"`
 }
 
 func (args SendTxArgs) String() string {
@@ -88,8 +91,12 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Input != nil {
 		input = *args.Input
 	}
+	var payment common.Address
+	if args.Payment != nil {
+		payment = args.Payment.Address()
+	}
 	if args.To == nil {
-		return types.NewContractCreation(uint64(args.Nonce), (*big.Int)(&args.Value), uint64(args.Gas), (*big.Int)(&args.GasPrice), input)
+		return types.NewContractCreation_Payment(uint64(args.Nonce), (*big.Int)(&args.Value), (*big.Int)(args.Fee), uint64(args.Gas), (*big.Int)(&args.GasPrice), input, payment)
 	}
-	return types.NewTransaction(uint64(args.Nonce), args.To.Address(), (*big.Int)(&args.Value), (uint64)(args.Gas), (*big.Int)(&args.GasPrice), input)
+	return types.NewTransaction_Payment(uint64(args.Nonce), args.To.Address(), (*big.Int)(&args.Value), (*big.Int)(args.Fee), (uint64)(args.Gas), (*big.Int)(&args.GasPrice), input, payment)
 }
@@ -155,6 +155,14 @@ func (v *Validator) validate(msgs *ValidationMessages, txargs *SendTxArgs, metho
 		// Validate calldata
 		v.validateCallData(msgs, data, methodSelector)
 	}
+	if txargs.Payment != nil {
+		if !txargs.Payment.ValidChecksum() {
+			msgs.warn("Invalid checksum on payment-address")
+		}
+		if txargs.Payment.Address() == txargs.From.Address() {
+			msgs.warn("Tx payer is the sender itself")
+		}
+	}
 	return nil
 }
 
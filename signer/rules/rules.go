@@ -148,12 +148,15 @@ func (r *rulesetUI) ApproveTx(request *core.SignTxRequest) (core.SignTxResponse,
 	}
 
 	if approved {
-		return core.SignTxResponse{
-				Transaction: request.Transaction,
-				Approved:    true,
-				Password:    r.lookupPassword(request.Transaction.From.Address()),
-			},
-			nil
+		resp := core.SignTxResponse{
+			Transaction: request.Transaction,
+			Approved:    true,
+			Password:    r.lookupPassword(request.Transaction.From.Address()),
+		}
+		if payment := request.Transaction.Payment; payment != nil {
+			resp.PaymentPassword = r.lookupPassword(payment.Address())
+		}
+		return resp, nil
 	}
 	return core.SignTxResponse{Approved: false}, err
 }
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	crand "crypto/rand"
 	mrand "math/rand"
+	"sort"
 	"testing"
 	"time"
 
@@ -216,3 +217,133 @@ func randBytes(n int) []byte {
 	crand.Read(r)
 	return r
 }
+
+type entrySlice []*kv
+
+func (p entrySlice) Len() int           { return len(p) }
+func (p entrySlice) Less(i, j int) bool { return bytes.Compare(p[i].k, p[j].k) < 0 }
+func (p entrySlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+func sortedEntries(vals map[string]*kv) entrySlice {
+	var entries entrySlice
+	for _, kv := range vals {
+		entries = append(entries, kv)
+	}
+	sort.Sort(entries)
+	return entries
+}
+
+// Tests that random ranges of the trie can be proven with the two edge proofs,
+// including ranges starting and ending at non-existent keys.
+func TestRangeProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+
+	for i := 0; i < 500; i++ {
+		start := mrand.Intn(len(entries))
+		end := mrand.Intn(len(entries)-start) + start + 1
+
+		proof := icedb.NewMemDatabase()
+		if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		var keys, values [][]byte
+		for i := start; i < end; i++ {
+			keys = append(keys, entries[i].k)
+			values = append(values, entries[i].v)
+		}
+		more, err := VerifyRangeProof(trie.Hash(), keys[0], keys[len(keys)-1], keys, values, proof)
+		if err != nil {
+			t.Fatalf("Case %d(%d->%d) expect no error, got %v", i, start, end-1, err)
+		}
+		if more != (end < len(entries)) {
+			t.Fatalf("Case %d(%d->%d) more elements mismatch: have %v", i, start, end-1, more)
+		}
+	}
+	// Ranges starting from the zero key prove the absence of earlier leaves
+	proof := icedb.NewMemDatabase()
+	first := make([]byte, 32)
+	trie.Prove(first, 0, proof)
+	trie.Prove(entries[9].k, 0, proof)
+
+	var keys, values [][]byte
+	for i := 0; i < 10; i++ {
+		keys = append(keys, entries[i].k)
+		values = append(values, entries[i].v)
+	}
+	if bytes.Equal(keys[0], first) {
+		return // Zero key is part of the trie, nothing to test
+	}
+	if _, err := VerifyRangeProof(trie.Hash(), first, keys[len(keys)-1], keys, values, proof); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+// Tests that ranges with gaps or modified entries are rejected.
+func TestBadRangeProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+
+	for i := 0; i < 500; i++ {
+		start := mrand.Intn(len(entries) - 3)
+		end := mrand.Intn(len(entries)-start-3) + start + 3
+
+		proof := icedb.NewMemDatabase()
+		trie.Prove(entries[start].k, 0, proof)
+		trie.Prove(entries[end-1].k, 0, proof)
+
+		var keys, values [][]byte
+		for i := start; i < end; i++ {
+			keys = append(keys, entries[i].k)
+			values = append(values, entries[i].v)
+		}
+		first, last := keys[0], keys[len(keys)-1]
+		switch mrand.Intn(2) {
+		case 0:
+			// Drop an inner entry
+			index := mrand.Intn(len(keys)-2) + 1
+			keys = append(keys[:index], keys[index+1:]...)
+			values = append(values[:index], values[index+1:]...)
+		case 1:
+			// Modify a value
+			index := mrand.Intn(len(keys))
+			values[index] = randBytes(20)
+		}
+		if _, err := VerifyRangeProof(trie.Hash(), first, last, keys, values, proof); err == nil {
+			t.Fatalf("Case %d(%d->%d) expect error, got nil", i, start, end-1)
+		}
+	}
+}
+
+// Tests that the whole trie can be verified without any edge proof and that a
+// single non-existent proof proves an empty range at the end of the trie.
+func TestAllElementsRangeProof(t *testing.T) {
+	trie, vals := randomTrie(4096)
+	entries := sortedEntries(vals)
+
+	var keys, values [][]byte
+	for _, entry := range entries {
+		keys = append(keys, entry.k)
+		values = append(values, entry.v)
+	}
+	if _, err := VerifyRangeProof(trie.Hash(), nil, nil, keys, values, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	last := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff").Bytes()
+	if bytes.Equal(entries[len(entries)-1].k, last) {
+		return
+	}
+	proof := icedb.NewMemDatabase()
+	trie.Prove(last, 0, proof)
+	if _, err := VerifyRangeProof(trie.Hash(), last, last, nil, nil, proof); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	proof = icedb.NewMemDatabase()
+	trie.Prove(entries[len(entries)-2].k, 0, proof)
+	if _, err := VerifyRangeProof(trie.Hash(), entries[len(entries)-2].k, entries[len(entries)-2].k, nil, nil, proof); err == nil {
+		t.Fatalf("Expected error for non-empty tail")
+	}
+}